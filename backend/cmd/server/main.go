@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // so TIMEZONE resolves on hosts without a zoneinfo database
 
 	"github.com/gin-gonic/gin"
 
@@ -17,16 +19,33 @@ import (
 	"oa-system/internal/model"
 	"oa-system/internal/service"
 	"oa-system/migrations"
+	"oa-system/pkg/emailer"
 	"oa-system/pkg/jwt"
+	"oa-system/pkg/pdf"
 )
 
+// version is the build version, overridden at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
-	log.Println("OA System starting...")
+	log.Printf("OA System %s starting...", version)
 
 	// Load configuration
 	cfg := config.Load()
 	log.Printf("Database config: %s:%s@%s:%s/%s", cfg.Database.User, "***", cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName)
 
+	// Reckon calendar days in the configured time zone rather than the server's
+	location, err := cfg.Server.Location()
+	if err != nil {
+		log.Fatalf("Invalid TIMEZONE %q: %v", cfg.Server.Timezone, err)
+	}
+	service.SetTimezone(location)
+	log.Printf("Using time zone %s", location)
+
+	if !cfg.Salary.ValidRounding() {
+		log.Fatalf("Invalid SALARY_ROUNDING %q: expected %s or %s", cfg.Salary.Rounding, config.SalaryRoundingHalfUp, config.SalaryRoundingHalfEven)
+	}
+
 	// Initialize database
 	if err := model.InitDB(&cfg.Database); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -38,7 +57,7 @@ func main() {
 	}
 
 	// Seed initial data
-	if err := migrations.SeedDatabase(model.GetDB()); err != nil {
+	if err := migrations.SeedDatabase(model.GetDB(), cfg.Database.SeedDemo); err != nil {
 		log.Fatalf("Failed to seed database: %v", err)
 	}
 
@@ -46,33 +65,92 @@ func main() {
 
 	// Initialize JWT manager
 	jwtManager := jwt.NewJWTManager(cfg.JWT.Secret, cfg.JWT.ExpireHour)
+	jwtManager.SetIssuerAndAudience(cfg.JWT.Issuer, cfg.JWT.Audience, cfg.JWT.AcceptLegacyTokens)
+
+	// Initialize PDF generator
+	pdfGenerator := pdf.NewGenerator(cfg.Contract.PDFFontPath)
+
+	// Initialize the notification email queue; mail is discarded when SMTP is not configured
+	var mailSender emailer.Sender = emailer.NopSender{}
+	if cfg.Email.SMTPHost != "" {
+		mailSender = emailer.NewSMTPSender(emailer.SMTPConfig{
+			Host:     cfg.Email.SMTPHost,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.SMTPUsername,
+			Password: cfg.Email.SMTPPassword,
+			From:     cfg.Email.From,
+		})
+	} else {
+		log.Println("SMTP_HOST not set, notification emails will not be sent")
+	}
+	mailQueue := emailer.NewQueue(mailSender, cfg.Email.QueueSize)
+	service.SetNotificationMailer(mailQueue)
 
 	// Initialize services
 	authService := service.NewAuthService(model.GetDB(), jwtManager)
-	employeeService := service.NewEmployeeService(model.GetDB())
-	attendanceService := service.NewAttendanceService(model.GetDB())
-	leaveService := service.NewLeaveService(model.GetDB())
-	deviceService := service.NewDeviceService(model.GetDB())
-	meetingRoomService := service.NewMeetingRoomService(model.GetDB())
-	contractService := service.NewContractService(model.GetDB())
-	salaryService := service.NewSalaryService(model.GetDB())
+	employeeService := service.NewEmployeeService(model.GetDB(), &cfg.Avatar, &cfg.Booking)
+	employeeService.SetRevokeTokensOnDisable(cfg.JWT.RevokeOnDisable)
+	attendanceService := service.NewAttendanceService(model.GetDB(), &cfg.Attendance)
+	leaveService := service.NewLeaveService(model.GetDB(), &cfg.Leave)
+	attachmentService := service.NewAttachmentService(model.GetDB(), &cfg.Attachment)
+	deviceService := service.NewDeviceService(model.GetDB(), &cfg.Device)
+	meetingRoomService := service.NewMeetingRoomService(model.GetDB(), &cfg.Booking)
+	contractService := service.NewContractService(model.GetDB(), pdfGenerator, &cfg.Contract)
+	onboardingService := service.NewOnboardingService(model.GetDB(), pdfGenerator, &cfg.Avatar, &cfg.Contract, &cfg.Device, &cfg.Booking)
+	onboardingService.SetRevokeTokensOnDisable(cfg.JWT.RevokeOnDisable)
+	salaryService := service.NewSalaryService(model.GetDB(), pdfGenerator, &cfg.Salary)
+	dashboardService := service.NewDashboardService(model.GetDB(), attendanceService, leaveService)
+	holidayService := service.NewHolidayService(model.GetDB())
+	workScheduleService := service.NewWorkScheduleService(model.GetDB())
+	departmentService := service.NewDepartmentService(model.GetDB())
+	notificationService := service.NewNotificationService(model.GetDB())
+	auditService := service.NewAuditService(model.GetDB())
+	roleService := service.NewRoleService(model.GetDB(), middleware.IsKnownPermission)
+
+	// Resolve role permissions from the database instead of the built-in map
+	middleware.SetRolePermissionLoader(roleService.LoadPermissionMap)
+
+	// Serve account status checks from memory between database reloads
+	middleware.SetAccountCacheTTL(time.Duration(cfg.JWT.AccountCacheTTLSeconds) * time.Second)
+
+	// Replay responses to retried create requests carrying an Idempotency-Key
+	middleware.SetIdempotencyTTL(time.Duration(cfg.Server.IdempotencyTTLMinutes) * time.Minute)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
 	employeeHandler := handler.NewEmployeeHandler(employeeService)
 	attendanceHandler := handler.NewAttendanceHandler(attendanceService)
-	leaveHandler := handler.NewLeaveHandler(leaveService)
+	leaveHandler := handler.NewLeaveHandler(leaveService, attachmentService)
 	deviceHandler := handler.NewDeviceHandler(deviceService)
 	meetingRoomHandler := handler.NewMeetingRoomHandler(meetingRoomService)
 	contractHandler := handler.NewContractHandler(contractService)
+	onboardingHandler := handler.NewOnboardingHandler(onboardingService)
 	salaryHandler := handler.NewSalaryHandler(salaryService)
+	dashboardHandler := handler.NewDashboardHandler(dashboardService)
+	healthHandler := handler.NewHealthHandler(model.GetDB(), version)
+	roleHandler := handler.NewRoleHandler(roleService)
+	holidayHandler := handler.NewHolidayHandler(holidayService)
+	workScheduleHandler := handler.NewWorkScheduleHandler(workScheduleService)
+	departmentHandler := handler.NewDepartmentHandler(departmentService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	// Start background jobs
+	stopJobs := make(chan struct{})
+	go runNoShowSweep(meetingRoomService, stopJobs)
+	go runContractExpirySweep(contractService, stopJobs)
+	go runMissingSignOutSweep(attendanceService, stopJobs)
 
 	// Setup Gin router
 	gin.SetMode(cfg.Server.Mode)
-	router := gin.Default()
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.RequestLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil))))
+	router.Use(gin.Recovery())
+	router.Use(middleware.RequestTimeout(time.Duration(cfg.Server.RequestTimeoutSeconds) * time.Second))
 
 	// Setup routes
-	setupRoutes(router, jwtManager, authHandler, employeeHandler, attendanceHandler, leaveHandler, deviceHandler, meetingRoomHandler, contractHandler, salaryHandler)
+	setupRoutes(router, jwtManager, authHandler, employeeHandler, attendanceHandler, leaveHandler, deviceHandler, meetingRoomHandler, contractHandler, onboardingHandler, salaryHandler, dashboardHandler, healthHandler, roleHandler, holidayHandler, auditHandler, workScheduleHandler, departmentHandler, notificationHandler)
 
 	// Start server with graceful shutdown
 	srv := &http.Server{
@@ -92,6 +170,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	close(stopJobs)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -99,10 +178,83 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Send the emails still queued by the final requests
+	mailQueue.Close()
+
 	log.Println("Server exited")
 }
 
-func setupRoutes(router *gin.Engine, jwtManager *jwt.JWTManager, authHandler *handler.AuthHandler, employeeHandler *handler.EmployeeHandler, attendanceHandler *handler.AttendanceHandler, leaveHandler *handler.LeaveHandler, deviceHandler *handler.DeviceHandler, meetingRoomHandler *handler.MeetingRoomHandler, contractHandler *handler.ContractHandler, salaryHandler *handler.SalaryHandler) {
+// runNoShowSweep periodically cancels meeting room bookings that were never checked in
+func runNoShowSweep(meetingRoomService *service.MeetingRoomService, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			cancelled, err := meetingRoomService.AutoCancelNoShows(now)
+			if err != nil {
+				log.Printf("Failed to cancel no-show bookings: %v", err)
+				continue
+			}
+			if cancelled > 0 {
+				log.Printf("Cancelled %d no-show booking(s)", cancelled)
+			}
+		}
+	}
+}
+
+// runContractExpirySweep periodically reminds HR about signed contracts nearing expiry
+func runContractExpirySweep(contractService *service.ContractService, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			reminded, err := contractService.SendExpiryReminders(now)
+			if err != nil {
+				log.Printf("Failed to send contract expiry reminders: %v", err)
+				continue
+			}
+			if reminded > 0 {
+				log.Printf("Sent expiry reminders for %d contract(s)", reminded)
+			}
+		}
+	}
+}
+
+// runMissingSignOutSweep periodically signs out attendance records left open on previous days
+func runMissingSignOutSweep(attendanceService *service.AttendanceService, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			closed, err := attendanceService.AutoCloseMissingSignOuts(now)
+			if err != nil {
+				log.Printf("Failed to close missing sign-outs: %v", err)
+				continue
+			}
+			if closed > 0 {
+				log.Printf("Auto-closed %d attendance record(s) missing a sign-out", closed)
+			}
+		}
+	}
+}
+
+func setupRoutes(router *gin.Engine, jwtManager *jwt.JWTManager, authHandler *handler.AuthHandler, employeeHandler *handler.EmployeeHandler, attendanceHandler *handler.AttendanceHandler, leaveHandler *handler.LeaveHandler, deviceHandler *handler.DeviceHandler, meetingRoomHandler *handler.MeetingRoomHandler, contractHandler *handler.ContractHandler, onboardingHandler *handler.OnboardingHandler, salaryHandler *handler.SalaryHandler, dashboardHandler *handler.DashboardHandler, healthHandler *handler.HealthHandler, roleHandler *handler.RoleHandler, holidayHandler *handler.HolidayHandler, auditHandler *handler.AuditHandler, workScheduleHandler *handler.WorkScheduleHandler, departmentHandler *handler.DepartmentHandler, notificationHandler *handler.NotificationHandler) {
+	// Health probes (unauthenticated, outside /api)
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+
 	api := router.Group("/api")
 
 	// Public routes (no authentication required)
@@ -120,20 +272,71 @@ func setupRoutes(router *gin.Engine, jwtManager *jwt.JWTManager, authHandler *ha
 		{
 			protectedAuth.POST("/change-password", authHandler.ChangePassword)
 			protectedAuth.GET("/me", authHandler.GetCurrentUser)
+			protectedAuth.GET("/permissions", authHandler.GetPermissions)
+		}
+
+		// Every route registered below also requires the initial password to have been changed
+		protected.Use(middleware.RequirePasswordChange())
+
+		// Dashboard routes
+		protected.GET("/dashboard", dashboardHandler.Get)
+		protected.GET("/me/requests", dashboardHandler.GetMyRequests)
+
+		// Notification routes
+		notifications := protected.Group("/notifications")
+		{
+			notifications.GET("/stream", middleware.WithoutRequestTimeout(), notificationHandler.Stream)
+			notifications.GET("/preferences", notificationHandler.GetPreferences)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+			notifications.DELETE("", notificationHandler.Clear)
+			notifications.DELETE("/:id", notificationHandler.Delete)
+		}
+
+		// Management routes are guarded by permissions so custom roles can be granted them; system
+		// settings (roles, holidays, work schedules, audit logs, employee numbering) stay super admin only
+
+		// Role routes (super admin only)
+		roles := protected.Group("/roles")
+		{
+			roles.GET("", middleware.RequireSuperAdmin(), roleHandler.List)
+			roles.POST("", middleware.RequireSuperAdmin(), roleHandler.Create)
+			roles.PUT("/:id/permissions", middleware.RequireSuperAdmin(), roleHandler.SetPermission)
 		}
 
 		// Employee routes
 		employees := protected.Group("/employees")
 		{
-			employees.GET("", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), employeeHandler.List)
+			employees.GET("", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.List)
 			employees.GET("/me", employeeHandler.GetMe)
+			employees.GET("/subordinates", employeeHandler.GetSubordinates)
+			employees.GET("/statistics", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.GetStatistics)
+			employees.GET("/next-number", middleware.RequireSuperAdmin(), employeeHandler.GetNextNumber)
+			employees.GET("/number-report", middleware.RequireSuperAdmin(), employeeHandler.GetNumberReport)
+			employees.POST("/me/avatar", employeeHandler.UploadAvatar)
 			employees.GET("/:id", employeeHandler.GetByID)
-			employees.POST("", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), employeeHandler.Create)
+			employees.POST("", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.Create)
 			employees.PUT("/:id", employeeHandler.Update)
-			employees.PUT("/:id/role", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), employeeHandler.UpdateRole)
-			employees.PUT("/:id/supervisor", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), employeeHandler.UpdateSupervisor)
-			employees.PUT("/:id/status", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), employeeHandler.UpdateStatus)
-			employees.DELETE("/:id", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), employeeHandler.Delete)
+			employees.PUT("/:id/role", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.UpdateRole)
+			employees.PUT("/:id/supervisor", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.UpdateSupervisor)
+			employees.GET("/:id/supervisor-history", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.GetSupervisorHistory)
+			employees.PUT("/:id/delegate", employeeHandler.UpdateDelegate)
+			employees.PUT("/:id/status", middleware.RequireSuperAdminOrPermission(middleware.PermManageAccounts), employeeHandler.UpdateStatus)
+			employees.POST("/:id/offboard", middleware.RequireSuperAdminOrPermission(middleware.PermManageAccounts), onboardingHandler.Offboard)
+			employees.DELETE("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.Delete)
+			employees.POST("/:id/restore", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.Restore)
+			employees.GET("/:id/avatar", employeeHandler.GetAvatar)
+			employees.POST("/:id/avatar", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), employeeHandler.UploadAvatar)
+		}
+
+		// Department routes
+		departments := protected.Group("/departments")
+		{
+			departments.GET("", departmentHandler.List)
+			departments.GET("/:id", departmentHandler.GetByID)
+			departments.GET("/:id/employees", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), departmentHandler.ListEmployees)
+			departments.POST("", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), departmentHandler.Create)
+			departments.PUT("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), departmentHandler.Update)
+			departments.DELETE("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), departmentHandler.Delete)
 		}
 
 		// Attendance routes
@@ -142,18 +345,32 @@ func setupRoutes(router *gin.Engine, jwtManager *jwt.JWTManager, authHandler *ha
 			attendance.POST("/sign-in", attendanceHandler.SignIn)
 			attendance.POST("/sign-out", attendanceHandler.SignOut)
 			attendance.GET("/today", attendanceHandler.GetTodayStatus)
+			attendance.GET("/team/today", middleware.RequireSuperAdminOrPermission(middleware.PermApproveLeave, middleware.PermManageEmployees), attendanceHandler.GetTeamToday)
 			attendance.GET("", attendanceHandler.GetMonthlyRecords)
+			attendance.GET("/summary", attendanceHandler.GetMonthlySummary)
+			attendance.GET("/export", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), attendanceHandler.Export)
+			attendance.GET("/perfect", middleware.RequireSuperAdminOrPermission(middleware.PermManageEmployees), attendanceHandler.GetPerfectAttendance)
+			attendance.GET("/heatmap", middleware.RequireSuperAdminOrPermission(middleware.PermApproveLeave, middleware.PermManageEmployees), attendanceHandler.GetHeatmap)
 		}
 
 		// Leave routes
 		leaves := protected.Group("/leaves")
 		{
-			leaves.POST("", leaveHandler.Create)
+			leaves.POST("", middleware.Idempotent(), leaveHandler.Create)
 			leaves.GET("", leaveHandler.GetMyLeaves)
-			leaves.GET("/pending", middleware.RequireRole(model.RoleSupervisor, model.RoleSuperAdmin), leaveHandler.GetPending)
-			leaves.PUT("/:id/approve", middleware.RequireRole(model.RoleSupervisor, model.RoleSuperAdmin), leaveHandler.Approve)
-			leaves.PUT("/:id/reject", middleware.RequireRole(model.RoleSupervisor, model.RoleSuperAdmin), leaveHandler.Reject)
+			leaves.GET("/calendar", leaveHandler.GetCalendar)
+			// Open to all roles so delegates can act; the service enforces who may approve
+			leaves.GET("/pending", leaveHandler.GetPending)
+			leaves.GET("/pending/count", leaveHandler.GetPendingCount)
+			leaves.PUT("/bulk-approve", leaveHandler.BulkApprove)
+			leaves.PUT("/bulk-reject", leaveHandler.BulkReject)
+			leaves.GET("/:id", leaveHandler.GetByID)
+			leaves.PUT("/:id", leaveHandler.Update)
+			leaves.PUT("/:id/approve", leaveHandler.Approve)
+			leaves.PUT("/:id/reject", leaveHandler.Reject)
 			leaves.PUT("/:id/cancel", leaveHandler.Cancel)
+			leaves.POST("/:id/attachments", leaveHandler.UploadAttachment)
+			leaves.GET("/:id/attachments", leaveHandler.ListAttachments)
 		}
 
 		// Device routes
@@ -161,72 +378,129 @@ func setupRoutes(router *gin.Engine, jwtManager *jwt.JWTManager, authHandler *ha
 		{
 			devices.GET("", deviceHandler.GetAllDevices)
 			devices.GET("/available", deviceHandler.GetAvailableDevices)
+			devices.GET("/low-stock", middleware.RequireSuperAdminOrPermission(middleware.PermManageDevices), deviceHandler.GetLowStockDevices)
 			devices.GET("/:id", deviceHandler.GetDevice)
-			devices.POST("", middleware.RequireRole(model.RoleDeviceAdmin, model.RoleSuperAdmin), deviceHandler.CreateDevice)
-			devices.PUT("/:id", middleware.RequireRole(model.RoleDeviceAdmin, model.RoleSuperAdmin), deviceHandler.UpdateDevice)
-			devices.DELETE("/:id", middleware.RequireRole(model.RoleDeviceAdmin, model.RoleSuperAdmin), deviceHandler.DeleteDevice)
+			devices.GET("/:id/qrcode", deviceHandler.GetDeviceQRCode)
+			devices.GET("/:id/history", middleware.RequireSuperAdminOrPermission(middleware.PermManageDevices), deviceHandler.GetDeviceHistory)
+			devices.POST("", middleware.RequireSuperAdminOrPermission(middleware.PermManageDevices), deviceHandler.CreateDevice)
+			devices.PUT("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageDevices), deviceHandler.UpdateDevice)
+			devices.DELETE("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageDevices), deviceHandler.DeleteDevice)
 		}
 
 		// Device request routes
 		deviceRequests := protected.Group("/device-requests")
 		{
-			deviceRequests.POST("", deviceHandler.CreateRequest)
+			deviceRequests.POST("", middleware.Idempotent(), deviceHandler.CreateRequest)
 			deviceRequests.GET("", deviceHandler.GetMyRequests)
-			deviceRequests.GET("/pending", middleware.RequireRole(model.RoleDeviceAdmin, model.RoleSuperAdmin), deviceHandler.GetPendingRequests)
-			deviceRequests.GET("/return-pending", middleware.RequireRole(model.RoleDeviceAdmin, model.RoleSuperAdmin), deviceHandler.GetReturnPendingRequests)
-			deviceRequests.PUT("/:id/approve", middleware.RequireRole(model.RoleDeviceAdmin, model.RoleSuperAdmin), deviceHandler.ApproveRequest)
-			deviceRequests.PUT("/:id/reject", middleware.RequireRole(model.RoleDeviceAdmin, model.RoleSuperAdmin), deviceHandler.RejectRequest)
+			deviceRequests.GET("/all", middleware.RequireSuperAdminOrPermission(middleware.PermApproveDeviceRequest), deviceHandler.ListRequests)
+			deviceRequests.GET("/pending", middleware.RequireSuperAdminOrPermission(middleware.PermApproveDeviceRequest), deviceHandler.GetPendingRequests)
+			deviceRequests.GET("/return-pending", middleware.RequireSuperAdminOrPermission(middleware.PermConfirmDeviceReturn), deviceHandler.GetReturnPendingRequests)
+			deviceRequests.GET("/:id", deviceHandler.GetRequest)
+			deviceRequests.PUT("/:id/approve", middleware.RequireSuperAdminOrPermission(middleware.PermApproveDeviceRequest), deviceHandler.ApproveRequest)
+			deviceRequests.PUT("/:id/reject", middleware.RequireSuperAdminOrPermission(middleware.PermApproveDeviceRequest), deviceHandler.RejectRequest)
+			deviceRequests.PUT("/:id/transfer", middleware.RequireSuperAdminOrPermission(middleware.PermApproveDeviceRequest), deviceHandler.TransferRequest)
 			deviceRequests.PUT("/:id/collect", deviceHandler.CollectDevice)
 			deviceRequests.PUT("/:id/return", deviceHandler.InitiateReturn)
-			deviceRequests.PUT("/:id/confirm-return", middleware.RequireRole(model.RoleDeviceAdmin, model.RoleSuperAdmin), deviceHandler.ConfirmReturn)
+			deviceRequests.PUT("/:id/confirm-return", middleware.RequireSuperAdminOrPermission(middleware.PermConfirmDeviceReturn), deviceHandler.ConfirmReturn)
 			deviceRequests.PUT("/:id/cancel", deviceHandler.CancelRequest)
+			deviceRequests.PUT("/cancel-all", deviceHandler.CancelAllRequests)
 		}
 
 		// Meeting room routes
 		meetingRooms := protected.Group("/meeting-rooms")
 		{
 			meetingRooms.GET("", meetingRoomHandler.GetAllMeetingRooms)
+			meetingRooms.GET("/availability", meetingRoomHandler.GetAvailabilityRange)
 			meetingRooms.GET("/:id", meetingRoomHandler.GetMeetingRoom)
 			meetingRooms.GET("/:id/availability", meetingRoomHandler.GetRoomAvailability)
-			meetingRooms.POST("", middleware.RequireRole(model.RoleSuperAdmin), meetingRoomHandler.CreateMeetingRoom)
-			meetingRooms.PUT("/:id", middleware.RequireRole(model.RoleSuperAdmin), meetingRoomHandler.UpdateMeetingRoom)
-			meetingRooms.DELETE("/:id", middleware.RequireRole(model.RoleSuperAdmin), meetingRoomHandler.DeleteMeetingRoom)
+			meetingRooms.GET("/:id/free-slots", meetingRoomHandler.GetFreeSlots)
+			meetingRooms.POST("", middleware.RequireSuperAdminOrPermission(middleware.PermManageMeetingRooms), meetingRoomHandler.CreateMeetingRoom)
+			meetingRooms.PUT("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageMeetingRooms), meetingRoomHandler.UpdateMeetingRoom)
+			meetingRooms.DELETE("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageMeetingRooms), meetingRoomHandler.DeleteMeetingRoom)
 		}
 
 		// Meeting room booking routes
 		meetingRoomBookings := protected.Group("/meeting-room-bookings")
 		{
-			meetingRoomBookings.POST("", meetingRoomHandler.CreateBooking)
+			meetingRoomBookings.POST("", middleware.Idempotent(), meetingRoomHandler.CreateBooking)
+			meetingRoomBookings.POST("/for", middleware.RequireSuperAdminOrPermission(middleware.PermBookOnBehalf), middleware.Idempotent(), meetingRoomHandler.CreateBookingFor)
 			meetingRoomBookings.GET("", meetingRoomHandler.GetMyBookings)
+			meetingRoomBookings.POST("/waitlist", middleware.Idempotent(), meetingRoomHandler.JoinWaitlist)
+			meetingRoomBookings.GET("/waitlist", meetingRoomHandler.GetMyWaitlist)
+			meetingRoomBookings.DELETE("/waitlist/:id", meetingRoomHandler.LeaveWaitlist)
+			meetingRoomBookings.GET("/:id", meetingRoomHandler.GetBooking)
+			meetingRoomBookings.PUT("/:id", meetingRoomHandler.UpdateBooking)
 			meetingRoomBookings.PUT("/:id/complete", meetingRoomHandler.CompleteBooking)
 			meetingRoomBookings.PUT("/:id/cancel", meetingRoomHandler.CancelBooking)
+			meetingRoomBookings.PUT("/:id/check-in", meetingRoomHandler.CheckInBooking)
+		}
+
+		// Audit log routes
+		protected.GET("/audit-logs", middleware.RequireSuperAdmin(), auditHandler.List)
+
+		// Holiday calendar routes
+		holidays := protected.Group("/holidays")
+		{
+			holidays.GET("", holidayHandler.List)
+			holidays.GET("/:id", holidayHandler.GetByID)
+			holidays.POST("", middleware.RequireSuperAdmin(), holidayHandler.Create)
+			holidays.PUT("/:id", middleware.RequireSuperAdmin(), holidayHandler.Update)
+			holidays.DELETE("/:id", middleware.RequireSuperAdmin(), holidayHandler.Delete)
+		}
+
+		// Department work schedule routes
+		workSchedules := protected.Group("/work-schedules")
+		{
+			workSchedules.GET("", middleware.RequireSuperAdmin(), workScheduleHandler.List)
+			workSchedules.POST("", middleware.RequireSuperAdmin(), workScheduleHandler.Create)
+			workSchedules.PUT("/:id", middleware.RequireSuperAdmin(), workScheduleHandler.Update)
+			workSchedules.DELETE("/:id", middleware.RequireSuperAdmin(), workScheduleHandler.Delete)
 		}
 
 		// Contract template routes
 		contractTemplates := protected.Group("/contract-templates")
 		{
-			contractTemplates.GET("", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), contractHandler.ListTemplates)
-			contractTemplates.GET("/:id", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), contractHandler.GetTemplateByID)
+			contractTemplates.GET("", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.ListTemplates)
+			contractTemplates.GET("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.GetTemplateByID)
+			contractTemplates.POST("", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.CreateTemplate)
+			contractTemplates.PUT("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.UpdateTemplate)
+			contractTemplates.DELETE("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.DeleteTemplate)
 		}
 
 		// Contract routes
 		contracts := protected.Group("/contracts")
 		{
-			contracts.POST("", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), contractHandler.Create)
-			contracts.GET("", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), contractHandler.List)
+			contracts.POST("", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.Create)
+			contracts.GET("", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.List)
 			contracts.GET("/my", contractHandler.GetMyContracts)
+			contracts.GET("/my/pending", contractHandler.GetMyPending)
+			contracts.GET("/my/pending/count", contractHandler.GetMyPendingCount)
+			contracts.GET("/expiring", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.GetExpiring)
 			contracts.GET("/:id", contractHandler.GetByID)
+			contracts.GET("/:id/pdf", contractHandler.DownloadPDF)
+			contracts.PUT("/:id/approve", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.Approve)
 			contracts.PUT("/:id/sign", contractHandler.Sign)
-			contracts.DELETE("/:id", middleware.RequireRole(model.RoleHR, model.RoleSuperAdmin), contractHandler.Delete)
+			contracts.PUT("/:id/decline", contractHandler.Decline)
+			contracts.DELETE("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contractHandler.Delete)
 		}
 
+		// Onboarding routes
+		protected.POST("/onboarding", middleware.RequireSuperAdminOrPermission(middleware.PermManageAccounts), middleware.Idempotent(), onboardingHandler.Onboard)
+
 		// Salary routes
 		salaries := protected.Group("/salaries")
 		{
-			salaries.POST("", middleware.RequireRole(model.RoleFinance, model.RoleSuperAdmin), salaryHandler.Create)
-			salaries.GET("", middleware.RequireRole(model.RoleFinance, model.RoleSuperAdmin), salaryHandler.List)
+			salaries.POST("", middleware.RequireSuperAdminOrPermission(middleware.PermManageSalaries), salaryHandler.Create)
+			salaries.POST("/batch", middleware.RequireSuperAdminOrPermission(middleware.PermManageSalaries), salaryHandler.BatchCreate)
+			salaries.POST("/publish", middleware.RequireSuperAdminOrPermission(middleware.PermManageSalaries), salaryHandler.PublishMonth)
+			salaries.GET("", middleware.RequireSuperAdminOrPermission(middleware.PermViewAllSalaries), salaryHandler.List)
+			salaries.GET("/statistics", middleware.RequireSuperAdminOrPermission(middleware.PermViewAllSalaries), salaryHandler.GetStatistics)
 			salaries.GET("/my", salaryHandler.GetMy)
+			salaries.GET("/my/summary", salaryHandler.GetMySummary)
 			salaries.GET("/:id", salaryHandler.GetByID)
+			salaries.GET("/:id/payslip", salaryHandler.DownloadPayslip)
+			salaries.PUT("/:id", middleware.RequireSuperAdminOrPermission(middleware.PermManageSalaries), salaryHandler.Update)
+			salaries.PUT("/:id/publish", middleware.RequireSuperAdminOrPermission(middleware.PermManageSalaries), salaryHandler.Publish)
 		}
 	}
 }
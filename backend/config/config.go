@@ -3,61 +3,244 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Booking    BookingConfig
+	Contract   ContractConfig
+	Attachment AttachmentConfig
+	Avatar     AvatarConfig
+	Attendance AttendanceConfig
+	Leave      LeaveConfig
+	Device     DeviceConfig
+	Salary     SalaryConfig
+	Email      EmailConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	Port string
-	Mode string // debug, release, test
+	Port                  string
+	Mode                  string // debug, release, test
+	RequestTimeoutSeconds int    // deadline for a request's database work; 0 disables it
+	IdempotencyTTLMinutes int    // how long responses are replayed for a repeated Idempotency-Key
+	Timezone              string // IANA zone calendar days are reckoned in, e.g. Asia/Shanghai; Local uses the server's zone
 }
 
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
+	Driver     string // mysql or sqlite
+	Host       string
+	Port       string
+	User       string
+	Password   string
+	DBName     string
+	SQLitePath string // database file for the sqlite driver, or :memory: for a throwaway database
+	SeedDemo   bool   // also seed sample employees, devices, rooms and leaves; for dev and demo environments only
+
+	MaxOpenConns           int // upper bound on open connections
+	MaxIdleConns           int // connections kept open while idle
+	ConnMaxLifetimeMinutes int // connections older than this are closed and replaced
 }
 
 // JWTConfig holds JWT-related configuration
 type JWTConfig struct {
-	Secret     string
-	ExpireHour int
+	Secret                 string
+	ExpireHour             int
+	AccountCacheTTLSeconds int    // how long account status checks are served from memory; 0 disables the cache
+	Issuer                 string // iss claim set on and required of every token
+	Audience               string // aud claim set on and required of every token
+	AcceptLegacyTokens     bool   // accept tokens carrying neither iss nor aud, issued before they were introduced; only for a rollout window
+	RevokeOnDisable        bool   // disabling an account revokes every token issued to it so far, even once re-enabled
+}
+
+// BookingConfig holds meeting room booking configuration
+type BookingConfig struct {
+	CheckInGraceMinutes int    // minutes after start time within which the booker must check in
+	MinDurationMinutes  int    // shortest allowed booking
+	MaxDurationMinutes  int    // longest allowed booking
+	OpenTime            string // HH:MM at which meeting rooms open
+	CloseTime           string // HH:MM at which meeting rooms close
+	BlockDuringLeave    bool   // refuse bookings on days the employee is on approved leave
+}
+
+// ContractConfig holds contract-related configuration
+type ContractConfig struct {
+	PDFFontPath        string // TrueType font embedded in PDFs; empty uses the bundled font
+	ExpiryReminderDays int    // HR is reminded this many days before a signed contract expires
+}
+
+// AttachmentConfig holds file attachment configuration
+type AttachmentConfig struct {
+	StorageDir string // directory uploaded files are written to
+	MaxSizeMB  int    // maximum size of a single upload
+}
+
+// AvatarConfig holds employee profile photo storage configuration
+type AvatarConfig struct {
+	StorageDir string // directory profile photos are written to
+	MaxSizeMB  int    // maximum size of a single photo
+}
+
+// AttendanceConfig holds attendance-related configuration
+type AttendanceConfig struct {
+	WorkStartTime            string // HH:MM start of the working day; sign-ins after it count as late
+	WorkEndTime              string // HH:MM end of the working day; sign-outs after it count as overtime
+	OvertimeThresholdMinutes int    // overtime shorter than this is ignored
+}
+
+// LeaveConfig holds leave request configuration
+type LeaveConfig struct {
+	BackdateDays  int            // how many days before today a backdatable leave may start
+	BackdateTypes []string       // leave types that may start in the past, e.g. sick
+	MaxDays       map[string]int // most working days a single request of each type may span; unlisted types are unlimited
+
+	BlockOnMissingSignOuts  bool // refuse new leave from employees with too many missing sign-outs last month
+	MissingSignOutThreshold int  // missing sign-outs in the previous month at which new leave is refused; below 1 disables the rule
+}
+
+// DeviceConfig holds device inventory configuration
+type DeviceConfig struct {
+	LowStockThreshold int    // default available quantity at or below which a device counts as low on stock
+	QRLinkBase        string // frontend base URL that device QR codes link into
+}
+
+// SalaryConfig holds payroll configuration
+type SalaryConfig struct {
+	Currency string // ISO 4217 code salary amounts are paid in, returned with every salary payload
+	Rounding string // how amounts are rounded to whole cents: half_up or half_even
+}
+
+// Salary rounding modes
+const (
+	SalaryRoundingHalfUp   = "half_up"   // halves round away from zero
+	SalaryRoundingHalfEven = "half_even" // halves round to the even cent
+)
+
+// EmailConfig holds outgoing mail configuration; notification emails are discarded when SMTPHost is empty
+type EmailConfig struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string // leave empty for relays that do not require authentication
+	SMTPPassword string
+	From         string // sender address of notification emails
+	QueueSize    int    // emails waiting to be sent beyond this are dropped
 }
 
 // Load loads configuration from environment variables with defaults
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Mode: getEnv("GIN_MODE", "debug"),
+			Port:                  getEnv("SERVER_PORT", "8080"),
+			Mode:                  getEnv("GIN_MODE", "debug"),
+			RequestTimeoutSeconds: getEnvInt("REQUEST_TIMEOUT_SECONDS", 30),
+			IdempotencyTTLMinutes: getEnvInt("IDEMPOTENCY_TTL_MINUTES", 1440),
+			Timezone:              getEnv("TIMEZONE", "Local"),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "3306"),
-			User:     getEnv("DB_USER", "root"),
-			Password: getEnv("DB_PASSWORD", "root"),
-			DBName:   getEnv("DB_NAME", "oa"),
+			Driver:     getEnv("DB_DRIVER", "mysql"),
+			Host:       getEnv("DB_HOST", "localhost"),
+			Port:       getEnv("DB_PORT", "3306"),
+			User:       getEnv("DB_USER", "root"),
+			Password:   getEnv("DB_PASSWORD", "root"),
+			DBName:     getEnv("DB_NAME", "oa"),
+			SQLitePath: getEnv("DB_SQLITE_PATH", "oa.db"),
+			SeedDemo:   getEnvBool("DB_SEED_DEMO", false),
+
+			MaxOpenConns:           getEnvInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:           getEnvInt("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetimeMinutes: getEnvInt("DB_CONN_MAX_LIFETIME_MINUTES", 30),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "oa-system-secret-key"),
-			ExpireHour: getEnvInt("JWT_EXPIRE_HOUR", 24),
+			Secret:                 getEnv("JWT_SECRET", "oa-system-secret-key"),
+			ExpireHour:             getEnvInt("JWT_EXPIRE_HOUR", 24),
+			AccountCacheTTLSeconds: getEnvInt("AUTH_ACCOUNT_CACHE_TTL_SECONDS", 30),
+			Issuer:                 getEnv("JWT_ISSUER", "oa-system"),
+			Audience:               getEnv("JWT_AUDIENCE", "oa-system-api"),
+			AcceptLegacyTokens:     getEnvBool("JWT_ACCEPT_LEGACY_TOKENS", false),
+			RevokeOnDisable:        getEnvBool("JWT_REVOKE_ON_DISABLE", true),
+		},
+		Booking: BookingConfig{
+			CheckInGraceMinutes: getEnvInt("BOOKING_CHECKIN_GRACE_MINUTES", 15),
+			MinDurationMinutes:  getEnvInt("BOOKING_MIN_DURATION_MINUTES", 15),
+			MaxDurationMinutes:  getEnvInt("BOOKING_MAX_DURATION_MINUTES", 240),
+			OpenTime:            getEnv("BOOKING_OPEN_TIME", "08:00"),
+			CloseTime:           getEnv("BOOKING_CLOSE_TIME", "20:00"),
+			BlockDuringLeave:    getEnvBool("BOOKING_BLOCK_DURING_LEAVE", false),
+		},
+		Contract: ContractConfig{
+			PDFFontPath:        getEnv("CONTRACT_PDF_FONT_PATH", ""),
+			ExpiryReminderDays: getEnvInt("CONTRACT_EXPIRY_REMINDER_DAYS", 30),
+		},
+		Attachment: AttachmentConfig{
+			StorageDir: getEnv("ATTACHMENT_STORAGE_DIR", "uploads"),
+			MaxSizeMB:  getEnvInt("ATTACHMENT_MAX_SIZE_MB", 5),
+		},
+		Avatar: AvatarConfig{
+			StorageDir: getEnv("AVATAR_STORAGE_DIR", "uploads/avatars"),
+			MaxSizeMB:  getEnvInt("AVATAR_MAX_SIZE_MB", 2),
+		},
+		Attendance: AttendanceConfig{
+			WorkStartTime:            getEnv("ATTENDANCE_WORK_START_TIME", "09:00"),
+			WorkEndTime:              getEnv("ATTENDANCE_WORK_END_TIME", "18:00"),
+			OvertimeThresholdMinutes: getEnvInt("ATTENDANCE_OVERTIME_THRESHOLD_MINUTES", 15),
+		},
+		Leave: LeaveConfig{
+			BackdateDays:  getEnvInt("LEAVE_BACKDATE_DAYS", 7),
+			BackdateTypes: getEnvList("LEAVE_BACKDATE_TYPES", []string{"sick"}),
+			MaxDays:       getEnvIntMap("LEAVE_MAX_DAYS", map[string]int{"annual": 15, "sick": 30}),
+
+			BlockOnMissingSignOuts:  getEnvBool("LEAVE_BLOCK_ON_MISSING_SIGNOUTS", false),
+			MissingSignOutThreshold: getEnvInt("LEAVE_MISSING_SIGNOUT_THRESHOLD", 3),
+		},
+		Device: DeviceConfig{
+			LowStockThreshold: getEnvInt("DEVICE_LOW_STOCK_THRESHOLD", 1),
+			QRLinkBase:        getEnv("DEVICE_QR_LINK_BASE", "http://localhost:5173"),
+		},
+		Salary: SalaryConfig{
+			Currency: getEnv("SALARY_CURRENCY", "CNY"),
+			Rounding: getEnv("SALARY_ROUNDING", "half_up"),
+		},
+		Email: EmailConfig{
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnvInt("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("SMTP_FROM", "oa-system@localhost"),
+			QueueSize:    getEnvInt("EMAIL_QUEUE_SIZE", 100),
 		},
 	}
 }
 
 
+// Location loads the configured time zone
+func (c *ServerConfig) Location() (*time.Location, error) {
+	return time.LoadLocation(c.Timezone)
+}
+
+// ValidRounding reports whether the configured salary rounding mode is known
+func (c *SalaryConfig) ValidRounding() bool {
+	return c.Rounding == SalaryRoundingHalfUp || c.Rounding == SalaryRoundingHalfEven
+}
+
+// SQLiteDSN returns the SQLite Data Source Name
+// An in-memory database is shared across the pool's connections so every query sees the same data
+func (c *DatabaseConfig) SQLiteDSN() string {
+	if c.SQLitePath == ":memory:" {
+		return "file::memory:?cache=shared&_foreign_keys=on"
+	}
+	return "file:" + c.SQLitePath + "?_foreign_keys=on"
+}
+
 // DSN returns the MySQL Data Source Name
+// Timestamps are read and written in UTC; AutoMigrate converts databases written under loc=Local
 func (c *DatabaseConfig) DSN() string {
-	return c.User + ":" + c.Password + "@tcp(" + c.Host + ":" + c.Port + ")/" + c.DBName + "?charset=utf8mb4&parseTime=True&loc=Local"
+	return c.User + ":" + c.Password + "@tcp(" + c.Host + ":" + c.Port + ")/" + c.DBName + "?charset=utf8mb4&parseTime=True&loc=UTC"
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -77,3 +260,49 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvBool gets an environment variable as bool or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList gets a comma-separated environment variable or returns a default value
+// Blank entries are dropped, so an empty list can be configured with a lone comma
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvIntMap gets a comma-separated list of key:value pairs with integer values,
+// e.g. "annual:15,sick:30", or returns a default value. Malformed pairs are skipped
+func getEnvIntMap(key string, defaultValue map[string]int) map[string]int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	result := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		name, number, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		if intVal, err := strconv.Atoi(strings.TrimSpace(number)); err == nil {
+			result[strings.TrimSpace(name)] = intVal
+		}
+	}
+	return result
+}
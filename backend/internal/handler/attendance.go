@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -28,13 +29,9 @@ func NewAttendanceHandler(attendanceService *service.AttendanceService) *Attenda
 func (h *AttendanceHandler) SignIn(c *gin.Context) {
 	employeeID := middleware.GetUserID(c)
 
-	resp, err := h.attendanceService.SignIn(employeeID)
+	resp, err := h.attendanceService.SignIn(c.Request.Context(), employeeID)
 	if err != nil {
-		if errors.Is(err, service.ErrAlreadySignedIn) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code":    "ATTENDANCE_ALREADY_SIGNED_IN",
-				"message": "今日已签到",
-			})
+		if respondIfTimedOut(c, err) {
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -53,19 +50,17 @@ func (h *AttendanceHandler) SignIn(c *gin.Context) {
 func (h *AttendanceHandler) SignOut(c *gin.Context) {
 	employeeID := middleware.GetUserID(c)
 
-	resp, err := h.attendanceService.SignOut(employeeID)
+	resp, err := h.attendanceService.SignOut(c.Request.Context(), employeeID)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
 		switch {
 		case errors.Is(err, service.ErrNotSignedIn):
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    "ATTENDANCE_NOT_SIGNED_IN",
 				"message": "未签到不能签退",
 			})
-		case errors.Is(err, service.ErrAlreadySignedOut):
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code":    "ATTENDANCE_ALREADY_SIGNED_OUT",
-				"message": "今日已签退",
-			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -83,8 +78,11 @@ func (h *AttendanceHandler) SignOut(c *gin.Context) {
 func (h *AttendanceHandler) GetTodayStatus(c *gin.Context) {
 	employeeID := middleware.GetUserID(c)
 
-	status, err := h.attendanceService.GetTodayStatus(employeeID)
+	status, err := h.attendanceService.GetTodayStatus(c.Request.Context(), employeeID)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    "INTERNAL_ERROR",
 			"message": "获取今日考勤状态失败",
@@ -95,45 +93,202 @@ func (h *AttendanceHandler) GetTodayStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
+// GetTeamToday returns today's attendance status of the current user's team
+// GET /api/attendance/team/today?department=
+func (h *AttendanceHandler) GetTeamToday(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	role := middleware.GetRole(c)
+
+	team, err := h.attendanceService.GetTeamToday(c.Request.Context(), userID, role, c.Query("department"))
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取团队今日考勤失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
 // GetMonthlyRecords returns attendance records for a specific month
 // GET /api/attendance
 func (h *AttendanceHandler) GetMonthlyRecords(c *gin.Context) {
 	employeeID := middleware.GetUserID(c)
 
-	// Parse year and month from query parameters
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+
+	records, err := h.attendanceService.GetMonthlyRecords(c.Request.Context(), employeeID, year, month)
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取考勤记录失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// GetMonthlySummary returns the current user's attendance and overtime summary for a month
+// GET /api/attendance/summary?year=&month=
+func (h *AttendanceHandler) GetMonthlySummary(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.attendanceService.GetMonthlySummary(c.Request.Context(), employeeID, year, month)
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取考勤汇总失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetPerfectAttendance lists employees who attended every expected working day of a month
+// GET /api/attendance/perfect?year=&month=&department=
+func (h *AttendanceHandler) GetPerfectAttendance(c *gin.Context) {
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+
+	employees, err := h.attendanceService.GetPerfectAttendance(c.Request.Context(), year, month, c.Query("department"))
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取全勤名单失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, employees)
+}
+
+// GetHeatmap returns the day-by-day attendance status of a department or the caller's team for a month
+// GET /api/attendance/heatmap?year=&month=&department=
+func (h *AttendanceHandler) GetHeatmap(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	role := middleware.GetRole(c)
+
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+
+	heatmap, err := h.attendanceService.GetHeatmap(c.Request.Context(), userID, role, year, month, c.Query("department"))
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取考勤热力图失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+// Export streams all employees' attendance for a month as CSV
+// GET /api/attendance/export?year=&month=&department=
+func (h *AttendanceHandler) Export(c *gin.Context) {
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+	if year == 0 || month == 0 {
+		today := service.Today()
+		year, month = today.Year(), int(today.Month())
+	}
+
+	records, err := h.attendanceService.GetAllMonthlyRecords(c.Request.Context(), year, month, c.Query("department"))
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "导出考勤记录失败",
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=attendance-%04d-%02d.csv", year, month))
+	c.Status(http.StatusOK)
+	if err := h.attendanceService.WriteCSV(c.Writer, records); err != nil {
+		// Headers are already sent, so the error can only be recorded
+		_ = c.Error(err)
+	}
+}
+
+// Accepted range of the year query parameter
+const (
+	minQueryYear = 2000
+	maxQueryYear = 2100
+)
+
+// parseYearMonth reads the optional year and month query parameters, writing a validation error
+// response and returning false when either is invalid or only one of them is given.
+// Both absent yields zeros, which the services treat as the current month
+func parseYearMonth(c *gin.Context) (int, int, bool) {
+	yearStr, monthStr := c.Query("year"), c.Query("month")
+	if (yearStr == "") != (monthStr == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "年份和月份参数必须同时提供",
+		})
+		return 0, 0, false
+	}
+
 	var year, month int
-	if yearStr := c.Query("year"); yearStr != "" {
+	if yearStr != "" {
 		y, err := strconv.Atoi(yearStr)
-		if err != nil {
+		if err != nil || y < minQueryYear || y > maxQueryYear {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    "VALIDATION_ERROR",
-				"message": "无效的年份参数",
+				"message": fmt.Sprintf("无效的年份参数，应在%d到%d之间", minQueryYear, maxQueryYear),
 			})
-			return
+			return 0, 0, false
 		}
 		year = y
 	}
 
-	if monthStr := c.Query("month"); monthStr != "" {
+	if monthStr != "" {
 		m, err := strconv.Atoi(monthStr)
 		if err != nil || m < 1 || m > 12 {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    "VALIDATION_ERROR",
 				"message": "无效的月份参数",
 			})
-			return
+			return 0, 0, false
 		}
 		month = m
 	}
 
-	records, err := h.attendanceService.GetMonthlyRecords(employeeID, year, month)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    "INTERNAL_ERROR",
-			"message": "获取考勤记录失败",
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, records)
+	return year, month, true
 }
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+)
+
+func newAttendanceHandler(db *gorm.DB) *AttendanceHandler {
+	return NewAttendanceHandler(service.NewAttendanceService(db, &config.AttendanceConfig{WorkStartTime: "09:00", WorkEndTime: "18:00"}))
+}
+
+// createAttendance records a 09:00–18:00 working day for employee
+func createAttendance(t *testing.T, db *gorm.DB, employee *model.Employee, day time.Time) {
+	t.Helper()
+	signIn, signOut := day.Add(9*time.Hour), day.Add(18*time.Hour)
+	record := &model.Attendance{EmployeeID: employee.ID, Date: day, SignInTime: &signIn, SignOutTime: &signOut}
+	if err := db.Create(record).Error; err != nil {
+		t.Fatalf("create attendance: %v", err)
+	}
+}
+
+func TestExportAttendance(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newAttendanceHandler(db)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	db.Model(alice).Update("department", "研发部")
+	db.Model(bob).Update("department", "市场部")
+	march := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	createAttendance(t, db, alice, march)
+	createAttendance(t, db, alice, march.AddDate(0, 0, 1))
+	createAttendance(t, db, bob, march)
+	createAttendance(t, db, bob, march.AddDate(0, 1, 0))
+
+	rows := func(path string) [][]string {
+		t.Helper()
+		rec := serve(http.MethodGet, "/attendance/export", path, "", hr, h.Export)
+		assertStatus(t, rec, http.StatusOK)
+		if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+			t.Errorf("Content-Type = %q, want text/csv", got)
+		}
+		rows, err := csv.NewReader(rec.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("parse CSV: %v", err)
+		}
+		return rows
+	}
+
+	all := rows("/attendance/export?year=2026&month=3")
+	if got := strings.Join(all[0], ","); got != "employee_no,name,date,sign_in,sign_out,work_hours,auto_closed" {
+		t.Errorf("header = %s", got)
+	}
+	if len(all) != 4 {
+		t.Fatalf("got %d data rows, want 3 (April is excluded)", len(all)-1)
+	}
+	if got := all[1]; got[0] != alice.EmployeeNo || got[2] != "2026-03-02" || got[3] != "09:00:00" || got[5] != "9.00" {
+		t.Errorf("first row = %v", got)
+	}
+
+	scoped := rows("/attendance/export?year=2026&month=3&department=" + url.QueryEscape("市场部"))
+	if len(scoped) != 2 || scoped[1][0] != bob.EmployeeNo {
+		t.Errorf("department export = %v, want bob's single day", scoped[1:])
+	}
+}
+
+func TestAttendanceYearMonthValidation(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newAttendanceHandler(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", http.StatusOK},
+		{"year=2000&month=1", http.StatusOK},
+		{"year=2100&month=12", http.StatusOK},
+		{"year=1999&month=12", http.StatusBadRequest},
+		{"year=2101&month=1", http.StatusBadRequest},
+		{"year=99999&month=1", http.StatusBadRequest},
+		{"year=-2026&month=1", http.StatusBadRequest},
+		{"year=abc&month=1", http.StatusBadRequest},
+		{"year=2026&month=0", http.StatusBadRequest},
+		{"year=2026&month=13", http.StatusBadRequest},
+		{"year=2026", http.StatusBadRequest},
+		{"month=3", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		rec := serve(http.MethodGet, "/attendance", "/attendance?"+tt.query, "", alice, h.GetMonthlyRecords)
+		if rec.Code != tt.want {
+			t.Errorf("?%s: status = %d (%s), want %d", tt.query, rec.Code, rec.Body.String(), tt.want)
+		}
+		if tt.want == http.StatusBadRequest && !strings.Contains(rec.Body.String(), "VALIDATION_ERROR") {
+			t.Errorf("?%s: body = %s, want code VALIDATION_ERROR", tt.query, rec.Body.String())
+		}
+	}
+}
+
+func TestRepeatSignInAndSignOut(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newAttendanceHandler(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	// response holds the fields sign-in and sign-out responses share
+	type response struct {
+		Attendance *model.Attendance `json:"attendance"`
+		Already    bool              `json:"already"`
+	}
+	// post runs an attendance action and decodes its response
+	post := func(path string, handler gin.HandlerFunc) (int, response) {
+		t.Helper()
+		rec := serve(http.MethodPost, path, path, "", alice, handler)
+		var resp response
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	if code, _ := post("/attendance/sign-out", h.SignOut); code != http.StatusBadRequest {
+		t.Errorf("sign-out before signing in = %d, want 400", code)
+	}
+	code, first := post("/attendance/sign-in", h.SignIn)
+	if code != http.StatusOK || first.Already {
+		t.Fatalf("first sign-in = %d already %v, want 200 and not already", code, first.Already)
+	}
+	code, second := post("/attendance/sign-in", h.SignIn)
+	if code != http.StatusOK || !second.Already {
+		t.Fatalf("second sign-in = %d already %v, want 200 and already", code, second.Already)
+	}
+	if second.Attendance.ID != first.Attendance.ID || !second.Attendance.SignInTime.Equal(*first.Attendance.SignInTime) {
+		t.Errorf("second sign-in returned record %d at %v, want the existing %d at %v", second.Attendance.ID, second.Attendance.SignInTime, first.Attendance.ID, first.Attendance.SignInTime)
+	}
+
+	if code, resp := post("/attendance/sign-out", h.SignOut); code != http.StatusOK || resp.Already {
+		t.Errorf("first sign-out = %d already %v, want 200 and not already", code, resp.Already)
+	}
+	if code, resp := post("/attendance/sign-out", h.SignOut); code != http.StatusOK || !resp.Already {
+		t.Errorf("second sign-out = %d already %v, want 200 and already", code, resp.Already)
+	}
+}
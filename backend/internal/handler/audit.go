@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/service"
+)
+
+// AuditHandler handles audit log HTTP requests
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// List returns a page of audit log entries
+// GET /api/audit-logs?actor_id=1&action=employee.role_update&start=2024-01-01&end=2024-01-31&page=1&page_size=20
+func (h *AuditHandler) List(c *gin.Context) {
+	filters := make(map[string]interface{})
+
+	if actorIDStr := c.Query("actor_id"); actorIDStr != "" {
+		actorID, err := strconv.ParseUint(actorIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid actor ID",
+			})
+			return
+		}
+		filters["actor_id"] = uint(actorID)
+	}
+	if action := c.Query("action"); action != "" {
+		filters["action"] = action
+	}
+	if start := c.Query("start"); start != "" {
+		filters["start"] = start
+	}
+	if end := c.Query("end"); end != "" {
+		filters["end"] = end
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid page",
+		})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Page size must be between 1 and 100",
+		})
+		return
+	}
+
+	result, err := h.auditService.List(filters, page, pageSize)
+	if err != nil {
+		if errors.Is(err, service.ErrAuditInvalidDateFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid date format, expected YYYY-MM-DD",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve audit logs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -96,6 +96,9 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// Changing the password clears the first-login flag cached by AuthMiddleware
+	middleware.InvalidateAccountCache(userID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Password changed successfully",
 	})
@@ -123,3 +126,15 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, employee)
 }
+
+// GetPermissions returns the current user's role and the permissions it grants, as enforced by
+// the permission middleware, so the frontend can show only what the user may do
+// GET /api/auth/permissions
+func (h *AuthHandler) GetPermissions(c *gin.Context) {
+	role := middleware.GetRole(c)
+
+	c.JSON(http.StatusOK, gin.H{
+		"role":        role,
+		"permissions": middleware.GetRolePermissions(role),
+	})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/middleware"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/jwt"
+)
+
+func newAuthHandler(db *gorm.DB) *AuthHandler {
+	return NewAuthHandler(service.NewAuthService(db, jwt.NewJWTManager("test-secret", 1)))
+}
+
+func TestGetPermissions(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newAuthHandler(db)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+
+	permissions := func(user *model.Employee) []middleware.Permission {
+		t.Helper()
+		rec := serve(http.MethodGet, "/auth/permissions", "/auth/permissions", "", user, h.GetPermissions)
+		assertStatus(t, rec, http.StatusOK)
+		var resp struct {
+			Role        string                  `json:"role"`
+			Permissions []middleware.Permission `json:"permissions"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if resp.Role != user.Role {
+			t.Errorf("role = %q, want %q", resp.Role, user.Role)
+		}
+		return resp.Permissions
+	}
+
+	employee := permissions(testutil.CreateEmployee(t, db, "alice", model.RoleEmployee))
+	if !slices.Equal(employee, middleware.RolePermissions[model.RoleEmployee]) {
+		t.Errorf("employee permissions = %v, want %v", employee, middleware.RolePermissions[model.RoleEmployee])
+	}
+	if slices.Contains(employee, middleware.PermManageEmployees) {
+		t.Error("employee is granted manage_employees")
+	}
+
+	hr := permissions(testutil.CreateEmployee(t, db, "hr", model.RoleHR))
+	if !slices.Equal(hr, middleware.RolePermissions[model.RoleHR]) {
+		t.Errorf("HR permissions = %v, want %v", hr, middleware.RolePermissions[model.RoleHR])
+	}
+	for _, want := range []middleware.Permission{middleware.PermManageEmployees, middleware.PermManageContracts} {
+		if !slices.Contains(hr, want) {
+			t.Errorf("HR permissions lack %s", want)
+		}
+	}
+
+	// Roles stored in the database report the permissions granted to them there
+	roleService := service.NewRoleService(db, middleware.IsKnownPermission)
+	middleware.SetRolePermissionLoader(roleService.LoadPermissionMap)
+	t.Cleanup(func() { middleware.SetRolePermissionLoader(nil) })
+	if _, err := roleService.Create(admin.ID, &service.CreateRoleRequest{Name: "recruiter", Permissions: []string{string(middleware.PermManageContracts)}}); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	recruiter := permissions(testutil.CreateEmployee(t, db, "recruiter", "recruiter"))
+	if !slices.Equal(recruiter, []middleware.Permission{middleware.PermManageContracts}) {
+		t.Errorf("recruiter permissions = %v, want [manage_contracts]", recruiter)
+	}
+}
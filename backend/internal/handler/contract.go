@@ -2,13 +2,16 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	"oa-system/internal/middleware"
+	"oa-system/internal/model"
 	"oa-system/internal/service"
+	"oa-system/pkg/pagination"
 )
 
 // ContractHandler handles contract HTTP requests
@@ -71,6 +74,137 @@ func (h *ContractHandler) GetTemplateByID(c *gin.Context) {
 }
 
 
+// CreateTemplate creates a new contract template
+// POST /api/contract-templates
+func (h *ContractHandler) CreateTemplate(c *gin.Context) {
+	var req service.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	template, err := h.contractService.CreateTemplate(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidContractType):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_CONTRACT_TYPE",
+				"message": "Contract type must be lowercase letters, digits or underscores (max 20)",
+			})
+		case errors.Is(err, service.ErrContractTemplateTypeExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "TEMPLATE_TYPE_EXISTS",
+				"message": "A contract template with this type already exists",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to create contract template",
+			})
+		}
+		return
+	}
+
+	respondCreated(c, "/api/contract-templates", template.ID, template)
+}
+
+// UpdateTemplate updates a contract template
+// PUT /api/contract-templates/:id
+func (h *ContractHandler) UpdateTemplate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid template ID",
+		})
+		return
+	}
+
+	var req service.UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	template, err := h.contractService.UpdateTemplate(uint(id), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrContractTemplateNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "TEMPLATE_NOT_FOUND",
+				"message": "Contract template not found",
+			})
+		case errors.Is(err, service.ErrInvalidContractType):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_CONTRACT_TYPE",
+				"message": "Contract type must be lowercase letters, digits or underscores (max 20)",
+			})
+		case errors.Is(err, service.ErrContractTemplateTypeExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "TEMPLATE_TYPE_EXISTS",
+				"message": "A contract template with this type already exists",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to update contract template",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate deletes a contract template
+// DELETE /api/contract-templates/:id
+func (h *ContractHandler) DeleteTemplate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid template ID",
+		})
+		return
+	}
+
+	err = h.contractService.DeleteTemplate(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrContractTemplateNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "TEMPLATE_NOT_FOUND",
+				"message": "Contract template not found",
+			})
+		case errors.Is(err, service.ErrContractTemplateInUse):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "TEMPLATE_IN_USE",
+				"message": "Contract template is referenced by existing contracts and cannot be deleted",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to delete contract template",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Contract template deleted successfully",
+	})
+}
+
 // Create creates a new contract
 // POST /api/contracts
 func (h *ContractHandler) Create(c *gin.Context) {
@@ -97,6 +231,17 @@ func (h *ContractHandler) Create(c *gin.Context) {
 				"code":    "TEMPLATE_NOT_FOUND",
 				"message": "Contract template not found",
 			})
+		case errors.Is(err, service.ErrInvalidContractExpiry):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_EXPIRY_DATE",
+				"message": "Expiry date must be a future date in YYYY-MM-DD format",
+			})
+		case errors.Is(err, service.ErrUnresolvedPlaceholder):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "UNRESOLVED_PLACEHOLDER",
+				"message": "Contract template contains placeholders that cannot be resolved for this employee",
+				"details": err.Error(),
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -106,11 +251,11 @@ func (h *ContractHandler) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, contract)
+	respondCreated(c, "/api/contracts", contract.ID, contract)
 }
 
-// List returns all contracts
-// GET /api/contracts
+// List returns a page of contracts
+// GET /api/contracts?employee_id=1&type=onboarding&status=signed&page=1&page_size=20&sort=created_at&order=desc
 func (h *ContractHandler) List(c *gin.Context) {
 	filters := make(map[string]interface{})
 
@@ -126,8 +271,25 @@ func (h *ContractHandler) List(c *gin.Context) {
 	if status := c.Query("status"); status != "" {
 		filters["status"] = status
 	}
+	includeDeleted, ok := parseIncludeDeleted(c)
+	if !ok {
+		return
+	}
+	if includeDeleted {
+		filters["include_deleted"] = true
+	}
 
-	contracts, err := h.contractService.List(filters)
+	params, err := pagination.Parse(c, service.ContractSortOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid pagination parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.contractService.List(filters, params)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    "INTERNAL_ERROR",
@@ -136,7 +298,7 @@ func (h *ContractHandler) List(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, contracts)
+	c.JSON(http.StatusOK, result)
 }
 
 
@@ -170,12 +332,7 @@ func (h *ContractHandler) GetByID(c *gin.Context) {
 	}
 
 	// Check if user is HR or the contract owner
-	currentUserID := middleware.GetUserID(c)
-	currentRole := middleware.GetRole(c)
-
-	if contract.EmployeeID != currentUserID &&
-		currentRole != "super_admin" &&
-		currentRole != "hr" {
+	if !canViewContract(c, contract) {
 		c.JSON(http.StatusForbidden, gin.H{
 			"code":    "FORBIDDEN",
 			"message": "You don't have permission to view this contract",
@@ -186,6 +343,63 @@ func (h *ContractHandler) GetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, contract)
 }
 
+// DownloadPDF streams a contract rendered as PDF
+// GET /api/contracts/:id/pdf
+func (h *ContractHandler) DownloadPDF(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid contract ID",
+		})
+		return
+	}
+
+	contract, err := h.contractService.GetByID(uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrContractNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "CONTRACT_NOT_FOUND",
+				"message": "Contract not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve contract",
+		})
+		return
+	}
+
+	// Same access rule as GetByID: HR or the contract owner
+	if !canViewContract(c, contract) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    "FORBIDDEN",
+			"message": "You don't have permission to view this contract",
+		})
+		return
+	}
+
+	data, err := h.contractService.GeneratePDF(contract)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to generate contract PDF",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=contract-%d.pdf", contract.ID))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// canViewContract reports whether the current user manages contracts or owns the contract
+func canViewContract(c *gin.Context, contract *model.Contract) bool {
+	return contract.EmployeeID == middleware.GetUserID(c) ||
+		middleware.HasPermissionOrSuperAdmin(middleware.GetRole(c), middleware.PermManageContracts)
+}
+
 // GetMyContracts returns contracts for the current user
 // GET /api/contracts/my
 func (h *ContractHandler) GetMyContracts(c *gin.Context) {
@@ -203,6 +417,70 @@ func (h *ContractHandler) GetMyContracts(c *gin.Context) {
 	c.JSON(http.StatusOK, contracts)
 }
 
+// GetMyPending returns the current user's contracts awaiting their signature
+// GET /api/contracts/my/pending
+func (h *ContractHandler) GetMyPending(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	contracts, err := h.contractService.GetAwaitingSignature(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve pending contracts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, contracts)
+}
+
+// GetMyPendingCount counts the current user's contracts awaiting their signature
+// GET /api/contracts/my/pending/count
+func (h *ContractHandler) GetMyPendingCount(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	count, err := h.contractService.CountAwaitingSignature(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to count pending contracts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": count,
+	})
+}
+
+// GetExpiring returns signed contracts expiring within the given number of days
+// GET /api/contracts/expiring?within_days=30
+func (h *ContractHandler) GetExpiring(c *gin.Context) {
+	withinDays := 30
+	if daysStr := c.Query("within_days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 || days > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "within_days must be between 0 and 365",
+			})
+			return
+		}
+		withinDays = days
+	}
+
+	contracts, err := h.contractService.GetExpiring(withinDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve expiring contracts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, contracts)
+}
+
 
 // Sign signs a contract
 // PUT /api/contracts/:id/sign
@@ -232,6 +510,16 @@ func (h *ContractHandler) Sign(c *gin.Context) {
 				"code":    "CONTRACT_ALREADY_SIGNED",
 				"message": "Contract has already been signed",
 			})
+		case errors.Is(err, service.ErrContractNotPending):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CONTRACT_NOT_PENDING",
+				"message": "Only pending contracts can be signed",
+			})
+		case errors.Is(err, service.ErrContractNotApproved):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CONTRACT_NOT_APPROVED",
+				"message": "Contract must be approved before it can be signed",
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -244,6 +532,111 @@ func (h *ContractHandler) Sign(c *gin.Context) {
 	c.JSON(http.StatusOK, contract)
 }
 
+// Approve approves a contract that requires approval before signing
+// PUT /api/contracts/:id/approve
+func (h *ContractHandler) Approve(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid contract ID",
+		})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+
+	contract, err := h.contractService.Approve(uint(id), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrContractNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "CONTRACT_NOT_FOUND",
+				"message": "Contract not found",
+			})
+		case errors.Is(err, service.ErrContractSelfApproval):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "CONTRACT_SELF_APPROVAL",
+				"message": "Cannot approve your own contract",
+			})
+		case errors.Is(err, service.ErrContractNotPending):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CONTRACT_NOT_PENDING",
+				"message": "Only pending contracts can be approved",
+			})
+		case errors.Is(err, service.ErrContractApprovalNotNeeded):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CONTRACT_APPROVAL_NOT_REQUIRED",
+				"message": "Contract does not require approval",
+			})
+		case errors.Is(err, service.ErrContractAlreadyApproved):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CONTRACT_ALREADY_APPROVED",
+				"message": "Contract has already been approved",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to approve contract",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
+// Decline declines a contract
+// PUT /api/contracts/:id/decline
+func (h *ContractHandler) Decline(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid contract ID",
+		})
+		return
+	}
+
+	var req service.DeclineContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "A decline reason is required",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+
+	contract, err := h.contractService.Decline(uint(id), userID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrContractNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "CONTRACT_NOT_FOUND",
+				"message": "Contract not found",
+			})
+		case errors.Is(err, service.ErrContractNotPending):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CONTRACT_NOT_PENDING",
+				"message": "Only pending contracts can be declined",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to decline contract",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
 // Delete soft deletes a contract
 // DELETE /api/contracts/:id
 func (h *ContractHandler) Delete(c *gin.Context) {
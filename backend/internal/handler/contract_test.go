@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/pdf"
+)
+
+// createContract inserts a pending contract for employee
+func createContract(t *testing.T, db *gorm.DB, employee *model.Employee) *model.Contract {
+	t.Helper()
+	template := &model.ContractTemplate{Type: "test", Title: "劳动合同", Content: "{{name}}"}
+	if err := db.Create(template).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	contract := &model.Contract{
+		EmployeeID: employee.ID,
+		TemplateID: template.ID,
+		Type:       template.Type,
+		Content:    "甲方：示例公司\n乙方：" + employee.Name,
+		Status:     model.ContractStatusPending,
+	}
+	if err := db.Create(contract).Error; err != nil {
+		t.Fatalf("create contract: %v", err)
+	}
+	return contract
+}
+
+func newContractHandler(db *gorm.DB) *ContractHandler {
+	return NewContractHandler(service.NewContractService(db, pdf.NewGenerator(""), &config.ContractConfig{ExpiryReminderDays: 30}))
+}
+
+func TestDownloadPDF(t *testing.T) {
+	db := testutil.NewDB(t)
+	owner := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	contract := createContract(t, db, owner)
+	h := newContractHandler(db)
+	path := fmt.Sprintf("/contracts/%d/pdf", contract.ID)
+
+	for _, user := range []*model.Employee{owner, hr} {
+		rec := serve(http.MethodGet, "/contracts/:id/pdf", path, "", user, h.DownloadPDF)
+		assertStatus(t, rec, http.StatusOK)
+		if rec.Body.Len() == 0 || !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF")) {
+			t.Errorf("%s: body is not a PDF", user.Username)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/pdf" {
+			t.Errorf("%s: Content-Type = %q, want application/pdf", user.Username, got)
+		}
+	}
+}
+
+func TestDownloadPDFNonOwner(t *testing.T) {
+	db := testutil.NewDB(t)
+	owner := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	other := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	contract := createContract(t, db, owner)
+	h := newContractHandler(db)
+
+	rec := serve(http.MethodGet, "/contracts/:id/pdf", fmt.Sprintf("/contracts/%d/pdf", contract.ID), "", other, h.DownloadPDF)
+	assertStatus(t, rec, http.StatusForbidden)
+}
+
+func TestMyPendingContracts(t *testing.T) {
+	db := testutil.NewDB(t)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	pending := createContract(t, db, alice)
+	h := newContractHandler(db)
+
+	// A signed contract of alice's and a pending one of bob's from the same template
+	now := time.Now()
+	for _, contract := range []*model.Contract{
+		{EmployeeID: alice.ID, TemplateID: pending.TemplateID, Type: pending.Type, Status: model.ContractStatusSigned, SignedAt: &now},
+		{EmployeeID: bob.ID, TemplateID: pending.TemplateID, Type: pending.Type, Status: model.ContractStatusPending},
+	} {
+		if err := db.Create(contract).Error; err != nil {
+			t.Fatalf("create contract: %v", err)
+		}
+	}
+
+	rec := serve(http.MethodGet, "/contracts/my/pending", "/contracts/my/pending", "", alice, h.GetMyPending)
+	assertStatus(t, rec, http.StatusOK)
+	var contracts []model.Contract
+	if err := json.Unmarshal(rec.Body.Bytes(), &contracts); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(contracts) != 1 || contracts[0].ID != pending.ID {
+		t.Errorf("pending contracts = %+v, want only contract %d", contracts, pending.ID)
+	}
+
+	rec = serve(http.MethodGet, "/contracts/my/pending/count", "/contracts/my/pending/count", "", alice, h.GetMyPendingCount)
+	assertStatus(t, rec, http.StatusOK)
+	var count struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &count); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if count.Count != 1 {
+		t.Errorf("pending count = %d, want 1", count.Count)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/middleware"
+	"oa-system/internal/service"
+)
+
+// DashboardHandler handles dashboard HTTP requests
+type DashboardHandler struct {
+	dashboardService *service.DashboardService
+}
+
+// NewDashboardHandler creates a new dashboard handler
+func NewDashboardHandler(dashboardService *service.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardService: dashboardService,
+	}
+}
+
+// Get returns the role-aware home screen summary for the current user
+// GET /api/dashboard
+func (h *DashboardHandler) Get(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	role := middleware.GetRole(c)
+
+	dashboard, err := h.dashboardService.GetDashboard(c.Request.Context(), userID, role)
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取首页概览失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// GetMyRequests returns the current user's pending and active items across leave, devices, bookings and contracts
+// GET /api/me/requests?kind=
+func (h *DashboardHandler) GetMyRequests(c *gin.Context) {
+	items, err := h.dashboardService.GetMyRequests(middleware.GetUserID(c), c.Query("kind"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRequestKind) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "无效的类型",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取我的申请失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/service"
+)
+
+// DepartmentHandler handles department HTTP requests
+type DepartmentHandler struct {
+	departmentService *service.DepartmentService
+}
+
+// NewDepartmentHandler creates a new department handler
+func NewDepartmentHandler(departmentService *service.DepartmentService) *DepartmentHandler {
+	return &DepartmentHandler{
+		departmentService: departmentService,
+	}
+}
+
+// List returns all departments
+// GET /api/departments
+func (h *DepartmentHandler) List(c *gin.Context) {
+	departments, err := h.departmentService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve departments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, departments)
+}
+
+// GetByID returns a department by ID
+// GET /api/departments/:id
+func (h *DepartmentHandler) GetByID(c *gin.Context) {
+	id, ok := parseDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	department, err := h.departmentService.GetByID(id)
+	if err != nil {
+		respondDepartmentError(c, err, "Failed to retrieve department")
+		return
+	}
+
+	c.JSON(http.StatusOK, department)
+}
+
+// Create creates a department
+// POST /api/departments
+func (h *DepartmentHandler) Create(c *gin.Context) {
+	var req service.CreateDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	department, err := h.departmentService.Create(&req)
+	if err != nil {
+		respondDepartmentError(c, err, "Failed to create department")
+		return
+	}
+
+	respondCreated(c, "/api/departments", department.ID, department)
+}
+
+// Update updates a department
+// PUT /api/departments/:id
+func (h *DepartmentHandler) Update(c *gin.Context) {
+	id, ok := parseDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	department, err := h.departmentService.Update(id, &req)
+	if err != nil {
+		respondDepartmentError(c, err, "Failed to update department")
+		return
+	}
+
+	c.JSON(http.StatusOK, department)
+}
+
+// Delete deletes a department that has no employees
+// DELETE /api/departments/:id
+func (h *DepartmentHandler) Delete(c *gin.Context) {
+	id, ok := parseDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.departmentService.Delete(id); err != nil {
+		respondDepartmentError(c, err, "Failed to delete department")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Department deleted successfully",
+	})
+}
+
+// ListEmployees returns the employees of a department
+// GET /api/departments/:id/employees
+func (h *DepartmentHandler) ListEmployees(c *gin.Context) {
+	id, ok := parseDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	employees, err := h.departmentService.ListEmployees(id)
+	if err != nil {
+		respondDepartmentError(c, err, "Failed to retrieve department employees")
+		return
+	}
+
+	c.JSON(http.StatusOK, employees)
+}
+
+// parseDepartmentID reads the department ID path parameter, writing a validation error
+// response and returning false when it is invalid
+func parseDepartmentID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid department ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondDepartmentError maps department service errors to HTTP responses
+func respondDepartmentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrDepartmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    "DEPARTMENT_NOT_FOUND",
+			"message": "Department not found",
+		})
+	case errors.Is(err, service.ErrDepartmentNameExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    "DEPARTMENT_NAME_EXISTS",
+			"message": "Department name already exists",
+		})
+	case errors.Is(err, service.ErrDepartmentCodeExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    "DEPARTMENT_CODE_EXISTS",
+			"message": "Department code already exists",
+		})
+	case errors.Is(err, service.ErrDepartmentHeadNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "HEAD_NOT_FOUND",
+			"message": "Specified department head not found",
+		})
+	case errors.Is(err, service.ErrDepartmentHasEmployees):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    "DEPARTMENT_HAS_EMPLOYEES",
+			"message": "Department still has employees, reassign them first",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": fallback,
+		})
+	}
+}
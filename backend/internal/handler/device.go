@@ -2,8 +2,11 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -47,14 +50,20 @@ func (h *DeviceHandler) CreateDevice(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, device)
+	respondCreated(c, "/api/devices", device.ID, device)
 }
 
 
-// GetAllDevices handles getting all devices
-// GET /api/devices
+// GetAllDevices handles getting all devices, optionally searched by name or type
+// GET /api/devices?q=laptop
 func (h *DeviceHandler) GetAllDevices(c *gin.Context) {
-	devices, err := h.deviceService.GetAllDevices()
+	filters := make(map[string]interface{})
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		filters["q"] = q
+	}
+
+	devices, err := h.deviceService.GetAllDevices(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    "INTERNAL_ERROR",
@@ -81,6 +90,21 @@ func (h *DeviceHandler) GetAvailableDevices(c *gin.Context) {
 	c.JSON(http.StatusOK, devices)
 }
 
+// GetLowStockDevices handles listing devices at or below their low-stock threshold
+// GET /api/devices/low-stock
+func (h *DeviceHandler) GetLowStockDevices(c *gin.Context) {
+	devices, err := h.deviceService.GetLowStockDevices()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取库存不足设备列表失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, devices)
+}
+
 // GetDevice handles getting a device by ID
 // GET /api/devices/:id
 func (h *DeviceHandler) GetDevice(c *gin.Context) {
@@ -112,6 +136,69 @@ func (h *DeviceHandler) GetDevice(c *gin.Context) {
 	c.JSON(http.StatusOK, device)
 }
 
+// GetDeviceHistory handles getting every request made for a device, oldest first
+// GET /api/devices/:id/history?status=
+func (h *DeviceHandler) GetDeviceHistory(c *gin.Context) {
+	deviceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的设备ID",
+		})
+		return
+	}
+
+	requests, err := h.deviceService.GetDeviceHistory(uint(deviceID), c.Query("status"))
+	if err != nil {
+		if errors.Is(err, service.ErrDeviceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "设备不存在",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取设备申请记录失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetDeviceQRCode handles rendering a device's QR code label
+// GET /api/devices/:id/qrcode
+func (h *DeviceHandler) GetDeviceQRCode(c *gin.Context) {
+	deviceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的设备ID",
+		})
+		return
+	}
+
+	png, err := h.deviceService.GetDeviceQRCode(uint(deviceID))
+	if err != nil {
+		if errors.Is(err, service.ErrDeviceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "设备不存在",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "生成设备二维码失败",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=device-%d.png", deviceID))
+	c.Data(http.StatusOK, "image/png", png)
+}
+
 
 // UpdateDevice handles updating a device
 // PUT /api/devices/:id
@@ -218,6 +305,17 @@ func (h *DeviceHandler) CreateRequest(c *gin.Context) {
 				"code":    "DEVICE_NOT_AVAILABLE",
 				"message": "设备不可用",
 			})
+		case errors.Is(err, service.ErrDeviceReservationInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "无效的预约日期",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrDeviceReserved):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "DEVICE_RESERVED",
+				"message": "设备在所选日期已被预约",
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -227,16 +325,64 @@ func (h *DeviceHandler) CreateRequest(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, request)
+	respondCreated(c, "/api/device-requests", request.ID, request)
+}
+
+// GetRequest handles getting a single device request, visible to the requester and to
+// whoever approves requests or confirms returns
+// GET /api/device-requests/:id
+func (h *DeviceHandler) GetRequest(c *gin.Context) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的设备申请ID",
+		})
+		return
+	}
+
+	request, err := h.deviceService.GetRequestByID(uint(requestID))
+	if err != nil {
+		if errors.Is(err, service.ErrDeviceRequestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "设备申请不存在",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取设备申请失败",
+		})
+		return
+	}
+
+	if request.EmployeeID != middleware.GetUserID(c) &&
+		!middleware.HasPermissionOrSuperAdmin(middleware.GetRole(c), middleware.PermApproveDeviceRequest, middleware.PermConfirmDeviceReturn) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    "FORBIDDEN",
+			"message": "无权查看该设备申请",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
 }
 
 // GetMyRequests handles getting the current employee's device requests
-// GET /api/device-requests
+// GET /api/device-requests?status=returned&sort=returned_at&order=desc
 func (h *DeviceHandler) GetMyRequests(c *gin.Context) {
 	employeeID := middleware.GetUserID(c)
 
-	requests, err := h.deviceService.GetMyRequests(employeeID)
+	requests, err := h.deviceService.GetMyRequests(employeeID, c.Query("status"), c.Query("sort"), c.Query("order"))
 	if err != nil {
+		if errors.Is(err, service.ErrDeviceRequestInvalidSort) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "无效的排序参数",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    "INTERNAL_ERROR",
 			"message": "获取设备申请记录失败",
@@ -278,6 +424,57 @@ func (h *DeviceHandler) GetReturnPendingRequests(c *gin.Context) {
 	c.JSON(http.StatusOK, requests)
 }
 
+// ListRequests handles browsing all device requests with filters and pagination
+// GET /api/device-requests/all?status=returned&employee_id=1&device_id=2&page=1&page_size=20
+func (h *DeviceHandler) ListRequests(c *gin.Context) {
+	filters := make(map[string]interface{})
+
+	if status := c.Query("status"); status != "" {
+		filters["status"] = status
+	}
+	for _, key := range []string{"employee_id", "device_id"} {
+		if value := c.Query(key); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    "VALIDATION_ERROR",
+					"message": "无效的筛选参数: " + key,
+				})
+				return
+			}
+			filters[key] = uint(id)
+		}
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的页码",
+		})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "每页数量应在1到100之间",
+		})
+		return
+	}
+
+	result, err := h.deviceService.ListRequests(filters, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取设备申请列表失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // ApproveRequest handles approving a device request
 // PUT /api/device-requests/:id/approve
 func (h *DeviceHandler) ApproveRequest(c *gin.Context) {
@@ -290,7 +487,18 @@ func (h *DeviceHandler) ApproveRequest(c *gin.Context) {
 		return
 	}
 
-	request, err := h.deviceService.ApproveRequest(uint(requestID))
+	// The approval comment is optional, so an empty body is accepted
+	var input service.ApproveDeviceRequestInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	request, err := h.deviceService.ApproveRequest(uint(requestID), input.Comment)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrDeviceRequestNotFound):
@@ -315,6 +523,58 @@ func (h *DeviceHandler) ApproveRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, request)
 }
 
+// TransferRequest handles handing a collected device over to another employee
+// PUT /api/device-requests/:id/transfer
+func (h *DeviceHandler) TransferRequest(c *gin.Context) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的设备申请ID",
+		})
+		return
+	}
+
+	var input service.TransferDeviceRequestInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	request, err := h.deviceService.TransferRequest(uint(requestID), input.EmployeeID, middleware.GetUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDeviceRequestNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "设备申请不存在",
+			})
+		case errors.Is(err, service.ErrDeviceRequestInvalidStatus):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "DEVICE_REQUEST_INVALID_STATUS",
+				"message": "只能转交已领用的设备",
+			})
+		case errors.Is(err, service.ErrDeviceTransferTarget):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_TRANSFER_TARGET",
+				"message": "只能转交给其他在职员工",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "转交设备失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
 
 // RejectRequest handles rejecting a device request
 // PUT /api/device-requests/:id/reject
@@ -426,7 +686,18 @@ func (h *DeviceHandler) InitiateReturn(c *gin.Context) {
 		return
 	}
 
-	request, err := h.deviceService.InitiateReturn(uint(requestID), employeeID)
+	// The condition report is optional, so an empty body is accepted
+	var input service.InitiateReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	request, err := h.deviceService.InitiateReturn(uint(requestID), employeeID, &input)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrDeviceRequestNotFound):
@@ -468,7 +739,18 @@ func (h *DeviceHandler) ConfirmReturn(c *gin.Context) {
 		return
 	}
 
-	request, err := h.deviceService.ConfirmReturn(uint(requestID))
+	// Overriding the reported condition is optional, so an empty body is accepted
+	var input service.ConfirmReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	request, err := h.deviceService.ConfirmReturn(uint(requestID), &input)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrDeviceRequestNotFound):
@@ -542,6 +824,25 @@ func (h *DeviceHandler) CancelRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, request)
 }
 
+// CancelAllRequests handles cancelling all of the current employee's pending device requests
+// PUT /api/device-requests/cancel-all
+func (h *DeviceHandler) CancelAllRequests(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	count, err := h.deviceService.CancelAllPendingByEmployee(employeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "取消设备申请失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": count,
+	})
+}
+
 func handleCancelError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, service.ErrDeviceRequestNotFound):
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+)
+
+func TestGetDeviceQRCode(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := NewDeviceHandler(service.NewDeviceService(db, &config.DeviceConfig{}))
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	device := &model.Device{Name: "ThinkPad", TotalQuantity: 1, AvailableQuantity: 1}
+	if err := db.Create(device).Error; err != nil {
+		t.Fatalf("create device: %v", err)
+	}
+
+	rec := serve(http.MethodGet, "/devices/:id/qrcode", fmt.Sprintf("/devices/%d/qrcode", device.ID), "", alice, h.GetDeviceQRCode)
+	assertStatus(t, rec, http.StatusOK)
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", got)
+	}
+	if _, err := png.Decode(bytes.NewReader(rec.Body.Bytes())); err != nil {
+		t.Errorf("body is not a PNG: %v", err)
+	}
+
+	rec = serve(http.MethodGet, "/devices/:id/qrcode", fmt.Sprintf("/devices/%d/qrcode", device.ID+1), "", alice, h.GetDeviceQRCode)
+	assertStatus(t, rec, http.StatusNotFound)
+}
+
+func TestCreateDeviceRequestLocation(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := NewDeviceHandler(service.NewDeviceService(db, &config.DeviceConfig{}))
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+	device := &model.Device{Name: "ThinkPad", TotalQuantity: 1, AvailableQuantity: 1}
+	if err := db.Create(device).Error; err != nil {
+		t.Fatalf("create device: %v", err)
+	}
+
+	rec := serve(http.MethodPost, "/api/device-requests", "/api/device-requests", fmt.Sprintf(`{"device_id":%d}`, device.ID), alice, h.CreateRequest)
+	assertStatus(t, rec, http.StatusCreated)
+	var created model.DeviceRequest
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	location := rec.Header().Get("Location")
+	if want := fmt.Sprintf("/api/device-requests/%d", created.ID); location != want {
+		t.Fatalf("Location = %q, want %q", location, want)
+	}
+
+	// The Location resolves for the requester and approvers, but not for other employees
+	for _, tt := range []struct {
+		user *model.Employee
+		want int
+	}{
+		{alice, http.StatusOK},
+		{admin, http.StatusOK},
+		{bob, http.StatusForbidden},
+	} {
+		rec = serve(http.MethodGet, "/api/device-requests/:id", location, "", tt.user, h.GetRequest)
+		if rec.Code != tt.want {
+			t.Errorf("GET %s as %s = %d, want %d", location, tt.user.Username, rec.Code, tt.want)
+		}
+	}
+}
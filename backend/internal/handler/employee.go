@@ -10,6 +10,7 @@ import (
 	"oa-system/internal/middleware"
 	"oa-system/internal/model"
 	"oa-system/internal/service"
+	"oa-system/pkg/pagination"
 )
 
 // EmployeeHandler handles employee HTTP requests
@@ -24,8 +25,8 @@ func NewEmployeeHandler(employeeService *service.EmployeeService) *EmployeeHandl
 	}
 }
 
-// List returns all employees
-// GET /api/employees
+// List returns a page of employees
+// GET /api/employees?department=&role=&is_active=&page=1&page_size=20&sort=id&order=asc
 func (h *EmployeeHandler) List(c *gin.Context) {
 	filters := make(map[string]interface{})
 	
@@ -38,9 +39,29 @@ func (h *EmployeeHandler) List(c *gin.Context) {
 	if isActive := c.Query("is_active"); isActive != "" {
 		filters["is_active"] = isActive == "true"
 	}
+	includeDeleted, ok := parseIncludeDeleted(c)
+	if !ok {
+		return
+	}
+	if includeDeleted {
+		filters["include_deleted"] = true
+	}
+
+	params, err := pagination.Parse(c, service.EmployeeSortOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid pagination parameters",
+			"details": err.Error(),
+		})
+		return
+	}
 
-	employees, err := h.employeeService.List(filters)
+	employees, err := h.employeeService.List(c.Request.Context(), filters, params)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    "INTERNAL_ERROR",
 			"message": "Failed to retrieve employees",
@@ -65,13 +86,11 @@ func (h *EmployeeHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	// Check if user is accessing their own info or has admin/HR role
+	// Check if user is accessing their own info or manages employees
 	currentUserID := middleware.GetUserID(c)
 	currentRole := middleware.GetRole(c)
 	
-	if uint(id) != currentUserID && 
-		currentRole != model.RoleSuperAdmin && 
-		currentRole != model.RoleHR {
+	if uint(id) != currentUserID && !middleware.HasPermissionOrSuperAdmin(currentRole, middleware.PermManageEmployees) {
 		c.JSON(http.StatusForbidden, gin.H{
 			"code":    "FORBIDDEN",
 			"message": "You don't have permission to view this employee",
@@ -79,8 +98,11 @@ func (h *EmployeeHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	employee, err := h.employeeService.GetByID(uint(id))
+	employee, err := h.employeeService.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
 		if errors.Is(err, service.ErrEmployeeNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"code":    "EMPLOYEE_NOT_FOUND",
@@ -98,13 +120,34 @@ func (h *EmployeeHandler) GetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, employee)
 }
 
+// GetStatistics returns headcount overall, per department and per role
+// GET /api/employees/statistics
+func (h *EmployeeHandler) GetStatistics(c *gin.Context) {
+	stats, err := h.employeeService.GetStatistics(c.Request.Context())
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve employee statistics",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // GetMe returns the current authenticated user's info
 // GET /api/employees/me
 func (h *EmployeeHandler) GetMe(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 	
-	employee, err := h.employeeService.GetByID(userID)
+	employee, err := h.employeeService.GetByID(c.Request.Context(), userID)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
 		if errors.Is(err, service.ErrEmployeeNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"code":    "EMPLOYEE_NOT_FOUND",
@@ -122,6 +165,32 @@ func (h *EmployeeHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, employee)
 }
 
+// GetSubordinates returns the current user's reports; ?recursive=true includes indirect reports
+// GET /api/employees/subordinates
+func (h *EmployeeHandler) GetSubordinates(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var subordinates []model.Employee
+	var err error
+	if c.Query("recursive") == "true" {
+		subordinates, err = h.employeeService.GetAllSubordinates(c.Request.Context(), userID)
+	} else {
+		subordinates, err = h.employeeService.GetSubordinates(c.Request.Context(), userID)
+	}
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve subordinates",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, subordinates)
+}
+
 // Create creates a new employee
 // POST /api/employees
 func (h *EmployeeHandler) Create(c *gin.Context) {
@@ -135,19 +204,37 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.employeeService.Create(&req)
+	resp, err := h.employeeService.Create(c.Request.Context(), &req)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
 		switch {
 		case errors.Is(err, service.ErrInvalidRole):
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    "INVALID_ROLE",
 				"message": "Invalid role specified",
 			})
+		case errors.Is(err, service.ErrEmailExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "EMAIL_EXISTS",
+				"message": "Email already exists",
+			})
 		case errors.Is(err, service.ErrSupervisorNotFound):
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    "SUPERVISOR_NOT_FOUND",
 				"message": "Specified supervisor not found",
 			})
+		case errors.Is(err, service.ErrEmployeeDepartmentNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "DEPARTMENT_NOT_FOUND",
+				"message": "Specified department not found",
+			})
+		case errors.Is(err, service.ErrEmployeeNoExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "EMPLOYEE_NO_CONFLICT",
+				"message": "Could not claim a free employee number, please retry",
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -157,7 +244,43 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, resp)
+	respondCreated(c, "/api/employees", resp.Employee.ID, resp)
+}
+
+// GetNextNumber previews the employee number the next created employee would get
+// GET /api/employees/next-number
+func (h *EmployeeHandler) GetNextNumber(c *gin.Context) {
+	employeeNo, err := h.employeeService.PreviewNextEmployeeNo(c.Request.Context())
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to compute the next employee number",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"employee_no": employeeNo})
+}
+
+// GetNumberReport reports malformed and duplicate employee numbers
+// GET /api/employees/number-report
+func (h *EmployeeHandler) GetNumberReport(c *gin.Context) {
+	report, err := h.employeeService.GetEmployeeNumberReport(c.Request.Context())
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to check employee numbers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
 }
 
 
@@ -190,8 +313,14 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 			return
 		}
 
-		employee, err := h.employeeService.Update(uint(id), &req)
+		employee, err := h.employeeService.Update(c.Request.Context(), uint(id), &req)
 		if err != nil {
+			if respondIfTimedOut(c, err) {
+				return
+			}
+			if respondIfConflict(c, err) {
+				return
+			}
 			if errors.Is(err, service.ErrEmployeeNotFound) {
 				c.JSON(http.StatusNotFound, gin.H{
 					"code":    "EMPLOYEE_NOT_FOUND",
@@ -199,6 +328,13 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 				})
 				return
 			}
+			if errors.Is(err, service.ErrEmailExists) {
+				c.JSON(http.StatusConflict, gin.H{
+					"code":    "EMAIL_EXISTS",
+					"message": "Email already exists",
+				})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
 				"message": "Failed to update employee",
@@ -211,7 +347,7 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 	}
 
 	// Admin/HR update: more fields allowed
-	if currentRole != model.RoleSuperAdmin && currentRole != model.RoleHR {
+	if !middleware.HasPermissionOrSuperAdmin(currentRole, middleware.PermManageEmployees) {
 		c.JSON(http.StatusForbidden, gin.H{
 			"code":    "FORBIDDEN",
 			"message": "You don't have permission to update this employee",
@@ -229,19 +365,45 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 		return
 	}
 
-	employee, err := h.employeeService.AdminUpdate(uint(id), &req)
+	employee, err := h.employeeService.AdminUpdate(c.Request.Context(), uint(id), currentUserID, &req)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		if respondIfConflict(c, err) {
+			return
+		}
 		switch {
 		case errors.Is(err, service.ErrEmployeeNotFound):
 			c.JSON(http.StatusNotFound, gin.H{
 				"code":    "EMPLOYEE_NOT_FOUND",
 				"message": "Employee not found",
 			})
+		case errors.Is(err, service.ErrEmailExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "EMAIL_EXISTS",
+				"message": "Email already exists",
+			})
 		case errors.Is(err, service.ErrSupervisorNotFound):
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    "SUPERVISOR_NOT_FOUND",
 				"message": "Specified supervisor not found",
 			})
+		case errors.Is(err, service.ErrSelfSupervisor):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "SELF_SUPERVISOR",
+				"message": "An employee cannot be their own supervisor",
+			})
+		case errors.Is(err, service.ErrCircularSupervisor):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CIRCULAR_SUPERVISOR",
+				"message": "Supervisor assignment would create a reporting cycle",
+			})
+		case errors.Is(err, service.ErrEmployeeDepartmentNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "DEPARTMENT_NOT_FOUND",
+				"message": "Specified department not found",
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -277,8 +439,14 @@ func (h *EmployeeHandler) UpdateRole(c *gin.Context) {
 		return
 	}
 
-	employee, err := h.employeeService.UpdateRole(uint(id), &req)
+	employee, err := h.employeeService.UpdateRole(c.Request.Context(), uint(id), middleware.GetUserID(c), &req)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		if respondIfConflict(c, err) {
+			return
+		}
 		switch {
 		case errors.Is(err, service.ErrEmployeeNotFound):
 			c.JSON(http.StatusNotFound, gin.H{
@@ -290,6 +458,11 @@ func (h *EmployeeHandler) UpdateRole(c *gin.Context) {
 				"code":    "INVALID_ROLE",
 				"message": "Invalid role specified",
 			})
+		case errors.Is(err, service.ErrLastSuperAdmin):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "LAST_SUPER_ADMIN",
+				"message": "Cannot remove the last active super admin",
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -326,8 +499,14 @@ func (h *EmployeeHandler) UpdateSupervisor(c *gin.Context) {
 		return
 	}
 
-	employee, err := h.employeeService.UpdateSupervisor(uint(id), &req)
+	employee, err := h.employeeService.UpdateSupervisor(c.Request.Context(), uint(id), middleware.GetUserID(c), &req)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		if respondIfConflict(c, err) {
+			return
+		}
 		switch {
 		case errors.Is(err, service.ErrEmployeeNotFound):
 			c.JSON(http.StatusNotFound, gin.H{
@@ -339,6 +518,16 @@ func (h *EmployeeHandler) UpdateSupervisor(c *gin.Context) {
 				"code":    "SUPERVISOR_NOT_FOUND",
 				"message": "Specified supervisor not found",
 			})
+		case errors.Is(err, service.ErrSelfSupervisor):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "SELF_SUPERVISOR",
+				"message": "An employee cannot be their own supervisor",
+			})
+		case errors.Is(err, service.ErrCircularSupervisor):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CIRCULAR_SUPERVISOR",
+				"message": "Supervisor assignment would create a reporting cycle",
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code":    "INTERNAL_ERROR",
@@ -351,6 +540,106 @@ func (h *EmployeeHandler) UpdateSupervisor(c *gin.Context) {
 	c.JSON(http.StatusOK, employee)
 }
 
+// GetSupervisorHistory returns an employee's reporting line changes, newest first
+// GET /api/employees/:id/supervisor-history
+func (h *EmployeeHandler) GetSupervisorHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid employee ID",
+		})
+		return
+	}
+
+	history, err := h.employeeService.GetSupervisorHistory(c.Request.Context(), uint(id))
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrEmployeeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "EMPLOYEE_NOT_FOUND",
+				"message": "Employee not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve supervisor history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// UpdateDelegate sets or clears a supervisor's leave approval delegate
+// PUT /api/employees/:id/delegate
+func (h *EmployeeHandler) UpdateDelegate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid employee ID",
+		})
+		return
+	}
+
+	// Only the supervisor themselves or HR/admin can set a delegate
+	currentUserID := middleware.GetUserID(c)
+	currentRole := middleware.GetRole(c)
+
+	if uint(id) != currentUserID && !middleware.HasPermissionOrSuperAdmin(currentRole, middleware.PermManageEmployees) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    "FORBIDDEN",
+			"message": "You don't have permission to set this employee's delegate",
+		})
+		return
+	}
+
+	var req service.UpdateDelegateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	employee, err := h.employeeService.UpdateDelegate(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		if respondIfConflict(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrEmployeeNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "EMPLOYEE_NOT_FOUND",
+				"message": "Employee not found",
+			})
+		case errors.Is(err, service.ErrInvalidDelegate):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_DELEGATE",
+				"message": "Delegate must be another active employee",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to update delegate",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, employee)
+}
+
 // UpdateStatus enables or disables an employee account
 // PUT /api/employees/:id/status
 func (h *EmployeeHandler) UpdateStatus(c *gin.Context) {
@@ -375,8 +664,14 @@ func (h *EmployeeHandler) UpdateStatus(c *gin.Context) {
 	}
 
 	currentUserID := middleware.GetUserID(c)
-	employee, err := h.employeeService.UpdateStatus(uint(id), currentUserID, &req)
+	employee, err := h.employeeService.UpdateStatus(c.Request.Context(), uint(id), currentUserID, &req)
 	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		if respondIfConflict(c, err) {
+			return
+		}
 		switch {
 		case errors.Is(err, service.ErrEmployeeNotFound):
 			c.JSON(http.StatusNotFound, gin.H{
@@ -402,6 +697,9 @@ func (h *EmployeeHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
+	// Disabling or enabling must take effect on the employee's next request
+	middleware.InvalidateAccountCache(uint(id))
+
 	c.JSON(http.StatusOK, employee)
 }
 
@@ -418,23 +716,191 @@ func (h *EmployeeHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.employeeService.Delete(uint(id))
+	// Forced deletion is reserved for super admins
+	force := c.Query("force") == "true"
+	if force && middleware.GetRole(c) != model.RoleSuperAdmin {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    "FORBIDDEN",
+			"message": "Only super admin can force delete an employee",
+		})
+		return
+	}
+
+	err = h.employeeService.Delete(c.Request.Context(), uint(id), middleware.GetUserID(c), force)
 	if err != nil {
-		if errors.Is(err, service.ErrEmployeeNotFound) {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrEmployeeNotFound):
 			c.JSON(http.StatusNotFound, gin.H{
 				"code":    "EMPLOYEE_NOT_FOUND",
 				"message": "Employee not found",
 			})
-			return
+		case errors.Is(err, service.ErrEmployeeHasActiveAssets):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "EMPLOYEE_HAS_ACTIVE_ASSETS",
+				"message": "Employee still holds devices or has active bookings",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrLastSuperAdmin):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "LAST_SUPER_ADMIN",
+				"message": "Cannot remove the last active super admin",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to delete employee",
+			})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    "INTERNAL_ERROR",
-			"message": "Failed to delete employee",
-		})
 		return
 	}
 
+	middleware.InvalidateAccountCache(uint(id))
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Employee deleted successfully",
 	})
 }
+
+// Restore restores a soft-deleted employee
+// POST /api/employees/:id/restore
+func (h *EmployeeHandler) Restore(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid employee ID",
+		})
+		return
+	}
+
+	employee, err := h.employeeService.Restore(c.Request.Context(), uint(id))
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrEmployeeNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "EMPLOYEE_NOT_FOUND",
+				"message": "Employee not found",
+			})
+		case errors.Is(err, service.ErrEmployeeNotDeleted):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "EMPLOYEE_NOT_DELETED",
+				"message": "Employee is not deleted",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to restore employee",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, employee)
+}
+
+// UploadAvatar stores a profile photo for the current user, or for the employee in the path when
+// called by HR
+// POST /api/employees/me/avatar
+// POST /api/employees/:id/avatar
+func (h *EmployeeHandler) UploadAvatar(c *gin.Context) {
+	id := middleware.GetUserID(c)
+	if idStr := c.Param("id"); idStr != "" {
+		parsed, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid employee ID",
+			})
+			return
+		}
+		id = uint(parsed)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "An image file is required",
+		})
+		return
+	}
+
+	employee, err := h.employeeService.UploadAvatar(c.Request.Context(), id, file)
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrEmployeeNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "EMPLOYEE_NOT_FOUND",
+				"message": "Employee not found",
+			})
+		case errors.Is(err, service.ErrAvatarTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    "AVATAR_TOO_LARGE",
+				"message": "Image exceeds the maximum size",
+			})
+		case errors.Is(err, service.ErrAvatarTypeNotAllowed):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "AVATAR_TYPE_NOT_ALLOWED",
+				"message": "Only JPEG, PNG and WebP images are allowed",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to upload avatar",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, employee)
+}
+
+// GetAvatar serves an employee's profile photo
+// GET /api/employees/:id/avatar
+func (h *EmployeeHandler) GetAvatar(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid employee ID",
+		})
+		return
+	}
+
+	path, err := h.employeeService.GetAvatarPath(c.Request.Context(), uint(id))
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrEmployeeNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "EMPLOYEE_NOT_FOUND",
+				"message": "Employee not found",
+			})
+		case errors.Is(err, service.ErrAvatarNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "AVATAR_NOT_FOUND",
+				"message": "Employee has no avatar",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to retrieve avatar",
+			})
+		}
+		return
+	}
+
+	c.File(path)
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/middleware"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/jwt"
+)
+
+func newEmployeeHandler(t *testing.T, db *gorm.DB) *EmployeeHandler {
+	return NewEmployeeHandler(service.NewEmployeeService(db, &config.AvatarConfig{StorageDir: t.TempDir(), MaxSizeMB: 1}, &config.BookingConfig{}))
+}
+
+// reportTo makes supervisor the direct supervisor of each employee
+func reportTo(t *testing.T, db *gorm.DB, supervisor *model.Employee, employees ...*model.Employee) {
+	t.Helper()
+	for _, employee := range employees {
+		if err := db.Model(employee).Update("supervisor_id", supervisor.ID).Error; err != nil {
+			t.Fatalf("set supervisor of %s: %v", employee.Username, err)
+		}
+	}
+}
+
+func TestGetSubordinates(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newEmployeeHandler(t, db)
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	lead := testutil.CreateEmployee(t, db, "lead", model.RoleSupervisor)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	reportTo(t, db, boss, lead, alice)
+	reportTo(t, db, lead, bob)
+	reportTo(t, db, bob, carol)
+
+	usernames := func(path string, user *model.Employee) []string {
+		t.Helper()
+		rec := serve(http.MethodGet, "/employees/subordinates", path, "", user, h.GetSubordinates)
+		assertStatus(t, rec, http.StatusOK)
+		var employees []model.Employee
+		if err := json.Unmarshal(rec.Body.Bytes(), &employees); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		names := []string{}
+		for _, employee := range employees {
+			names = append(names, employee.Username)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	if got := usernames("/employees/subordinates", boss); !slices.Equal(got, []string{"alice", "lead"}) {
+		t.Errorf("direct reports = %v, want [alice lead]", got)
+	}
+	if got := usernames("/employees/subordinates?recursive=true", boss); !slices.Equal(got, []string{"alice", "bob", "carol", "lead"}) {
+		t.Errorf("recursive reports = %v, want [alice bob carol lead]", got)
+	}
+	if got := usernames("/employees/subordinates?recursive=true", alice); len(got) != 0 {
+		t.Errorf("reports of an employee without any = %v, want none", got)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newEmployeeHandler(t, db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	// A cancelled context aborts the repository call instead of running the query
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := h.employeeService.GetByID(ctx, alice.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetByID with a cancelled context: err = %v, want context.Canceled", err)
+	}
+
+	rec := serve(http.MethodGet, "/employees/me", "/employees/me", "", alice, middleware.RequestTimeout(time.Nanosecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		h.GetMe(c)
+	})
+	assertStatus(t, rec, http.StatusServiceUnavailable)
+	if !strings.Contains(rec.Body.String(), "REQUEST_TIMEOUT") {
+		t.Errorf("body = %s, want code REQUEST_TIMEOUT", rec.Body.String())
+	}
+}
+
+func TestCreateEmployeeLocation(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newEmployeeHandler(t, db)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+
+	rec := serve(http.MethodPost, "/api/employees", "/api/employees", `{"name":"Alice"}`, admin, h.Create)
+	assertStatus(t, rec, http.StatusCreated)
+	var created service.CreateEmployeeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	location := rec.Header().Get("Location")
+	if want := fmt.Sprintf("/api/employees/%d", created.Employee.ID); location != want {
+		t.Fatalf("Location = %q, want %q", location, want)
+	}
+
+	// The Location resolves through the GET route to the employee just created
+	rec = serve(http.MethodGet, "/api/employees/:id", location, "", admin, h.GetByID)
+	assertStatus(t, rec, http.StatusOK)
+	var fetched model.Employee
+	if err := json.Unmarshal(rec.Body.Bytes(), &fetched); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if fetched.ID != created.Employee.ID || fetched.Name != "Alice" {
+		t.Errorf("GET %s = employee %d %q, want %d Alice", location, fetched.ID, fetched.Name, created.Employee.ID)
+	}
+}
+
+func TestUploadAvatar(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newEmployeeHandler(t, db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	rec := uploadFile(t, "/employees/me/avatar", "/employees/me/avatar", alice, "avatar.png", []byte("not an image"), h.UploadAvatar)
+	assertStatus(t, rec, http.StatusBadRequest)
+	if !strings.Contains(rec.Body.String(), "AVATAR_TYPE_NOT_ALLOWED") {
+		t.Errorf("body = %s, want code AVATAR_TYPE_NOT_ALLOWED", rec.Body.String())
+	}
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	rec = uploadFile(t, "/employees/me/avatar", "/employees/me/avatar", alice, "avatar.png", png, h.UploadAvatar)
+	assertStatus(t, rec, http.StatusOK)
+	var stored model.Employee
+	if err := db.First(&stored, alice.ID).Error; err != nil {
+		t.Fatalf("load employee: %v", err)
+	}
+	if !strings.HasSuffix(stored.AvatarPath, ".png") {
+		t.Fatalf("avatar path = %q, want a stored .png", stored.AvatarPath)
+	}
+
+	rec = serve(http.MethodGet, "/employees/:id/avatar", fmt.Sprintf("/employees/%d/avatar", alice.ID), "", alice, h.GetAvatar)
+	assertStatus(t, rec, http.StatusOK)
+	if !bytes.Equal(rec.Body.Bytes(), png) {
+		t.Error("downloaded avatar differs from the uploaded image")
+	}
+}
+
+func TestStaleEmployeeUpdate(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newEmployeeHandler(t, db)
+	first := testutil.CreateEmployee(t, db, "hr1", model.RoleHR)
+	second := testutil.CreateEmployee(t, db, "hr2", model.RoleHR)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	path := fmt.Sprintf("/employees/%d", alice.ID)
+
+	// Both HR users edit the version they loaded
+	body := func(position string) string {
+		return fmt.Sprintf(`{"position":%q,"version":%d}`, position, alice.Version)
+	}
+	rec := serve(http.MethodPut, "/employees/:id", path, body("Engineer"), first, h.Update)
+	assertStatus(t, rec, http.StatusOK)
+	rec = serve(http.MethodPut, "/employees/:id", path, body("Manager"), second, h.Update)
+	assertStatus(t, rec, http.StatusConflict)
+	if !strings.Contains(rec.Body.String(), "CONCURRENT_MODIFICATION") {
+		t.Errorf("body = %s, want code CONCURRENT_MODIFICATION", rec.Body.String())
+	}
+
+	var stored model.Employee
+	if err := db.First(&stored, alice.ID).Error; err != nil {
+		t.Fatalf("load employee: %v", err)
+	}
+	if stored.Position != "Engineer" || stored.Version != alice.Version+1 {
+		t.Errorf("stored = %q version %d, want Engineer version %d", stored.Position, stored.Version, alice.Version+1)
+	}
+}
+
+func TestListIncludeDeleted(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newEmployeeHandler(t, db)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	gone := testutil.CreateEmployee(t, db, "gone", model.RoleEmployee)
+	if err := db.Delete(gone).Error; err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+
+	// list returns the usernames listed and whether each carries a deleted_at
+	list := func(path string) map[string]bool {
+		t.Helper()
+		rec := serve(http.MethodGet, "/employees", path, "", admin, h.List)
+		assertStatus(t, rec, http.StatusOK)
+		var page struct {
+			Items []map[string]any `json:"items"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		listed := map[string]bool{}
+		for _, item := range page.Items {
+			_, deleted := item["deleted_at"]
+			listed[item["username"].(string)] = deleted
+		}
+		return listed
+	}
+
+	if got := list("/employees"); len(got) != 2 || got["admin"] || got["hr"] {
+		t.Errorf("default list = %v, want admin and hr without deleted_at", got)
+	}
+	got := list("/employees?include_deleted=true")
+	if deleted, ok := got["gone"]; !ok || !deleted || got["admin"] {
+		t.Errorf("list with include_deleted = %v, want gone with deleted_at and the others without", got)
+	}
+
+	rec := serve(http.MethodGet, "/employees", "/employees?include_deleted=true", "", hr, h.List)
+	assertStatus(t, rec, http.StatusForbidden)
+}
+
+func TestDisableRevokesTokensWithinCacheTTL(t *testing.T) {
+	db := testutil.NewDB(t)
+	middleware.SetAccountCacheTTL(time.Minute)
+	t.Cleanup(func() { middleware.SetAccountCacheTTL(0) })
+	employeeService := service.NewEmployeeService(db, &config.AvatarConfig{StorageDir: t.TempDir(), MaxSizeMB: 1}, &config.BookingConfig{})
+	employeeService.SetRevokeTokensOnDisable(true)
+	h := NewEmployeeHandler(employeeService)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	jwtManager := jwt.NewJWTManager("test-secret", 1)
+	token := func() string {
+		t.Helper()
+		token, err := jwtManager.GenerateToken(alice.ID, alice.Username, alice.Role, false)
+		if err != nil {
+			t.Fatalf("GenerateToken: %v", err)
+		}
+		return token
+	}
+	// request calls a protected route with token and returns the response
+	request := func(token string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/me", middleware.AuthMiddleware(jwtManager), func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(middleware.AuthorizationHeader, middleware.BearerPrefix+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	setStatus := func(active bool) {
+		t.Helper()
+		rec := serve(http.MethodPut, "/employees/:id/status", fmt.Sprintf("/employees/%d/status", alice.ID), fmt.Sprintf(`{"is_active":%t}`, active), admin, h.UpdateStatus)
+		assertStatus(t, rec, http.StatusOK)
+	}
+
+	// The first request caches alice's account as active
+	before := token()
+	assertStatus(t, request(before), http.StatusOK)
+
+	setStatus(false)
+	rec := request(before)
+	assertStatus(t, rec, http.StatusUnauthorized)
+	if !strings.Contains(rec.Body.String(), "AUTH_ACCOUNT_DISABLED") {
+		t.Errorf("body = %s, want code AUTH_ACCOUNT_DISABLED", rec.Body.String())
+	}
+
+	// Re-enabled straight away, a fresh login works even within the second of the revocation
+	setStatus(true)
+	assertStatus(t, request(token()), http.StatusOK)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds how long the readiness probe waits on the database
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	db      *gorm.DB
+	version string
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(db *gorm.DB, version string) *HealthHandler {
+	return &HealthHandler{
+		db:      db,
+		version: version,
+	}
+}
+
+// Liveness reports that the process is up
+// GET /healthz
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"version": h.version,
+	})
+}
+
+// Readiness reports whether the database is reachable
+// GET /readyz
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := h.db.WithContext(ctx).Exec("SELECT 1").Error; err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "unavailable",
+			"version": h.version,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"version": h.version,
+	})
+}
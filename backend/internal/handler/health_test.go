@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"oa-system/internal/testutil"
+	"testing"
+)
+
+func TestReadiness(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := NewHealthHandler(db, "1.2.3")
+
+	rec := serve(http.MethodGet, "/readyz", "/readyz", "", nil, h.Readiness)
+	assertStatus(t, rec, http.StatusOK)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db handle: %v", err)
+	}
+	sqlDB.Close()
+
+	rec = serve(http.MethodGet, "/readyz", "/readyz", "", nil, h.Readiness)
+	assertStatus(t, rec, http.StatusServiceUnavailable)
+	var body struct {
+		Status  string `json:"status"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Status != "unavailable" || body.Version != "1.2.3" {
+		t.Errorf("body = %+v, want status unavailable and version 1.2.3", body)
+	}
+
+	rec = serve(http.MethodGet, "/healthz", "/healthz", "", nil, h.Liveness)
+	assertStatus(t, rec, http.StatusOK)
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/service"
+)
+
+// HolidayHandler handles holiday calendar HTTP requests
+type HolidayHandler struct {
+	holidayService *service.HolidayService
+}
+
+// NewHolidayHandler creates a new holiday handler
+func NewHolidayHandler(holidayService *service.HolidayService) *HolidayHandler {
+	return &HolidayHandler{
+		holidayService: holidayService,
+	}
+}
+
+// List handles listing the holidays of a year
+// GET /api/holidays?year=2024
+func (h *HolidayHandler) List(c *gin.Context) {
+	year := 0
+	if yearStr := c.Query("year"); yearStr != "" {
+		var err error
+		year, err = strconv.Atoi(yearStr)
+		if err != nil || year < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "无效的年份",
+			})
+			return
+		}
+	}
+
+	holidays, err := h.holidayService.List(year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取节假日列表失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, holidays)
+}
+
+// Create handles creating a holiday or make-up working day
+// POST /api/holidays
+func (h *HolidayHandler) Create(c *gin.Context) {
+	var req service.CreateHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	holiday, err := h.holidayService.Create(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrHolidayInvalidDate):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "日期格式无效，应为YYYY-MM-DD",
+			})
+		case errors.Is(err, service.ErrHolidayDateExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "HOLIDAY_EXISTS",
+				"message": "该日期已设置节假日",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "创建节假日失败",
+			})
+		}
+		return
+	}
+
+	respondCreated(c, "/api/holidays", holiday.ID, holiday)
+}
+
+// GetByID handles getting a single holiday
+// GET /api/holidays/:id
+func (h *HolidayHandler) GetByID(c *gin.Context) {
+	holidayID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的节假日ID",
+		})
+		return
+	}
+
+	holiday, err := h.holidayService.GetByID(uint(holidayID))
+	if err != nil {
+		if errors.Is(err, service.ErrHolidayNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "节假日不存在",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取节假日失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, holiday)
+}
+
+// Update handles updating a holiday
+// PUT /api/holidays/:id
+func (h *HolidayHandler) Update(c *gin.Context) {
+	holidayID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的节假日ID",
+		})
+		return
+	}
+
+	var req service.UpdateHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	holiday, err := h.holidayService.Update(uint(holidayID), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrHolidayNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "节假日不存在",
+			})
+		case errors.Is(err, service.ErrHolidayInvalidDate):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "日期格式无效，应为YYYY-MM-DD",
+			})
+		case errors.Is(err, service.ErrHolidayDateExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "HOLIDAY_EXISTS",
+				"message": "该日期已设置节假日",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "更新节假日失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, holiday)
+}
+
+// Delete handles deleting a holiday
+// DELETE /api/holidays/:id
+func (h *HolidayHandler) Delete(c *gin.Context) {
+	holidayID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的节假日ID",
+		})
+		return
+	}
+
+	if err := h.holidayService.Delete(uint(holidayID)); err != nil {
+		if errors.Is(err, service.ErrHolidayNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "节假日不存在",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "删除节假日失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "节假日删除成功",
+	})
+}
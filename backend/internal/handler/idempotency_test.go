@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/config"
+	"oa-system/internal/middleware"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+)
+
+// postIdempotent posts body through the Idempotent middleware and handler as user with the given key
+func postIdempotent(route string, user *model.Employee, key, body string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserID, user.ID)
+		c.Set(middleware.ContextRole, user.Role)
+	})
+	router.POST(route, middleware.Idempotent(), handler)
+	req := httptest.NewRequest(http.MethodPost, route, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	db := testutil.NewDB(t)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	devices := NewDeviceHandler(service.NewDeviceService(db, &config.DeviceConfig{}))
+	rooms := NewMeetingRoomHandler(service.NewMeetingRoomService(db, &config.BookingConfig{MinDurationMinutes: 15, MaxDurationMinutes: 240}))
+	device := &model.Device{Name: "ThinkPad", TotalQuantity: 3, AvailableQuantity: 3}
+	room := &model.MeetingRoom{Name: "A", Capacity: 6}
+	db.Create(device)
+	db.Create(room)
+
+	countRequests := func(employee *model.Employee) int64 {
+		t.Helper()
+		var count int64
+		db.Model(&model.DeviceRequest{}).Where("employee_id = ?", employee.ID).Count(&count)
+		return count
+	}
+	requestBody := fmt.Sprintf(`{"device_id":%d}`, device.ID)
+	first := postIdempotent("/device-requests", alice, "k1", requestBody, devices.CreateRequest)
+	assertStatus(t, first, http.StatusCreated)
+	replay := postIdempotent("/device-requests", alice, "k1", requestBody, devices.CreateRequest)
+	assertStatus(t, replay, http.StatusCreated)
+	if replay.Body.String() != first.Body.String() || replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replay = %s, want the original %s marked as replayed", replay.Body.String(), first.Body.String())
+	}
+	if got := countRequests(alice); got != 1 {
+		t.Errorf("device requests after a replay = %d, want 1", got)
+	}
+
+	// Keys are scoped per user, and a new key is a new request
+	assertStatus(t, postIdempotent("/device-requests", bob, "k1", requestBody, devices.CreateRequest), http.StatusCreated)
+	assertStatus(t, postIdempotent("/device-requests", alice, "k2", requestBody, devices.CreateRequest), http.StatusCreated)
+	if alices, bobs := countRequests(alice), countRequests(bob); alices != 2 || bobs != 1 {
+		t.Errorf("device requests = %d for alice and %d for bob, want 2 and 1", alices, bobs)
+	}
+	if rec := postIdempotent("/device-requests", alice, "k1", `{"device_id":999}`, devices.CreateRequest); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("key reused with another body: status = %d, want 422", rec.Code)
+	}
+
+	bookingBody := fmt.Sprintf(`{"meeting_room_id":%d,"booking_date":"%s","start_time":"10:00","end_time":"11:00"}`,
+		room.ID, service.Today().AddDate(0, 0, 7).Format("2006-01-02"))
+	booked := postIdempotent("/meeting-room-bookings", alice, "b1", bookingBody, rooms.CreateBooking)
+	assertStatus(t, booked, http.StatusCreated)
+	rebooked := postIdempotent("/meeting-room-bookings", alice, "b1", bookingBody, rooms.CreateBooking)
+	assertStatus(t, rebooked, http.StatusCreated)
+	if location := booked.Header().Get("Location"); location == "" || rebooked.Header().Get("Location") != location {
+		t.Errorf("replayed Location = %q, want %q", rebooked.Header().Get("Location"), location)
+	}
+	if rebooked.Body.String() != booked.Body.String() {
+		t.Errorf("replayed booking = %s, want %s", rebooked.Body.String(), booked.Body.String())
+	}
+}
@@ -14,13 +14,15 @@ import (
 
 // LeaveHandler handles leave request HTTP requests
 type LeaveHandler struct {
-	leaveService *service.LeaveService
+	leaveService      *service.LeaveService
+	attachmentService *service.AttachmentService
 }
 
 // NewLeaveHandler creates a new leave handler
-func NewLeaveHandler(leaveService *service.LeaveService) *LeaveHandler {
+func NewLeaveHandler(leaveService *service.LeaveService, attachmentService *service.AttachmentService) *LeaveHandler {
 	return &LeaveHandler{
-		leaveService: leaveService,
+		leaveService:      leaveService,
+		attachmentService: attachmentService,
 	}
 }
 
@@ -42,39 +44,200 @@ func (h *LeaveHandler) Create(c *gin.Context) {
 	leave, err := h.leaveService.Create(employeeID, &req)
 	if err != nil {
 		switch {
-		case errors.Is(err, service.ErrLeaveInvalidDateRange):
+		case errors.Is(err, service.ErrAttendanceIssuesBlockLeave):
 			c.JSON(http.StatusBadRequest, gin.H{
-				"code":    "VALIDATION_ERROR",
-				"message": "结束日期必须大于或等于开始日期",
+				"code":    "ATTENDANCE_ISSUES_BLOCK_LEAVE",
+				"message": "上月缺少签退记录过多，请先处理考勤异常",
+				"details": err.Error(),
+			})
+		default:
+			respondLeaveValidationError(c, err)
+		}
+		return
+	}
+
+	respondCreated(c, "/api/leaves", leave.ID, leave)
+}
+
+// GetByID handles getting a single leave request, visible to its owner and approvers
+// GET /api/leaves/:id
+func (h *LeaveHandler) GetByID(c *gin.Context) {
+	leaveID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的请假申请ID",
+		})
+		return
+	}
+
+	leave, err := h.leaveService.CheckViewer(uint(leaveID), middleware.GetUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLeaveRequestNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "请假申请不存在",
+			})
+		case errors.Is(err, service.ErrLeaveAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "无权查看此请假申请",
 			})
 		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "获取请假申请失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, leave)
+}
+
+// Update handles editing the type, dates and reason of the employee's own pending leave request
+// PUT /api/leaves/:id
+func (h *LeaveHandler) Update(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	leaveID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的请假申请ID",
+		})
+		return
+	}
+
+	var req service.UpdateLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	leave, err := h.leaveService.Update(uint(leaveID), employeeID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLeaveRequestNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "请假申请不存在",
+			})
+		case errors.Is(err, service.ErrLeaveAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "只能修改自己的请假申请",
+			})
+		case errors.Is(err, service.ErrLeaveInvalidStatus):
 			c.JSON(http.StatusBadRequest, gin.H{
-				"code":    "VALIDATION_ERROR",
-				"message": err.Error(),
+				"code":    "LEAVE_INVALID_STATUS",
+				"message": "只能修改待审批的请假申请",
 			})
+		default:
+			respondLeaveValidationError(c, err)
 		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, leave)
+	c.JSON(http.StatusOK, leave)
 }
 
+// respondLeaveValidationError maps the errors of the date and length checks shared by creating
+// and editing a leave request, and of the overlap check on edits, to HTTP responses
+func respondLeaveValidationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrLeaveInvalidDateRange):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "结束日期必须大于或等于开始日期",
+		})
+	case errors.Is(err, service.ErrLeaveStartInPast):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "LEAVE_START_IN_PAST",
+			"message": "开始日期不能早于今天",
+		})
+	case errors.Is(err, service.ErrLeaveExceedsMax):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "LEAVE_EXCEEDS_MAX",
+			"message": "请假天数超过该类型的上限",
+			"details": err.Error(),
+		})
+	case errors.Is(err, service.ErrLeaveOverlap):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    "LEAVE_OVERLAP",
+			"message": "与已有的待审批或已批准请假时间重叠",
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": err.Error(),
+		})
+	}
+}
 
 // GetMyLeaves handles getting the current employee's leave requests
-// GET /api/leaves
+// GET /api/leaves?start=&end=&leave_type=&status=
 func (h *LeaveHandler) GetMyLeaves(c *gin.Context) {
 	employeeID := middleware.GetUserID(c)
 
-	leaves, err := h.leaveService.GetMyLeaves(employeeID)
+	filters := make(map[string]interface{})
+	for _, key := range []string{"start", "end", "leave_type", "status"} {
+		if value := c.Query(key); value != "" {
+			filters[key] = value
+		}
+	}
+
+	leaves, err := h.leaveService.GetMyLeaves(employeeID, filters)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLeaveInvalidDateRange):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "结束日期必须大于或等于开始日期",
+			})
+		case errors.Is(err, service.ErrLeaveInvalidDateFormat):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "日期格式无效，应为 YYYY-MM-DD",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "获取请假记录失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, leaves)
+}
+
+// GetCalendar handles getting the team's approved leaves for a month
+// GET /api/leaves/calendar?year=&month=
+func (h *LeaveHandler) GetCalendar(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	role := middleware.GetRole(c)
+
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.leaveService.GetCalendar(userID, role, year, month)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    "INTERNAL_ERROR",
-			"message": "获取请假记录失败",
+			"message": "获取请假日历失败",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, leaves)
+	c.JSON(http.StatusOK, entries)
 }
 
 // GetPending handles getting pending leave requests for supervisor approval
@@ -94,6 +257,25 @@ func (h *LeaveHandler) GetPending(c *gin.Context) {
 	c.JSON(http.StatusOK, leaves)
 }
 
+// GetPendingCount handles counting pending leave requests awaiting the supervisor
+// GET /api/leaves/pending/count
+func (h *LeaveHandler) GetPendingCount(c *gin.Context) {
+	supervisorID := middleware.GetUserID(c)
+
+	count, err := h.leaveService.CountPendingForSupervisor(supervisorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取待审批请假数量失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": count,
+	})
+}
+
 // Approve handles approving a leave request
 // PUT /api/leaves/:id/approve
 func (h *LeaveHandler) Approve(c *gin.Context) {
@@ -202,6 +384,56 @@ func (h *LeaveHandler) Reject(c *gin.Context) {
 	c.JSON(http.StatusOK, leave)
 }
 
+// BulkApprove handles approving many leave requests at once
+// PUT /api/leaves/bulk-approve
+func (h *LeaveHandler) BulkApprove(c *gin.Context) {
+	var req service.BulkApproveLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请提供请假申请ID列表",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.leaveService.BulkApprove(middleware.GetUserID(c), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "批量审批请假申请失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// BulkReject handles rejecting many leave requests at once with a shared reason
+// PUT /api/leaves/bulk-reject
+func (h *LeaveHandler) BulkReject(c *gin.Context) {
+	var req service.BulkRejectLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请提供请假申请ID列表和拒绝原因",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.leaveService.BulkReject(middleware.GetUserID(c), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "批量拒绝请假申请失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // Cancel handles cancelling a leave request
 // PUT /api/leaves/:id/cancel
 func (h *LeaveHandler) Cancel(c *gin.Context) {
@@ -255,3 +487,121 @@ func (h *LeaveHandler) Cancel(c *gin.Context) {
 
 	c.JSON(http.StatusOK, leave)
 }
+
+// UploadAttachment handles attaching a file (e.g. a doctor's note) to the employee's own leave request
+// POST /api/leaves/:id/attachments
+func (h *LeaveHandler) UploadAttachment(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	leaveID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的请假申请ID",
+		})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请上传附件文件",
+		})
+		return
+	}
+
+	leave, err := h.leaveService.CheckOwner(uint(leaveID), employeeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLeaveRequestNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "请假申请不存在",
+			})
+		case errors.Is(err, service.ErrLeaveAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "只能为自己的请假申请上传附件",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "上传附件失败",
+			})
+		}
+		return
+	}
+
+	attachment, err := h.attachmentService.Upload(model.AttachmentOwnerLeave, leave.ID, employeeID, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAttachmentTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    "ATTACHMENT_TOO_LARGE",
+				"message": "附件大小超出限制",
+			})
+		case errors.Is(err, service.ErrAttachmentTypeNotAllowed):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "ATTACHMENT_TYPE_NOT_ALLOWED",
+				"message": "仅支持 PDF、JPG、PNG 格式的附件",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "上传附件失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// ListAttachments handles listing a leave request's attachments for its owner or approvers
+// GET /api/leaves/:id/attachments
+func (h *LeaveHandler) ListAttachments(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	leaveID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的请假申请ID",
+		})
+		return
+	}
+
+	leave, err := h.leaveService.CheckViewer(uint(leaveID), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLeaveRequestNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "请假申请不存在",
+			})
+		case errors.Is(err, service.ErrLeaveAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "无权查看此请假申请的附件",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "获取附件失败",
+			})
+		}
+		return
+	}
+
+	attachments, err := h.attachmentService.List(model.AttachmentOwnerLeave, leave.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取附件失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
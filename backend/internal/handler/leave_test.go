@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/middleware"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+)
+
+func newLeaveHandler(t *testing.T, db *gorm.DB) *LeaveHandler {
+	return NewLeaveHandler(
+		service.NewLeaveService(db, &config.LeaveConfig{}),
+		service.NewAttachmentService(db, &config.AttachmentConfig{StorageDir: t.TempDir(), MaxSizeMB: 1}),
+	)
+}
+
+// createLeave inserts a pending leave request for employee on the given day
+func createLeave(t *testing.T, db *gorm.DB, employee *model.Employee, day time.Time) *model.LeaveRequest {
+	t.Helper()
+	leave := &model.LeaveRequest{EmployeeID: employee.ID, LeaveType: model.LeaveTypeSick, StartDate: day, EndDate: day, Status: model.LeaveStatusPending}
+	if err := db.Create(leave).Error; err != nil {
+		t.Fatalf("create leave: %v", err)
+	}
+	return leave
+}
+
+// uploadFile posts content as the multipart "file" field through handler as user
+func uploadFile(t *testing.T, route, path string, user *model.Employee, filename string, content []byte, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write(content)
+	form.Close()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserID, user.ID)
+		c.Set(middleware.ContextRole, user.Role)
+	})
+	router.POST(route, handler)
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLeaveAttachments(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newLeaveHandler(t, db)
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	reportTo(t, db, boss, alice, bob)
+	leave := createLeave(t, db, alice, service.Today())
+	path := fmt.Sprintf("/leaves/%d/attachments", leave.ID)
+	note := append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("x"), 64)...)
+
+	rec := uploadFile(t, "/leaves/:id/attachments", path, alice, "note.pdf", note, h.UploadAttachment)
+	assertStatus(t, rec, http.StatusCreated)
+
+	rec = uploadFile(t, "/leaves/:id/attachments", path, alice, "huge.pdf", append(note, make([]byte, 1<<20)...), h.UploadAttachment)
+	assertStatus(t, rec, http.StatusRequestEntityTooLarge)
+	rec = uploadFile(t, "/leaves/:id/attachments", path, alice, "note.txt", []byte("plain text"), h.UploadAttachment)
+	assertStatus(t, rec, http.StatusBadRequest)
+	rec = uploadFile(t, "/leaves/:id/attachments", path, bob, "note.pdf", note, h.UploadAttachment)
+	assertStatus(t, rec, http.StatusForbidden)
+
+	for _, user := range []*model.Employee{alice, boss} {
+		rec := serve(http.MethodGet, "/leaves/:id/attachments", path, "", user, h.ListAttachments)
+		assertStatus(t, rec, http.StatusOK)
+		if !bytes.Contains(rec.Body.Bytes(), []byte("note.pdf")) {
+			t.Errorf("%s: attachment list %s lacks note.pdf", user.Username, rec.Body.String())
+		}
+	}
+	assertStatus(t, serve(http.MethodGet, "/leaves/:id/attachments", path, "", bob, h.ListAttachments), http.StatusForbidden)
+}
+
+func TestUpdateLeaveErrors(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := newLeaveHandler(t, db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	day := service.Today().AddDate(0, 0, 14)
+	leave := createLeave(t, db, alice, day)
+	createLeave(t, db, alice, day.AddDate(0, 0, 1))
+	body := func(start, end time.Time) string {
+		return fmt.Sprintf(`{"leave_type":%q,"start_date":%q,"end_date":%q}`, model.LeaveTypeSick, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	path := fmt.Sprintf("/leaves/%d", leave.ID)
+
+	rec := serve(http.MethodPut, "/leaves/:id", path, body(day, day.AddDate(0, 0, 1)), alice, h.Update)
+	assertStatus(t, rec, http.StatusConflict)
+	if !strings.Contains(rec.Body.String(), "LEAVE_OVERLAP") {
+		t.Errorf("body = %s, want code LEAVE_OVERLAP", rec.Body.String())
+	}
+	rec = serve(http.MethodPut, "/leaves/:id", path, body(day, day.AddDate(0, 0, -1)), alice, h.Update)
+	assertStatus(t, rec, http.StatusBadRequest)
+
+	if err := db.Model(leave).Update("status", model.LeaveStatusApproved).Error; err != nil {
+		t.Fatalf("approve leave: %v", err)
+	}
+	rec = serve(http.MethodPut, "/leaves/:id", path, body(day, day), alice, h.Update)
+	assertStatus(t, rec, http.StatusBadRequest)
+	if !strings.Contains(rec.Body.String(), "LEAVE_INVALID_STATUS") {
+		t.Errorf("body = %s, want code LEAVE_INVALID_STATUS", rec.Body.String())
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/middleware"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	service.SetTimezone(time.UTC)
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// serve runs a single request through handlers as the given user, or anonymously
+// when user is nil, registering them on route so path parameters are bound
+func serve(method, route, path, body string, user *model.Employee, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user == nil {
+			return
+		}
+		c.Set(middleware.ContextUserID, user.ID)
+		c.Set(middleware.ContextUsername, user.Username)
+		c.Set(middleware.ContextRole, user.Role)
+		c.Set(middleware.ContextIsFirstLogin, false)
+	})
+	router.Handle(method, route, handlers...)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// assertStatus fails the test when the response status differs from want
+func assertStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d (%s), want %d %s", rec.Code, rec.Body.String(), want, http.StatusText(want))
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"oa-system/internal/middleware"
+	"oa-system/internal/model"
 	"oa-system/internal/service"
 )
 
@@ -47,14 +49,34 @@ func (h *MeetingRoomHandler) CreateMeetingRoom(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, room)
+	respondCreated(c, "/api/meeting-rooms", room.ID, room)
 }
 
 
 // GetAllMeetingRooms handles getting all meeting rooms
-// GET /api/meeting-rooms
+// GET /api/meeting-rooms?amenity=projector&min_capacity=10&q=3f
 func (h *MeetingRoomHandler) GetAllMeetingRooms(c *gin.Context) {
-	rooms, err := h.meetingRoomService.GetAllMeetingRooms()
+	filters := make(map[string]interface{})
+
+	if amenity := c.Query("amenity"); amenity != "" {
+		filters["amenity"] = amenity
+	}
+	if minCapacityStr := c.Query("min_capacity"); minCapacityStr != "" {
+		minCapacity, err := strconv.Atoi(minCapacityStr)
+		if err != nil || minCapacity < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "无效的最小容量",
+			})
+			return
+		}
+		filters["min_capacity"] = minCapacity
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		filters["q"] = q
+	}
+
+	rooms, err := h.meetingRoomService.GetAllMeetingRooms(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    "INTERNAL_ERROR",
@@ -173,7 +195,7 @@ func (h *MeetingRoomHandler) DeleteMeetingRoom(c *gin.Context) {
 }
 
 // GetRoomAvailability handles getting a meeting room's availability
-// GET /api/meeting-rooms/:id/availability
+// GET /api/meeting-rooms/:id/availability?date=2024-01-15&start_time=09:00&end_time=10:00
 func (h *MeetingRoomHandler) GetRoomAvailability(c *gin.Context) {
 	roomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -193,7 +215,7 @@ func (h *MeetingRoomHandler) GetRoomAvailability(c *gin.Context) {
 		return
 	}
 
-	availability, err := h.meetingRoomService.GetRoomAvailability(uint(roomID), date)
+	availability, err := h.meetingRoomService.GetRoomAvailability(uint(roomID), date, c.Query("start_time"), c.Query("end_time"))
 	if err != nil {
 		if errors.Is(err, service.ErrMeetingRoomNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -202,6 +224,13 @@ func (h *MeetingRoomHandler) GetRoomAvailability(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrBookingInvalidTime) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "时间格式无效，应为HH:MM",
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    "VALIDATION_ERROR",
 			"message": err.Error(),
@@ -212,6 +241,93 @@ func (h *MeetingRoomHandler) GetRoomAvailability(c *gin.Context) {
 	c.JSON(http.StatusOK, availability)
 }
 
+// GetFreeSlots handles getting a meeting room's free intervals for a date
+// GET /api/meeting-rooms/:id/free-slots?date=2024-01-15&duration=30
+func (h *MeetingRoomHandler) GetFreeSlots(c *gin.Context) {
+	roomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的会议室ID",
+		})
+		return
+	}
+
+	date := c.Query("date")
+	if date == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请提供日期参数 (date=YYYY-MM-DD)",
+		})
+		return
+	}
+
+	var duration int
+	if durationStr := c.Query("duration"); durationStr != "" {
+		duration, err = strconv.Atoi(durationStr)
+		if err != nil || duration < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "无效的时长参数",
+			})
+			return
+		}
+	}
+
+	slots, err := h.meetingRoomService.GetFreeSlots(uint(roomID), date, duration)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMeetingRoomNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "会议室不存在",
+			})
+		case errors.Is(err, service.ErrBookingDuration):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_BOOKING_DURATION",
+				"message": "预定时长超出允许范围",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
+
+// GetAvailabilityRange handles getting every room's bookings over a range of days
+// GET /api/meeting-rooms/availability?start=2024-01-15&days=7
+func (h *MeetingRoomHandler) GetAvailabilityRange(c *gin.Context) {
+	days := 7
+	if daysStr := c.Query("days"); daysStr != "" {
+		var err error
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "无效的天数",
+			})
+			return
+		}
+	}
+
+	schedules, err := h.meetingRoomService.GetAvailabilityRange(c.Query("start"), days)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
 
 // ===== Booking Management =====
 
@@ -244,11 +360,94 @@ func (h *MeetingRoomHandler) CreateBooking(c *gin.Context) {
 				"message": "会议室预定时间冲突",
 				"details": conflictInfo,
 			})
+		case errors.Is(err, service.ErrBookingDuration):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_BOOKING_DURATION",
+				"message": "预定时长超出允许范围",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrBookingInvalidTime):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "时间格式无效，应为HH:MM",
+			})
 		case errors.Is(err, service.ErrBookingLimitExceeded):
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    "BOOKING_LIMIT_EXCEEDED",
 				"message": "已有活跃预定，不能再预定",
 			})
+		case errors.Is(err, service.ErrBookingDuringLeave):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_DURING_LEAVE",
+				"message": "预定日期处于已批准的请假期间",
+			})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	respondCreated(c, "/api/meeting-room-bookings", booking.ID, booking)
+}
+
+// CreateBookingFor handles booking a meeting room on behalf of another employee
+// POST /api/meeting-room-bookings/for
+func (h *MeetingRoomHandler) CreateBookingFor(c *gin.Context) {
+	creatorID := middleware.GetUserID(c)
+
+	var req service.CreateBookingForRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	booking, conflictInfo, err := h.meetingRoomService.CreateBookingFor(creatorID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBookingEmployeeNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "EMPLOYEE_NOT_FOUND",
+				"message": "预定对象员工不存在或已停用",
+			})
+		case errors.Is(err, service.ErrMeetingRoomNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "会议室不存在",
+			})
+		case errors.Is(err, service.ErrBookingConflict):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "BOOKING_CONFLICT",
+				"message": "会议室预定时间冲突",
+				"details": conflictInfo,
+			})
+		case errors.Is(err, service.ErrBookingDuration):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_BOOKING_DURATION",
+				"message": "预定时长超出允许范围",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrBookingInvalidTime):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "时间格式无效，应为HH:MM",
+			})
+		case errors.Is(err, service.ErrBookingLimitExceeded):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_LIMIT_EXCEEDED",
+				"message": "该员工已有活跃预定，不能再预定",
+			})
+		case errors.Is(err, service.ErrBookingDuringLeave):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_DURING_LEAVE",
+				"message": "该员工在预定日期处于已批准的请假期间",
+			})
 		default:
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code":    "VALIDATION_ERROR",
@@ -258,7 +457,7 @@ func (h *MeetingRoomHandler) CreateBooking(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, booking)
+	respondCreated(c, "/api/meeting-room-bookings", booking.ID, booking)
 }
 
 // GetMyBookings handles getting the current employee's bookings
@@ -278,6 +477,48 @@ func (h *MeetingRoomHandler) GetMyBookings(c *gin.Context) {
 	c.JSON(http.StatusOK, bookings)
 }
 
+// GetBooking handles getting a single booking, visible to the booker, whoever booked it on
+// their behalf, and super admins
+// GET /api/meeting-room-bookings/:id
+func (h *MeetingRoomHandler) GetBooking(c *gin.Context) {
+	bookingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的预定ID",
+		})
+		return
+	}
+
+	booking, err := h.meetingRoomService.GetBookingByID(uint(bookingID))
+	if err != nil {
+		if errors.Is(err, service.ErrBookingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "预定不存在",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取预定失败",
+		})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	isCreator := booking.CreatedBy != nil && *booking.CreatedBy == userID
+	if booking.EmployeeID != userID && !isCreator && middleware.GetRole(c) != model.RoleSuperAdmin {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    "FORBIDDEN",
+			"message": "无权查看该预定",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
 
 // CompleteBooking handles marking a booking as completed
 // PUT /api/meeting-room-bookings/:id/complete
@@ -366,3 +607,267 @@ func (h *MeetingRoomHandler) CancelBooking(c *gin.Context) {
 
 	c.JSON(http.StatusOK, booking)
 }
+
+// JoinWaitlist handles waiting for a taken slot, which is booked automatically once it frees up
+// POST /api/meeting-room-bookings/waitlist
+func (h *MeetingRoomHandler) JoinWaitlist(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	var req service.CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.meetingRoomService.JoinWaitlist(employeeID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMeetingRoomNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "会议室不存在",
+			})
+		case errors.Is(err, service.ErrWaitlistSlotAvailable):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "WAITLIST_SLOT_AVAILABLE",
+				"message": "该时间段空闲，请直接预定",
+			})
+		case errors.Is(err, service.ErrWaitlistDuplicate):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "WAITLIST_DUPLICATE",
+				"message": "已在该时间段的候补名单中",
+			})
+		case errors.Is(err, service.ErrBookingDuration):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_BOOKING_DURATION",
+				"message": "预定时长超出允许范围",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrBookingInvalidTime):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "时间格式无效，应为HH:MM",
+			})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	// Waitlist entries have no route of their own to point a Location header at
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetMyWaitlist handles getting the current employee's waitlist entries
+// GET /api/meeting-room-bookings/waitlist
+func (h *MeetingRoomHandler) GetMyWaitlist(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	entries, err := h.meetingRoomService.GetMyWaitlist(employeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取候补记录失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// LeaveWaitlist handles cancelling one of the current employee's waitlist entries
+// DELETE /api/meeting-room-bookings/waitlist/:id
+func (h *MeetingRoomHandler) LeaveWaitlist(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	entryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的候补ID",
+		})
+		return
+	}
+
+	if err := h.meetingRoomService.LeaveWaitlist(uint(entryID), employeeID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrWaitlistEntryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "候补记录不存在",
+			})
+		case errors.Is(err, service.ErrWaitlistNotOwner):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "只能取消自己的候补",
+			})
+		case errors.Is(err, service.ErrWaitlistInvalidStatus):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "WAITLIST_INVALID_STATUS",
+				"message": "候补已结束，不能取消",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "取消候补失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "候补已取消",
+	})
+}
+
+// UpdateBooking handles moving a booking to another date or time
+// PUT /api/meeting-room-bookings/:id
+func (h *MeetingRoomHandler) UpdateBooking(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	bookingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的预定ID",
+		})
+		return
+	}
+
+	var req service.UpdateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	booking, conflictInfo, err := h.meetingRoomService.UpdateBooking(uint(bookingID), employeeID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "预定不存在",
+			})
+		case errors.Is(err, service.ErrBookingNotOwner):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "只能修改自己的预定",
+			})
+		case errors.Is(err, service.ErrBookingInvalidStatus):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_INVALID_STATUS",
+				"message": "预定状态不允许此操作",
+			})
+		case errors.Is(err, service.ErrBookingAlreadyCheckedIn):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_ALREADY_CHECKED_IN",
+				"message": "已签到的预定不能修改",
+			})
+		case errors.Is(err, service.ErrBookingConflict):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "BOOKING_CONFLICT",
+				"message": "会议室预定时间冲突",
+				"details": conflictInfo,
+			})
+		case errors.Is(err, service.ErrBookingDuration):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_BOOKING_DURATION",
+				"message": "预定时长超出允许范围",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrBookingInvalidTime):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "时间格式无效，应为HH:MM",
+			})
+		case errors.Is(err, service.ErrMeetingRoomNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "会议室不存在",
+			})
+		case errors.Is(err, service.ErrBookingDuringLeave):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_DURING_LEAVE",
+				"message": "预定日期处于已批准的请假期间",
+			})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// CheckInBooking handles checking in to a booking
+// PUT /api/meeting-room-bookings/:id/check-in
+func (h *MeetingRoomHandler) CheckInBooking(c *gin.Context) {
+	employeeID := middleware.GetUserID(c)
+
+	bookingID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的预定ID",
+		})
+		return
+	}
+
+	booking, err := h.meetingRoomService.CheckInBooking(uint(bookingID), employeeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "预定不存在",
+			})
+		case errors.Is(err, service.ErrBookingNotOwner):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "只能签到自己的预定",
+			})
+		case errors.Is(err, service.ErrBookingInvalidStatus):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_INVALID_STATUS",
+				"message": "预定状态不允许此操作",
+			})
+		case errors.Is(err, service.ErrBookingAlreadyCheckedIn):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_ALREADY_CHECKED_IN",
+				"message": "预定已签到",
+			})
+		case errors.Is(err, service.ErrBookingCheckInNotOpen):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_CHECK_IN_NOT_OPEN",
+				"message": "预定尚未开始，暂不能签到",
+			})
+		case errors.Is(err, service.ErrBookingCheckInExpired):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "BOOKING_CHECK_IN_EXPIRED",
+				"message": "已超过签到时限",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "预定签到失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/middleware"
+	"oa-system/internal/service"
+)
+
+// notificationHeartbeatInterval is how often an idle stream sends a comment line so that proxies
+// do not close it
+const notificationHeartbeatInterval = 25 * time.Second
+
+// NotificationHandler handles notification HTTP requests
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// Delete removes one of the current user's notifications
+// DELETE /api/notifications/:id
+func (h *NotificationHandler) Delete(c *gin.Context) {
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的通知ID",
+		})
+		return
+	}
+
+	if err := h.notificationService.Delete(uint(notificationID), middleware.GetUserID(c)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotificationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "NOT_FOUND",
+				"message": "通知不存在",
+			})
+		case errors.Is(err, service.ErrNotificationNotOwner):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "只能删除自己的通知",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "删除通知失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "通知删除成功",
+	})
+}
+
+// Clear removes all of the current user's notifications, or only the read ones with ?read_only=true
+// DELETE /api/notifications
+func (h *NotificationHandler) Clear(c *gin.Context) {
+	readOnly, err := strconv.ParseBool(c.DefaultQuery("read_only", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的read_only参数",
+		})
+		return
+	}
+
+	deleted, err := h.notificationService.Clear(middleware.GetUserID(c), readOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "清空通知失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "通知已清空",
+		"deleted": deleted,
+	})
+}
+
+// GetPreferences returns the current user's delivery preference for every notification type
+// GET /api/notifications/preferences
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.notificationService.GetPreferences(middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取通知设置失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences changes the current user's delivery preferences for the listed notification types
+// PUT /api/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req service.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(middleware.GetUserID(c), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownNotificationType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "UNKNOWN_NOTIFICATION_TYPE",
+				"message": "未知的通知类型",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "更新通知设置失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// Stream pushes the current user's new notifications as server-sent events until the client disconnects
+// GET /api/notifications/stream
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	notifications, unsubscribe := h.notificationService.Subscribe(middleware.GetUserID(c))
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(notificationHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Writer.Flush()
+
+	done := c.Request.Context().Done()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-done:
+			return false
+		case notification := <-notifications:
+			c.SSEvent("notification", notification)
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+)
+
+// createNotification inserts a notification for employee, already read when read is set
+func createNotification(t *testing.T, db *gorm.DB, employee *model.Employee, read bool) *model.Notification {
+	t.Helper()
+	notification := &model.Notification{EmployeeID: employee.ID, Type: model.NotificationTypeDeviceTransferred, Title: "notice", IsRead: read}
+	if err := db.Create(notification).Error; err != nil {
+		t.Fatalf("create notification: %v", err)
+	}
+	return notification
+}
+
+func TestDeleteNotifications(t *testing.T) {
+	db := testutil.NewDB(t)
+	h := NewNotificationHandler(service.NewNotificationService(db))
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	read := createNotification(t, db, alice, true)
+	unread := createNotification(t, db, alice, false)
+	bobs := createNotification(t, db, bob, true)
+
+	remaining := func(employee *model.Employee) (ids []uint) {
+		t.Helper()
+		if err := db.Model(&model.Notification{}).Where("employee_id = ?", employee.ID).Order("id").Pluck("id", &ids).Error; err != nil {
+			t.Fatalf("list notifications: %v", err)
+		}
+		return ids
+	}
+
+	rec := serve(http.MethodDelete, "/notifications/:id", fmt.Sprintf("/notifications/%d", bobs.ID), "", alice, h.Delete)
+	assertStatus(t, rec, http.StatusForbidden)
+	if got := remaining(bob); len(got) != 1 {
+		t.Errorf("bob's notifications after alice's delete = %v, want [%d]", got, bobs.ID)
+	}
+
+	rec = serve(http.MethodDelete, "/notifications", "/notifications?read_only=true", "", alice, h.Clear)
+	assertStatus(t, rec, http.StatusOK)
+	if got := remaining(alice); len(got) != 1 || got[0] != unread.ID {
+		t.Errorf("alice's notifications after clearing read ones = %v, want [%d]", got, unread.ID)
+	}
+
+	rec = serve(http.MethodDelete, "/notifications/:id", fmt.Sprintf("/notifications/%d", unread.ID), "", alice, h.Delete)
+	assertStatus(t, rec, http.StatusOK)
+	if got := remaining(alice); len(got) != 0 {
+		t.Errorf("alice's notifications after deleting = %v, want none", got)
+	}
+	rec = serve(http.MethodDelete, "/notifications/:id", fmt.Sprintf("/notifications/%d", read.ID), "", alice, h.Delete)
+	assertStatus(t, rec, http.StatusNotFound)
+
+	// Deleted notifications are soft deleted, like other records
+	var stored int64
+	db.Unscoped().Model(&model.Notification{}).Where("employee_id = ?", alice.ID).Count(&stored)
+	if stored != 2 {
+		t.Errorf("soft deleted rows = %d, want 2", stored)
+	}
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/middleware"
+	"oa-system/internal/service"
+)
+
+// OnboardingHandler handles new hire onboarding HTTP requests
+type OnboardingHandler struct {
+	onboardingService *service.OnboardingService
+}
+
+// NewOnboardingHandler creates a new onboarding handler
+func NewOnboardingHandler(onboardingService *service.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{
+		onboardingService: onboardingService,
+	}
+}
+
+// Onboard creates a new hire with their onboarding contract and issued devices
+// POST /api/onboarding
+func (h *OnboardingHandler) Onboard(c *gin.Context) {
+	var req service.OnboardingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.onboardingService.Onboard(c.Request.Context(), &req)
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrInvalidRole):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_ROLE",
+				"message": "Invalid role specified",
+			})
+		case errors.Is(err, service.ErrEmailExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "EMAIL_EXISTS",
+				"message": "Email already exists",
+			})
+		case errors.Is(err, service.ErrSupervisorNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "SUPERVISOR_NOT_FOUND",
+				"message": "Specified supervisor not found",
+			})
+		case errors.Is(err, service.ErrEmployeeDepartmentNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "DEPARTMENT_NOT_FOUND",
+				"message": "Specified department not found",
+			})
+		case errors.Is(err, service.ErrEmployeeNoExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "EMPLOYEE_NO_CONFLICT",
+				"message": "Could not claim a free employee number, please retry",
+			})
+		case errors.Is(err, service.ErrContractTemplateNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "TEMPLATE_NOT_FOUND",
+				"message": "No onboarding contract template is configured",
+			})
+		case errors.Is(err, service.ErrInvalidContractExpiry):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_EXPIRY_DATE",
+				"message": "Contract expiry date must be a future date in YYYY-MM-DD format",
+			})
+		case errors.Is(err, service.ErrUnresolvedPlaceholder):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "UNRESOLVED_PLACEHOLDER",
+				"message": "Onboarding contract template contains placeholders that cannot be resolved for this employee",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrDeviceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "DEVICE_NOT_FOUND",
+				"message": "Device not found",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrDeviceNotAvailable):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "DEVICE_NOT_AVAILABLE",
+				"message": "Device is not available",
+				"details": err.Error(),
+			})
+		case errors.Is(err, service.ErrDeviceReserved):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "DEVICE_RESERVED",
+				"message": "Device is fully reserved",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to onboard employee",
+			})
+		}
+		return
+	}
+
+	respondCreated(c, "/api/employees", result.Employee.ID, result)
+}
+
+// Offboard disables a departing employee, generates their offboarding contract and returns the devices they still hold
+// POST /api/employees/:id/offboard
+func (h *OnboardingHandler) Offboard(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid employee ID",
+		})
+		return
+	}
+
+	result, err := h.onboardingService.Offboard(c.Request.Context(), uint(id), middleware.GetUserID(c))
+	if err != nil {
+		if respondIfTimedOut(c, err) {
+			return
+		}
+		if respondIfConflict(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrEmployeeNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "EMPLOYEE_NOT_FOUND",
+				"message": "Employee not found",
+			})
+		case errors.Is(err, service.ErrCannotModifySelf):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CANNOT_MODIFY_SELF",
+				"message": "Cannot offboard yourself",
+			})
+		case errors.Is(err, service.ErrCannotDisableSuperAdmin):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "CANNOT_DISABLE_SUPER_ADMIN",
+				"message": "Cannot offboard a super admin account",
+			})
+		case errors.Is(err, service.ErrContractTemplateNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "TEMPLATE_NOT_FOUND",
+				"message": "No offboarding contract template is configured",
+			})
+		case errors.Is(err, service.ErrUnresolvedPlaceholder):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "UNRESOLVED_PLACEHOLDER",
+				"message": "Offboarding contract template contains placeholders that cannot be resolved for this employee",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to offboard employee",
+			})
+		}
+		return
+	}
+
+	// The disabled account must be rejected on the employee's next request
+	middleware.InvalidateAccountCache(uint(id))
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/middleware"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+)
+
+// respondCreated answers 201 with the created resource and a Location header pointing at
+// the route that retrieves it, e.g. respondCreated(c, "/api/employees", id, employee).
+// The body is the resource in the same shape its GET route returns. Resources without a GET
+// route of their own (roles, work schedules, waitlist entries, attachments) answer a plain 201
+func respondCreated(c *gin.Context, collection string, id uint, resource interface{}) {
+	c.Header("Location", fmt.Sprintf("%s/%d", collection, id))
+	c.JSON(http.StatusCreated, resource)
+}
+
+// respondIfConflict writes a 409 response when err reports that the record was modified since
+// the client loaded it, and reports whether it did
+func respondIfConflict(c *gin.Context, err error) bool {
+	if !errors.Is(err, service.ErrConcurrentModification) {
+		return false
+	}
+
+	c.JSON(http.StatusConflict, gin.H{
+		"code":    "CONCURRENT_MODIFICATION",
+		"message": "The record was modified by someone else, reload and try again",
+	})
+	return true
+}
+
+// parseIncludeDeleted reads the include_deleted query parameter, which only super admins may set,
+// writing an error response and returning false when it is invalid or not allowed
+func parseIncludeDeleted(c *gin.Context) (bool, bool) {
+	includeDeleted, err := strconv.ParseBool(c.DefaultQuery("include_deleted", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid include_deleted parameter",
+		})
+		return false, false
+	}
+	if includeDeleted && middleware.GetRole(c) != model.RoleSuperAdmin {
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    "FORBIDDEN",
+			"message": "Only super admins can list deleted records",
+		})
+		return false, false
+	}
+	return includeDeleted, true
+}
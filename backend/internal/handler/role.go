@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/middleware"
+	"oa-system/internal/service"
+)
+
+// RoleHandler handles role management HTTP requests
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// List returns all roles with their permissions
+// GET /api/roles
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.roleService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve roles",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// Create creates a custom role
+// POST /api/roles
+func (h *RoleHandler) Create(c *gin.Context) {
+	var req service.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	role, err := h.roleService.Create(middleware.GetUserID(c), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRoleExists):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "ROLE_EXISTS",
+				"message": "Role already exists",
+			})
+		case errors.Is(err, service.ErrInvalidRoleName):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_ROLE_NAME",
+				"message": "Role name must be lowercase letters, digits or underscores (max 20 characters)",
+			})
+		case errors.Is(err, service.ErrUnknownPermission):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "UNKNOWN_PERMISSION",
+				"message": "Unknown permission",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to create role",
+			})
+		}
+		return
+	}
+
+	middleware.InvalidatePermissionCache()
+	c.JSON(http.StatusCreated, role)
+}
+
+// SetPermission grants or revokes a permission on a role
+// PUT /api/roles/:id/permissions
+func (h *RoleHandler) SetPermission(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid role ID",
+		})
+		return
+	}
+
+	var req service.SetPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	role, err := h.roleService.SetPermission(uint(id), middleware.GetUserID(c), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRoleNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "ROLE_NOT_FOUND",
+				"message": "Role not found",
+			})
+		case errors.Is(err, service.ErrUnknownPermission):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "UNKNOWN_PERMISSION",
+				"message": "Unknown permission",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to update role permissions",
+			})
+		}
+		return
+	}
+
+	middleware.InvalidatePermissionCache()
+	c.JSON(http.StatusOK, role)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"oa-system/internal/middleware"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+)
+
+func TestCustomRolePermissions(t *testing.T) {
+	db := testutil.NewDB(t)
+	roleService := service.NewRoleService(db, middleware.IsKnownPermission)
+	middleware.SetRolePermissionLoader(roleService.LoadPermissionMap)
+	t.Cleanup(func() { middleware.SetRolePermissionLoader(nil) })
+	h := NewRoleHandler(roleService)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+	recruiter := testutil.CreateEmployee(t, db, "recruiter", "recruiter")
+	contracts := newContractHandler(db)
+
+	rec := serve(http.MethodPost, "/roles", "/roles", `{"name":"recruiter","permissions":["manage_contracts"]}`, admin, h.Create)
+	assertStatus(t, rec, http.StatusCreated)
+	var role model.Role
+	if err := db.Where("name = ?", "recruiter").First(&role).Error; err != nil {
+		t.Fatalf("load role: %v", err)
+	}
+
+	listContracts := func() int {
+		rec := serve(http.MethodGet, "/contracts", "/contracts", "", recruiter,
+			middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contracts.List)
+		return rec.Code
+	}
+	if code := listContracts(); code != http.StatusOK {
+		t.Errorf("granted role: status = %d, want 200", code)
+	}
+	if rec := serve(http.MethodGet, "/x", "/x", "", recruiter, middleware.RequirePermission(middleware.PermManageSalaries)); rec.Code != http.StatusForbidden {
+		t.Errorf("ungranted permission: status = %d, want 403", rec.Code)
+	}
+
+	path := fmt.Sprintf("/roles/%d/permissions", role.ID)
+	rec = serve(http.MethodPut, "/roles/:id/permissions", path, `{"permission":"manage_contracts","granted":false}`, admin, h.SetPermission)
+	assertStatus(t, rec, http.StatusOK)
+	if code := listContracts(); code != http.StatusForbidden {
+		t.Errorf("revoked role: status = %d, want 403", code)
+	}
+
+	// Super admins keep access whatever their stored permissions
+	rec = serve(http.MethodGet, "/contracts", "/contracts", "", admin,
+		middleware.RequireSuperAdminOrPermission(middleware.PermManageContracts), contracts.List)
+	assertStatus(t, rec, http.StatusOK)
+}
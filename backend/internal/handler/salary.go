@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -37,7 +38,7 @@ func (h *SalaryHandler) Create(c *gin.Context) {
 		return
 	}
 
-	salary, err := h.salaryService.Create(&req)
+	salary, err := h.salaryService.Create(middleware.GetUserID(c), &req)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrEmployeeNotFound):
@@ -69,7 +70,39 @@ func (h *SalaryHandler) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, salary)
+	respondCreated(c, "/api/salaries", salary.ID, salary)
+}
+
+// BatchCreate creates salary records for many employees in one month
+// POST /api/salaries/batch
+func (h *SalaryHandler) BatchCreate(c *gin.Context) {
+	var req service.BatchCreateSalaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.salaryService.BatchCreate(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMonth) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_MONTH",
+				"message": "Invalid month format, expected YYYY-MM",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to create salary records",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
 // List returns all salary records with optional filters (for finance role)
@@ -99,6 +132,29 @@ func (h *SalaryHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, salaries)
 }
 
+// GetStatistics returns aggregated payroll figures for a month (for finance role)
+// GET /api/salaries/statistics?month=YYYY-MM&department=
+func (h *SalaryHandler) GetStatistics(c *gin.Context) {
+	stats, err := h.salaryService.GetStatistics(c.Query("month"), c.Query("department"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMonth):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_MONTH",
+				"message": "Invalid month format, expected YYYY-MM",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to compute salary statistics",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
 // GetMy returns the current user's salary records
 // GET /api/salaries/my
 // Implements Property 4: Data isolation - only returns records belonging to the employee
@@ -118,6 +174,35 @@ func (h *SalaryHandler) GetMy(c *gin.Context) {
 	c.JSON(http.StatusOK, salaries)
 }
 
+// GetMySummary returns the current user's year-to-date salary totals with a month-by-month series
+// GET /api/salaries/my/summary?year=2024
+// Implements Property 4: Data isolation - only sums records belonging to the employee
+func (h *SalaryHandler) GetMySummary(c *gin.Context) {
+	year := 0
+	if yearStr := c.Query("year"); yearStr != "" {
+		var err error
+		year, err = strconv.Atoi(yearStr)
+		if err != nil || year < 1 || year > 9999 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid year",
+			})
+			return
+		}
+	}
+
+	summary, err := h.salaryService.GetSummaryForEmployee(middleware.GetUserID(c), year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to compute salary summary",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
 
 // GetByID returns a salary record by ID
 // GET /api/salaries/:id
@@ -138,7 +223,7 @@ func (h *SalaryHandler) GetByID(c *gin.Context) {
 	var salary *model.Salary
 
 	// Finance can view any salary record
-	if currentRole == model.RoleFinance || currentRole == model.RoleSuperAdmin {
+	if middleware.HasPermissionOrSuperAdmin(currentRole, middleware.PermViewAllSalaries) {
 		salary, err = h.salaryService.GetByID(uint(id))
 	} else {
 		// Regular employees can only view their own salary records
@@ -163,3 +248,173 @@ func (h *SalaryHandler) GetByID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, salary)
 }
+
+// Update updates a salary record
+// PUT /api/salaries/:id
+func (h *SalaryHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid salary ID",
+		})
+		return
+	}
+
+	var req service.UpdateSalaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	salary, err := h.salaryService.Update(uint(id), &req)
+	if err != nil {
+		if respondIfConflict(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrSalaryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "SALARY_NOT_FOUND",
+				"message": "Salary record not found",
+			})
+		case errors.Is(err, service.ErrSalaryDuplicate):
+			c.JSON(http.StatusConflict, gin.H{
+				"code":    "SALARY_DUPLICATE",
+				"message": "Salary record already exists for this employee and month",
+			})
+		case errors.Is(err, service.ErrInvalidMonth):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_MONTH",
+				"message": "Invalid month format, expected YYYY-MM",
+			})
+		case errors.Is(err, service.ErrInvalidSalaryData):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_SALARY_DATA",
+				"message": "Invalid salary data: values cannot be negative",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to update salary record",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, salary)
+}
+
+// Publish makes a draft salary record visible to its employee
+// PUT /api/salaries/:id/publish
+func (h *SalaryHandler) Publish(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid salary ID",
+		})
+		return
+	}
+
+	salary, err := h.salaryService.Publish(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSalaryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "SALARY_NOT_FOUND",
+				"message": "Salary record not found",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to publish salary record",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, salary)
+}
+
+// PublishMonth publishes every draft salary record of a month
+// POST /api/salaries/publish?month=YYYY-MM
+func (h *SalaryHandler) PublishMonth(c *gin.Context) {
+	month := c.Query("month")
+	published, err := h.salaryService.PublishMonth(month)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMonth):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_MONTH",
+				"message": "Invalid month format, expected YYYY-MM",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Failed to publish salary records",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"month": month, "published": published})
+}
+
+// DownloadPayslip streams a salary record rendered as a payslip PDF
+// GET /api/salaries/:id/payslip
+func (h *SalaryHandler) DownloadPayslip(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "Invalid salary ID",
+		})
+		return
+	}
+
+	currentUserID := middleware.GetUserID(c)
+	currentRole := middleware.GetRole(c)
+
+	var salary *model.Salary
+
+	// Finance can download any payslip, employees only their own (Property 4: Data isolation)
+	if middleware.HasPermissionOrSuperAdmin(currentRole, middleware.PermViewAllSalaries) {
+		salary, err = h.salaryService.GetByID(uint(id))
+	} else {
+		salary, err = h.salaryService.GetByIDForEmployee(uint(id), currentUserID)
+	}
+
+	if err != nil {
+		if errors.Is(err, service.ErrSalaryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    "SALARY_NOT_FOUND",
+				"message": "Salary record not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to retrieve salary record",
+		})
+		return
+	}
+
+	data, err := h.salaryService.GeneratePayslip(salary)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "Failed to generate payslip",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=payslip-%s-%d.pdf", salary.Month, salary.ID))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/service"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/pdf"
+)
+
+func TestDownloadPayslip(t *testing.T) {
+	db := testutil.NewDB(t)
+	owner := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	other := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	finance := testutil.CreateEmployee(t, db, "finance", model.RoleFinance)
+	salaryService := service.NewSalaryService(db, pdf.NewGenerator(""), &config.SalaryConfig{Currency: "CNY", Rounding: config.SalaryRoundingHalfUp})
+	salary, err := salaryService.Create(finance.ID, &service.CreateSalaryRequest{
+		EmployeeID: owner.ID, Month: "2026-03", BaseSalary: 10000, Bonus: 1500, Deduction: 300, Published: true,
+	})
+	if err != nil {
+		t.Fatalf("create salary: %v", err)
+	}
+	h := NewSalaryHandler(salaryService)
+	path := fmt.Sprintf("/salaries/%d/payslip", salary.ID)
+
+	for _, user := range []*model.Employee{owner, finance} {
+		rec := serve(http.MethodGet, "/salaries/:id/payslip", path, "", user, h.DownloadPayslip)
+		assertStatus(t, rec, http.StatusOK)
+		if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF")) {
+			t.Errorf("%s: body is not a PDF", user.Username)
+		}
+	}
+
+	rec := serve(http.MethodGet, "/salaries/:id/payslip", path, "", other, h.DownloadPayslip)
+	assertStatus(t, rec, http.StatusNotFound)
+}
+
+func TestSalaryVisibility(t *testing.T) {
+	db := testutil.NewDB(t)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	finance := testutil.CreateEmployee(t, db, "finance", model.RoleFinance)
+	salaryService := service.NewSalaryService(db, pdf.NewGenerator(""), &config.SalaryConfig{Currency: "CNY", Rounding: config.SalaryRoundingHalfUp})
+	h := NewSalaryHandler(salaryService)
+	create := func(month string, published bool) *model.Salary {
+		t.Helper()
+		salary, err := salaryService.Create(finance.ID, &service.CreateSalaryRequest{EmployeeID: alice.ID, Month: month, BaseSalary: 10000, Published: published})
+		if err != nil {
+			t.Fatalf("create salary: %v", err)
+		}
+		return salary
+	}
+	published := create("2026-02", true)
+	draft := create("2026-03", false)
+	path := fmt.Sprintf("/salaries/%d", draft.ID)
+
+	// mine lists the months of the salaries alice sees
+	mine := func() []string {
+		t.Helper()
+		rec := serve(http.MethodGet, "/salaries/my", "/salaries/my", "", alice, h.GetMy)
+		assertStatus(t, rec, http.StatusOK)
+		var salaries []model.Salary
+		if err := json.Unmarshal(rec.Body.Bytes(), &salaries); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		months := []string{}
+		for _, salary := range salaries {
+			months = append(months, salary.Month)
+		}
+		return months
+	}
+
+	rec := serve(http.MethodGet, "/salaries/:id", path, "", alice, h.GetByID)
+	assertStatus(t, rec, http.StatusNotFound)
+	rec = serve(http.MethodGet, "/salaries/:id", path, "", finance, h.GetByID)
+	assertStatus(t, rec, http.StatusOK)
+	if got := mine(); !slices.Equal(got, []string{published.Month}) {
+		t.Errorf("employee's salaries = %v, want only %s", got, published.Month)
+	}
+
+	rec = serve(http.MethodPut, "/salaries/:id/publish", path+"/publish", "", finance, h.Publish)
+	assertStatus(t, rec, http.StatusOK)
+	rec = serve(http.MethodGet, "/salaries/:id", path, "", alice, h.GetByID)
+	assertStatus(t, rec, http.StatusOK)
+	if got := mine(); !slices.Equal(got, []string{draft.Month, published.Month}) {
+		t.Errorf("employee's salaries after publishing = %v, want %s and %s", got, draft.Month, published.Month)
+	}
+}
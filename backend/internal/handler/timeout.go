@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondIfTimedOut writes a 503 response when err comes from the request context being
+// cancelled or running past its deadline, and reports whether it did
+func respondIfTimedOut(c *gin.Context, err error) bool {
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) && c.Request.Context().Err() == nil {
+		return false
+	}
+
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"code":    "REQUEST_TIMEOUT",
+		"message": "Request timed out",
+	})
+	return true
+}
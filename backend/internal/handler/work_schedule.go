@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"oa-system/internal/service"
+)
+
+// WorkScheduleHandler handles department work schedule HTTP requests
+type WorkScheduleHandler struct {
+	workScheduleService *service.WorkScheduleService
+}
+
+// NewWorkScheduleHandler creates a new work schedule handler
+func NewWorkScheduleHandler(workScheduleService *service.WorkScheduleService) *WorkScheduleHandler {
+	return &WorkScheduleHandler{
+		workScheduleService: workScheduleService,
+	}
+}
+
+// List handles listing all department work schedules
+// GET /api/work-schedules
+func (h *WorkScheduleHandler) List(c *gin.Context) {
+	schedules, err := h.workScheduleService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": "获取作息时间列表失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// Create handles creating a department work schedule
+// POST /api/work-schedules
+func (h *WorkScheduleHandler) Create(c *gin.Context) {
+	var req service.CreateWorkScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.workScheduleService.Create(&req)
+	if err != nil {
+		respondWorkScheduleError(c, err, "创建作息时间失败")
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// Update handles updating a department work schedule
+// PUT /api/work-schedules/:id
+func (h *WorkScheduleHandler) Update(c *gin.Context) {
+	scheduleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的作息时间ID",
+		})
+		return
+	}
+
+	var req service.UpdateWorkScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "请求参数无效",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.workScheduleService.Update(uint(scheduleID), &req)
+	if err != nil {
+		respondWorkScheduleError(c, err, "更新作息时间失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// Delete handles deleting a department work schedule
+// DELETE /api/work-schedules/:id
+func (h *WorkScheduleHandler) Delete(c *gin.Context) {
+	scheduleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "无效的作息时间ID",
+		})
+		return
+	}
+
+	if err := h.workScheduleService.Delete(uint(scheduleID)); err != nil {
+		respondWorkScheduleError(c, err, "删除作息时间失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "作息时间删除成功",
+	})
+}
+
+// respondWorkScheduleError maps work schedule service errors to HTTP responses
+func respondWorkScheduleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrWorkScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    "NOT_FOUND",
+			"message": "作息时间不存在",
+		})
+	case errors.Is(err, service.ErrWorkScheduleInvalidTime):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "时间格式无效，应为HH:MM且上班时间早于下班时间",
+		})
+	case errors.Is(err, service.ErrWorkScheduleInvalidWorkdays):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    "VALIDATION_ERROR",
+			"message": "工作日设置无效，应为1-127的位掩码",
+		})
+	case errors.Is(err, service.ErrWorkScheduleExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    "WORK_SCHEDULE_EXISTS",
+			"message": "该部门已设置作息时间",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": fallback,
+		})
+	}
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -19,6 +21,84 @@ const (
 	ContextIsFirstLogin = "is_first_login"
 )
 
+// accountState is the cached subset of an employee record needed to authenticate a request
+type accountState struct {
+	isActive        bool
+	isFirstLogin    bool
+	tokensRevokedAt *time.Time
+	expiresAt       time.Time
+}
+
+// revokes reports whether the token was issued before the account's tokens were revoked
+// Token issue times have second precision, so both sides are compared by the second: a token from
+// the second of the revocation, such as one from logging in right after re-enabling, stays valid
+func (a accountState) revokes(claims *jwt.Claims) bool {
+	if a.tokensRevokedAt == nil {
+		return false
+	}
+	if claims.IssuedAt == nil {
+		return true
+	}
+	return claims.IssuedAt.Time.Truncate(time.Second).Before(a.tokensRevokedAt.Truncate(time.Second))
+}
+
+// accountCache holds account states keyed by user ID; a zero ttl disables caching
+var accountCache struct {
+	sync.RWMutex
+	ttl     time.Duration
+	entries map[uint]accountState
+}
+
+// SetAccountCacheTTL sets how long an account's active and first-login flags are served from
+// memory before AuthMiddleware reloads them from the database
+func SetAccountCacheTTL(ttl time.Duration) {
+	accountCache.Lock()
+	defer accountCache.Unlock()
+	accountCache.ttl = ttl
+	accountCache.entries = make(map[uint]accountState)
+}
+
+// InvalidateAccountCache forces the next request by the user to reload their account from the database
+func InvalidateAccountCache(userID uint) {
+	accountCache.Lock()
+	defer accountCache.Unlock()
+	delete(accountCache.entries, userID)
+}
+
+// loadAccountState returns the user's account state, from the cache while it is fresh
+func loadAccountState(userID uint) (accountState, error) {
+	now := time.Now()
+
+	accountCache.RLock()
+	state, ok := accountCache.entries[userID]
+	ttl := accountCache.ttl
+	accountCache.RUnlock()
+	if ok && now.Before(state.expiresAt) {
+		return state, nil
+	}
+
+	var employee model.Employee
+	if err := model.GetDB().First(&employee, userID).Error; err != nil {
+		return accountState{}, err
+	}
+	state = accountState{
+		isActive:        employee.IsActive,
+		isFirstLogin:    employee.IsFirstLogin,
+		tokensRevokedAt: employee.TokensRevokedAt,
+		expiresAt:       now.Add(ttl),
+	}
+
+	if ttl > 0 {
+		accountCache.Lock()
+		if accountCache.entries == nil {
+			accountCache.entries = make(map[uint]accountState)
+		}
+		accountCache.entries[userID] = state
+		accountCache.Unlock()
+	}
+	return state, nil
+}
+
 // AuthMiddleware creates a JWT authentication middleware
 func AuthMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -59,8 +139,8 @@ func AuthMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 		}
 
 		// Check if account is still active
-		var employee model.Employee
-		if err := model.GetDB().First(&employee, claims.UserID).Error; err != nil {
+		account, err := loadAccountState(claims.UserID)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    "AUTH_USER_NOT_FOUND",
 				"message": "User not found",
@@ -69,7 +149,7 @@ func AuthMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		if !employee.IsActive {
+		if !account.isActive {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"code":    "AUTH_ACCOUNT_DISABLED",
 				"message": "Account has been disabled",
@@ -78,11 +158,20 @@ func AuthMiddleware(jwtManager *jwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		if account.revokes(claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    "AUTH_TOKEN_REVOKED",
+				"message": "Token has been revoked",
+			})
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set(ContextUserID, claims.UserID)
 		c.Set(ContextUsername, claims.Username)
 		c.Set(ContextRole, claims.Role)
-		c.Set(ContextIsFirstLogin, employee.IsFirstLogin)
+		c.Set(ContextIsFirstLogin, account.isFirstLogin)
 
 		c.Next()
 	}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/jwt"
+)
+
+// countQueries counts the SELECT statements run on db from now on
+func countQueries(t *testing.T, db *gorm.DB) *atomic.Int64 {
+	t.Helper()
+	var count atomic.Int64
+	if err := db.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) {
+		count.Add(1)
+	}); err != nil {
+		t.Fatalf("register query callback: %v", err)
+	}
+	return &count
+}
+
+// authenticate runs a request carrying token through AuthMiddleware and returns the response status
+func authenticate(jwtManager *jwt.JWTManager, token string) int {
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(jwtManager), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(AuthorizationHeader, BearerPrefix+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestAccountCache(t *testing.T) {
+	db := testutil.NewDB(t)
+	SetAccountCacheTTL(time.Minute)
+	t.Cleanup(func() { SetAccountCacheTTL(0) })
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	jwtManager := jwt.NewJWTManager("test-secret", 1)
+	token, err := jwtManager.GenerateToken(alice.ID, alice.Username, alice.Role, false)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	queries := countQueries(t, db)
+
+	if got := authenticate(jwtManager, token); got != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", got)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("first request ran %d queries, want 1", got)
+	}
+	if got := authenticate(jwtManager, token); got != http.StatusOK {
+		t.Fatalf("cached request status = %d, want 200", got)
+	}
+	if got := queries.Load(); got != 1 {
+		t.Errorf("cached request ran %d more queries, want 0", got-1)
+	}
+
+	// Disabling the account invalidates its entry, so the next request sees the change at once
+	if err := db.Model(alice).Update("is_active", false).Error; err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	InvalidateAccountCache(alice.ID)
+	if got := authenticate(jwtManager, token); got != http.StatusUnauthorized {
+		t.Errorf("request after disabling status = %d, want 401", got)
+	}
+}
+
+func TestRequirePasswordChange(t *testing.T) {
+	db := testutil.NewDB(t)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	if err := db.Model(alice).Update("is_first_login", true).Error; err != nil {
+		t.Fatalf("set first login: %v", err)
+	}
+	jwtManager := jwt.NewJWTManager("test-secret", 1)
+	token, err := jwtManager.GenerateToken(alice.ID, alice.Username, alice.Role, true)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	// Laid out like setupRoutes: the password change route is registered before the requirement applies
+	router := gin.New()
+	protected := router.Group("/api", AuthMiddleware(jwtManager))
+	protected.POST("/auth/change-password", func(c *gin.Context) { c.Status(http.StatusOK) })
+	protected.Use(RequirePasswordChange())
+	protected.POST("/leaves", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	post := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set(AuthorizationHeader, BearerPrefix+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/api/leaves")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "AUTH_FIRST_LOGIN_REQUIRED") {
+		t.Errorf("create leave before changing password = %d %s, want 403 AUTH_FIRST_LOGIN_REQUIRED", rec.Code, rec.Body.String())
+	}
+	if rec := post("/api/auth/change-password"); rec.Code != http.StatusOK {
+		t.Errorf("change password = %d, want 200", rec.Code)
+	}
+
+	// Once the password is changed the same token reaches the rest of the API
+	if err := db.Model(alice).Update("is_first_login", false).Error; err != nil {
+		t.Fatalf("clear first login: %v", err)
+	}
+	InvalidateAccountCache(alice.ID)
+	if rec := post("/api/leaves"); rec.Code != http.StatusCreated {
+		t.Errorf("create leave after changing password = %d, want 201", rec.Code)
+	}
+}
+
+func TestRevokes(t *testing.T) {
+	revokedAt := time.Date(2026, 3, 10, 9, 0, 0, 600_000_000, time.UTC)
+	account := accountState{tokensRevokedAt: &revokedAt}
+	tests := []struct {
+		name     string
+		issuedAt time.Time // zero for a token without an issue time
+		want     bool
+	}{
+		{"earlier second", revokedAt.Add(-time.Second), true},
+		{"same second", revokedAt.Truncate(time.Second), false},
+		{"later second", revokedAt.Add(time.Second), false},
+		{"no issue time", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &jwt.Claims{}
+			if !tt.issuedAt.IsZero() {
+				claims.IssuedAt = gojwt.NewNumericDate(tt.issuedAt)
+			}
+			if got := account.revokes(claims); got != tt.want {
+				t.Errorf("revokes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if (accountState{}).revokes(&jwt.Claims{}) {
+		t.Error("account without a revocation revokes a token")
+	}
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength caps the length of a client-supplied idempotency key
+const maxIdempotencyKeyLength = 128
+
+// idempotentResponse is a processed request's response, kept for replay; a zero status means
+// the original request is still being handled
+type idempotentResponse struct {
+	bodyHash  [sha256.Size]byte
+	status    int
+	header    http.Header // response headers such as Content-Type and Location
+	body      []byte
+	expiresAt time.Time
+}
+
+// idempotencyStore holds processed responses keyed by user, route and idempotency key
+var idempotencyStore = struct {
+	sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*idempotentResponse
+	lastPurge time.Time
+}{
+	ttl:     24 * time.Hour,
+	entries: make(map[string]*idempotentResponse),
+}
+
+// SetIdempotencyTTL sets how long processed responses are replayed for a repeated idempotency key
+func SetIdempotencyTTL(ttl time.Duration) {
+	idempotencyStore.Lock()
+	defer idempotencyStore.Unlock()
+	idempotencyStore.ttl = ttl
+}
+
+// responseRecorder copies the response body while it is written to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotent makes a create endpoint safe to retry: a request carrying an Idempotency-Key the
+// same user already sent to the route gets the original response replayed instead of being
+// processed again. Requests without the header are handled normally. Must run after AuthMiddleware
+func Idempotent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "Idempotency-Key is too long",
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "Failed to read request body",
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		bodyHash := sha256.Sum256(body)
+
+		storeKey := fmt.Sprintf("%d:%s:%s:%s", GetUserID(c), c.Request.Method, c.FullPath(), key)
+		now := time.Now()
+
+		idempotencyStore.Lock()
+		purgeExpiredIdempotencyKeys(now)
+		entry, exists := idempotencyStore.entries[storeKey]
+		if exists && now.After(entry.expiresAt) {
+			exists = false
+		}
+		if !exists {
+			// Reserve the key so a concurrent retry cannot slip through while this one runs
+			idempotencyStore.entries[storeKey] = &idempotentResponse{
+				bodyHash:  bodyHash,
+				expiresAt: now.Add(idempotencyStore.ttl),
+			}
+		}
+		var replay idempotentResponse
+		if exists {
+			replay = *entry
+		}
+		idempotencyStore.Unlock()
+
+		if exists {
+			switch {
+			case replay.bodyHash != bodyHash:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"code":    "IDEMPOTENCY_KEY_REUSED",
+					"message": "Idempotency-Key was already used with a different request body",
+				})
+			case replay.status == 0:
+				c.JSON(http.StatusConflict, gin.H{
+					"code":    "IDEMPOTENCY_KEY_IN_PROGRESS",
+					"message": "A request with this Idempotency-Key is still being processed",
+				})
+			default:
+				for name, values := range replay.header {
+					c.Writer.Header()[name] = values
+				}
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(replay.status, replay.header.Get("Content-Type"), replay.body)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// Settle the reservation however the handler ends, so a panic does not leave the key
+		// stuck in progress
+		handled := false
+		defer func() {
+			idempotencyStore.Lock()
+			defer idempotencyStore.Unlock()
+			status := recorder.Status()
+			if !handled || status >= http.StatusInternalServerError {
+				// Server errors are not final, so the client may retry with the same key
+				delete(idempotencyStore.entries, storeKey)
+				return
+			}
+			if entry, ok := idempotencyStore.entries[storeKey]; ok {
+				entry.status = status
+				entry.header = recorder.Header().Clone()
+				entry.body = recorder.body.Bytes()
+			}
+		}()
+
+		c.Next()
+		handled = true
+	}
+}
+
+// purgeExpiredIdempotencyKeys drops expired entries at most once per minute; the store lock must be held
+func purgeExpiredIdempotencyKeys(now time.Time) {
+	if now.Sub(idempotencyStore.lastPurge) < time.Minute {
+		return
+	}
+	idempotencyStore.lastPurge = now
+	for key, entry := range idempotencyStore.entries {
+		if now.After(entry.expiresAt) {
+			delete(idempotencyStore.entries, key)
+		}
+	}
+}
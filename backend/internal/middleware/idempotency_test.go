@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotentPanicReleasesKey(t *testing.T) {
+	calls := 0
+	router := gin.New()
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.POST("/items", func(c *gin.Context) {
+		c.Set(ContextUserID, uint(1))
+	}, Idempotent(), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	post := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "panic-key")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if got := post(); got != http.StatusInternalServerError {
+		t.Fatalf("panicking request status = %d, want 500", got)
+	}
+	// The retry is processed rather than reported as still in progress
+	if got := post(); got != http.StatusCreated || calls != 2 {
+		t.Errorf("retry status = %d after %d calls, want 201 after 2", got, calls)
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader  = "X-Request-ID"
+	ContextRequestID = "request_id"
+)
+
+// contextUntimedRequest holds the request context as it was before RequestTimeout bounded it
+const contextUntimedRequest = "untimed_request_context"
+
+// maxRequestIDLength caps the length of a client-supplied request ID
+const maxRequestIDLength = 128
+
+// RequestID assigns every request a correlation ID, honoring an incoming X-Request-ID header,
+// and echoes it back in the response header
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newUUID()
+		}
+
+		c.Set(ContextRequestID, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// RequestTimeout bounds every request's context with the given timeout so that database calls
+// bound to it are cancelled instead of hanging; a zero timeout leaves the context untouched
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		c.Set(contextUntimedRequest, c.Request.Context())
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// WithoutRequestTimeout lifts the RequestTimeout bound for long-lived routes such as event
+// streams; the context is still cancelled when the client disconnects
+func WithoutRequestTimeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ctx, ok := c.Get(contextUntimedRequest); ok {
+			c.Request = c.Request.WithContext(ctx.(context.Context))
+		}
+		c.Next()
+	}
+}
+
+// GetRequestID extracts the request ID from context
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(ContextRequestID)
+}
+
+// RequestLogger emits one structured log line per request
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		attrs := []any{
+			slog.String("request_id", GetRequestID(c)),
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID, exists := c.Get(ContextUserID); exists {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		logger.Info("request", attrs...)
+	}
+}
+
+// newUUID generates a random RFC 4122 version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	router := gin.New()
+	router.Use(RequestID(), RequestLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Set(ContextUserID, uint(7))
+		c.String(http.StatusOK, GetRequestID(c))
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	generated := rec.Header().Get(RequestIDHeader)
+	if !uuidRegex.MatchString(generated) {
+		t.Errorf("generated request ID = %q, want a v4 UUID", generated)
+	}
+	if rec.Body.String() != generated {
+		t.Errorf("context request ID = %q, want %q", rec.Body.String(), generated)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log line %q: %v", logs.String(), err)
+	}
+	if entry["request_id"] != generated || entry["method"] != "GET" || entry["path"] != "/ping" ||
+		entry["status"] != float64(200) || entry["user_id"] != float64(7) {
+		t.Errorf("log entry = %v, want the request's ID, method, path, status and user", entry)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(RequestIDHeader, "upstream-123")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if got := rec.Header().Get(RequestIDHeader); got != "upstream-123" {
+		t.Errorf("incoming request ID echoed as %q, want upstream-123", got)
+	}
+}
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 
@@ -39,6 +41,7 @@ const (
 	PermManageRoles        Permission = "manage_roles"
 	PermManageEmployees    Permission = "manage_employees"
 	PermManageMeetingRooms Permission = "manage_meeting_rooms"
+	PermBookOnBehalf       Permission = "book_on_behalf" // can be granted to custom roles, e.g. executive assistants
 
 	// HR permissions
 	PermManageAccounts  Permission = "manage_accounts"
@@ -82,48 +85,128 @@ var employeePermissions = []Permission{
 	PermViewNotifications,
 }
 
+// withEmployeePermissions returns a fresh slice holding the base employee permissions
+// followed by extra, so no two roles share a backing array
+func withEmployeePermissions(extra ...Permission) []Permission {
+	permissions := make([]Permission, 0, len(employeePermissions)+len(extra))
+	permissions = append(permissions, employeePermissions...)
+	return append(permissions, extra...)
+}
+
 // RolePermissions maps roles to their permissions
 var RolePermissions = map[string][]Permission{
-	model.RoleEmployee: employeePermissions,
+	model.RoleEmployee: withEmployeePermissions(),
 
-	model.RoleSuperAdmin: append(
-		employeePermissions,
+	model.RoleSuperAdmin: withEmployeePermissions(
 		PermManageRoles,
 		PermManageEmployees,
 		PermManageMeetingRooms,
 		PermManageAccounts,
+		PermBookOnBehalf,
 	),
 
-	model.RoleHR: append(
-		employeePermissions,
+	model.RoleHR: withEmployeePermissions(
 		PermManageEmployees,
 		PermManageAccounts,
 		PermManageContracts,
 		PermManageRoles,
 	),
 
-	model.RoleFinance: append(
-		employeePermissions,
+	model.RoleFinance: withEmployeePermissions(
 		PermManageSalaries,
 		PermViewAllSalaries,
 	),
 
-	model.RoleDeviceAdmin: append(
-		employeePermissions,
+	model.RoleDeviceAdmin: withEmployeePermissions(
 		PermManageDevices,
 		PermApproveDeviceRequest,
 		PermConfirmDeviceReturn,
 	),
 
-	model.RoleSupervisor: append(
-		employeePermissions,
+	model.RoleSupervisor: withEmployeePermissions(
 		PermApproveLeave,
 	),
 }
 
+// RolePermissionLoader loads every role's granted permissions, keyed by role name
+type RolePermissionLoader func() (map[string][]string, error)
+
+// permissionCache holds the loaded role permissions; a nil roles map means the cache is cold
+var permissionCache struct {
+	sync.RWMutex
+	loader RolePermissionLoader
+	roles  map[string][]Permission
+}
+
+// SetRolePermissionLoader makes permission checks consult the loader instead of the static
+// RolePermissions map; results are cached until InvalidatePermissionCache is called
+func SetRolePermissionLoader(loader RolePermissionLoader) {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+	permissionCache.loader = loader
+	permissionCache.roles = nil
+}
+
+// InvalidatePermissionCache forces the next permission check to reload from the loader
+func InvalidatePermissionCache() {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+	permissionCache.roles = nil
+}
+
+// currentRolePermissions returns the cached role permissions, loading them on a cold cache;
+// falls back to the static RolePermissions map when no loader is set or loading fails
+func currentRolePermissions() map[string][]Permission {
+	permissionCache.RLock()
+	roles, loader := permissionCache.roles, permissionCache.loader
+	permissionCache.RUnlock()
+
+	if roles != nil {
+		return roles
+	}
+	if loader == nil {
+		return RolePermissions
+	}
+
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+	if permissionCache.roles != nil {
+		return permissionCache.roles
+	}
+
+	loaded, err := loader()
+	if err != nil {
+		log.Printf("Failed to load role permissions, using built-in defaults: %v", err)
+		return RolePermissions
+	}
+
+	roles = make(map[string][]Permission, len(loaded))
+	for role, names := range loaded {
+		permissions := make([]Permission, len(names))
+		for i, name := range names {
+			permissions[i] = Permission(name)
+		}
+		roles[role] = permissions
+	}
+	permissionCache.roles = roles
+	return roles
+}
+
+// IsKnownPermission checks if a permission is one defined by the application
+func IsKnownPermission(permission string) bool {
+	for _, permissions := range RolePermissions {
+		for _, p := range permissions {
+			if string(p) == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // HasPermission checks if a role has a specific permission
 func HasPermission(role string, permission Permission) bool {
-	permissions, exists := RolePermissions[role]
+	permissions, exists := currentRolePermissions()[role]
 	if !exists {
 		return false
 	}
@@ -158,7 +241,7 @@ func HasAllPermissions(role string, permissions ...Permission) bool {
 
 // GetRolePermissions returns all permissions for a given role
 func GetRolePermissions(role string) []Permission {
-	permissions, exists := RolePermissions[role]
+	permissions, exists := currentRolePermissions()[role]
 	if !exists {
 		return []Permission{}
 	}
@@ -167,7 +250,7 @@ func GetRolePermissions(role string) []Permission {
 
 // IsValidRole checks if a role is valid
 func IsValidRole(role string) bool {
-	_, exists := RolePermissions[role]
+	_, exists := currentRolePermissions()[role]
 	return exists
 }
 
@@ -278,6 +361,39 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 	}
 }
 
+// HasPermissionOrSuperAdmin reports whether a role is the super admin or has any of the permissions
+func HasPermissionOrSuperAdmin(role string, permissions ...Permission) bool {
+	return role == model.RoleSuperAdmin || HasAnyPermission(role, permissions...)
+}
+
+// RequireSuperAdminOrPermission creates a middleware that admits super admins and any role
+// granted one of the permissions, so super admins keep access even if a permission was never
+// seeded for them or was revoked from their role
+func RequireSuperAdminOrPermission(permissions ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetRole(c)
+		if role == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    "AUTH_CONTEXT_ERROR",
+				"message": "Authentication context not found",
+			})
+			c.Abort()
+			return
+		}
+
+		if !HasPermissionOrSuperAdmin(role, permissions...) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "AUTH_PERMISSION_DENIED",
+				"message": "You do not have permission to access this resource",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequireSuperAdmin is a convenience middleware for super admin only routes
 func RequireSuperAdmin() gin.HandlerFunc {
 	return RequireRole(model.RoleSuperAdmin)
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"slices"
+	"testing"
+
+	"oa-system/internal/model"
+)
+
+func TestRolePermissionsAreIndependent(t *testing.T) {
+	for role, permissions := range RolePermissions {
+		for other, otherPermissions := range RolePermissions {
+			if role != other && &permissions[0] == &otherPermissions[0] {
+				t.Errorf("%s and %s share a backing array", role, other)
+			}
+		}
+	}
+
+	// Appending to one role's list must not overwrite another role's entries
+	before := slices.Clone(RolePermissions[model.RoleSupervisor])
+	_ = append(RolePermissions[model.RoleEmployee], PermManageSalaries)
+	_ = withEmployeePermissions(PermManageDevices)
+	if !slices.Equal(RolePermissions[model.RoleSupervisor], before) {
+		t.Errorf("supervisor permissions changed to %v", RolePermissions[model.RoleSupervisor])
+	}
+}
+
+func TestRolePermissionsContents(t *testing.T) {
+	want := map[string][]Permission{
+		model.RoleEmployee:    nil,
+		model.RoleSuperAdmin:  {PermManageRoles, PermManageEmployees, PermManageMeetingRooms, PermManageAccounts, PermBookOnBehalf},
+		model.RoleHR:          {PermManageEmployees, PermManageAccounts, PermManageContracts, PermManageRoles},
+		model.RoleFinance:     {PermManageSalaries, PermViewAllSalaries},
+		model.RoleDeviceAdmin: {PermManageDevices, PermApproveDeviceRequest, PermConfirmDeviceReturn},
+		model.RoleSupervisor:  {PermApproveLeave},
+	}
+	if len(RolePermissions) != len(want) {
+		t.Errorf("roles = %d, want %d", len(RolePermissions), len(want))
+	}
+	for role, extra := range want {
+		expected := append(slices.Clone(employeePermissions), extra...)
+		if got := RolePermissions[role]; !slices.Equal(got, expected) {
+			t.Errorf("%s permissions = %v, want %v", role, got, expected)
+		}
+	}
+}
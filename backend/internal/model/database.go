@@ -1,11 +1,17 @@
 package model
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 
 	"oa-system/config"
 )
@@ -15,24 +21,261 @@ var DB *gorm.DB
 // InitDB initializes the database connection
 func InitDB(cfg *config.DatabaseConfig) error {
 	var err error
-	
+
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// Timestamps are stored in UTC; calendar days are reckoned in the configured zone by the services
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case "", "mysql":
+		dialector = mysql.Open(cfg.DSN())
+	case "sqlite":
+		// Column types such as decimal(10,2), date and json map onto SQLite's type affinities,
+		// so the same models migrate unchanged
+		dialector = sqlite.Open(cfg.SQLiteDSN())
+	default:
+		return fmt.Errorf("unsupported database driver %q, expected mysql or sqlite", cfg.Driver)
+	}
+
+	DB, err = gorm.Open(dialector, gormConfig)
+	if err != nil {
+		return err
 	}
 
-	DB, err = gorm.Open(mysql.Open(cfg.DSN()), gormConfig)
+	sqlDB, err := DB.DB()
 	if err != nil {
 		return err
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetimeMinutes) * time.Minute)
 
-	log.Println("Database connected successfully")
+	// Fail fast at startup rather than on the first request
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("database unreachable: %w", err)
+	}
+
+	log.Printf("Database connected successfully (%s)", dialector.Name())
 	return nil
 }
 
 // AutoMigrate runs auto migration for all models
 func AutoMigrate() error {
 	log.Println("Running auto migration...")
-	return DB.AutoMigrate(AllModels()...)
+	publishExistingSalaries := !DB.Migrator().HasColumn(&Salary{}, "Published")
+	freshDatabase := !DB.Migrator().HasTable(&Employee{})
+	if err := DB.AutoMigrate(AllModels()...); err != nil {
+		return err
+	}
+	// Only MySQL connections used loc=Local; SQLite keeps each timestamp's offset
+	if DB.Dialector.Name() == "mysql" {
+		if err := convertTimestampsToUTC(freshDatabase, time.Local); err != nil {
+			return err
+		}
+	}
+	if err := migrateDepartments(); err != nil {
+		return err
+	}
+	// Salaries recorded before drafts existed were already visible to employees
+	if publishExistingSalaries {
+		if err := DB.Unscoped().Model(&Salary{}).Where("1 = 1").Update("published", true).Error; err != nil {
+			return err
+		}
+	}
+	return ensureEmailUniqueIndex()
+}
+
+// migrateDepartments creates a department row for every free-text department name still used by
+// employees and links those employees to it; already linked employees are left alone
+func migrateDepartments() error {
+	var names []string
+	err := DB.Unscoped().Model(&Employee{}).
+		Where("department_id IS NULL AND department <> ''").
+		Distinct("department").
+		Pluck("department", &names).Error
+	if err != nil || len(names) == 0 {
+		return err
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Department{}).Count(&count).Error; err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			var department Department
+			err := tx.Where("name = ?", name).First(&department).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				department = Department{Name: name}
+				// Skip codes HR may already have assigned by hand
+				for taken := int64(1); taken > 0; {
+					count++
+					department.Code = fmt.Sprintf("DEPT%03d", count)
+					if err := tx.Model(&Department{}).Where("code = ?", department.Code).Count(&taken).Error; err != nil {
+						return err
+					}
+				}
+				err = tx.Create(&department).Error
+			}
+			if err != nil {
+				return err
+			}
+
+			err = tx.Unscoped().Model(&Employee{}).
+				Where("department_id IS NULL AND department = ?", name).
+				Update("department_id", department.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("Migrated %d free-text departments", len(names))
+		return nil
+	})
+}
+
+// timestampsToUTCMigration names the one-off conversion of stored timestamps to UTC
+const timestampsToUTCMigration = "timestamps_to_utc"
+
+// timestampConversionBatch is how many rows are converted per query
+const timestampConversionBatch = 500
+
+// convertTimestampsToUTC rewrites the timestamp columns of a database whose rows were written while
+// connections used loc=Local, moving them from that zone's wall clock to UTC, which connections use
+// now. It runs once; a database created after the switch is only marked as converted. Date columns
+// hold calendar days rather than instants and are left alone
+func convertTimestampsToUTC(fresh bool, legacy *time.Location) error {
+	var applied int64
+	if err := DB.Model(&SchemaMigration{}).Where("name = ?", timestampsToUTCMigration).Count(&applied).Error; err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if !fresh {
+			for _, value := range AllModels() {
+				if err := convertTableTimestamps(tx, value, legacy); err != nil {
+					return err
+				}
+			}
+			log.Printf("Converted stored timestamps from %s to UTC", legacy)
+		}
+		return tx.Create(&SchemaMigration{Name: timestampsToUTCMigration, AppliedAt: time.Now().UTC()}).Error
+	})
+}
+
+// convertTableTimestamps converts the timestamp columns of one model's table, batch by batch
+func convertTableTimestamps(tx *gorm.DB, value interface{}, legacy *time.Location) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(value); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && field.DataType == schema.Time {
+			columns = append(columns, field.DBName)
+		}
+	}
+	if len(columns) == 0 || stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	id := stmt.Schema.PrioritizedPrimaryField.DBName
+
+	for lastID := uint64(0); ; {
+		rows, err := tx.Table(table).
+			Select(append([]string{id}, columns...)).
+			Where(id+" > ?", lastID).
+			Order(id).
+			Limit(timestampConversionBatch).
+			Rows()
+		if err != nil {
+			return err
+		}
+
+		// Collect the batch first: the connection cannot run updates while rows are being read
+		updates := make(map[uint64]map[string]interface{})
+		var ids []uint64
+		for rows.Next() {
+			var rowID uint64
+			values := make([]sql.NullTime, len(columns))
+			dest := []interface{}{&rowID}
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return err
+			}
+
+			changes := make(map[string]interface{})
+			for i, column := range columns {
+				if values[i].Valid {
+					changes[column] = legacyToUTC(values[i].Time, legacy)
+				}
+			}
+			ids = append(ids, rowID)
+			updates[rowID] = changes
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, rowID := range ids {
+			if len(updates[rowID]) == 0 {
+				continue
+			}
+			if err := tx.Table(table).Where(id+" = ?", rowID).UpdateColumns(updates[rowID]).Error; err != nil {
+				return err
+			}
+		}
+		if len(ids) < timestampConversionBatch {
+			return nil
+		}
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// legacyToUTC reads a timestamp's wall clock, as the connection returned it, as a time in the legacy zone
+func legacyToUTC(t time.Time, legacy *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), legacy).UTC()
+}
+
+// employeeEmailIndex is the unique index over non-empty employee emails
+const employeeEmailIndex = "idx_employees_email_key"
+
+// ensureEmailUniqueIndex creates the unique email index unless existing rows already
+// share an email, in which case it logs the duplicates and leaves uniqueness to the service layer
+func ensureEmailUniqueIndex() error {
+	if DB.Migrator().HasIndex(&Employee{}, employeeEmailIndex) {
+		return nil
+	}
+
+	var duplicates []string
+	err := DB.Unscoped().Model(&Employee{}).
+		Where("email <> ''").
+		Group("email").
+		Having("COUNT(*) > 1").
+		Pluck("email", &duplicates).Error
+	if err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		log.Printf("Skipping unique email index, duplicate emails must be resolved first: %v", duplicates)
+		return nil
+	}
+
+	return DB.Exec("CREATE UNIQUE INDEX " + employeeEmailIndex + " ON employees (email_key)").Error
 }
 
 // GetDB returns the database instance
@@ -0,0 +1,210 @@
+package model_test
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"oa-system/config"
+	"oa-system/internal/middleware"
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+	"oa-system/migrations"
+	"oa-system/pkg/password"
+)
+
+func TestAutoMigrateAddsEmailKeyToExistingSQLiteDatabase(t *testing.T) {
+	db := testutil.NewDB(t)
+
+	// Roll the schema back to before the email index existed
+	for _, statement := range []string{
+		"DROP INDEX idx_employees_email_key",
+		"ALTER TABLE employees DROP COLUMN email_key",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	if err := model.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate on an existing database: %v", err)
+	}
+	if !db.Migrator().HasColumn(&model.Employee{}, "email_key") || !db.Migrator().HasIndex(&model.Employee{}, "idx_employees_email_key") {
+		t.Fatal("email_key column or its unique index is missing after migration")
+	}
+
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	if err := db.Model(bob).Update("email", "shared@example.com").Error; err != nil {
+		t.Fatalf("set email: %v", err)
+	}
+	if err := db.Model(carol).Update("email", "shared@example.com").Error; err == nil {
+		t.Error("second employee with the same email was stored")
+	}
+}
+
+// initSQLite initializes the global database as an in-memory SQLite with cfg's pool settings
+func initSQLite(t *testing.T, cfg config.DatabaseConfig) *gorm.DB {
+	t.Helper()
+	cfg.Driver, cfg.SQLitePath = "sqlite", ":memory:"
+	if err := model.InitDB(&cfg); err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	db := model.GetDB()
+	db.Logger = logger.Discard
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db handle: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestSeedSQLiteDatabase(t *testing.T) {
+	db := initSQLite(t, config.DatabaseConfig{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err := model.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	if err := migrations.SeedDatabase(db, false); err != nil {
+		t.Fatalf("SeedDatabase: %v", err)
+	}
+
+	var admin model.Employee
+	if err := db.Where("username = ?", "admin").First(&admin).Error; err != nil {
+		t.Fatalf("load super admin: %v", err)
+	}
+	if admin.Role != model.RoleSuperAdmin || !admin.IsActive || !password.Verify("admin123", admin.Password) {
+		t.Errorf("super admin = role %q, active %v; want an active super admin with the initial password", admin.Role, admin.IsActive)
+	}
+	var roles, templates int64
+	db.Model(&model.Role{}).Count(&roles)
+	db.Model(&model.ContractTemplate{}).Count(&templates)
+	if roles != int64(len(middleware.RolePermissions)) {
+		t.Errorf("roles = %d, want %d", roles, len(middleware.RolePermissions))
+	}
+	if templates != 2 {
+		t.Errorf("contract templates = %d, want 2", templates)
+	}
+
+	// Seeding again leaves the existing rows alone
+	if err := migrations.SeedDatabase(db, false); err != nil {
+		t.Fatalf("second SeedDatabase: %v", err)
+	}
+	var admins int64
+	db.Model(&model.Employee{}).Where("username = ?", "admin").Count(&admins)
+	if admins != 1 {
+		t.Errorf("super admins after reseeding = %d, want 1", admins)
+	}
+}
+
+func TestInitDBAppliesPoolSettings(t *testing.T) {
+	db := initSQLite(t, config.DatabaseConfig{MaxOpenConns: 7, MaxIdleConns: 3, ConnMaxLifetimeMinutes: 5})
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db handle: %v", err)
+	}
+	if got := sqlDB.Stats().MaxOpenConnections; got != 7 {
+		t.Errorf("MaxOpenConnections = %d, want 7", got)
+	}
+}
+
+func TestConvertTimestampsToUTC(t *testing.T) {
+	db := testutil.NewDB(t)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	// Written under loc=Local on a UTC+8 server: the wall clock is local, labelled UTC on read
+	signIn := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	record := &model.Attendance{EmployeeID: alice.ID, Date: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), SignInTime: &signIn}
+	if err := db.Create(record).Error; err != nil {
+		t.Fatalf("create attendance: %v", err)
+	}
+	legacy := time.FixedZone("UTC+8", 8*60*60)
+
+	for range 2 {
+		// The conversion is recorded, so running the migration again leaves the rows alone
+		if err := model.ConvertTimestampsToUTC(false, legacy); err != nil {
+			t.Fatalf("ConvertTimestampsToUTC: %v", err)
+		}
+	}
+
+	var stored model.Attendance
+	if err := db.First(&stored, record.ID).Error; err != nil {
+		t.Fatalf("load attendance: %v", err)
+	}
+	if want := time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC); !stored.SignInTime.Equal(want) {
+		t.Errorf("sign-in = %v, want %v", stored.SignInTime, want)
+	}
+	if stored.SignOutTime != nil {
+		t.Errorf("unset sign-out became %v", stored.SignOutTime)
+	}
+	if got := stored.Date.Format("2006-01-02"); got != "2026-03-10" {
+		t.Errorf("date column = %s, want 2026-03-10 unchanged", got)
+	}
+}
+
+func TestConvertTimestampsToUTCOnFreshDatabase(t *testing.T) {
+	db := testutil.NewDB(t)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	if err := model.ConvertTimestampsToUTC(true, time.FixedZone("UTC+8", 8*60*60)); err != nil {
+		t.Fatalf("ConvertTimestampsToUTC: %v", err)
+	}
+	var stored model.Employee
+	if err := db.First(&stored, alice.ID).Error; err != nil {
+		t.Fatalf("load employee: %v", err)
+	}
+	if !stored.CreatedAt.Equal(alice.CreatedAt) {
+		t.Errorf("created_at = %v, want %v unchanged", stored.CreatedAt, alice.CreatedAt)
+	}
+	var applied int64
+	db.Model(&model.SchemaMigration{}).Count(&applied)
+	if applied != 1 {
+		t.Errorf("recorded migrations = %d, want 1", applied)
+	}
+}
+
+func TestSeedDemoData(t *testing.T) {
+	db := testutil.NewDB(t)
+	// counts returns the number of rows in each table demo seeding fills
+	counts := func() map[string]int64 {
+		t.Helper()
+		got := map[string]int64{}
+		for _, table := range []string{"employees", "departments", "devices", "meeting_rooms", "leave_requests"} {
+			var count int64
+			if err := db.Table(table).Count(&count).Error; err != nil {
+				t.Fatalf("count %s: %v", table, err)
+			}
+			got[table] = count
+		}
+		return got
+	}
+
+	if err := migrations.SeedDatabase(db, false); err != nil {
+		t.Fatalf("SeedDatabase: %v", err)
+	}
+	want := map[string]int64{"employees": 1, "departments": 0, "devices": 0, "meeting_rooms": 0, "leave_requests": 0}
+	if got := counts(); !maps.Equal(got, want) {
+		t.Errorf("rows without demo data = %v, want only the super admin", got)
+	}
+
+	if err := migrations.SeedDatabase(db, true); err != nil {
+		t.Fatalf("SeedDatabase with demo data: %v", err)
+	}
+	seeded := counts()
+	for table, count := range seeded {
+		if count < 2 {
+			t.Errorf("%s after demo seeding = %d rows, want sample data", table, count)
+		}
+	}
+
+	// Seeding again finds every record and creates nothing new
+	if err := migrations.SeedDatabase(db, true); err != nil {
+		t.Fatalf("second SeedDatabase with demo data: %v", err)
+	}
+	if got := counts(); !maps.Equal(got, seeded) {
+		t.Errorf("rows after reseeding = %v, want %v", got, seeded)
+	}
+}
@@ -0,0 +1,4 @@
+package model
+
+// ConvertTimestampsToUTC exposes the one-off timestamp conversion to the external tests
+var ConvertTimestampsToUTC = convertTimestampsToUTC
@@ -1,9 +1,12 @@
 package model
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // Role constants
@@ -45,7 +48,6 @@ const (
 	DeviceRequestStatusCancelled     = "cancelled"
 )
 
-
 // Booking status constants
 const (
 	BookingStatusActive    = "active"
@@ -53,6 +55,14 @@ const (
 	BookingStatusCancelled = "cancelled"
 )
 
+// Booking waitlist status constants
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusPromoted  = "promoted"
+	WaitlistStatusCancelled = "cancelled"
+	WaitlistStatusExpired   = "expired"
+)
+
 // Contract type constants
 const (
 	ContractTypeOnboarding  = "onboarding"
@@ -61,41 +71,130 @@ const (
 
 // Contract status constants
 const (
-	ContractStatusPending = "pending"
-	ContractStatusSigned  = "signed"
+	ContractStatusPending  = "pending"
+	ContractStatusSigned   = "signed"
+	ContractStatusDeclined = "declined"
+)
+
+// Notification type constants
+const (
+	NotificationTypeContractDeclined      = "contract_declined"
+	NotificationTypeContractExpiring      = "contract_expiring"
+	NotificationTypeContractPending       = "contract_pending"
+	NotificationTypeDeviceRequestApproved = "device_request_approved"
+	NotificationTypeDeviceRequestRejected = "device_request_rejected"
+	NotificationTypeDeviceAutoApproved    = "device_request_auto_approved"
+	NotificationTypeDeviceLowStock        = "device_low_stock"
+	NotificationTypeDeviceTransferred     = "device_transferred"
+	NotificationTypeSupervisorAssigned    = "supervisor_assigned"
+	NotificationTypeBookingPromoted       = "booking_promoted"
+)
+
+// AllNotificationTypes returns every notification type employees can set preferences for
+func AllNotificationTypes() []string {
+	return []string{
+		NotificationTypeContractDeclined,
+		NotificationTypeContractExpiring,
+		NotificationTypeContractPending,
+		NotificationTypeDeviceRequestApproved,
+		NotificationTypeDeviceRequestRejected,
+		NotificationTypeDeviceAutoApproved,
+		NotificationTypeDeviceLowStock,
+		NotificationTypeDeviceTransferred,
+		NotificationTypeSupervisorAssigned,
+		NotificationTypeBookingPromoted,
+	}
+}
+
+// Notification related type constants
+const (
+	NotificationRelatedContract      = "contract"
+	NotificationRelatedDeviceRequest = "device_request"
+	NotificationRelatedDevice        = "device"
+	NotificationRelatedEmployee      = "employee"
+	NotificationRelatedBooking       = "meeting_room_booking"
+)
+
+// Attachment owner type constants
+const (
+	AttachmentOwnerLeave = "leave"
+)
+
+// Audit action constants
+const (
+	AuditActionEmployeeRoleUpdate       = "employee.role_update"
+	AuditActionEmployeeSupervisorUpdate = "employee.supervisor_update"
+	AuditActionEmployeeStatusUpdate     = "employee.status_update"
+	AuditActionEmployeeDelete           = "employee.delete"
+	AuditActionEmployeeOffboard         = "employee.offboard"
+	AuditActionPasswordChange           = "auth.password_change"
+	AuditActionTokensRevoke             = "auth.tokens_revoke"
+	AuditActionSalaryCreate             = "salary.create"
+	AuditActionRoleCreate               = "role.create"
+	AuditActionRolePermissionUpdate     = "role.permission_update"
+)
+
+// Audit target type constants
+const (
+	AuditTargetEmployee = "employee"
+	AuditTargetSalary   = "salary"
+	AuditTargetRole     = "role"
 )
 
+// EmailKey is the generated copy of an employee's email that the unique email index covers
+type EmailKey struct {
+	sql.NullString
+}
+
+// GormDBDataType declares the generated column. SQLite cannot add a STORED generated column to
+// an existing table, so the column is VIRTUAL there
+func (EmailKey) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	storage := "STORED"
+	if db.Dialector.Name() == "sqlite" {
+		storage = "VIRTUAL"
+	}
+	return "varchar(100) GENERATED ALWAYS AS (NULLIF(email, '')) " + storage
+}
+
 // Employee represents an employee in the system
 type Employee struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	Username     string         `gorm:"uniqueIndex;size:50;not null" json:"username"`
-	EmployeeNo   string         `gorm:"uniqueIndex;size:20;not null" json:"employee_no"`
-	Name         string         `gorm:"size:100;not null" json:"name"`
-	Department   string         `gorm:"size:100" json:"department"`
-	Position     string         `gorm:"size:100" json:"position"`
-	Phone        string         `gorm:"size:20" json:"phone"`
-	Email        string         `gorm:"size:100" json:"email"`
-	HireDate     time.Time      `json:"hire_date"`
-	SupervisorID *uint          `json:"supervisor_id"`
-	Supervisor   *Employee      `gorm:"foreignKey:SupervisorID" json:"supervisor,omitempty"`
-	Role         string         `gorm:"size:20;not null;default:employee" json:"role"`
-	Password     string         `gorm:"size:255;not null" json:"-"`
-	IsFirstLogin bool           `gorm:"default:true" json:"is_first_login"`
-	IsActive     bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	Username           string         `gorm:"uniqueIndex;size:50;not null" json:"username"`
+	EmployeeNo         string         `gorm:"uniqueIndex;size:20;not null" json:"employee_no"`
+	Name               string         `gorm:"size:100;not null" json:"name"`
+	Department         string         `gorm:"size:100" json:"department"` // kept in sync with DepartmentID for older clients
+	DepartmentID       *uint          `gorm:"index" json:"department_id"`
+	Position           string         `gorm:"size:100" json:"position"`
+	Phone              string         `gorm:"size:20" json:"phone"`
+	Email              string         `gorm:"size:100" json:"email"`
+	EmailKey           EmailKey       `gorm:"->" json:"-"`                           // NULL for empty emails so the unique index ignores them
+	AvatarPath         string         `gorm:"size:255" json:"avatar_path,omitempty"` // relative to the avatar storage directory
+	HireDate           time.Time      `json:"hire_date"`
+	SupervisorID       *uint          `json:"supervisor_id"`
+	Supervisor         *Employee      `gorm:"foreignKey:SupervisorID" json:"supervisor,omitempty"`
+	DelegateApproverID *uint          `json:"delegate_approver_id"` // approves subordinates' leave while the supervisor is away
+	Role               string         `gorm:"size:20;not null;default:employee" json:"role"`
+	Password           string         `gorm:"size:255;not null" json:"-"`
+	IsFirstLogin       bool           `gorm:"default:true" json:"is_first_login"`
+	IsActive           bool           `gorm:"default:true" json:"is_active"`
+	TokensRevokedAt    *time.Time     `json:"-"`                                 // tokens issued in an earlier second are rejected
+	Version            int            `gorm:"not null;default:0" json:"version"` // optimistic lock, bumped on every full update
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"deleted_at,omitzero"` // only present on soft-deleted rows listed with include_deleted
 }
 
-
 // Attendance represents daily attendance record
 type Attendance struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	EmployeeID  uint       `gorm:"not null;index" json:"employee_id"`
-	Employee    Employee   `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
-	Date        time.Time  `gorm:"type:date;not null;index" json:"date"`
-	SignInTime  *time.Time `json:"sign_in_time"`
-	SignOutTime *time.Time `json:"sign_out_time"`
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	EmployeeID      uint       `gorm:"not null;index" json:"employee_id"`
+	Employee        Employee   `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	Date            time.Time  `gorm:"type:date;not null;index" json:"date"`
+	SignInTime      *time.Time `json:"sign_in_time"`
+	SignOutTime     *time.Time `json:"sign_out_time"`
+	AutoClosed      bool       `gorm:"not null;default:false" json:"auto_closed"` // sign-out was filled in by the system, not the employee
+	OvertimeMinutes int        `gorm:"-" json:"overtime_minutes"`                 // derived from sign-out time, not stored
+	LateMinutes     int        `gorm:"-" json:"late_minutes"`                     // derived from sign-in time, not stored
 }
 
 // LeaveRequest represents a leave request
@@ -109,6 +208,10 @@ type LeaveRequest struct {
 	Reason       string         `gorm:"type:text" json:"reason"`
 	Status       string         `gorm:"size:20;not null;default:pending" json:"status"`
 	RejectReason string         `gorm:"type:text" json:"reject_reason"`
+	ApprovedBy   *uint          `gorm:"index" json:"approved_by"` // supervisor who approved, rejected or cancelled the request
+	Approver     *Employee      `gorm:"foreignKey:ApprovedBy" json:"approver,omitempty"`
+	ActionedAt   *time.Time     `json:"actioned_at"`
+	WorkingDays  int            `gorm:"not null;default:0" json:"working_days"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -121,25 +224,48 @@ type Device struct {
 	Type              string         `gorm:"size:50" json:"type"`
 	TotalQuantity     int            `gorm:"not null;default:0" json:"total_quantity"`
 	AvailableQuantity int            `gorm:"not null;default:0" json:"available_quantity"`
+	LowStockThreshold *int           `json:"low_stock_threshold"`               // overrides the global default when set
+	AutoApprove       bool           `gorm:"default:false" json:"auto_approve"` // requests skip admin approval, e.g. for consumables
 	Description       string         `gorm:"type:text" json:"description"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
-
 // DeviceRequest represents a device request
 type DeviceRequest struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	EmployeeID   uint           `gorm:"not null;index" json:"employee_id"`
-	Employee     Employee       `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
-	DeviceID     uint           `gorm:"not null;index" json:"device_id"`
-	Device       Device         `gorm:"foreignKey:DeviceID" json:"device,omitempty"`
-	Status       string         `gorm:"size:20;not null;default:pending" json:"status"`
-	RejectReason string         `gorm:"type:text" json:"reject_reason"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	EmployeeID        uint           `gorm:"not null;index" json:"employee_id"`
+	Employee          Employee       `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	DeviceID          uint           `gorm:"not null;index" json:"device_id"`
+	Device            Device         `gorm:"foreignKey:DeviceID" json:"device,omitempty"`
+	Status            string         `gorm:"size:20;not null;default:pending" json:"status"`
+	ApprovalComment   string         `gorm:"type:text" json:"approval_comment"`
+	RejectReason      string         `gorm:"type:text" json:"reject_reason"`
+	ReturnCondition   string         `gorm:"size:500" json:"return_condition"`
+	IsDamaged         bool           `gorm:"default:false" json:"is_damaged"` // damaged returns are not put back into the available pool
+	NeededFrom        *time.Time     `gorm:"type:date" json:"needed_from"`    // first day of a reservation for later pickup; nil for immediate use
+	NeededUntil       *time.Time     `gorm:"type:date" json:"needed_until"`   // last day of the reservation
+	ApprovedAt        *time.Time     `json:"approved_at"`
+	CollectedAt       *time.Time     `json:"collected_at"`
+	ReturnInitiatedAt *time.Time     `json:"return_initiated_at"`
+	ReturnedAt        *time.Time     `json:"returned_at"`
+	LoanDurationDays  *int           `gorm:"-" json:"loan_duration_days,omitempty"` // computed for returned requests
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// DeviceTransfer records a collected device handed from one employee to another without being returned
+type DeviceTransfer struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	DeviceRequestID uint      `gorm:"not null;index" json:"device_request_id"`
+	FromEmployeeID  uint      `gorm:"not null" json:"from_employee_id"`
+	FromEmployee    Employee  `gorm:"foreignKey:FromEmployeeID" json:"from_employee,omitempty"`
+	ToEmployeeID    uint      `gorm:"not null" json:"to_employee_id"`
+	ToEmployee      Employee  `gorm:"foreignKey:ToEmployeeID" json:"to_employee,omitempty"`
+	TransferredBy   uint      `gorm:"not null" json:"transferred_by"`
+	CreatedAt       time.Time `json:"transferred_at"`
 }
 
 // MeetingRoom represents a meeting room
@@ -148,10 +274,18 @@ type MeetingRoom struct {
 	Name      string         `gorm:"size:100;not null" json:"name"`
 	Capacity  int            `gorm:"not null;default:0" json:"capacity"`
 	Location  string         `gorm:"size:200" json:"location"`
+	Amenities []RoomAmenity  `gorm:"foreignKey:MeetingRoomID" json:"amenities"`
 	CreatedAt time.Time      `json:"created_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// RoomAmenity represents a piece of equipment or feature of a meeting room, e.g. "projector"
+type RoomAmenity struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	MeetingRoomID uint   `gorm:"not null;uniqueIndex:idx_room_amenity" json:"meeting_room_id"`
+	Name          string `gorm:"size:50;not null;uniqueIndex:idx_room_amenity;index" json:"name"`
+}
+
 // MeetingRoomBooking represents a meeting room booking
 type MeetingRoomBooking struct {
 	ID            uint           `gorm:"primaryKey" json:"id"`
@@ -163,10 +297,27 @@ type MeetingRoomBooking struct {
 	StartTime     string         `gorm:"size:10;not null" json:"start_time"` // HH:MM format
 	EndTime       string         `gorm:"size:10;not null" json:"end_time"`   // HH:MM format
 	Status        string         `gorm:"size:20;not null;default:active" json:"status"`
+	CheckedInAt   *time.Time     `json:"checked_in_at"`
+	CreatedBy     *uint          `gorm:"index" json:"created_by"` // set when booked on behalf of the employee
 	CreatedAt     time.Time      `json:"created_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// BookingWaitlist represents an employee waiting for a taken meeting room slot
+// When the slot frees up the entry is promoted into a booking recorded in BookingID
+type BookingWaitlist struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	EmployeeID    uint        `gorm:"not null;index" json:"employee_id"`
+	Employee      Employee    `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	MeetingRoomID uint        `gorm:"not null;index:idx_waitlist_room_date" json:"meeting_room_id"`
+	MeetingRoom   MeetingRoom `gorm:"foreignKey:MeetingRoomID" json:"meeting_room,omitempty"`
+	BookingDate   time.Time   `gorm:"type:date;not null;index:idx_waitlist_room_date" json:"booking_date"`
+	StartTime     string      `gorm:"size:10;not null" json:"start_time"` // HH:MM format
+	EndTime       string      `gorm:"size:10;not null" json:"end_time"`   // HH:MM format
+	Status        string      `gorm:"size:20;not null;default:waiting" json:"status"`
+	BookingID     *uint       `json:"booking_id"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
 
 // ContractTemplate represents a contract template
 type ContractTemplate struct {
@@ -179,34 +330,53 @@ type ContractTemplate struct {
 
 // Contract represents a contract
 type Contract struct {
+	ID               uint             `gorm:"primaryKey" json:"id"`
+	EmployeeID       uint             `gorm:"not null;index" json:"employee_id"`
+	Employee         Employee         `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	TemplateID       uint             `gorm:"not null" json:"template_id"`
+	Template         ContractTemplate `gorm:"foreignKey:TemplateID" json:"template,omitempty"`
+	Type             string           `gorm:"size:20;not null" json:"type"`
+	Content          string           `gorm:"type:text" json:"content"`
+	Status           string           `gorm:"size:20;not null;default:pending" json:"status"`
+	RequiresApproval bool             `gorm:"not null;default:false" json:"requires_approval"` // must be approved before the employee can sign
+	ApprovedBy       *uint            `json:"approved_by"`
+	ApprovedAt       *time.Time       `json:"approved_at"`
+	SignedAt         *time.Time       `json:"signed_at"`
+	DeclineReason    string           `gorm:"type:text" json:"decline_reason"`
+	ExpiresAt        *time.Time       `gorm:"type:date;index" json:"expires_at"`
+	ExpiryRemindedAt *time.Time       `json:"-"` // set once HR has been reminded of the upcoming expiry
+	CreatedAt        time.Time        `json:"created_at"`
+	DeletedAt        gorm.DeletedAt   `gorm:"index" json:"deleted_at,omitzero"` // only present on soft-deleted rows listed with include_deleted
+}
+
+// Salary represents a salary record
+type Salary struct {
 	ID         uint              `gorm:"primaryKey" json:"id"`
 	EmployeeID uint              `gorm:"not null;index" json:"employee_id"`
 	Employee   Employee          `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
-	TemplateID uint              `gorm:"not null" json:"template_id"`
-	Template   ContractTemplate  `gorm:"foreignKey:TemplateID" json:"template,omitempty"`
-	Type       string            `gorm:"size:20;not null" json:"type"`
-	Content    string            `gorm:"type:text" json:"content"`
-	Status     string            `gorm:"size:20;not null;default:pending" json:"status"`
-	SignedAt   *time.Time        `json:"signed_at"`
+	Month      string            `gorm:"size:7;not null;index" json:"month"` // YYYY-MM format
+	BaseSalary float64           `gorm:"type:decimal(10,2);not null" json:"base_salary"`
+	Bonus      float64           `gorm:"type:decimal(10,2);default:0" json:"bonus"`
+	Deduction  float64           `gorm:"type:decimal(10,2);default:0" json:"deduction"`
+	NetSalary  float64           `gorm:"type:decimal(10,2);not null" json:"net_salary"`
+	Currency   string            `gorm:"-" json:"currency"` // filled in from the payroll configuration, not stored
+	Components []SalaryComponent `gorm:"foreignKey:SalaryID" json:"components"`
+	Published  bool              `gorm:"not null;default:false;index" json:"published"` // drafts are hidden from the employee until finance publishes them
+	Version    int               `gorm:"not null;default:0" json:"version"`             // optimistic lock, bumped on every full update
 	CreatedAt  time.Time         `json:"created_at"`
 	DeletedAt  gorm.DeletedAt    `gorm:"index" json:"-"`
 }
 
-// Salary represents a salary record
-type Salary struct {
-	ID         uint           `gorm:"primaryKey" json:"id"`
-	EmployeeID uint           `gorm:"not null;index" json:"employee_id"`
-	Employee   Employee       `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
-	Month      string         `gorm:"size:7;not null;index" json:"month"` // YYYY-MM format
-	BaseSalary float64        `gorm:"type:decimal(10,2);not null" json:"base_salary"`
-	Bonus      float64        `gorm:"type:decimal(10,2);default:0" json:"bonus"`
-	Deduction  float64        `gorm:"type:decimal(10,2);default:0" json:"deduction"`
-	NetSalary  float64        `gorm:"type:decimal(10,2);not null" json:"net_salary"`
-	CreatedAt  time.Time      `json:"created_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+// SalaryComponent represents an itemized salary line such as social insurance or income tax
+type SalaryComponent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	SalaryID    uint      `gorm:"not null;index" json:"salary_id"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	Amount      float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	IsDeduction bool      `gorm:"default:false" json:"is_deduction"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
-
 // Notification represents a notification
 type Notification struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
@@ -222,19 +392,141 @@ type Notification struct {
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// Attachment represents an uploaded file attached to another record
+type Attachment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OwnerType   string    `gorm:"size:50;not null;index:idx_attachment_owner" json:"owner_type"`
+	OwnerID     uint      `gorm:"not null;index:idx_attachment_owner" json:"owner_id"`
+	FileName    string    `gorm:"size:255;not null" json:"file_name"`
+	StoragePath string    `gorm:"size:500;not null" json:"-"`
+	ContentType string    `gorm:"size:100;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	UploadedBy  uint      `gorm:"not null" json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AuditLog records a sensitive action: who did what to which record
+type AuditLog struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	ActorID    uint            `gorm:"not null;index" json:"actor_id"`
+	Action     string          `gorm:"size:50;not null;index" json:"action"`
+	TargetType string          `gorm:"size:50;not null;index:idx_audit_target" json:"target_type"`
+	TargetID   uint            `gorm:"not null;index:idx_audit_target" json:"target_id"`
+	Metadata   json.RawMessage `gorm:"type:json" json:"metadata"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+}
+
+// Holiday represents a calendar override: a public holiday, or a make-up working day when IsWorkday is set
+type Holiday struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Date      time.Time `gorm:"type:date;uniqueIndex;not null" json:"date"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	IsWorkday bool      `gorm:"default:false" json:"is_workday"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPreference records how an employee wants to receive one notification type
+// Types without a row are delivered in-app and not by email
+type NotificationPreference struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	EmployeeID uint      `gorm:"not null;uniqueIndex:idx_notification_preference" json:"-"`
+	Type       string    `gorm:"size:50;not null;uniqueIndex:idx_notification_preference" json:"type"`
+	InApp      bool      `gorm:"not null" json:"in_app"`
+	Email      bool      `gorm:"not null" json:"email"`
+	UpdatedAt  time.Time `json:"-"`
+}
+
+// SupervisorChange records a change to an employee's reporting line
+type SupervisorChange struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	EmployeeID      uint      `gorm:"not null;index" json:"employee_id"`
+	OldSupervisorID *uint     `json:"old_supervisor_id"`
+	OldSupervisor   *Employee `gorm:"foreignKey:OldSupervisorID" json:"old_supervisor,omitempty"`
+	NewSupervisorID *uint     `json:"new_supervisor_id"`
+	NewSupervisor   *Employee `gorm:"foreignKey:NewSupervisorID" json:"new_supervisor,omitempty"`
+	ChangedBy       uint      `gorm:"not null" json:"changed_by"`
+	CreatedAt       time.Time `json:"changed_at"`
+}
+
+// Department is an organisational unit employees belong to
+type Department struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Name           string    `gorm:"uniqueIndex;size:100;not null" json:"name"`
+	Code           string    `gorm:"uniqueIndex;size:20;not null" json:"code"`
+	HeadEmployeeID *uint     `json:"head_employee_id"`
+	Head           *Employee `gorm:"foreignKey:HeadEmployeeID" json:"head,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DefaultWorkdays is the workdays bitmask for Monday through Friday
+const DefaultWorkdays = 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+
+// WorkSchedule overrides the global working hours for a department
+// Workdays is a bitmask where bit n set means time.Weekday(n) is a working day
+type WorkSchedule struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Department string    `gorm:"size:100;uniqueIndex;not null" json:"department"`
+	StartTime  string    `gorm:"size:5;not null" json:"start_time"` // HH:MM
+	EndTime    string    `gorm:"size:5;not null" json:"end_time"`   // HH:MM
+	Workdays   int       `gorm:"not null" json:"workdays"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Role represents an assignable role and the permissions it grants
+type Role struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	Name        string           `gorm:"uniqueIndex;size:20;not null" json:"name"`
+	Description string           `gorm:"size:200" json:"description"`
+	IsSystem    bool             `gorm:"default:false" json:"is_system"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID" json:"permissions"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// RolePermission represents a permission granted to a role
+type RolePermission struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	RoleID     uint      `gorm:"uniqueIndex:idx_role_permission;not null" json:"role_id"`
+	Permission string    `gorm:"uniqueIndex:idx_role_permission;size:50;not null" json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SchemaMigration records a one-off data migration that has already been applied
+type SchemaMigration struct {
+	Name      string    `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
 // AllModels returns all models for auto migration
 func AllModels() []interface{} {
 	return []interface{}{
 		&Employee{},
+		&SupervisorChange{},
+		&Department{},
 		&Attendance{},
 		&LeaveRequest{},
 		&Device{},
 		&DeviceRequest{},
+		&DeviceTransfer{},
 		&MeetingRoom{},
+		&RoomAmenity{},
 		&MeetingRoomBooking{},
+		&BookingWaitlist{},
 		&ContractTemplate{},
 		&Contract{},
 		&Salary{},
+		&SalaryComponent{},
 		&Notification{},
+		&NotificationPreference{},
+		&Role{},
+		&RolePermission{},
+		&Attachment{},
+		&Holiday{},
+		&AuditLog{},
+		&WorkSchedule{},
+		&SchemaMigration{},
 	}
 }
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+)
+
+// AttachmentRepository handles attachment data access
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+// Create creates a new attachment record
+func (r *AttachmentRepository) Create(attachment *model.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+// ListByOwner retrieves all attachments of a record, oldest first
+func (r *AttachmentRepository) ListByOwner(ownerType string, ownerID uint) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := r.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -23,6 +24,11 @@ func NewAttendanceRepository(db *gorm.DB) *AttendanceRepository {
 	return &AttendanceRepository{db: db}
 }
 
+// WithContext returns a copy of the repository whose queries are bound to ctx
+func (r *AttendanceRepository) WithContext(ctx context.Context) *AttendanceRepository {
+	return &AttendanceRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create creates a new attendance record
 func (r *AttendanceRepository) Create(attendance *model.Attendance) error {
 	return r.db.Create(attendance).Error
@@ -58,12 +64,37 @@ func (r *AttendanceRepository) GetByEmployeeAndDate(employeeID uint, date time.T
 }
 
 
+// GetByEmployeesAndDate retrieves the attendance records of the given employees on a date
+func (r *AttendanceRepository) GetByEmployeesAndDate(employeeIDs []uint, date time.Time) ([]model.Attendance, error) {
+	var attendances []model.Attendance
+	if len(employeeIDs) == 0 {
+		return attendances, nil
+	}
+	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	err := r.db.Where("employee_id IN ? AND date = ?", employeeIDs, dateOnly).Find(&attendances).Error
+	return attendances, err
+}
+
+// GetByEmployeesAndRange retrieves the attendance records of the given employees between start and end inclusive
+func (r *AttendanceRepository) GetByEmployeesAndRange(employeeIDs []uint, start, end time.Time) ([]model.Attendance, error) {
+	var attendances []model.Attendance
+	if len(employeeIDs) == 0 {
+		return attendances, nil
+	}
+
+	err := r.db.Where("employee_id IN ? AND date >= ? AND date <= ?", employeeIDs, start, end).
+		Order("employee_id ASC, date ASC").
+		Find(&attendances).Error
+	return attendances, err
+}
+
 // GetByEmployeeAndMonth retrieves all attendance records for an employee in a specific month
 func (r *AttendanceRepository) GetByEmployeeAndMonth(employeeID uint, year int, month int) ([]model.Attendance, error) {
 	var attendances []model.Attendance
 	
 	// Calculate start and end of month
-	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
 	
 	err := r.db.Where("employee_id = ? AND date >= ? AND date <= ?", employeeID, startDate, endDate).
@@ -73,6 +104,67 @@ func (r *AttendanceRepository) GetByEmployeeAndMonth(employeeID uint, year int,
 	return attendances, err
 }
 
+// GetByMonth retrieves all employees' attendance records for a month, optionally scoped to a department
+// Records of employees deleted since are kept, with their names, as the days were still worked
+func (r *AttendanceRepository) GetByMonth(year int, month int, department string) ([]model.Attendance, error) {
+	var attendances []model.Attendance
+
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	query := r.db.Preload("Employee", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Joins("JOIN employees ON employees.id = attendances.employee_id").
+		Where("attendances.date >= ? AND attendances.date <= ?", startDate, endDate)
+	if department != "" {
+		query = query.Where("employees.department = ?", department)
+	}
+
+	err := query.Order("employees.employee_no ASC, attendances.date ASC").
+		Find(&attendances).Error
+	return attendances, err
+}
+
+// GetCompletedDays retrieves the employee and date of every record between start and end inclusive
+// that was both signed in and signed out by the employee; auto-closed sign-outs do not count
+func (r *AttendanceRepository) GetCompletedDays(start, end time.Time) ([]model.Attendance, error) {
+	var attendances []model.Attendance
+	err := r.db.Select("employee_id", "date").
+		Where("date >= ? AND date <= ?", start, end).
+		Where("sign_in_time IS NOT NULL AND sign_out_time IS NOT NULL AND auto_closed = ?", false).
+		Find(&attendances).Error
+	return attendances, err
+}
+
+// GetMissingSignOutsBefore retrieves records from days before the date that were signed into but never signed out of
+func (r *AttendanceRepository) GetMissingSignOutsBefore(date time.Time) ([]model.Attendance, error) {
+	var attendances []model.Attendance
+	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	err := r.db.Where("date < ? AND sign_in_time IS NOT NULL AND sign_out_time IS NULL", dateOnly).
+		Find(&attendances).Error
+	return attendances, err
+}
+
+// CountMissingSignOuts counts an employee's records between start and end inclusive that were signed into
+// but not signed out of by the employee, whether still open or closed by the system
+func (r *AttendanceRepository) CountMissingSignOuts(employeeID uint, start, end time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Attendance{}).
+		Where("employee_id = ? AND date >= ? AND date <= ?", employeeID, start, end).
+		Where("sign_in_time IS NOT NULL AND (sign_out_time IS NULL OR auto_closed = ?)", true).
+		Count(&count).Error
+	return count, err
+}
+
+// AutoCloseSignOut fills in the sign-out time of a record still missing one and flags it as auto-closed
+// It returns false when the record was signed out in the meantime
+func (r *AttendanceRepository) AutoCloseSignOut(id uint, signOutTime time.Time) (bool, error) {
+	result := r.db.Model(&model.Attendance{}).
+		Where("id = ? AND sign_out_time IS NULL", id).
+		Updates(map[string]interface{}{"sign_out_time": signOutTime, "auto_closed": true})
+	return result.RowsAffected > 0, result.Error
+}
+
 // Update updates an attendance record
 func (r *AttendanceRepository) Update(attendance *model.Attendance) error {
 	return r.db.Save(attendance).Error
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+)
+
+// AuditLogRepository handles audit log data access
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create creates a new audit log entry
+func (r *AuditLogRepository) Create(entry *model.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// List retrieves a page of audit log entries with optional filters, newest first,
+// along with the total number of matching entries
+// Supported filters: actor_id, action, start and end (YYYY-MM-DD, inclusive)
+func (r *AuditLogRepository) List(filters map[string]interface{}, offset, limit int) ([]model.AuditLog, int64, error) {
+	var entries []model.AuditLog
+	query := r.db.Model(&model.AuditLog{})
+
+	if actorID, ok := filters["actor_id"]; ok {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if action, ok := filters["action"]; ok && action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if start, ok := filters["start"]; ok && start != "" {
+		query = query.Where("DATE(created_at) >= ?", start)
+	}
+	if end, ok := filters["end"]; ok && end != "" {
+		query = query.Where("DATE(created_at) <= ?", end)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&entries).Error
+	return entries, total, err
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
 	"oa-system/internal/model"
+	"oa-system/pkg/pagination"
 )
 
 var (
@@ -62,6 +64,24 @@ func (r *ContractRepository) ListTemplates() ([]model.ContractTemplate, error) {
 	return templates, err
 }
 
+// ExistsTemplateByType checks if another template already uses the given type
+func (r *ContractRepository) ExistsTemplateByType(contractType string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ContractTemplate{}).
+		Where("type = ? AND id <> ?", contractType, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountByTemplateID counts contracts (including soft-deleted ones) that reference a template
+func (r *ContractRepository) CountByTemplateID(templateID uint) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.Contract{}).
+		Where("template_id = ?", templateID).
+		Count(&count).Error
+	return count, err
+}
+
 // UpdateTemplate updates a contract template
 func (r *ContractRepository) UpdateTemplate(template *model.ContractTemplate) error {
 	return r.db.Save(template).Error
@@ -97,10 +117,22 @@ func (r *ContractRepository) GetByID(id uint) (*model.Contract, error) {
 	return &contract, nil
 }
 
-// List retrieves all contracts with optional filters
-func (r *ContractRepository) List(filters map[string]interface{}) ([]model.Contract, error) {
+// List retrieves a page of contracts with optional filters, sorted and limited by params,
+// along with the total number of matching contracts
+// With include_deleted, soft-deleted contracts and their soft-deleted employees are included
+func (r *ContractRepository) List(filters map[string]interface{}, params pagination.Params) ([]model.Contract, int64, error) {
 	var contracts []model.Contract
-	query := r.db.Preload("Employee").Preload("Template")
+	query := r.db.Model(&model.Contract{})
+	includeDeleted, _ := filters["include_deleted"].(bool)
+	if includeDeleted {
+		query = query.Unscoped()
+	}
+	preloadEmployee := func(db *gorm.DB) *gorm.DB {
+		if includeDeleted {
+			return db.Unscoped()
+		}
+		return db
+	}
 
 	if employeeID, ok := filters["employee_id"]; ok {
 		query = query.Where("employee_id = ?", employeeID)
@@ -112,8 +144,13 @@ func (r *ContractRepository) List(filters map[string]interface{}) ([]model.Contr
 		query = query.Where("status = ?", status)
 	}
 
-	err := query.Order("created_at DESC").Find(&contracts).Error
-	return contracts, err
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := params.Apply(query.Preload("Employee", preloadEmployee).Preload("Template")).Find(&contracts).Error
+	return contracts, total, err
 }
 
 // GetByEmployeeID retrieves all contracts for a specific employee
@@ -126,6 +163,53 @@ func (r *ContractRepository) GetByEmployeeID(employeeID uint) ([]model.Contract,
 	return contracts, err
 }
 
+// awaitingSignature scopes a query to an employee's pending contracts that can be signed now,
+// leaving out those still waiting for approval
+func (r *ContractRepository) awaitingSignature(employeeID uint) *gorm.DB {
+	return r.db.Model(&model.Contract{}).
+		Where("employee_id = ? AND status = ?", employeeID, model.ContractStatusPending).
+		Where("requires_approval = ? OR approved_by IS NOT NULL", false)
+}
+
+// GetAwaitingSignature retrieves an employee's contracts awaiting their signature, newest first
+func (r *ContractRepository) GetAwaitingSignature(employeeID uint) ([]model.Contract, error) {
+	var contracts []model.Contract
+	err := r.awaitingSignature(employeeID).
+		Preload("Employee").Preload("Template").
+		Order("created_at DESC").
+		Find(&contracts).Error
+	return contracts, err
+}
+
+// CountAwaitingSignature counts an employee's contracts awaiting their signature
+func (r *ContractRepository) CountAwaitingSignature(employeeID uint) (int64, error) {
+	var count int64
+	err := r.awaitingSignature(employeeID).Count(&count).Error
+	return count, err
+}
+
+// GetSignedExpiringBetween retrieves signed contracts expiring between from and to inclusive, soonest first
+func (r *ContractRepository) GetSignedExpiringBetween(from, to time.Time) ([]model.Contract, error) {
+	var contracts []model.Contract
+	err := r.db.Preload("Employee").Preload("Template").
+		Where("status = ? AND expires_at >= ? AND expires_at <= ?", model.ContractStatusSigned, from, to).
+		Order("expires_at ASC, id ASC").
+		Find(&contracts).Error
+	return contracts, err
+}
+
+// MarkExpiryReminded records that HR was reminded about a contract's expiry
+func (r *ContractRepository) MarkExpiryReminded(id uint, at time.Time) error {
+	return r.db.Model(&model.Contract{}).Where("id = ?", id).Update("expiry_reminded_at", at).Error
+}
+
+// CountByStatus counts contracts in the given status
+func (r *ContractRepository) CountByStatus(status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Contract{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
 // Update updates a contract
 func (r *ContractRepository) Update(contract *model.Contract) error {
 	return r.db.Save(contract).Error
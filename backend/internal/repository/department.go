@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+)
+
+var (
+	ErrDepartmentNotFound = errors.New("department not found")
+)
+
+// DepartmentRepository handles department data access
+type DepartmentRepository struct {
+	db *gorm.DB
+}
+
+// NewDepartmentRepository creates a new department repository
+func NewDepartmentRepository(db *gorm.DB) *DepartmentRepository {
+	return &DepartmentRepository{db: db}
+}
+
+// WithContext returns a copy of the repository whose queries are bound to ctx
+func (r *DepartmentRepository) WithContext(ctx context.Context) *DepartmentRepository {
+	return &DepartmentRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create creates a new department
+func (r *DepartmentRepository) Create(department *model.Department) error {
+	return r.db.Create(department).Error
+}
+
+// GetByID retrieves a department by ID with its head
+func (r *DepartmentRepository) GetByID(id uint) (*model.Department, error) {
+	var department model.Department
+	err := r.db.Preload("Head").First(&department, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDepartmentNotFound
+		}
+		return nil, err
+	}
+	return &department, nil
+}
+
+// GetByName retrieves a department by its exact name
+func (r *DepartmentRepository) GetByName(name string) (*model.Department, error) {
+	var department model.Department
+	err := r.db.Where("name = ?", name).First(&department).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDepartmentNotFound
+		}
+		return nil, err
+	}
+	return &department, nil
+}
+
+// GetAll retrieves all departments ordered by code
+func (r *DepartmentRepository) GetAll() ([]model.Department, error) {
+	var departments []model.Department
+	err := r.db.Preload("Head").Order("code ASC").Find(&departments).Error
+	return departments, err
+}
+
+// ExistsByName checks if another department already uses the name
+func (r *DepartmentRepository) ExistsByName(name string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Department{}).
+		Where("name = ? AND id <> ?", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ExistsByCode checks if another department already uses the code
+func (r *DepartmentRepository) ExistsByCode(code string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Department{}).
+		Where("code = ? AND id <> ?", code, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountEmployees counts employees (including soft-deleted ones) assigned to a department
+func (r *DepartmentRepository) CountEmployees(id uint) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.Employee{}).
+		Where("department_id = ?", id).
+		Count(&count).Error
+	return count, err
+}
+
+// GetEmployees retrieves the employees assigned to a department ordered by employee number
+func (r *DepartmentRepository) GetEmployees(id uint) ([]model.Employee, error) {
+	var employees []model.Employee
+	err := r.db.Where("department_id = ?", id).
+		Order("employee_no ASC").
+		Find(&employees).Error
+	return employees, err
+}
+
+// Update updates a department and copies its name onto its employees' free-text department
+func (r *DepartmentRepository) Update(department *model.Department) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Head").Save(department).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&model.Employee{}).
+			Where("department_id = ?", department.ID).
+			Update("department", department.Name).Error
+	})
+}
+
+// Delete deletes a department
+func (r *DepartmentRepository) Delete(id uint) error {
+	result := r.db.Delete(&model.Department{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDepartmentNotFound
+	}
+	return nil
+}
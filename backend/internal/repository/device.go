@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -11,6 +13,7 @@ import (
 var (
 	ErrDeviceNotFound        = errors.New("device not found")
 	ErrDeviceRequestNotFound = errors.New("device request not found")
+	ErrDeviceRequestChanged  = errors.New("device request changed concurrently")
 )
 
 // DeviceRepository handles device data access
@@ -42,9 +45,15 @@ func (r *DeviceRepository) GetByID(id uint) (*model.Device, error) {
 }
 
 // GetAll retrieves all devices
-func (r *DeviceRepository) GetAll() ([]model.Device, error) {
+func (r *DeviceRepository) GetAll(filters map[string]interface{}) ([]model.Device, error) {
 	var devices []model.Device
-	err := r.db.Order("created_at DESC").Find(&devices).Error
+	query := r.db
+
+	if q, ok := filters["q"].(string); ok {
+		query = whereContains(query, q, "name", "type")
+	}
+
+	err := query.Order("created_at DESC").Find(&devices).Error
 	return devices, err
 }
 
@@ -57,6 +66,16 @@ func (r *DeviceRepository) GetAvailable() ([]model.Device, error) {
 	return devices, err
 }
 
+// GetLowStock retrieves devices whose available quantity is at or below their own threshold,
+// or defaultThreshold for devices without one
+func (r *DeviceRepository) GetLowStock(defaultThreshold int) ([]model.Device, error) {
+	var devices []model.Device
+	err := r.db.Where("available_quantity <= COALESCE(low_stock_threshold, ?)", defaultThreshold).
+		Order("available_quantity ASC, id ASC").
+		Find(&devices).Error
+	return devices, err
+}
+
 // Update updates a device
 func (r *DeviceRepository) Update(device *model.Device) error {
 	return r.db.Save(device).Error
@@ -98,6 +117,11 @@ func NewDeviceRequestRepository(db *gorm.DB) *DeviceRequestRepository {
 	return &DeviceRequestRepository{db: db}
 }
 
+// WithContext returns a copy of the repository whose queries are bound to ctx
+func (r *DeviceRequestRepository) WithContext(ctx context.Context) *DeviceRequestRepository {
+	return &DeviceRequestRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create creates a new device request
 func (r *DeviceRequestRepository) Create(request *model.DeviceRequest) error {
 	return r.db.Create(request).Error
@@ -118,13 +142,31 @@ func (r *DeviceRequestRepository) GetByID(id uint) (*model.DeviceRequest, error)
 
 
 // GetByEmployeeID retrieves all device requests for an employee
+// Supported filters: status; orderBy is a trusted ORDER BY clause chosen by the caller
 // Implements Requirement 7.8: Employee views their device requests
-func (r *DeviceRequestRepository) GetByEmployeeID(employeeID uint) ([]model.DeviceRequest, error) {
+func (r *DeviceRequestRepository) GetByEmployeeID(employeeID uint, filters map[string]interface{}, orderBy string) ([]model.DeviceRequest, error) {
 	var requests []model.DeviceRequest
-	err := r.db.Preload("Device").
-		Where("employee_id = ?", employeeID).
-		Order("created_at DESC").
-		Find(&requests).Error
+	query := r.db.Preload("Device").Where("employee_id = ?", employeeID)
+
+	if status, ok := filters["status"]; ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Order(orderBy).Find(&requests).Error
+	return requests, err
+}
+
+// GetByDeviceID retrieves every request for a device, oldest first
+// Supported filters: status
+func (r *DeviceRequestRepository) GetByDeviceID(deviceID uint, filters map[string]interface{}) ([]model.DeviceRequest, error) {
+	var requests []model.DeviceRequest
+	query := r.db.Preload("Employee").Where("device_id = ?", deviceID)
+
+	if status, ok := filters["status"]; ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Order("created_at ASC, id ASC").Find(&requests).Error
 	return requests, err
 }
 
@@ -150,6 +192,52 @@ func (r *DeviceRequestRepository) GetReturnPending() ([]model.DeviceRequest, err
 	return requests, err
 }
 
+// GetHeldByEmployee retrieves device requests whose devices are still held by an employee
+func (r *DeviceRequestRepository) GetHeldByEmployee(employeeID uint) ([]model.DeviceRequest, error) {
+	var requests []model.DeviceRequest
+	err := r.db.Preload("Device").
+		Where("employee_id = ? AND status IN ?", employeeID,
+			[]string{model.DeviceRequestStatusCollected, model.DeviceRequestStatusReturnPending}).
+		Find(&requests).Error
+	return requests, err
+}
+
+// FlagCollectedForReturn moves an employee's collected devices to return_pending
+func (r *DeviceRequestRepository) FlagCollectedForReturn(employeeID uint) error {
+	return r.db.Model(&model.DeviceRequest{}).
+		Where("employee_id = ? AND status = ?", employeeID, model.DeviceRequestStatusCollected).
+		Updates(map[string]interface{}{
+			"status":              model.DeviceRequestStatusReturnPending,
+			"return_initiated_at": time.Now(),
+		}).Error
+}
+
+// CancelPendingByEmployee cancels all of an employee's pending device requests and returns how many were cancelled
+func (r *DeviceRequestRepository) CancelPendingByEmployee(employeeID uint) (int64, error) {
+	result := r.db.Model(&model.DeviceRequest{}).
+		Where("employee_id = ? AND status = ?", employeeID, model.DeviceRequestStatusPending).
+		Update("status", model.DeviceRequestStatusCancelled)
+	return result.RowsAffected, result.Error
+}
+
+// TransferTo moves a collected device request to another employee and records the transfer in one transaction
+// Returns ErrDeviceRequestChanged if the request was returned or transferred in the meantime
+func (r *DeviceRequestRepository) TransferTo(request *model.DeviceRequest, transfer *model.DeviceTransfer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.DeviceRequest{}).
+			Where("id = ? AND employee_id = ? AND status = ?", request.ID, transfer.FromEmployeeID, model.DeviceRequestStatusCollected).
+			Update("employee_id", transfer.ToEmployeeID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDeviceRequestChanged
+		}
+		request.EmployeeID = transfer.ToEmployeeID
+		return tx.Create(transfer).Error
+	})
+}
+
 // Update updates a device request
 func (r *DeviceRequestRepository) Update(request *model.DeviceRequest) error {
 	return r.db.Save(request).Error
@@ -186,10 +274,29 @@ func (r *DeviceRequestRepository) CountCollectedByDeviceID(deviceID uint) (int64
 	return count, err
 }
 
-// List retrieves all device requests with optional filters
-func (r *DeviceRequestRepository) List(filters map[string]interface{}) ([]model.DeviceRequest, error) {
+// CountReservationsOverlapping counts a device's requests in one of the statuses whose reservation
+// window shares at least one day with from..until; requests without a window are not counted
+func (r *DeviceRequestRepository) CountReservationsOverlapping(deviceID uint, from, until time.Time, statuses []string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.DeviceRequest{}).
+		Where("device_id = ? AND status IN ?", deviceID, statuses).
+		Where("needed_from <= ? AND needed_until >= ?", until, from).
+		Count(&count).Error
+	return count, err
+}
+
+// CountByStatus counts device requests in the given status
+func (r *DeviceRequestRepository) CountByStatus(status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.DeviceRequest{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
+// List retrieves a page of device requests with optional filters, newest first,
+// along with the total number of matching requests
+func (r *DeviceRequestRepository) List(filters map[string]interface{}, offset, limit int) ([]model.DeviceRequest, int64, error) {
 	var requests []model.DeviceRequest
-	query := r.db.Preload("Employee").Preload("Device")
+	query := r.db.Model(&model.DeviceRequest{})
 
 	if employeeID, ok := filters["employee_id"]; ok {
 		query = query.Where("employee_id = ?", employeeID)
@@ -201,6 +308,14 @@ func (r *DeviceRequestRepository) List(filters map[string]interface{}) ([]model.
 		query = query.Where("status = ?", status)
 	}
 
-	err := query.Order("created_at DESC").Find(&requests).Error
-	return requests, err
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Employee").Preload("Device").
+		Order("created_at DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&requests).Error
+	return requests, total, err
 }
@@ -1,18 +1,21 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"gorm.io/gorm"
 
 	"oa-system/internal/model"
+	"oa-system/pkg/pagination"
 )
 
 var (
-	ErrEmployeeNotFound      = errors.New("employee not found")
-	ErrEmployeeNoExists      = errors.New("employee number already exists")
-	ErrUsernameExists        = errors.New("username already exists")
-	ErrSupervisorNotFound    = errors.New("supervisor not found")
+	ErrEmployeeNotFound   = errors.New("employee not found")
+	ErrEmployeeNoExists   = errors.New("employee number already exists")
+	ErrUsernameExists     = errors.New("username already exists")
+	ErrSupervisorNotFound = errors.New("supervisor not found")
+	ErrEmailExists        = errors.New("email already exists")
 )
 
 // EmployeeRepository handles employee data access
@@ -25,9 +28,42 @@ func NewEmployeeRepository(db *gorm.DB) *EmployeeRepository {
 	return &EmployeeRepository{db: db}
 }
 
+// WithContext returns a copy of the repository whose queries are bound to ctx
+func (r *EmployeeRepository) WithContext(ctx context.Context) *EmployeeRepository {
+	return &EmployeeRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create creates a new employee
+// ErrEmployeeNoExists is returned when the insert failed because the employee number, or the username
+// derived from it, is already taken, soft-deleted employees included, so a caller can retry with the next number.
+// ErrEmailExists is returned when it failed because another employee took the email first
 func (r *EmployeeRepository) Create(employee *model.Employee) error {
-	return r.db.Create(employee).Error
+	err := r.db.Create(employee).Error
+	if err == nil {
+		return nil
+	}
+
+	var count int64
+	lookup := r.db.Unscoped().Model(&model.Employee{}).
+		Where("employee_no = ? OR username = ?", employee.EmployeeNo, employee.Username).
+		Count(&count)
+	if lookup.Error == nil && count > 0 {
+		return ErrEmployeeNoExists
+	}
+	return r.emailConflict(employee, err)
+}
+
+// emailConflict returns ErrEmailExists in place of err when another employee already uses the
+// employee's email, which is how a write that lost a race on the unique email index fails
+func (r *EmployeeRepository) emailConflict(employee *model.Employee, err error) error {
+	if employee.Email == "" || errors.Is(err, ErrVersionConflict) {
+		return err
+	}
+	exists, lookupErr := r.ExistsByEmail(employee.Email, employee.ID)
+	if lookupErr == nil && exists {
+		return ErrEmailExists
+	}
+	return err
 }
 
 // GetByID retrieves an employee by ID
@@ -43,6 +79,16 @@ func (r *EmployeeRepository) GetByID(id uint) (*model.Employee, error) {
 	return &employee, nil
 }
 
+// GetByIDs retrieves all employees with the given IDs
+func (r *EmployeeRepository) GetByIDs(ids []uint) ([]model.Employee, error) {
+	var employees []model.Employee
+	if len(ids) == 0 {
+		return employees, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&employees).Error
+	return employees, err
+}
+
 // GetByUsername retrieves an employee by username
 func (r *EmployeeRepository) GetByUsername(username string) (*model.Employee, error) {
 	var employee model.Employee
@@ -69,12 +115,36 @@ func (r *EmployeeRepository) GetByEmployeeNo(employeeNo string) (*model.Employee
 	return &employee, nil
 }
 
-
 // List retrieves all employees with optional filters
 func (r *EmployeeRepository) List(filters map[string]interface{}) ([]model.Employee, error) {
 	var employees []model.Employee
-	query := r.db.Preload("Supervisor")
-	
+	query := applyEmployeeFilters(r.db.Preload("Supervisor"), filters)
+
+	err := query.Order("id ASC").Find(&employees).Error
+	return employees, err
+}
+
+// ListPage retrieves a page of employees with optional filters, sorted and limited by params,
+// along with the total number of matching employees
+func (r *EmployeeRepository) ListPage(filters map[string]interface{}, params pagination.Params) ([]model.Employee, int64, error) {
+	var employees []model.Employee
+	query := applyEmployeeFilters(r.db.Model(&model.Employee{}), filters)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := params.Apply(query.Preload("Supervisor")).Find(&employees).Error
+	return employees, total, err
+}
+
+// applyEmployeeFilters narrows an employee query by department, role and is_active,
+// and widens it to soft-deleted employees when include_deleted is set
+func applyEmployeeFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	if includeDeleted, ok := filters["include_deleted"].(bool); ok && includeDeleted {
+		query = query.Unscoped()
+	}
 	if department, ok := filters["department"]; ok && department != "" {
 		query = query.Where("department = ?", department)
 	}
@@ -84,14 +154,39 @@ func (r *EmployeeRepository) List(filters map[string]interface{}) ([]model.Emplo
 	if isActive, ok := filters["is_active"]; ok {
 		query = query.Where("is_active = ?", isActive)
 	}
-	
-	err := query.Order("id ASC").Find(&employees).Error
-	return employees, err
+	return query
 }
 
 // Update updates an employee's information
 func (r *EmployeeRepository) Update(employee *model.Employee) error {
-	return r.db.Save(employee).Error
+	if err := updateVersioned(r.db, employee, &employee.Version); err != nil {
+		return r.emailConflict(employee, err)
+	}
+	return nil
+}
+
+// UpdateWithSupervisorChange updates an employee and records the reporting line change in one transaction
+func (r *EmployeeRepository) UpdateWithSupervisorChange(employee *model.Employee, change *model.SupervisorChange) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateVersioned(tx, employee, &employee.Version); err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return r.emailConflict(employee, err)
+	}
+	return nil
+}
+
+// GetSupervisorChanges retrieves an employee's reporting line history, newest first
+func (r *EmployeeRepository) GetSupervisorChanges(employeeID uint) ([]model.SupervisorChange, error) {
+	var changes []model.SupervisorChange
+	err := r.db.Preload("OldSupervisor").Preload("NewSupervisor").
+		Where("employee_id = ?", employeeID).
+		Order("created_at DESC, id DESC").
+		Find(&changes).Error
+	return changes, err
 }
 
 // UpdateFields updates specific fields of an employee
@@ -118,6 +213,33 @@ func (r *EmployeeRepository) Delete(id uint) error {
 	return nil
 }
 
+// GetByIDUnscoped retrieves an employee by ID, including soft-deleted ones
+func (r *EmployeeRepository) GetByIDUnscoped(id uint) (*model.Employee, error) {
+	var employee model.Employee
+	err := r.db.Unscoped().First(&employee, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+	return &employee, nil
+}
+
+// Restore clears the soft-delete marker on a deleted employee
+func (r *EmployeeRepository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&model.Employee{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEmployeeNotFound
+	}
+	return nil
+}
+
 // ExistsByUsername checks if a username already exists
 func (r *EmployeeRepository) ExistsByUsername(username string) (bool, error) {
 	var count int64
@@ -132,6 +254,17 @@ func (r *EmployeeRepository) ExistsByEmployeeNo(employeeNo string) (bool, error)
 	return count > 0, err
 }
 
+// ExistsByEmail checks if an email is already used by another employee, including soft-deleted ones
+func (r *EmployeeRepository) ExistsByEmail(email string, excludeID uint) (bool, error) {
+	var count int64
+	query := r.db.Unscoped().Model(&model.Employee{}).Where("email = ?", email)
+	if excludeID != 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
 // GetSubordinates retrieves all direct subordinates of a supervisor
 func (r *EmployeeRepository) GetSubordinates(supervisorID uint) ([]model.Employee, error) {
 	var employees []model.Employee
@@ -139,17 +272,53 @@ func (r *EmployeeRepository) GetSubordinates(supervisorID uint) ([]model.Employe
 	return employees, err
 }
 
-// GetMaxEmployeeNo retrieves the maximum employee number for generating new ones
-func (r *EmployeeRepository) GetMaxEmployeeNo() (string, error) {
-	var employee model.Employee
-	err := r.db.Order("employee_no DESC").First(&employee).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", nil
-		}
-		return "", err
+// GetSubordinatesOf retrieves all direct subordinates of any of the given supervisors
+func (r *EmployeeRepository) GetSubordinatesOf(supervisorIDs []uint) ([]model.Employee, error) {
+	var employees []model.Employee
+	if len(supervisorIDs) == 0 {
+		return employees, nil
 	}
-	return employee.EmployeeNo, nil
+	err := r.db.Where("supervisor_id IN ?", supervisorIDs).Find(&employees).Error
+	return employees, err
+}
+
+// GetDelegatingSupervisorIDs returns the IDs of supervisors who delegated leave approval to the employee
+func (r *EmployeeRepository) GetDelegatingSupervisorIDs(delegateID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.Employee{}).
+		Where("delegate_approver_id = ?", delegateID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetMaxEmployeeNumber retrieves the highest numeric part of the EMP-prefixed employee numbers,
+// soft-deleted employees included as their numbers stay reserved, or 0 when there are none
+// The comparison is numeric, so EMP100000 counts as higher than EMP99999
+func (r *EmployeeRepository) GetMaxEmployeeNumber() (int, error) {
+	var highest int
+	err := r.db.Unscoped().Model(&model.Employee{}).
+		Where("employee_no LIKE ?", "EMP%").
+		Select("COALESCE(MAX(CAST(SUBSTR(employee_no, 4) AS SIGNED INTEGER)), 0)").
+		Scan(&highest).Error
+	return highest, err
+}
+
+// EmployeeNumber is an employee's number with just enough of the employee to identify them
+type EmployeeNumber struct {
+	ID         uint   `json:"id"`
+	EmployeeNo string `json:"employee_no"`
+	Name       string `json:"name"`
+	Deleted    bool   `json:"deleted"`
+}
+
+// ListEmployeeNumbers retrieves the number of every employee, soft-deleted ones included, ordered by ID
+func (r *EmployeeRepository) ListEmployeeNumbers() ([]EmployeeNumber, error) {
+	var numbers []EmployeeNumber
+	err := r.db.Unscoped().Model(&model.Employee{}).
+		Select("id, employee_no, name, deleted_at IS NOT NULL AS deleted").
+		Order("id ASC").
+		Scan(&numbers).Error
+	return numbers, err
 }
 
 // Count returns the total count of employees
@@ -159,9 +328,79 @@ func (r *EmployeeRepository) Count() (int64, error) {
 	return count, err
 }
 
+// CountActive returns the count of active employees
+func (r *EmployeeRepository) CountActive() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Employee{}).Where("is_active = ?", true).Count(&count).Error
+	return count, err
+}
+
+// CountActiveByRole returns the count of active employees holding a role
+func (r *EmployeeRepository) CountActiveByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Employee{}).Where("role = ? AND is_active = ?", role, true).Count(&count).Error
+	return count, err
+}
+
+// EmployeeCount holds headcount figures for a group of employees
+type EmployeeCount struct {
+	Name     string `json:"name"`
+	Total    int64  `json:"total"`
+	Active   int64  `json:"active"`
+	Inactive int64  `json:"inactive"`
+}
+
+// employeeCountSelect is the shared headcount projection over employees
+const employeeCountSelect = "COUNT(*) AS total, " +
+	"COALESCE(SUM(CASE WHEN is_active THEN 1 ELSE 0 END), 0) AS active, " +
+	"COALESCE(SUM(CASE WHEN is_active THEN 0 ELSE 1 END), 0) AS inactive"
+
+// CountByStatus computes total, active and inactive headcount
+func (r *EmployeeRepository) CountByStatus() (*EmployeeCount, error) {
+	var count EmployeeCount
+	err := r.db.Model(&model.Employee{}).
+		Select(employeeCountSelect).
+		Scan(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+// CountByDepartment computes headcount grouped by department
+func (r *EmployeeRepository) CountByDepartment() ([]EmployeeCount, error) {
+	return r.countGroupedBy("department")
+}
+
+// CountByRole computes headcount grouped by role
+func (r *EmployeeRepository) CountByRole() ([]EmployeeCount, error) {
+	return r.countGroupedBy("role")
+}
+
+// countGroupedBy computes headcount grouped by a column; column must not come from user input
+func (r *EmployeeRepository) countGroupedBy(column string) ([]EmployeeCount, error) {
+	var counts []EmployeeCount
+	err := r.db.Model(&model.Employee{}).
+		Select(column + " AS name, " + employeeCountSelect).
+		Group(column).
+		Order(column + " ASC").
+		Scan(&counts).Error
+	return counts, err
+}
+
 // GetEmployeesWithoutSupervisor retrieves all employees who don't have a supervisor
 func (r *EmployeeRepository) GetEmployeesWithoutSupervisor() ([]model.Employee, error) {
 	var employees []model.Employee
 	err := r.db.Where("supervisor_id IS NULL AND is_active = ?", true).Find(&employees).Error
 	return employees, err
 }
+
+// GetActiveByRoles retrieves all active employees holding any of the given roles
+func (r *EmployeeRepository) GetActiveByRoles(roles []string) ([]model.Employee, error) {
+	var employees []model.Employee
+	if len(roles) == 0 {
+		return employees, nil
+	}
+	err := r.db.Where("role IN ? AND is_active = ?", roles, true).Find(&employees).Error
+	return employees, err
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+)
+
+var (
+	ErrHolidayNotFound = errors.New("holiday not found")
+)
+
+// HolidayRepository handles holiday calendar data access
+type HolidayRepository struct {
+	db *gorm.DB
+}
+
+// NewHolidayRepository creates a new holiday repository
+func NewHolidayRepository(db *gorm.DB) *HolidayRepository {
+	return &HolidayRepository{db: db}
+}
+
+// Create creates a new holiday
+func (r *HolidayRepository) Create(holiday *model.Holiday) error {
+	return r.db.Create(holiday).Error
+}
+
+// GetByID retrieves a holiday by ID
+func (r *HolidayRepository) GetByID(id uint) (*model.Holiday, error) {
+	var holiday model.Holiday
+	err := r.db.First(&holiday, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+// GetInRange retrieves all holidays between start and end inclusive, ordered by date
+func (r *HolidayRepository) GetInRange(start, end time.Time) ([]model.Holiday, error) {
+	var holidays []model.Holiday
+	err := r.db.Where("date >= ? AND date <= ?", start, end).
+		Order("date ASC").
+		Find(&holidays).Error
+	return holidays, err
+}
+
+// ExistsByDate checks if another holiday is already defined for the date
+func (r *HolidayRepository) ExistsByDate(date time.Time, excludeID uint) (bool, error) {
+	var count int64
+	query := r.db.Model(&model.Holiday{}).Where("date = ?", date)
+	if excludeID != 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a holiday
+func (r *HolidayRepository) Update(holiday *model.Holiday) error {
+	return r.db.Save(holiday).Error
+}
+
+// Delete deletes a holiday
+func (r *HolidayRepository) Delete(id uint) error {
+	result := r.db.Delete(&model.Holiday{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrHolidayNotFound
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -10,6 +12,7 @@ import (
 
 var (
 	ErrLeaveRequestNotFound = errors.New("leave request not found")
+	ErrLeaveNotPending      = errors.New("leave request is no longer pending")
 )
 
 // LeaveRepository handles leave request data access
@@ -22,6 +25,11 @@ func NewLeaveRepository(db *gorm.DB) *LeaveRepository {
 	return &LeaveRepository{db: db}
 }
 
+// WithContext returns a copy of the repository whose queries are bound to ctx
+func (r *LeaveRepository) WithContext(ctx context.Context) *LeaveRepository {
+	return &LeaveRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create creates a new leave request
 func (r *LeaveRepository) Create(leave *model.LeaveRequest) error {
 	return r.db.Create(leave).Error
@@ -30,7 +38,7 @@ func (r *LeaveRepository) Create(leave *model.LeaveRequest) error {
 // GetByID retrieves a leave request by ID
 func (r *LeaveRepository) GetByID(id uint) (*model.LeaveRequest, error) {
 	var leave model.LeaveRequest
-	err := r.db.Preload("Employee").First(&leave, id).Error
+	err := r.db.Preload("Employee").Preload("Approver").First(&leave, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrLeaveRequestNotFound
@@ -57,18 +65,107 @@ func (r *LeaveRepository) GetPendingBySubordinates(subordinateIDs []uint) ([]mod
 	if len(subordinateIDs) == 0 {
 		return leaves, nil
 	}
-	err := r.db.Preload("Employee").
+	err := r.db.Preload("Employee").Preload("Approver").
 		Where("employee_id IN ? AND status = ?", subordinateIDs, model.LeaveStatusPending).
 		Order("created_at DESC").
 		Find(&leaves).Error
 	return leaves, err
 }
 
+// CountPendingBySubordinates counts pending leave requests from subordinates
+func (r *LeaveRepository) CountPendingBySubordinates(subordinateIDs []uint) (int64, error) {
+	var count int64
+	if len(subordinateIDs) == 0 {
+		return 0, nil
+	}
+	err := r.db.Model(&model.LeaveRequest{}).
+		Where("employee_id IN ? AND status = ?", subordinateIDs, model.LeaveStatusPending).
+		Count(&count).Error
+	return count, err
+}
+
+// GetApprovedOverlapping retrieves approved leave requests intersecting the [start, end] date range
+// A nil employeeIDs slice means all employees
+func (r *LeaveRepository) GetApprovedOverlapping(employeeIDs []uint, start, end time.Time) ([]model.LeaveRequest, error) {
+	var leaves []model.LeaveRequest
+	query := r.db.Preload("Employee").Preload("Approver").
+		Where("status = ? AND start_date <= ? AND end_date >= ?", model.LeaveStatusApproved, end, start)
+	if employeeIDs != nil {
+		if len(employeeIDs) == 0 {
+			return leaves, nil
+		}
+		query = query.Where("employee_id IN ?", employeeIDs)
+	}
+	err := query.Order("employee_id ASC, start_date ASC").Find(&leaves).Error
+	return leaves, err
+}
+
+// HasApprovedOn checks whether the employee has approved leave covering the date
+func (r *LeaveRepository) HasApprovedOn(employeeID uint, date time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.LeaveRequest{}).
+		Where("employee_id = ? AND status = ? AND start_date <= ? AND end_date >= ?", employeeID, model.LeaveStatusApproved, date, date).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// HasOverlapping checks whether the employee has a pending or approved leave, other than excludeID,
+// intersecting the [start, end] date range
+func (r *LeaveRepository) HasOverlapping(employeeID uint, excludeID uint, start, end time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.LeaveRequest{}).
+		Where("employee_id = ? AND id <> ? AND status IN ?", employeeID, excludeID, []string{model.LeaveStatusPending, model.LeaveStatusApproved}).
+		Where("start_date <= ? AND end_date >= ?", end, start).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // Update updates a leave request
 func (r *LeaveRepository) Update(leave *model.LeaveRequest) error {
 	return r.db.Save(leave).Error
 }
 
+// UpdatePending writes the editable fields of a leave request, but only while it is still pending
+// ErrLeaveNotPending is returned when it was approved, rejected or cancelled in the meantime
+func (r *LeaveRepository) UpdatePending(leave *model.LeaveRequest) error {
+	result := r.db.Model(&model.LeaveRequest{}).
+		Where("id = ? AND status = ?", leave.ID, model.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"leave_type":   leave.LeaveType,
+			"start_date":   leave.StartDate,
+			"end_date":     leave.EndDate,
+			"reason":       leave.Reason,
+			"working_days": leave.WorkingDays,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaveNotPending
+	}
+	return nil
+}
+
+// DecidePending records an approval or rejection of a leave request, but only while it is still pending
+// ErrLeaveNotPending is returned when it was decided or cancelled in the meantime
+func (r *LeaveRepository) DecidePending(leave *model.LeaveRequest) error {
+	result := r.db.Model(&model.LeaveRequest{}).
+		Where("id = ? AND status = ?", leave.ID, model.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"status":        leave.Status,
+			"reject_reason": leave.RejectReason,
+			"approved_by":   leave.ApprovedBy,
+			"actioned_at":   leave.ActionedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaveNotPending
+	}
+	return nil
+}
+
 // UpdateStatus updates the status of a leave request
 func (r *LeaveRepository) UpdateStatus(id uint, status string, rejectReason string) error {
 	updates := map[string]interface{}{
@@ -108,10 +205,19 @@ func (r *LeaveRepository) GetByEmployeeIDWithStatus(employeeID uint, status stri
 	return leaves, err
 }
 
+// CountByEmployeeAndStatus counts an employee's leave requests in the given status
+func (r *LeaveRepository) CountByEmployeeAndStatus(employeeID uint, status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.LeaveRequest{}).
+		Where("employee_id = ? AND status = ?", employeeID, status).
+		Count(&count).Error
+	return count, err
+}
+
 // List retrieves all leave requests with optional filters
 func (r *LeaveRepository) List(filters map[string]interface{}) ([]model.LeaveRequest, error) {
 	var leaves []model.LeaveRequest
-	query := r.db.Preload("Employee")
+	query := r.db.Preload("Employee").Preload("Approver")
 
 	if employeeID, ok := filters["employee_id"]; ok {
 		query = query.Where("employee_id = ?", employeeID)
@@ -122,6 +228,13 @@ func (r *LeaveRepository) List(filters map[string]interface{}) ([]model.LeaveReq
 	if leaveType, ok := filters["leave_type"]; ok && leaveType != "" {
 		query = query.Where("leave_type = ?", leaveType)
 	}
+	// Date range filters keep any leave overlapping [start, end]
+	if start, ok := filters["start"]; ok && start != "" {
+		query = query.Where("end_date >= ?", start)
+	}
+	if end, ok := filters["end"]; ok && end != "" {
+		query = query.Where("start_date <= ?", end)
+	}
 
 	err := query.Order("created_at DESC").Find(&leaves).Error
 	return leaves, err
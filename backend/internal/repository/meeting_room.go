@@ -1,18 +1,21 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"oa-system/internal/model"
 )
 
 var (
-	ErrMeetingRoomNotFound    = errors.New("meeting room not found")
-	ErrBookingNotFound        = errors.New("booking not found")
-	ErrBookingConflict        = errors.New("booking time conflict")
+	ErrMeetingRoomNotFound   = errors.New("meeting room not found")
+	ErrBookingNotFound       = errors.New("booking not found")
+	ErrBookingConflict       = errors.New("booking time conflict")
+	ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
 )
 
 // MeetingRoomRepository handles meeting room data access
@@ -34,7 +37,7 @@ func (r *MeetingRoomRepository) Create(room *model.MeetingRoom) error {
 // GetByID retrieves a meeting room by ID
 func (r *MeetingRoomRepository) GetByID(id uint) (*model.MeetingRoom, error) {
 	var room model.MeetingRoom
-	err := r.db.First(&room, id).Error
+	err := r.db.Preload("Amenities").First(&room, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrMeetingRoomNotFound
@@ -44,19 +47,64 @@ func (r *MeetingRoomRepository) GetByID(id uint) (*model.MeetingRoom, error) {
 	return &room, nil
 }
 
-// GetAll retrieves all meeting rooms
+// LockByID locks a meeting room row until the surrounding transaction ends, serializing changes to its bookings
+func (r *MeetingRoomRepository) LockByID(id uint) error {
+	var room model.MeetingRoom
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMeetingRoomNotFound
+	}
+	return err
+}
+
+// GetAll retrieves all meeting rooms matching the filters
+// Supported filters: amenity (normalized amenity name), min_capacity
 // Implements Requirement 8.4: Employee views meeting room availability
-func (r *MeetingRoomRepository) GetAll() ([]model.MeetingRoom, error) {
+func (r *MeetingRoomRepository) GetAll(filters map[string]interface{}) ([]model.MeetingRoom, error) {
 	var rooms []model.MeetingRoom
-	err := r.db.Order("created_at DESC").Find(&rooms).Error
+	query := r.db.Preload("Amenities")
+
+	if amenity, ok := filters["amenity"]; ok && amenity != "" {
+		query = query.Where("id IN (?)", r.db.Model(&model.RoomAmenity{}).Select("meeting_room_id").Where("name = ?", amenity))
+	}
+	if minCapacity, ok := filters["min_capacity"]; ok {
+		query = query.Where("capacity >= ?", minCapacity)
+	}
+	if q, ok := filters["q"].(string); ok {
+		query = whereContains(query, q, "name", "location")
+	}
+
+	err := query.Order("created_at DESC").Find(&rooms).Error
 	return rooms, err
 }
 
 
 // Update updates a meeting room
+// When amenities is non-nil the room's amenities are replaced in the same transaction
 // Implements Requirement 8.2: Super admin updates meeting room info
-func (r *MeetingRoomRepository) Update(room *model.MeetingRoom) error {
-	return r.db.Save(room).Error
+func (r *MeetingRoomRepository) Update(room *model.MeetingRoom, amenities []model.RoomAmenity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(room).Error; err != nil {
+			return err
+		}
+		if amenities == nil {
+			return nil
+		}
+		if err := tx.Where("meeting_room_id = ?", room.ID).Delete(&model.RoomAmenity{}).Error; err != nil {
+			return err
+		}
+		for i := range amenities {
+			amenities[i].ID = 0
+			amenities[i].MeetingRoomID = room.ID
+		}
+		if len(amenities) > 0 {
+			if err := tx.Create(&amenities).Error; err != nil {
+				return err
+			}
+		}
+		room.Amenities = amenities
+		return nil
+	})
 }
 
 // Delete soft deletes a meeting room
@@ -82,6 +130,11 @@ func NewMeetingRoomBookingRepository(db *gorm.DB) *MeetingRoomBookingRepository
 	return &MeetingRoomBookingRepository{db: db}
 }
 
+// WithContext returns a copy of the repository whose queries are bound to ctx
+func (r *MeetingRoomBookingRepository) WithContext(ctx context.Context) *MeetingRoomBookingRepository {
+	return &MeetingRoomBookingRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create creates a new booking
 func (r *MeetingRoomBookingRepository) Create(booking *model.MeetingRoomBooking) error {
 	return r.db.Create(booking).Error
@@ -139,10 +192,41 @@ func (r *MeetingRoomBookingRepository) HasActiveBooking(employeeID uint) (bool,
 	return count > 0, nil
 }
 
+// CountActiveByEmployee counts an employee's active bookings
+func (r *MeetingRoomBookingRepository) CountActiveByEmployee(employeeID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.MeetingRoomBooking{}).
+		Where("employee_id = ? AND status = ?", employeeID, model.BookingStatusActive).
+		Count(&count).Error
+	return count, err
+}
+
+// GetActiveByEmployee retrieves an employee's active bookings
+func (r *MeetingRoomBookingRepository) GetActiveByEmployee(employeeID uint) ([]model.MeetingRoomBooking, error) {
+	var bookings []model.MeetingRoomBooking
+	err := r.db.Preload("MeetingRoom").
+		Where("employee_id = ? AND status = ?", employeeID, model.BookingStatusActive).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// CancelActiveByEmployee cancels all of an employee's active bookings
+func (r *MeetingRoomBookingRepository) CancelActiveByEmployee(employeeID uint) error {
+	return r.db.Model(&model.MeetingRoomBooking{}).
+		Where("employee_id = ? AND status = ?", employeeID, model.BookingStatusActive).
+		Update("status", model.BookingStatusCancelled).Error
+}
+
 // HasConflict checks if there's a booking conflict for a meeting room at a specific time
 // Implements Property 12: 会议室预定冲突检测
 // Implements Requirement 8.5, 8.6: Check booking conflicts
 func (r *MeetingRoomBookingRepository) HasConflict(roomID uint, date time.Time, startTime, endTime string) (bool, *model.MeetingRoomBooking, error) {
+	return r.HasConflictExcluding(roomID, date, startTime, endTime, 0)
+}
+
+// HasConflictExcluding checks for a booking conflict like HasConflict, ignoring the booking with excludeID
+// so that a booking being moved does not conflict with its own current slot
+func (r *MeetingRoomBookingRepository) HasConflictExcluding(roomID uint, date time.Time, startTime, endTime string, excludeID uint) (bool, *model.MeetingRoomBooking, error) {
 	var booking model.MeetingRoomBooking
 	// 使用日期字符串比较，避免时区问题
 	dateStr := date.Format("2006-01-02")
@@ -151,6 +235,7 @@ func (r *MeetingRoomBookingRepository) HasConflict(roomID uint, date time.Time,
 	err := r.db.Preload("Employee").
 		Where("meeting_room_id = ? AND DATE(booking_date) = ? AND status = ?", roomID, dateStr, model.BookingStatusActive).
 		Where("start_time < ? AND end_time > ?", endTime, startTime).
+		Where("id <> ?", excludeID).
 		First(&booking).Error
 	
 	if err != nil {
@@ -167,6 +252,15 @@ func (r *MeetingRoomBookingRepository) Update(booking *model.MeetingRoomBooking)
 	return r.db.Save(booking).Error
 }
 
+// Reschedule moves a booking to another date and time
+func (r *MeetingRoomBookingRepository) Reschedule(id uint, date time.Time, startTime, endTime string) error {
+	return r.db.Model(&model.MeetingRoomBooking{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"booking_date": date,
+		"start_time":   startTime,
+		"end_time":     endTime,
+	}).Error
+}
+
 // UpdateStatus updates the status of a booking
 func (r *MeetingRoomBookingRepository) UpdateStatus(id uint, status string) error {
 	result := r.db.Model(&model.MeetingRoomBooking{}).Where("id = ?", id).Update("status", status)
@@ -179,6 +273,33 @@ func (r *MeetingRoomBookingRepository) UpdateStatus(id uint, status string) erro
 	return nil
 }
 
+// CancelNoShows cancels the given bookings, skipping any that were checked in or are no longer active
+func (r *MeetingRoomBookingRepository) CancelNoShows(ids []uint) (int64, error) {
+	result := r.db.Model(&model.MeetingRoomBooking{}).
+		Where("id IN ? AND status = ? AND checked_in_at IS NULL", ids, model.BookingStatusActive).
+		Update("status", model.BookingStatusCancelled)
+	return result.RowsAffected, result.Error
+}
+
+// GetNoShows retrieves active bookings that were never checked in and whose start time is at or
+// before the given cutoff, whose date and clock time are read in its own location
+// Inside a transaction the bookings stay locked until it ends, so none can be checked in meanwhile
+func (r *MeetingRoomBookingRepository) GetNoShows(cutoff time.Time) ([]model.MeetingRoomBooking, error) {
+	var bookings []model.MeetingRoomBooking
+	err := r.noShows(cutoff).Clauses(clause.Locking{Strength: "UPDATE"}).Find(&bookings).Error
+	return bookings, err
+}
+
+// noShows scopes a query to active bookings that started at or before the cutoff without a check-in
+func (r *MeetingRoomBookingRepository) noShows(cutoff time.Time) *gorm.DB {
+	// 使用日期字符串比较，避免时区问题
+	cutoffDate := cutoff.Format("2006-01-02")
+	cutoffTime := cutoff.Format("15:04")
+	return r.db.Model(&model.MeetingRoomBooking{}).
+		Where("status = ? AND checked_in_at IS NULL", model.BookingStatusActive).
+		Where("(DATE(booking_date) < ? OR (DATE(booking_date) = ? AND start_time <= ?))", cutoffDate, cutoffDate, cutoffTime)
+}
+
 // GetAllByDate retrieves all bookings for a specific date
 func (r *MeetingRoomBookingRepository) GetAllByDate(date time.Time) ([]model.MeetingRoomBooking, error) {
 	var bookings []model.MeetingRoomBooking
@@ -189,6 +310,21 @@ func (r *MeetingRoomBookingRepository) GetAllByDate(date time.Time) ([]model.Mee
 	return bookings, err
 }
 
+// GetActiveByDates retrieves the active bookings of all rooms on any of the given dates in one query
+func (r *MeetingRoomBookingRepository) GetActiveByDates(dates []time.Time) ([]model.MeetingRoomBooking, error) {
+	var bookings []model.MeetingRoomBooking
+	// 使用日期字符串比较，避免时区问题
+	dateStrs := make([]string, len(dates))
+	for i, date := range dates {
+		dateStrs[i] = date.Format("2006-01-02")
+	}
+	err := r.db.Preload("Employee").
+		Where("DATE(booking_date) IN ? AND status = ?", dateStrs, model.BookingStatusActive).
+		Order("meeting_room_id ASC, booking_date ASC, start_time ASC").
+		Find(&bookings).Error
+	return bookings, err
+}
+
 // List retrieves all bookings with optional filters
 func (r *MeetingRoomBookingRepository) List(filters map[string]interface{}) ([]model.MeetingRoomBooking, error) {
 	var bookings []model.MeetingRoomBooking
@@ -210,3 +346,80 @@ func (r *MeetingRoomBookingRepository) List(filters map[string]interface{}) ([]m
 	err := query.Order("booking_date DESC, start_time DESC").Find(&bookings).Error
 	return bookings, err
 }
+
+// BookingWaitlistRepository handles booking waitlist data access
+type BookingWaitlistRepository struct {
+	db *gorm.DB
+}
+
+// NewBookingWaitlistRepository creates a new booking waitlist repository
+func NewBookingWaitlistRepository(db *gorm.DB) *BookingWaitlistRepository {
+	return &BookingWaitlistRepository{db: db}
+}
+
+// Create creates a new waitlist entry
+func (r *BookingWaitlistRepository) Create(entry *model.BookingWaitlist) error {
+	return r.db.Create(entry).Error
+}
+
+// GetByID retrieves a waitlist entry by ID
+func (r *BookingWaitlistRepository) GetByID(id uint) (*model.BookingWaitlist, error) {
+	var entry model.BookingWaitlist
+	err := r.db.Preload("MeetingRoom").First(&entry, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWaitlistEntryNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetByEmployeeID retrieves all waitlist entries for an employee
+func (r *BookingWaitlistRepository) GetByEmployeeID(employeeID uint) ([]model.BookingWaitlist, error) {
+	var entries []model.BookingWaitlist
+	err := r.db.Preload("MeetingRoom").
+		Where("employee_id = ?", employeeID).
+		Order("booking_date DESC, start_time DESC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// HasWaiting checks if an employee is already waiting for the exact slot
+func (r *BookingWaitlistRepository) HasWaiting(employeeID, roomID uint, date time.Time, startTime, endTime string) (bool, error) {
+	var count int64
+	// 使用日期字符串比较，避免时区问题
+	dateStr := date.Format("2006-01-02")
+	err := r.db.Model(&model.BookingWaitlist{}).
+		Where("employee_id = ? AND meeting_room_id = ? AND DATE(booking_date) = ? AND status = ?", employeeID, roomID, dateStr, model.WaitlistStatusWaiting).
+		Where("start_time = ? AND end_time = ?", startTime, endTime).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetWaiting retrieves the waiting entries for a meeting room on a date, earliest first
+func (r *BookingWaitlistRepository) GetWaiting(roomID uint, date time.Time) ([]model.BookingWaitlist, error) {
+	var entries []model.BookingWaitlist
+	// 使用日期字符串比较，避免时区问题
+	dateStr := date.Format("2006-01-02")
+	err := r.db.Preload("MeetingRoom").
+		Where("meeting_room_id = ? AND DATE(booking_date) = ? AND status = ?", roomID, dateStr, model.WaitlistStatusWaiting).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// UpdateStatus updates the status of a waitlist entry, recording the booking it was promoted into if any
+func (r *BookingWaitlistRepository) UpdateStatus(id uint, status string, bookingID *uint) error {
+	result := r.db.Model(&model.BookingWaitlist{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     status,
+		"booking_id": bookingID,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWaitlistEntryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"oa-system/internal/model"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// NotificationRepository handles notification data access
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create creates a new notification
+func (r *NotificationRepository) Create(notification *model.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+// CreateBatch creates multiple notifications in a single insert
+func (r *NotificationRepository) CreateBatch(notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+// GetByID retrieves a notification by ID
+func (r *NotificationRepository) GetByID(id uint) (*model.Notification, error) {
+	var notification model.Notification
+	err := r.db.First(&notification, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// GetByEmployeeID retrieves all notifications for an employee, newest first
+func (r *NotificationRepository) GetByEmployeeID(employeeID uint) ([]model.Notification, error) {
+	var notifications []model.Notification
+	err := r.db.Where("employee_id = ?", employeeID).
+		Order("created_at DESC").
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// Delete soft deletes a notification
+func (r *NotificationRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Notification{}, id).Error
+}
+
+// DeleteByEmployeeID soft deletes an employee's notifications, only the read ones if readOnly is set,
+// and returns how many were deleted
+func (r *NotificationRepository) DeleteByEmployeeID(employeeID uint, readOnly bool) (int64, error) {
+	query := r.db.Where("employee_id = ?", employeeID)
+	if readOnly {
+		query = query.Where("is_read = ?", true)
+	}
+	result := query.Delete(&model.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// GetPreferences retrieves the notification preferences an employee has set
+func (r *NotificationRepository) GetPreferences(employeeID uint) ([]model.NotificationPreference, error) {
+	var preferences []model.NotificationPreference
+	err := r.db.Where("employee_id = ?", employeeID).Find(&preferences).Error
+	return preferences, err
+}
+
+// GetPreferencesForType retrieves the stored preferences of the employees for one notification type
+// Employees without a stored preference are absent from the result
+func (r *NotificationRepository) GetPreferencesForType(employeeIDs []uint, notificationType string) ([]model.NotificationPreference, error) {
+	var preferences []model.NotificationPreference
+	if len(employeeIDs) == 0 {
+		return preferences, nil
+	}
+	err := r.db.Where("employee_id IN ? AND type = ?", employeeIDs, notificationType).Find(&preferences).Error
+	return preferences, err
+}
+
+// SavePreferences creates or replaces an employee's preferences for the given types
+func (r *NotificationRepository) SavePreferences(preferences []model.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "employee_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "updated_at"}),
+	}).Create(&preferences).Error
+}
+
+// HasUnreadOfType checks if an employee has an unread notification of the given type
+func (r *NotificationRepository) HasUnreadOfType(employeeID uint, notificationType string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("employee_id = ? AND type = ? AND is_read = ?", employeeID, notificationType, false).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountUnread counts an employee's unread notifications
+func (r *NotificationRepository) CountUnread(employeeID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("employee_id = ? AND is_read = ?", employeeID, false).
+		Count(&count).Error
+	return count, err
+}
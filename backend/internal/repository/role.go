@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+)
+
+// RoleRepository handles role and role permission data access
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// WithContext returns a copy of the repository whose queries are bound to ctx
+func (r *RoleRepository) WithContext(ctx context.Context) *RoleRepository {
+	return &RoleRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create creates a new role together with its permissions
+func (r *RoleRepository) Create(role *model.Role) error {
+	return r.db.Create(role).Error
+}
+
+// GetByID retrieves a role by ID with its permissions
+func (r *RoleRepository) GetByID(id uint) (*model.Role, error) {
+	var role model.Role
+	err := r.db.Preload("Permissions").First(&role, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// List retrieves all roles with their permissions
+func (r *RoleRepository) List() ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+// ExistsByName checks if a role with the given name exists
+func (r *RoleRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Role{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// Count returns the total count of roles
+func (r *RoleRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Role{}).Count(&count).Error
+	return count, err
+}
+
+// GrantPermission grants a permission to a role; granting an existing permission is a no-op
+func (r *RoleRepository) GrantPermission(roleID uint, permission string) error {
+	var count int64
+	err := r.db.Model(&model.RolePermission{}).
+		Where("role_id = ? AND permission = ?", roleID, permission).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+	return r.db.Create(&model.RolePermission{RoleID: roleID, Permission: permission}).Error
+}
+
+// RevokePermission revokes a permission from a role
+func (r *RoleRepository) RevokePermission(roleID uint, permission string) error {
+	return r.db.Where("role_id = ? AND permission = ?", roleID, permission).
+		Delete(&model.RolePermission{}).Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
 
@@ -31,7 +32,7 @@ func (r *SalaryRepository) Create(salary *model.Salary) error {
 // GetByID retrieves a salary record by ID
 func (r *SalaryRepository) GetByID(id uint) (*model.Salary, error) {
 	var salary model.Salary
-	err := r.db.Preload("Employee").First(&salary, id).Error
+	err := r.db.Preload("Employee").Preload("Components").First(&salary, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrSalaryNotFound
@@ -64,20 +65,46 @@ func (r *SalaryRepository) ExistsByEmployeeAndMonth(employeeID uint, month strin
 	return count > 0, err
 }
 
-// ListByEmployeeID retrieves all salary records for an employee, ordered by month descending
+// GetEmployeeIDsByMonth returns which of the given employees already have a salary record for the month
+func (r *SalaryRepository) GetEmployeeIDsByMonth(month string, employeeIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(employeeIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&model.Salary{}).
+		Where("month = ? AND employee_id IN ?", month, employeeIDs).
+		Pluck("employee_id", &ids).Error
+	return ids, err
+}
+
+// ListPublishedByEmployeeID retrieves an employee's published salary records, ordered by month descending
 // This implements Property 5: Salary records should be ordered by month descending
-func (r *SalaryRepository) ListByEmployeeID(employeeID uint) ([]model.Salary, error) {
+func (r *SalaryRepository) ListPublishedByEmployeeID(employeeID uint) ([]model.Salary, error) {
 	var salaries []model.Salary
-	err := r.db.Where("employee_id = ?", employeeID).
+	err := r.db.Preload("Components").
+		Where("employee_id = ? AND published = ?", employeeID, true).
 		Order("month DESC").
 		Find(&salaries).Error
 	return salaries, err
 }
 
+// GetLatestPublishedByEmployeeID retrieves the most recent published salary record for an employee
+func (r *SalaryRepository) GetLatestPublishedByEmployeeID(employeeID uint) (*model.Salary, error) {
+	var salary model.Salary
+	err := r.db.Where("employee_id = ? AND published = ?", employeeID, true).Order("month DESC").First(&salary).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSalaryNotFound
+		}
+		return nil, err
+	}
+	return &salary, nil
+}
+
 // List retrieves all salary records with optional filters
 func (r *SalaryRepository) List(filters map[string]interface{}) ([]model.Salary, error) {
 	var salaries []model.Salary
-	query := r.db.Preload("Employee")
+	query := r.db.Preload("Employee").Preload("Components")
 
 	if employeeID, ok := filters["employee_id"]; ok && employeeID != nil {
 		query = query.Where("employee_id = ?", employeeID)
@@ -90,9 +117,97 @@ func (r *SalaryRepository) List(filters map[string]interface{}) ([]model.Salary,
 	return salaries, err
 }
 
+// SalaryAggregate holds aggregated net salary figures for a set of salary records
+type SalaryAggregate struct {
+	Department string  `json:"department,omitempty"`
+	Headcount  int64   `json:"headcount"`
+	Total      float64 `json:"total"`
+	Average    float64 `json:"average"`
+	Min        float64 `json:"min"`
+	Max        float64 `json:"max"`
+}
+
+// salaryAggregateSelect is the shared aggregate projection over net salaries
+const salaryAggregateSelect = "COUNT(DISTINCT salaries.employee_id) AS headcount, " +
+	"COALESCE(SUM(salaries.net_salary), 0) AS total, " +
+	"COALESCE(AVG(salaries.net_salary), 0) AS average, " +
+	"COALESCE(MIN(salaries.net_salary), 0) AS min, " +
+	"COALESCE(MAX(salaries.net_salary), 0) AS max"
+
+// monthAggregateQuery builds the base query for aggregating a month's salaries, optionally scoped to a department
+func (r *SalaryRepository) monthAggregateQuery(month, department string) *gorm.DB {
+	query := r.db.Model(&model.Salary{}).
+		Joins("JOIN employees ON employees.id = salaries.employee_id").
+		Where("salaries.month = ?", month)
+	if department != "" {
+		query = query.Where("employees.department = ?", department)
+	}
+	return query
+}
+
+// AggregateByMonth computes payroll totals for a month
+func (r *SalaryRepository) AggregateByMonth(month, department string) (*SalaryAggregate, error) {
+	var aggregate SalaryAggregate
+	err := r.monthAggregateQuery(month, department).
+		Select(salaryAggregateSelect).
+		Scan(&aggregate).Error
+	if err != nil {
+		return nil, err
+	}
+	aggregate.Department = department
+	return &aggregate, nil
+}
+
+// AggregateByMonthPerDepartment computes payroll totals for a month grouped by department
+func (r *SalaryRepository) AggregateByMonthPerDepartment(month, department string) ([]SalaryAggregate, error) {
+	var aggregates []SalaryAggregate
+	err := r.monthAggregateQuery(month, department).
+		Select("employees.department AS department, " + salaryAggregateSelect).
+		Group("employees.department").
+		Order("employees.department ASC").
+		Scan(&aggregates).Error
+	return aggregates, err
+}
+
 // Update updates a salary record
 func (r *SalaryRepository) Update(salary *model.Salary) error {
-	return r.db.Save(salary).Error
+	return updateVersioned(r.db, salary, &salary.Version)
+}
+
+// UpdateWithComponents updates a salary record and replaces its components in a single transaction
+func (r *SalaryRepository) UpdateWithComponents(salary *model.Salary, components []model.SalaryComponent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("salary_id = ?", salary.ID).Delete(&model.SalaryComponent{}).Error; err != nil {
+			return err
+		}
+		if err := updateVersioned(tx, salary, &salary.Version); err != nil {
+			return err
+		}
+		for i := range components {
+			components[i].ID = 0
+			components[i].SalaryID = salary.ID
+		}
+		if len(components) > 0 {
+			if err := tx.Create(&components).Error; err != nil {
+				return err
+			}
+		}
+		salary.Components = components
+		return nil
+	})
+}
+
+// Publish makes a salary record visible to its employee
+func (r *SalaryRepository) Publish(id uint) error {
+	return r.db.Model(&model.Salary{}).Where("id = ?", id).UpdateColumn("published", true).Error
+}
+
+// PublishMonth makes every unpublished salary record of the month visible and returns how many were published
+func (r *SalaryRepository) PublishMonth(month string) (int64, error) {
+	result := r.db.Model(&model.Salary{}).
+		Where("month = ? AND published = ?", month, false).
+		UpdateColumn("published", true)
+	return result.RowsAffected, result.Error
 }
 
 // Delete soft deletes a salary record
@@ -106,3 +221,49 @@ func (r *SalaryRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+// SalaryTotals holds summed salary figures for one employee, either over a whole year or for one month of it
+type SalaryTotals struct {
+	Month      string  `json:"month,omitempty"`
+	BaseSalary float64 `json:"base_salary"`
+	Bonus      float64 `json:"bonus"`
+	Deduction  float64 `json:"deduction"`
+	NetSalary  float64 `json:"net_salary"`
+}
+
+// salaryTotalsSelect is the shared sum projection over an employee's salary figures
+const salaryTotalsSelect = "COALESCE(SUM(base_salary), 0) AS base_salary, " +
+	"COALESCE(SUM(bonus), 0) AS bonus, " +
+	"COALESCE(SUM(deduction), 0) AS deduction, " +
+	"COALESCE(SUM(net_salary), 0) AS net_salary"
+
+// publishedYearQuery builds the base query over an employee's published salaries of a year
+// This implements Property 4: Data isolation - the query is always scoped to the one employee
+func (r *SalaryRepository) publishedYearQuery(employeeID uint, year int) *gorm.DB {
+	return r.db.Model(&model.Salary{}).
+		Where("employee_id = ? AND published = ?", employeeID, true).
+		Where("month BETWEEN ? AND ?", fmt.Sprintf("%04d-01", year), fmt.Sprintf("%04d-12", year))
+}
+
+// SumPublishedByEmployeeAndYear sums an employee's published salaries for a year
+func (r *SalaryRepository) SumPublishedByEmployeeAndYear(employeeID uint, year int) (*SalaryTotals, error) {
+	var totals SalaryTotals
+	err := r.publishedYearQuery(employeeID, year).
+		Select(salaryTotalsSelect).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// SumPublishedByEmployeeAndYearPerMonth sums an employee's published salaries for a year grouped by month, ordered by month ascending
+func (r *SalaryRepository) SumPublishedByEmployeeAndYearPerMonth(employeeID uint, year int) ([]SalaryTotals, error) {
+	var totals []SalaryTotals
+	err := r.publishedYearQuery(employeeID, year).
+		Select("month, " + salaryTotalsSelect).
+		Group("month").
+		Order("month ASC").
+		Scan(&totals).Error
+	return totals, err
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper escapes LIKE wildcards so search text is matched literally. '!' is used as the
+// escape character because a backslash would need quoting differently in MySQL and SQLite
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// whereContains narrows a query to rows where any of the columns contains the search text,
+// ignoring case. An empty search leaves the query unchanged
+func whereContains(query *gorm.DB, search string, columns ...string) *gorm.DB {
+	if search == "" || len(columns) == 0 {
+		return query
+	}
+
+	pattern := "%" + likeEscaper.Replace(strings.ToLower(search)) + "%"
+	conditions := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		conditions[i] = "LOWER(" + column + ") LIKE ? ESCAPE '!'"
+		args[i] = pattern
+	}
+	return query.Where(strings.Join(conditions, " OR "), args...)
+}
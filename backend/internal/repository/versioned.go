@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrVersionConflict = errors.New("record was modified since it was loaded")
+)
+
+// updateVersioned writes every column of value, which must carry its primary key, but only if
+// the stored row still has the version it was loaded with. The version is bumped on success and
+// ErrVersionConflict is returned when another update got there first. Associations are not saved
+func updateVersioned(db *gorm.DB, value interface{}, version *int) error {
+	loaded := *version
+	*version = loaded + 1
+
+	result := db.Model(value).
+		Omit(clause.Associations).
+		Select("*").
+		Where("version = ?", loaded).
+		Updates(value)
+	if result.Error != nil {
+		*version = loaded
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		*version = loaded
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+)
+
+var (
+	ErrWorkScheduleNotFound = errors.New("work schedule not found")
+)
+
+// WorkScheduleRepository handles department work schedule data access
+type WorkScheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkScheduleRepository creates a new work schedule repository
+func NewWorkScheduleRepository(db *gorm.DB) *WorkScheduleRepository {
+	return &WorkScheduleRepository{db: db}
+}
+
+// WithContext returns a copy of the repository whose queries are bound to ctx
+func (r *WorkScheduleRepository) WithContext(ctx context.Context) *WorkScheduleRepository {
+	return &WorkScheduleRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create creates a new work schedule
+func (r *WorkScheduleRepository) Create(schedule *model.WorkSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+// GetByID retrieves a work schedule by ID
+func (r *WorkScheduleRepository) GetByID(id uint) (*model.WorkSchedule, error) {
+	var schedule model.WorkSchedule
+	err := r.db.First(&schedule, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkScheduleNotFound
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetByDepartment retrieves the work schedule of a department
+func (r *WorkScheduleRepository) GetByDepartment(department string) (*model.WorkSchedule, error) {
+	var schedule model.WorkSchedule
+	err := r.db.Where("department = ?", department).First(&schedule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkScheduleNotFound
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetAll retrieves all work schedules ordered by department
+func (r *WorkScheduleRepository) GetAll() ([]model.WorkSchedule, error) {
+	var schedules []model.WorkSchedule
+	err := r.db.Order("department ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+// ExistsByDepartment checks if another work schedule is already defined for the department
+func (r *WorkScheduleRepository) ExistsByDepartment(department string, excludeID uint) (bool, error) {
+	var count int64
+	query := r.db.Model(&model.WorkSchedule{}).Where("department = ?", department)
+	if excludeID != 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a work schedule
+func (r *WorkScheduleRepository) Update(schedule *model.WorkSchedule) error {
+	return r.db.Save(schedule).Error
+}
+
+// Delete deletes a work schedule
+func (r *WorkScheduleRepository) Delete(id uint) error {
+	result := r.db.Delete(&model.WorkSchedule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWorkScheduleNotFound
+	}
+	return nil
+}
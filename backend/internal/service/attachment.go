@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+var (
+	ErrAttachmentTooLarge       = errors.New("attachment exceeds the maximum size")
+	ErrAttachmentTypeNotAllowed = errors.New("attachment content type is not allowed")
+)
+
+// allowedAttachmentTypes maps accepted content types to the extension used on disk
+var allowedAttachmentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// AttachmentService handles storing and listing uploaded files
+type AttachmentService struct {
+	repo       *repository.AttachmentRepository
+	storageDir string
+	maxSize    int64
+	db         *gorm.DB
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(db *gorm.DB, cfg *config.AttachmentConfig) *AttachmentService {
+	return &AttachmentService{
+		repo:       repository.NewAttachmentRepository(db),
+		storageDir: cfg.StorageDir,
+		maxSize:    int64(cfg.MaxSizeMB) << 20,
+		db:         db,
+	}
+}
+
+// Upload validates and stores a file for the given owner record
+// The content type is sniffed from the file contents rather than trusted from the client
+func (s *AttachmentService) Upload(ownerType string, ownerID uint, uploaderID uint, file *multipart.FileHeader) (*model.Attachment, error) {
+	if file.Size > s.maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedAttachmentTypes[contentType]
+	if !ok {
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	name, err := randomFileName(ext)
+	if err != nil {
+		return nil, err
+	}
+	relPath := filepath.Join(ownerType, strconv.FormatUint(uint64(ownerID), 10), name)
+	absPath := filepath.Join(s.storageDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+		return nil, err
+	}
+
+	size, err := s.writeFile(absPath, io.MultiReader(bytes.NewReader(head), src))
+	if err != nil {
+		os.Remove(absPath)
+		return nil, err
+	}
+
+	attachment := &model.Attachment{
+		OwnerType:   ownerType,
+		OwnerID:     ownerID,
+		FileName:    filepath.Base(file.Filename),
+		StoragePath: relPath,
+		ContentType: contentType,
+		Size:        size,
+		UploadedBy:  uploaderID,
+	}
+	if err := s.repo.Create(attachment); err != nil {
+		os.Remove(absPath)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// List retrieves all attachments of a record
+func (s *AttachmentService) List(ownerType string, ownerID uint) ([]model.Attachment, error) {
+	return s.repo.ListByOwner(ownerType, ownerID)
+}
+
+// writeFile copies at most maxSize bytes to path, failing if the source is larger
+func (s *AttachmentService) writeFile(path string, src io.Reader) (int64, error) {
+	dst, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+
+	size, err := io.Copy(dst, io.LimitReader(src, s.maxSize+1))
+	if err != nil {
+		return 0, err
+	}
+	if size > s.maxSize {
+		return 0, ErrAttachmentTooLarge
+	}
+	return size, nil
+}
+
+// randomFileName returns an unguessable file name with the given extension
+func randomFileName(ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b) + ext, nil
+}
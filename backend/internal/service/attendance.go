@@ -1,46 +1,100 @@
 package service
 
 import (
+	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
 
+	"oa-system/config"
 	"oa-system/internal/model"
 	"oa-system/internal/repository"
 )
 
 var (
-	ErrAttendanceNotFound   = errors.New("attendance record not found")
-	ErrAlreadySignedIn      = errors.New("already signed in today")
-	ErrNotSignedIn          = errors.New("not signed in today, cannot sign out")
-	ErrAlreadySignedOut     = errors.New("already signed out today")
+	ErrAttendanceNotFound = errors.New("attendance record not found")
+	ErrNotSignedIn        = errors.New("not signed in today, cannot sign out")
+)
+
+// defaultWorkStart and defaultWorkEnd are used when the configured work times cannot be parsed
+const (
+	defaultWorkStart = 9 * time.Hour
+	defaultWorkEnd   = 18 * time.Hour
 )
 
 // AttendanceService handles attendance business logic
 type AttendanceService struct {
-	repo *repository.AttendanceRepository
-	db   *gorm.DB
+	repo              *repository.AttendanceRepository
+	employeeRepo      *repository.EmployeeRepository
+	leaveRepo         *repository.LeaveRepository
+	scheduleRepo      *repository.WorkScheduleRepository
+	holidayService    *HolidayService
+	defaultSchedule   workSchedule // global hours for departments without their own schedule
+	overtimeThreshold time.Duration
+	db                *gorm.DB
 }
 
 // NewAttendanceService creates a new attendance service
-func NewAttendanceService(db *gorm.DB) *AttendanceService {
+func NewAttendanceService(db *gorm.DB, cfg *config.AttendanceConfig) *AttendanceService {
+	schedule := workSchedule{start: defaultWorkStart, end: defaultWorkEnd, workdays: model.DefaultWorkdays}
+	if start, err := parseClock(cfg.WorkStartTime); err == nil {
+		schedule.start = start
+	}
+	if end, err := parseClock(cfg.WorkEndTime); err == nil {
+		schedule.end = end
+	}
+
 	return &AttendanceService{
-		repo: repository.NewAttendanceRepository(db),
-		db:   db,
+		repo:              repository.NewAttendanceRepository(db),
+		employeeRepo:      repository.NewEmployeeRepository(db),
+		leaveRepo:         repository.NewLeaveRepository(db),
+		scheduleRepo:      repository.NewWorkScheduleRepository(db),
+		holidayService:    NewHolidayService(db),
+		defaultSchedule:   schedule,
+		overtimeThreshold: time.Duration(cfg.OvertimeThresholdMinutes) * time.Minute,
+		db:                db,
 	}
 }
 
+// MonthlySummary summarizes an employee's attendance for a month
+type MonthlySummary struct {
+	Year                 int `json:"year"`
+	Month                int `json:"month"`
+	ExpectedWorkdays     int `json:"expected_workdays"` // working days elapsed in the month, excluding holidays
+	DaysSignedIn         int `json:"days_signed_in"`
+	DaysSignedOut        int `json:"days_signed_out"`
+	OvertimeDays         int `json:"overtime_days"`
+	TotalOvertimeMinutes int `json:"total_overtime_minutes"`
+	LateDays             int `json:"late_days"`
+	TotalLateMinutes     int `json:"total_late_minutes"`
+}
+
+// PerfectAttendance is an employee who attended every expected working day of a month
+type PerfectAttendance struct {
+	EmployeeID       uint   `json:"employee_id"`
+	EmployeeNo       string `json:"employee_no"`
+	Name             string `json:"name"`
+	Department       string `json:"department"`
+	ExpectedWorkdays int    `json:"expected_workdays"` // working days elapsed since hire, excluding approved leave
+}
+
 // SignInResponse represents the response after signing in
 type SignInResponse struct {
 	Attendance *model.Attendance `json:"attendance"`
 	Message    string            `json:"message"`
+	Already    bool              `json:"already"` // the employee had already signed in today; the record is unchanged
 }
 
 // SignOutResponse represents the response after signing out
 type SignOutResponse struct {
 	Attendance *model.Attendance `json:"attendance"`
 	Message    string            `json:"message"`
+	Already    bool              `json:"already"` // the employee had already signed out today; the record is unchanged
 }
 
 // TodayStatusResponse represents today's attendance status
@@ -52,22 +106,45 @@ type TodayStatusResponse struct {
 	SignOutTime *time.Time `json:"sign_out_time"`
 }
 
+// TeamMemberToday represents a team member's attendance status for today
+type TeamMemberToday struct {
+	EmployeeID uint   `json:"employee_id"`
+	EmployeeNo string `json:"employee_no"`
+	Name       string `json:"name"`
+	Department string `json:"department"`
+	TodayStatusResponse
+	OnLeave   bool   `json:"on_leave"`             // covered by an approved leave, so not expected to sign in
+	LeaveType string `json:"leave_type,omitempty"` // type of the approved leave when on leave
+}
+
 
 // SignIn records the sign-in time for an employee
-// Implements Property 6: 签到幂等性 - First sign-in succeeds, subsequent attempts are rejected
-func (s *AttendanceService) SignIn(employeeID uint) (*SignInResponse, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+// Implements Property 6: 签到幂等性 - The first sign-in records the time; subsequent attempts
+// leave it unchanged and report the existing record with Already set
+func (s *AttendanceService) SignIn(ctx context.Context, employeeID uint) (*SignInResponse, error) {
+	repo := s.repo.WithContext(ctx)
+
+	now := time.Now().UTC()
+	today := dateOf(now)
 
 	// Check if already signed in today (Property 6: 签到幂等性)
-	attendance, err := s.repo.GetByEmployeeAndDate(employeeID, today)
+	attendance, err := repo.GetByEmployeeAndDate(employeeID, today)
 	if err != nil && !errors.Is(err, repository.ErrAttendanceNotFound) {
 		return nil, err
 	}
 
 	if attendance != nil && attendance.SignInTime != nil {
-		// Already signed in - reject (Property 6)
-		return nil, ErrAlreadySignedIn
+		// Already signed in - keep the original time (Property 6)
+		schedule, err := s.scheduleForEmployee(ctx, employeeID)
+		if err != nil {
+			return nil, err
+		}
+		s.applySchedule(attendance, schedule)
+		return &SignInResponse{
+			Attendance: attendance,
+			Message:    "今日已签到",
+			Already:    true,
+		}, nil
 	}
 
 	// Create new attendance record if not exists
@@ -77,17 +154,23 @@ func (s *AttendanceService) SignIn(employeeID uint) (*SignInResponse, error) {
 			Date:       today,
 			SignInTime: &now,
 		}
-		if err := s.repo.Create(attendance); err != nil {
+		if err := repo.Create(attendance); err != nil {
 			return nil, err
 		}
 	} else {
 		// Update existing record with sign-in time
 		attendance.SignInTime = &now
-		if err := s.repo.Update(attendance); err != nil {
+		if err := repo.Update(attendance); err != nil {
 			return nil, err
 		}
 	}
 
+	schedule, err := s.scheduleForEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	s.applySchedule(attendance, schedule)
+
 	return &SignInResponse{
 		Attendance: attendance,
 		Message:    "签到成功",
@@ -96,12 +179,14 @@ func (s *AttendanceService) SignIn(employeeID uint) (*SignInResponse, error) {
 
 // SignOut records the sign-out time for an employee
 // Implements Property 7: 签退前置条件 - Sign-out only succeeds if already signed in
-func (s *AttendanceService) SignOut(employeeID uint) (*SignOutResponse, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+func (s *AttendanceService) SignOut(ctx context.Context, employeeID uint) (*SignOutResponse, error) {
+	repo := s.repo.WithContext(ctx)
+
+	now := time.Now().UTC()
+	today := dateOf(now)
 
 	// Check if signed in today (Property 7: 签退前置条件)
-	attendance, err := s.repo.GetByEmployeeAndDate(employeeID, today)
+	attendance, err := repo.GetByEmployeeAndDate(employeeID, today)
 	if err != nil {
 		if errors.Is(err, repository.ErrAttendanceNotFound) {
 			// Not signed in - reject (Property 7)
@@ -115,68 +200,376 @@ func (s *AttendanceService) SignOut(employeeID uint) (*SignOutResponse, error) {
 		return nil, ErrNotSignedIn
 	}
 
-	// Check if already signed out
-	if attendance.SignOutTime != nil {
-		return nil, ErrAlreadySignedOut
+	// Already signed out - keep the original time
+	already := attendance.SignOutTime != nil
+	if !already {
+		attendance.SignOutTime = &now
+		if err := repo.Update(attendance); err != nil {
+			return nil, err
+		}
 	}
-
-	// Update sign-out time
-	attendance.SignOutTime = &now
-	if err := s.repo.Update(attendance); err != nil {
+	schedule, err := s.scheduleForEmployee(ctx, employeeID)
+	if err != nil {
 		return nil, err
 	}
+	s.applySchedule(attendance, schedule)
 
+	message := "签退成功"
+	if already {
+		message = "今日已签退"
+	}
 	return &SignOutResponse{
 		Attendance: attendance,
-		Message:    "签退成功",
+		Message:    message,
+		Already:    already,
 	}, nil
 }
 
 
 // GetTodayStatus returns today's attendance status for an employee
-func (s *AttendanceService) GetTodayStatus(employeeID uint) (*TodayStatusResponse, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+func (s *AttendanceService) GetTodayStatus(ctx context.Context, employeeID uint) (*TodayStatusResponse, error) {
+	today := Today()
 
-	attendance, err := s.repo.GetByEmployeeAndDate(employeeID, today)
+	attendance, err := s.repo.WithContext(ctx).GetByEmployeeAndDate(employeeID, today)
 	if err != nil {
 		if errors.Is(err, repository.ErrAttendanceNotFound) {
 			// No attendance record for today
-			return &TodayStatusResponse{
-				Date:        today.Format("2006-01-02"),
-				SignedIn:    false,
-				SignedOut:   false,
-				SignInTime:  nil,
-				SignOutTime: nil,
-			}, nil
+			return newTodayStatus(today, nil), nil
 		}
 		return nil, err
 	}
 
-	return &TodayStatusResponse{
-		Date:        today.Format("2006-01-02"),
-		SignedIn:    attendance.SignInTime != nil,
-		SignedOut:   attendance.SignOutTime != nil,
-		SignInTime:  attendance.SignInTime,
-		SignOutTime: attendance.SignOutTime,
-	}, nil
+	return newTodayStatus(today, attendance), nil
+}
+
+// GetTeamToday returns today's attendance status of each active team member
+// HR and super admins see every employee, optionally scoped to a department;
+// other callers see their direct subordinates
+func (s *AttendanceService) GetTeamToday(ctx context.Context, userID uint, role string, department string) ([]TeamMemberToday, error) {
+	today := Today()
+
+	var members []model.Employee
+	var err error
+	if role == model.RoleHR || role == model.RoleSuperAdmin {
+		members, err = s.employeeRepo.WithContext(ctx).List(map[string]interface{}{
+			"department": department,
+			"is_active":  true,
+		})
+	} else {
+		members, err = s.employeeRepo.WithContext(ctx).GetSubordinates(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		if member.IsActive {
+			ids = append(ids, member.ID)
+		}
+	}
+
+	// One query per table for the whole team instead of one per employee
+	records, err := s.repo.WithContext(ctx).GetByEmployeesAndDate(ids, today)
+	if err != nil {
+		return nil, err
+	}
+	attendanceByEmployee := make(map[uint]*model.Attendance, len(records))
+	for i := range records {
+		attendanceByEmployee[records[i].EmployeeID] = &records[i]
+	}
+
+	leaves, err := s.leaveRepo.WithContext(ctx).GetApprovedOverlapping(ids, today, today)
+	if err != nil {
+		return nil, err
+	}
+	leaveTypeByEmployee := make(map[uint]string, len(leaves))
+	for _, leave := range leaves {
+		leaveTypeByEmployee[leave.EmployeeID] = leave.LeaveType
+	}
+
+	team := make([]TeamMemberToday, 0, len(ids))
+	for _, member := range members {
+		if !member.IsActive {
+			continue
+		}
+		leaveType, onLeave := leaveTypeByEmployee[member.ID]
+		team = append(team, TeamMemberToday{
+			EmployeeID:          member.ID,
+			EmployeeNo:          member.EmployeeNo,
+			Name:                member.Name,
+			Department:          member.Department,
+			TodayStatusResponse: *newTodayStatus(today, attendanceByEmployee[member.ID]),
+			OnLeave:             onLeave,
+			LeaveType:           leaveType,
+		})
+	}
+	return team, nil
+}
+
+// newTodayStatus builds the today status from the day's attendance record, nil if there is none
+func newTodayStatus(today time.Time, attendance *model.Attendance) *TodayStatusResponse {
+	status := &TodayStatusResponse{Date: today.Format("2006-01-02")}
+	if attendance != nil {
+		status.SignedIn = attendance.SignInTime != nil
+		status.SignedOut = attendance.SignOutTime != nil
+		status.SignInTime = attendance.SignInTime
+		status.SignOutTime = attendance.SignOutTime
+	}
+	return status
 }
 
 // GetMonthlyRecords returns all attendance records for an employee in a specific month
-func (s *AttendanceService) GetMonthlyRecords(employeeID uint, year int, month int) ([]model.Attendance, error) {
+func (s *AttendanceService) GetMonthlyRecords(ctx context.Context, employeeID uint, year int, month int) ([]model.Attendance, error) {
+	// Default to current month if not specified
+	if year == 0 || month == 0 {
+		today := Today()
+		year = today.Year()
+		month = int(today.Month())
+	}
+
+	records, err := s.repo.WithContext(ctx).GetByEmployeeAndMonth(employeeID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.scheduleForEmployee(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		s.applySchedule(&records[i], schedule)
+	}
+	return records, nil
+}
+
+// GetMonthlySummary summarizes an employee's attendance and overtime for a month
+func (s *AttendanceService) GetMonthlySummary(ctx context.Context, employeeID uint, year int, month int) (*MonthlySummary, error) {
+	// Default to current month if not specified
+	if year == 0 || month == 0 {
+		today := Today()
+		year = today.Year()
+		month = int(today.Month())
+	}
+
+	records, err := s.GetMonthlyRecords(ctx, employeeID, year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only days up to today are expected to have a sign-in
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	if today := Today(); today.Before(monthEnd) {
+		monthEnd = today
+	}
+	expected, err := s.holidayService.CalculateWorkingDays(monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &MonthlySummary{Year: year, Month: month, ExpectedWorkdays: expected}
+	for _, record := range records {
+		if record.SignInTime != nil {
+			summary.DaysSignedIn++
+		}
+		if record.SignOutTime != nil {
+			summary.DaysSignedOut++
+		}
+		if record.OvertimeMinutes > 0 {
+			summary.OvertimeDays++
+			summary.TotalOvertimeMinutes += record.OvertimeMinutes
+		}
+		if record.LateMinutes > 0 {
+			summary.LateDays++
+			summary.TotalLateMinutes += record.LateMinutes
+		}
+	}
+	return summary, nil
+}
+
+// GetPerfectAttendance lists active employees, optionally scoped to a department, who signed in and out
+// on every working day of the month up to today. Working days come from the holiday calendar; days
+// before the employee was hired and days of approved leave are not expected. Employees with no expected
+// days are left out. Attendance and leave for the whole month are each loaded with a single query.
+func (s *AttendanceService) GetPerfectAttendance(ctx context.Context, year int, month int, department string) ([]PerfectAttendance, error) {
 	// Default to current month if not specified
 	if year == 0 || month == 0 {
-		now := time.Now()
-		year = now.Year()
-		month = int(now.Month())
+		today := Today()
+		year = today.Year()
+		month = int(today.Month())
+	}
+
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	if today := Today(); today.Before(monthEnd) {
+		monthEnd = today
+	}
+	workdays, err := s.holidayService.WorkingDates(monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	perfect := []PerfectAttendance{}
+	if len(workdays) == 0 {
+		return perfect, nil
+	}
+
+	employees, err := s.employeeRepo.WithContext(ctx).List(map[string]interface{}{
+		"department": department,
+		"is_active":  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.repo.WithContext(ctx).GetCompletedDays(monthStart, monthEnd)
+	if err != nil {
+		return nil, err
 	}
+	attended := make(map[uint]map[string]bool)
+	for _, record := range records {
+		if attended[record.EmployeeID] == nil {
+			attended[record.EmployeeID] = make(map[string]bool)
+		}
+		attended[record.EmployeeID][record.Date.Format("2006-01-02")] = true
+	}
+
+	leaves, err := s.leaveRepo.WithContext(ctx).GetApprovedOverlapping(nil, monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	onLeave := make(map[uint][]model.LeaveRequest)
+	for _, leave := range leaves {
+		onLeave[leave.EmployeeID] = append(onLeave[leave.EmployeeID], leave)
+	}
+
+	for _, employee := range employees {
+		hired := dateOf(employee.HireDate)
+		expected := 0
+		missed := false
+		for _, day := range workdays {
+			if day.Before(hired) || isOnLeave(onLeave[employee.ID], day) {
+				continue
+			}
+			expected++
+			if !attended[employee.ID][day.Format("2006-01-02")] {
+				missed = true
+				break
+			}
+		}
+		if missed || expected == 0 {
+			continue
+		}
+		perfect = append(perfect, PerfectAttendance{
+			EmployeeID:       employee.ID,
+			EmployeeNo:       employee.EmployeeNo,
+			Name:             employee.Name,
+			Department:       employee.Department,
+			ExpectedWorkdays: expected,
+		})
+	}
+	return perfect, nil
+}
 
-	return s.repo.GetByEmployeeAndMonth(employeeID, year, month)
+// isOnLeave reports whether any of the leaves covers the date
+func isOnLeave(leaves []model.LeaveRequest, date time.Time) bool {
+	for _, leave := range leaves {
+		if !date.Before(leave.StartDate) && !date.After(leave.EndDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleForEmployee resolves the work schedule of the employee's department,
+// falling back to the global hours when the department has none
+func (s *AttendanceService) scheduleForEmployee(ctx context.Context, employeeID uint) (workSchedule, error) {
+	employee, err := s.employeeRepo.WithContext(ctx).GetByID(employeeID)
+	if err != nil {
+		return workSchedule{}, err
+	}
+	if employee.Department == "" {
+		return s.defaultSchedule, nil
+	}
+
+	stored, err := s.scheduleRepo.WithContext(ctx).GetByDepartment(employee.Department)
+	if err != nil {
+		if errors.Is(err, repository.ErrWorkScheduleNotFound) {
+			return s.defaultSchedule, nil
+		}
+		return workSchedule{}, err
+	}
+
+	start, startErr := parseClock(stored.StartTime)
+	end, endErr := parseClock(stored.EndTime)
+	if startErr != nil || endErr != nil {
+		return s.defaultSchedule, nil
+	}
+	return workSchedule{start: start, end: end, workdays: stored.Workdays}, nil
+}
+
+// applySchedule sets the record's lateness and overtime against the schedule
+// Lateness is sign-in past the start time on a scheduled workday; overtime is sign-out past
+// the end time, floored at zero and ignored below the configured threshold
+func (s *AttendanceService) applySchedule(record *model.Attendance, schedule workSchedule) {
+	record.LateMinutes = 0
+	if record.SignInTime != nil && schedule.isWorkday(record.SignInTime.In(location).Weekday()) {
+		signIn := *record.SignInTime
+		if late := signIn.Sub(dayStart(signIn).Add(schedule.start)); late > 0 {
+			record.LateMinutes = int(late / time.Minute)
+		}
+	}
+
+	record.OvertimeMinutes = 0
+	if record.SignOutTime == nil {
+		return
+	}
+
+	signOut := *record.SignOutTime
+	overtime := signOut.Sub(dayStart(signOut).Add(schedule.end))
+	if overtime <= 0 || overtime < s.overtimeThreshold {
+		return
+	}
+	record.OvertimeMinutes = int(overtime / time.Minute)
+}
+
+// AutoCloseMissingSignOuts signs out every record from before the date's day that was never signed out of,
+// at the end of the employee's working day, and flags it as auto-closed. Records of the date itself
+// are left open since the employee may still sign out. It returns the number of records closed
+func (s *AttendanceService) AutoCloseMissingSignOuts(date time.Time) (int, error) {
+	ctx := context.Background()
+	records, err := s.repo.GetMissingSignOutsBefore(dateOf(date))
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, record := range records {
+		schedule, err := s.scheduleForEmployee(ctx, record.EmployeeID)
+		if err != nil {
+			return closed, err
+		}
+
+		// Someone who signed in after hours is closed at their sign-in so the day never has negative hours
+		signIn := *record.SignInTime
+		signOut := dayStart(signIn).Add(schedule.end).UTC()
+		if signOut.Before(signIn) {
+			signOut = signIn
+		}
+
+		updated, err := s.repo.AutoCloseSignOut(record.ID, signOut)
+		if err != nil {
+			return closed, err
+		}
+		if updated {
+			closed++
+		}
+	}
+	return closed, nil
 }
 
 // GetByID retrieves an attendance record by ID
-func (s *AttendanceService) GetByID(id uint) (*model.Attendance, error) {
-	attendance, err := s.repo.GetByID(id)
+func (s *AttendanceService) GetByID(ctx context.Context, id uint) (*model.Attendance, error) {
+	attendance, err := s.repo.WithContext(ctx).GetByID(id)
 	if err != nil {
 		if errors.Is(err, repository.ErrAttendanceNotFound) {
 			return nil, ErrAttendanceNotFound
@@ -185,3 +578,46 @@ func (s *AttendanceService) GetByID(id uint) (*model.Attendance, error) {
 	}
 	return attendance, nil
 }
+
+// GetAllMonthlyRecords retrieves all employees' attendance for a month, optionally scoped to a department
+func (s *AttendanceService) GetAllMonthlyRecords(ctx context.Context, year int, month int, department string) ([]model.Attendance, error) {
+	return s.repo.WithContext(ctx).GetByMonth(year, month, department)
+}
+
+// WriteCSV writes attendance records as CSV with a computed work-hours column
+// Days without a record are omitted rather than filled in
+func (s *AttendanceService) WriteCSV(w io.Writer, records []model.Attendance) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"employee_no", "name", "date", "sign_in", "sign_out", "work_hours", "auto_closed"}); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		var signIn, signOut, workHours string
+		if record.SignInTime != nil {
+			signIn = record.SignInTime.In(location).Format("15:04:05")
+		}
+		if record.SignOutTime != nil {
+			signOut = record.SignOutTime.In(location).Format("15:04:05")
+		}
+		if record.SignInTime != nil && record.SignOutTime != nil {
+			workHours = fmt.Sprintf("%.2f", record.SignOutTime.Sub(*record.SignInTime).Hours())
+		}
+
+		row := []string{
+			record.Employee.EmployeeNo,
+			record.Employee.Name,
+			record.Date.Format("2006-01-02"),
+			signIn,
+			signOut,
+			workHours,
+			strconv.FormatBool(record.AutoClosed),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"oa-system/internal/model"
+)
+
+// Heatmap day status constants
+const (
+	HeatmapPresent = "present" // signed in that day
+	HeatmapAbsent  = "absent"  // working day without a sign-in or approved leave
+	HeatmapLeave   = "leave"   // working day covered by an approved leave
+	HeatmapHoliday = "holiday" // weekend or public holiday by the holiday calendar
+	HeatmapNone    = "none"    // working day after today or before the employee was hired
+)
+
+// AttendanceHeatmap is the day-by-day attendance status of a group of employees for a month
+type AttendanceHeatmap struct {
+	Year      int                    `json:"year"`
+	Month     int                    `json:"month"`
+	Dates     []string               `json:"dates"` // every day of the month, YYYY-MM-DD
+	Employees []AttendanceHeatmapRow `json:"employees"`
+}
+
+// AttendanceHeatmapRow is one employee's row of the heatmap
+type AttendanceHeatmapRow struct {
+	EmployeeID uint     `json:"employee_id"`
+	EmployeeNo string   `json:"employee_no"`
+	Name       string   `json:"name"`
+	Department string   `json:"department"`
+	Statuses   []string `json:"statuses"` // one status per entry of Dates
+}
+
+// GetHeatmap returns the attendance status of each active employee on every day of the month
+// HR and super admins see every employee, optionally scoped to a department; other callers see their
+// direct subordinates, optionally narrowed to a department. Attendance, approved leave and the holiday
+// calendar for the month are each loaded with a single query.
+func (s *AttendanceService) GetHeatmap(ctx context.Context, userID uint, role string, year int, month int, department string) (*AttendanceHeatmap, error) {
+	// Default to current month if not specified
+	if year == 0 || month == 0 {
+		today := Today()
+		year = today.Year()
+		month = int(today.Month())
+	}
+
+	var members []model.Employee
+	var err error
+	if role == model.RoleHR || role == model.RoleSuperAdmin {
+		members, err = s.employeeRepo.WithContext(ctx).List(map[string]interface{}{
+			"department": department,
+			"is_active":  true,
+		})
+	} else {
+		members, err = s.employeeRepo.WithContext(ctx).GetSubordinates(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	employees := make([]model.Employee, 0, len(members))
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		if !member.IsActive || (department != "" && member.Department != department) {
+			continue
+		}
+		employees = append(employees, member)
+		ids = append(ids, member.ID)
+	}
+
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	workdays, err := s.holidayService.WorkingDates(monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	isWorkingDay := make(map[string]bool, len(workdays))
+	for _, day := range workdays {
+		isWorkingDay[day.Format("2006-01-02")] = true
+	}
+
+	records, err := s.repo.WithContext(ctx).GetByEmployeesAndRange(ids, monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	signedIn := make(map[uint]map[string]bool)
+	for _, record := range records {
+		if record.SignInTime == nil {
+			continue
+		}
+		if signedIn[record.EmployeeID] == nil {
+			signedIn[record.EmployeeID] = make(map[string]bool)
+		}
+		signedIn[record.EmployeeID][record.Date.Format("2006-01-02")] = true
+	}
+
+	leaves, err := s.leaveRepo.WithContext(ctx).GetApprovedOverlapping(ids, monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	onLeave := make(map[uint][]model.LeaveRequest)
+	for _, leave := range leaves {
+		onLeave[leave.EmployeeID] = append(onLeave[leave.EmployeeID], leave)
+	}
+
+	heatmap := &AttendanceHeatmap{
+		Year:      year,
+		Month:     month,
+		Dates:     make([]string, 0, monthEnd.Day()),
+		Employees: make([]AttendanceHeatmapRow, 0, len(employees)),
+	}
+	for day := monthStart; !day.After(monthEnd); day = day.AddDate(0, 0, 1) {
+		heatmap.Dates = append(heatmap.Dates, day.Format("2006-01-02"))
+	}
+
+	today := Today()
+	for _, employee := range employees {
+		hired := dateOf(employee.HireDate)
+		statuses := make([]string, 0, len(heatmap.Dates))
+		for day := monthStart; !day.After(monthEnd); day = day.AddDate(0, 0, 1) {
+			date := day.Format("2006-01-02")
+			status := HeatmapAbsent
+			switch {
+			case signedIn[employee.ID][date]:
+				status = HeatmapPresent
+			case !isWorkingDay[date]:
+				status = HeatmapHoliday
+			case isOnLeave(onLeave[employee.ID], day):
+				status = HeatmapLeave
+			case day.After(today) || day.Before(hired):
+				status = HeatmapNone
+			}
+			statuses = append(statuses, status)
+		}
+		heatmap.Employees = append(heatmap.Employees, AttendanceHeatmapRow{
+			EmployeeID: employee.ID,
+			EmployeeNo: employee.EmployeeNo,
+			Name:       employee.Name,
+			Department: employee.Department,
+			Statuses:   statuses,
+		})
+	}
+	return heatmap, nil
+}
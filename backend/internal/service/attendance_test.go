@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+// createAttendance records a day signed in and out at the given clock times, in UTC
+func createAttendance(t *testing.T, db *gorm.DB, employeeID uint, day time.Time, signIn, signOut string) *model.Attendance {
+	t.Helper()
+	clock := func(value string) *time.Time {
+		parsed, err := time.Parse("15:04", value)
+		if err != nil {
+			t.Fatalf("parse %q: %v", value, err)
+		}
+		at := day.Add(time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute)
+		return &at
+	}
+	record := &model.Attendance{EmployeeID: employeeID, Date: day, SignInTime: clock(signIn), SignOutTime: clock(signOut)}
+	if err := db.Create(record).Error; err != nil {
+		t.Fatalf("create attendance: %v", err)
+	}
+	return record
+}
+
+func TestOvertime(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewAttendanceService(db, testAttendanceConfig())
+	ctx := context.Background()
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	createAttendance(t, db, alice.ID, date(2026, 3, 2), "09:00", "19:30")
+	createAttendance(t, db, alice.ID, date(2026, 3, 3), "09:00", "18:00")
+	createAttendance(t, db, alice.ID, date(2026, 3, 4), "09:00", "18:10")
+	createAttendance(t, db, alice.ID, date(2026, 3, 5), "09:00", "18:45")
+
+	records, err := s.GetMonthlyRecords(ctx, alice.ID, 2026, 3)
+	if err != nil {
+		t.Fatalf("GetMonthlyRecords: %v", err)
+	}
+	want := map[string]int{"2026-03-02": 90, "2026-03-03": 0, "2026-03-04": 0, "2026-03-05": 45}
+	for _, record := range records {
+		day := record.Date.Format("2006-01-02")
+		if record.OvertimeMinutes != want[day] {
+			t.Errorf("%s: overtime = %d, want %d", day, record.OvertimeMinutes, want[day])
+		}
+	}
+
+	summary, err := s.GetMonthlySummary(ctx, alice.ID, 2026, 3)
+	if err != nil {
+		t.Fatalf("GetMonthlySummary: %v", err)
+	}
+	if summary.OvertimeDays != 2 || summary.TotalOvertimeMinutes != 135 {
+		t.Errorf("summary overtime = %d days / %d minutes, want 2 / 135", summary.OvertimeDays, summary.TotalOvertimeMinutes)
+	}
+}
+
+func TestDepartmentWorkSchedule(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewAttendanceService(db, testAttendanceConfig())
+	ctx := context.Background()
+	if _, err := NewWorkScheduleService(db).Create(&CreateWorkScheduleRequest{Department: "工厂", StartTime: "07:30", EndTime: "16:30", Workdays: model.DefaultWorkdays}); err != nil {
+		t.Fatalf("create schedule: %v", err)
+	}
+	worker := testutil.CreateEmployee(t, db, "worker", model.RoleEmployee)
+	clerk := testutil.CreateEmployee(t, db, "clerk", model.RoleEmployee)
+	db.Model(worker).Update("department", "工厂")
+	db.Model(clerk).Update("department", "行政部")
+
+	// Monday 2026-03-02, both signed in at 08:30 and out at 17:30
+	late := map[uint]int{}
+	overtime := map[uint]int{}
+	for _, employee := range []*model.Employee{worker, clerk} {
+		createAttendance(t, db, employee.ID, date(2026, 3, 2), "08:30", "17:30")
+		records, err := s.GetMonthlyRecords(ctx, employee.ID, 2026, 3)
+		if err != nil || len(records) != 1 {
+			t.Fatalf("GetMonthlyRecords %s: %d records, %v", employee.Username, len(records), err)
+		}
+		late[employee.ID], overtime[employee.ID] = records[0].LateMinutes, records[0].OvertimeMinutes
+	}
+	if late[worker.ID] != 60 || overtime[worker.ID] != 60 {
+		t.Errorf("factory schedule: late %d, overtime %d, want 60 and 60", late[worker.ID], overtime[worker.ID])
+	}
+	if late[clerk.ID] != 0 || overtime[clerk.ID] != 0 {
+		t.Errorf("default schedule: late %d, overtime %d, want 0 and 0", late[clerk.ID], overtime[clerk.ID])
+	}
+}
+
+func TestTeamToday(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewAttendanceService(db, testAttendanceConfig())
+	ctx := context.Background()
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	worked := testutil.CreateEmployee(t, db, "worked", model.RoleEmployee)
+	working := testutil.CreateEmployee(t, db, "working", model.RoleEmployee)
+	away := testutil.CreateEmployee(t, db, "away", model.RoleEmployee)
+	absent := testutil.CreateEmployee(t, db, "absent", model.RoleEmployee)
+	disabled := testutil.CreateEmployee(t, db, "disabled", model.RoleEmployee)
+	outsider := testutil.CreateEmployee(t, db, "outsider", model.RoleEmployee)
+	reportTo(t, db, boss, worked, working, away, absent, disabled)
+	db.Model(disabled).Update("is_active", false)
+	db.Model(&model.Employee{}).Where("id IN ?", []uint{worked.ID, working.ID}).Update("department", "研发部")
+	today := Today()
+	createAttendance(t, db, worked.ID, today, "09:00", "18:00")
+	signIn := today.Add(9 * time.Hour)
+	db.Create(&model.Attendance{EmployeeID: working.ID, Date: today, SignInTime: &signIn})
+	createLeave(t, db, away.ID, model.LeaveTypeSick, today, today.AddDate(0, 0, 2), model.LeaveStatusApproved)
+	createLeave(t, db, absent.ID, model.LeaveTypeAnnual, today, today, model.LeaveStatusPending)
+	createAttendance(t, db, outsider.ID, today, "09:00", "18:00")
+
+	team, err := s.GetTeamToday(ctx, boss.ID, boss.Role, "")
+	if err != nil {
+		t.Fatalf("GetTeamToday: %v", err)
+	}
+	type status struct{ signedIn, signedOut, onLeave bool }
+	got := map[string]status{}
+	for _, member := range team {
+		got[member.Name] = status{member.SignedIn, member.SignedOut, member.OnLeave}
+	}
+	want := map[string]status{
+		"worked":  {true, true, false},
+		"working": {true, false, false},
+		"away":    {false, false, true},
+		"absent":  {false, false, false},
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("team today = %+v, want %+v", got, want)
+	}
+
+	scoped, err := s.GetTeamToday(ctx, hr.ID, hr.Role, "研发部")
+	if err != nil {
+		t.Fatalf("GetTeamToday for HR: %v", err)
+	}
+	if len(scoped) != 2 {
+		t.Errorf("HR view of 研发部 has %d members, want 2", len(scoped))
+	}
+}
+
+func TestAutoCloseMissingSignOuts(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewAttendanceService(db, testAttendanceConfig())
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	today := date(2026, 3, 10)
+	yesterday := today.AddDate(0, 0, -1)
+
+	open := func(day time.Time) *model.Attendance {
+		t.Helper()
+		signIn := day.Add(9 * time.Hour)
+		record := &model.Attendance{EmployeeID: alice.ID, Date: day, SignInTime: &signIn}
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("create attendance: %v", err)
+		}
+		return record
+	}
+	stale, current := open(yesterday), open(today)
+	signedOut := createAttendance(t, db, alice.ID, yesterday.AddDate(0, 0, -1), "09:00", "17:30")
+
+	closed, err := s.AutoCloseMissingSignOuts(today.Add(8 * time.Hour))
+	if err != nil {
+		t.Fatalf("AutoCloseMissingSignOuts: %v", err)
+	}
+	if closed != 1 {
+		t.Errorf("closed = %d, want 1", closed)
+	}
+
+	load := func(record *model.Attendance) model.Attendance {
+		t.Helper()
+		var stored model.Attendance
+		if err := db.First(&stored, record.ID).Error; err != nil {
+			t.Fatalf("load attendance: %v", err)
+		}
+		return stored
+	}
+	if got := load(stale); got.SignOutTime == nil || !got.SignOutTime.Equal(yesterday.Add(18*time.Hour)) || !got.AutoClosed {
+		t.Errorf("yesterday = sign-out %v auto-closed %v, want 18:00 and auto-closed", got.SignOutTime, got.AutoClosed)
+	}
+	if got := load(current); got.SignOutTime != nil || got.AutoClosed {
+		t.Errorf("today = sign-out %v auto-closed %v, want still open", got.SignOutTime, got.AutoClosed)
+	}
+	if got := load(signedOut); !got.SignOutTime.Equal(*signedOut.SignOutTime) || got.AutoClosed {
+		t.Errorf("signed-out day = %v auto-closed %v, want untouched", got.SignOutTime, got.AutoClosed)
+	}
+}
+
+func TestSignInJustAfterMidnight(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewAttendanceService(db, testAttendanceConfig())
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	// A zone east of UTC in which it is a few minutes past midnight, so the local day is a day
+	// ahead of the UTC one
+	now := time.Now().UTC()
+	sinceMidnight := now.Sub(now.Truncate(24 * time.Hour))
+	zone := time.FixedZone("just-after-midnight", int((24*time.Hour-sinceMidnight+5*time.Minute)/time.Second))
+	SetTimezone(zone)
+	t.Cleanup(func() { SetTimezone(time.UTC) })
+
+	resp, err := s.SignIn(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatalf("SignIn: %v", err)
+	}
+	want := now.Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if !resp.Attendance.Date.Equal(want) {
+		t.Errorf("sign-in at %s local attributed to %s, want %s", resp.Attendance.SignInTime.In(zone).Format("15:04"), resp.Attendance.Date.Format("2006-01-02"), want.Format("2006-01-02"))
+	}
+
+	var stored model.Attendance
+	if err := db.First(&stored, resp.Attendance.ID).Error; err != nil {
+		t.Fatalf("load attendance: %v", err)
+	}
+	if got := stored.Date.Format("2006-01-02"); got != want.Format("2006-01-02") {
+		t.Errorf("stored date = %s, want %s", got, want.Format("2006-01-02"))
+	}
+}
+
+func TestPerfectAttendance(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewAttendanceService(db, testAttendanceConfig())
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	workdays, err := NewHolidayService(db).WorkingDates(date(2026, 2, 1), date(2026, 2, 28))
+	if err != nil {
+		t.Fatalf("WorkingDates: %v", err)
+	}
+
+	// Bob misses one day; carol is on approved leave for one day instead
+	missed, leaveDay := workdays[5], workdays[9]
+	createLeave(t, db, carol.ID, model.LeaveTypeAnnual, leaveDay, leaveDay, model.LeaveStatusApproved)
+	for _, day := range workdays {
+		createAttendance(t, db, alice.ID, day, "09:00", "18:00")
+		if !day.Equal(missed) {
+			createAttendance(t, db, bob.ID, day, "09:00", "18:00")
+		}
+		if !day.Equal(leaveDay) {
+			createAttendance(t, db, carol.ID, day, "09:00", "18:00")
+		}
+	}
+
+	perfect, err := s.GetPerfectAttendance(context.Background(), 2026, 2, "")
+	if err != nil {
+		t.Fatalf("GetPerfectAttendance: %v", err)
+	}
+	got := map[uint]int{}
+	for _, entry := range perfect {
+		got[entry.EmployeeID] = entry.ExpectedWorkdays
+	}
+	want := map[uint]int{alice.ID: len(workdays), carol.ID: len(workdays) - 1}
+	if !maps.Equal(got, want) {
+		t.Errorf("perfect attendance = %v, want %v", got, want)
+	}
+}
+
+func TestAttendanceHeatmap(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewAttendanceService(db, testAttendanceConfig())
+	ctx := context.Background()
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	outsider := testutil.CreateEmployee(t, db, "outsider", model.RoleEmployee)
+	reportTo(t, db, boss, alice, bob)
+	db.Model(alice).Update("department", "研发部")
+	db.Model(bob).Update("hire_date", date(2026, 3, 4))
+	// March 2026 starts on a Sunday; Wednesday the 4th is a public holiday
+	if err := db.Create(&model.Holiday{Date: date(2026, 3, 4), Name: "Holiday"}).Error; err != nil {
+		t.Fatalf("create holiday: %v", err)
+	}
+	createAttendance(t, db, alice.ID, date(2026, 3, 2), "09:00", "18:00")
+	createLeave(t, db, alice.ID, model.LeaveTypeAnnual, date(2026, 3, 3), date(2026, 3, 3), model.LeaveStatusApproved)
+	createLeave(t, db, bob.ID, model.LeaveTypeAnnual, date(2026, 3, 5), date(2026, 3, 5), model.LeaveStatusPending)
+	createAttendance(t, db, outsider.ID, date(2026, 3, 2), "09:00", "18:00")
+
+	heatmap, err := s.GetHeatmap(ctx, boss.ID, boss.Role, 2026, 3, "")
+	if err != nil {
+		t.Fatalf("GetHeatmap: %v", err)
+	}
+	if len(heatmap.Dates) != 31 || heatmap.Dates[0] != "2026-03-01" {
+		t.Fatalf("dates = %d from %v, want the 31 days of March", len(heatmap.Dates), heatmap.Dates[0])
+	}
+	// The first week, Sunday 1st to Saturday 7th. Bob was hired on the 4th and his pending leave does not count
+	got := map[string][]string{}
+	for _, row := range heatmap.Employees {
+		got[row.Name] = row.Statuses[:7]
+	}
+	want := map[string][]string{
+		"alice": {HeatmapHoliday, HeatmapPresent, HeatmapLeave, HeatmapHoliday, HeatmapAbsent, HeatmapAbsent, HeatmapHoliday},
+		"bob":   {HeatmapHoliday, HeatmapNone, HeatmapNone, HeatmapHoliday, HeatmapAbsent, HeatmapAbsent, HeatmapHoliday},
+	}
+	if !maps.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("first week = %v, want %v", got, want)
+	}
+
+	scoped, err := s.GetHeatmap(ctx, hr.ID, hr.Role, 2026, 3, "研发部")
+	if err != nil {
+		t.Fatalf("GetHeatmap for HR: %v", err)
+	}
+	if len(scoped.Employees) != 1 || scoped.Employees[0].EmployeeID != alice.ID {
+		t.Errorf("HR view of 研发部 = %+v, want only alice", scoped.Employees)
+	}
+}
+
+func TestAllMonthlyRecordsKeepDeletedEmployees(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewAttendanceService(db, testAttendanceConfig())
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	createAttendance(t, db, alice.ID, date(2026, 3, 2), "09:00", "18:00")
+	createAttendance(t, db, bob.ID, date(2026, 3, 2), "09:00", "18:00")
+
+	// Alice left after working in March, her days still count for that month
+	if err := db.Delete(alice).Error; err != nil {
+		t.Fatalf("delete alice: %v", err)
+	}
+
+	records, err := s.GetAllMonthlyRecords(context.Background(), 2026, 3, "")
+	if err != nil {
+		t.Fatalf("GetAllMonthlyRecords: %v", err)
+	}
+	names := map[uint]string{}
+	for _, record := range records {
+		names[record.EmployeeID] = record.Employee.Name
+	}
+	want := map[uint]string{alice.ID: alice.Name, bob.ID: bob.Name}
+	if !maps.Equal(names, want) {
+		t.Errorf("employee names = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+var (
+	ErrAuditInvalidDateFormat = errors.New("invalid date format, expected YYYY-MM-DD")
+)
+
+// AuditService records and lists sensitive actions
+type AuditService struct {
+	repo *repository.AuditLogRepository
+	db   *gorm.DB
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{
+		repo: repository.NewAuditLogRepository(db),
+		db:   db,
+	}
+}
+
+// AuditLogPage is a page of audit log entries
+type AuditLogPage struct {
+	Items    []model.AuditLog `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// Record stores an audit entry for an action actorID performed on a target record
+// Failures are logged rather than returned so auditing never undoes the action itself
+func (s *AuditService) Record(actorID uint, action string, targetType string, targetID uint, metadata map[string]interface{}) {
+	entry := &model.AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+	if metadata != nil {
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			log.Printf("audit: failed to encode metadata for %s on %s %d: %v", action, targetType, targetID, err)
+		} else {
+			entry.Metadata = raw
+		}
+	}
+
+	if err := s.repo.Create(entry); err != nil {
+		log.Printf("audit: failed to record %s by %d on %s %d: %v", action, actorID, targetType, targetID, err)
+	}
+}
+
+// List retrieves a page of audit log entries matching the filters
+// Supported filters: actor_id, action, start and end (YYYY-MM-DD)
+func (s *AuditService) List(filters map[string]interface{}, page, pageSize int) (*AuditLogPage, error) {
+	for _, key := range []string{"start", "end"} {
+		if value, ok := filters[key].(string); ok && value != "" {
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				return nil, ErrAuditInvalidDateFormat
+			}
+		}
+	}
+
+	entries, total, err := s.repo.List(filters, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLogPage{
+		Items:    entries,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
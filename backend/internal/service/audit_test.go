@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+func TestUpdateRoleIsAudited(t *testing.T) {
+	db := testutil.NewDB(t)
+	employees := newEmployeeService(t, db)
+	s := NewAuditService(db)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	if err := db.Create(&model.Role{Name: model.RoleHR, IsSystem: true}).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+
+	if _, err := employees.UpdateRole(context.Background(), alice.ID, admin.ID, &UpdateRoleRequest{Role: model.RoleHR}); err != nil {
+		t.Fatalf("UpdateRole: %v", err)
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	page, err := s.List(map[string]interface{}{"actor_id": admin.ID, "action": model.AuditActionEmployeeRoleUpdate, "start": today, "end": today}, 1, 20)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if page.Total != 1 {
+		t.Fatalf("audit entries = %d, want 1", page.Total)
+	}
+	entry := page.Items[0]
+	if entry.ActorID != admin.ID || entry.TargetType != model.AuditTargetEmployee || entry.TargetID != alice.ID {
+		t.Errorf("entry = actor %d on %s %d, want actor %d on employee %d", entry.ActorID, entry.TargetType, entry.TargetID, admin.ID, alice.ID)
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(entry.Metadata, &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if metadata["old_role"] != model.RoleEmployee || metadata["new_role"] != model.RoleHR {
+		t.Errorf("metadata = %v, want employee to hr", metadata)
+	}
+
+	if page, _ := s.List(map[string]interface{}{"actor_id": alice.ID}, 1, 20); page.Total != 0 {
+		t.Errorf("entries by the target = %d, want 0", page.Total)
+	}
+}
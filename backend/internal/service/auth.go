@@ -2,10 +2,13 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 
 	"gorm.io/gorm"
 
 	"oa-system/internal/model"
+	"oa-system/internal/repository"
 	"oa-system/pkg/jwt"
 	"oa-system/pkg/password"
 )
@@ -19,15 +22,21 @@ var (
 
 // AuthService handles authentication business logic
 type AuthService struct {
-	db         *gorm.DB
-	jwtManager *jwt.JWTManager
+	db                  *gorm.DB
+	jwtManager          *jwt.JWTManager
+	auditService        *AuditService
+	contractRepo        *repository.ContractRepository
+	notificationService *NotificationService
 }
 
 // NewAuthService creates a new authentication service
 func NewAuthService(db *gorm.DB, jwtManager *jwt.JWTManager) *AuthService {
 	return &AuthService{
-		db:         db,
-		jwtManager: jwtManager,
+		db:                  db,
+		jwtManager:          jwtManager,
+		auditService:        NewAuditService(db),
+		contractRepo:        repository.NewContractRepository(db),
+		notificationService: NewNotificationService(db),
 	}
 }
 
@@ -80,6 +89,11 @@ func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
 		return nil, err
 	}
 
+	// A failed reminder must not block the login
+	if err := s.remindPendingContracts(employee.ID); err != nil {
+		log.Printf("Failed to remind employee %d of pending contracts: %v", employee.ID, err)
+	}
+
 	// Clear password before returning
 	employee.Password = ""
 
@@ -89,6 +103,34 @@ func (s *AuthService) Login(req *LoginRequest) (*LoginResponse, error) {
 	}, nil
 }
 
+// remindPendingContracts notifies an employee who logs in with contracts awaiting their signature
+// No new reminder is sent while an earlier one is still unread
+func (s *AuthService) remindPendingContracts(employeeID uint) error {
+	contracts, err := s.contractRepo.GetAwaitingSignature(employeeID)
+	if err != nil || len(contracts) == 0 {
+		return err
+	}
+
+	unread, err := s.notificationService.HasUnreadOfType(employeeID, model.NotificationTypeContractPending)
+	if err != nil || unread {
+		return err
+	}
+
+	// Link the contract when there is only one to sign
+	notification := &model.Notification{
+		EmployeeID: employeeID,
+		Type:       model.NotificationTypeContractPending,
+		Title:      "合同待签署",
+		Content:    fmt.Sprintf("您有 %d 份合同待签署", len(contracts)),
+	}
+	if len(contracts) == 1 {
+		notification.Content = "合同「" + contracts[0].Template.Title + "」待您签署"
+		notification.RelatedType = model.NotificationRelatedContract
+		notification.RelatedID = contracts[0].ID
+	}
+	return s.notificationService.Notify(notification)
+}
+
 // ChangePassword changes a user's password
 func (s *AuthService) ChangePassword(userID uint, req *ChangePasswordRequest) error {
 	var employee model.Employee
@@ -111,10 +153,16 @@ func (s *AuthService) ChangePassword(userID uint, req *ChangePasswordRequest) er
 	}
 
 	// Update password and mark first login as complete
-	return s.db.Model(&employee).Updates(map[string]interface{}{
+	err = s.db.Model(&employee).Updates(map[string]interface{}{
 		"password":       hashedPassword,
 		"is_first_login": false,
 	}).Error
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(userID, model.AuditActionPasswordChange, model.AuditTargetEmployee, userID, nil)
+	return nil
 }
 
 // GetUserByID retrieves a user by ID
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+// JoinWaitlist puts the employee on the waitlist for a slot that is currently taken
+// The entry is promoted into a booking when the blocking booking is cancelled, completed or moved
+func (s *MeetingRoomService) JoinWaitlist(employeeID uint, req *CreateBookingRequest) (*model.BookingWaitlist, error) {
+	bookingDate, startTime, endTime, err := s.parseBookingSlot(req.BookingDate, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLeaveOn(s.leaveRepo, employeeID, bookingDate); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.roomRepo.GetByID(req.MeetingRoomID); err != nil {
+		if errors.Is(err, repository.ErrMeetingRoomNotFound) {
+			return nil, ErrMeetingRoomNotFound
+		}
+		return nil, err
+	}
+
+	// Only taken slots can be waited for; a free one should simply be booked
+	hasConflict, _, err := s.bookingRepo.HasConflict(req.MeetingRoomID, bookingDate, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if !hasConflict {
+		return nil, ErrWaitlistSlotAvailable
+	}
+
+	waiting, err := s.waitlistRepo.HasWaiting(employeeID, req.MeetingRoomID, bookingDate, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if waiting {
+		return nil, ErrWaitlistDuplicate
+	}
+
+	entry := &model.BookingWaitlist{
+		EmployeeID:    employeeID,
+		MeetingRoomID: req.MeetingRoomID,
+		BookingDate:   bookingDate,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		Status:        model.WaitlistStatusWaiting,
+	}
+	if err := s.waitlistRepo.Create(entry); err != nil {
+		return nil, err
+	}
+
+	return s.waitlistRepo.GetByID(entry.ID)
+}
+
+// GetMyWaitlist retrieves all waitlist entries for an employee
+func (s *MeetingRoomService) GetMyWaitlist(employeeID uint) ([]model.BookingWaitlist, error) {
+	return s.waitlistRepo.GetByEmployeeID(employeeID)
+}
+
+// LeaveWaitlist cancels one of the employee's waiting entries
+func (s *MeetingRoomService) LeaveWaitlist(entryID uint, employeeID uint) error {
+	entry, err := s.waitlistRepo.GetByID(entryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWaitlistEntryNotFound) {
+			return ErrWaitlistEntryNotFound
+		}
+		return err
+	}
+
+	if entry.EmployeeID != employeeID {
+		return ErrWaitlistNotOwner
+	}
+	if entry.Status != model.WaitlistStatusWaiting {
+		return ErrWaitlistInvalidStatus
+	}
+
+	return s.waitlistRepo.UpdateStatus(entry.ID, model.WaitlistStatusCancelled, nil)
+}
+
+// promoteWaitlist turns waiting entries for a room and date into bookings now that a slot has been released
+// Entries are considered earliest first while holding the room's lock, so when several waitlisters want
+// overlapping windows the first one wins and the others keep waiting behind the new booking.
+// Entries whose employee already holds an active booking or is on leave that day are skipped, and those
+// whose start time has passed are expired. The release that triggered this has already succeeded, so failures are only logged.
+func (s *MeetingRoomService) promoteWaitlist(roomID uint, date time.Time) {
+	var promoted []model.BookingWaitlist
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewMeetingRoomRepository(tx).LockByID(roomID); err != nil {
+			return err
+		}
+
+		bookingRepo := repository.NewMeetingRoomBookingRepository(tx)
+		waitlistRepo := repository.NewBookingWaitlistRepository(tx)
+		leaveRepo := repository.NewLeaveRepository(tx)
+		entries, err := waitlistRepo.GetWaiting(roomID, date)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, entry := range entries {
+			start, err := slotStartTime(entry.BookingDate, entry.StartTime)
+			if err != nil {
+				return err
+			}
+			if !now.Before(start) {
+				if err := waitlistRepo.UpdateStatus(entry.ID, model.WaitlistStatusExpired, nil); err != nil {
+					return err
+				}
+				continue
+			}
+
+			hasConflict, _, err := bookingRepo.HasConflict(roomID, entry.BookingDate, entry.StartTime, entry.EndTime)
+			if err != nil {
+				return err
+			}
+			if hasConflict {
+				continue
+			}
+
+			// Property 13: the waitlister may have booked something else meanwhile
+			hasActive, err := bookingRepo.HasActiveBooking(entry.EmployeeID)
+			if err != nil {
+				return err
+			}
+			if hasActive {
+				continue
+			}
+
+			// The waitlister may have gone on leave since joining; the entry waits in case the leave is cancelled
+			if err := s.checkLeaveOn(leaveRepo, entry.EmployeeID, entry.BookingDate); err != nil {
+				if errors.Is(err, ErrBookingDuringLeave) {
+					continue
+				}
+				return err
+			}
+
+			booking := &model.MeetingRoomBooking{
+				EmployeeID:    entry.EmployeeID,
+				MeetingRoomID: roomID,
+				BookingDate:   entry.BookingDate,
+				StartTime:     entry.StartTime,
+				EndTime:       entry.EndTime,
+				Status:        model.BookingStatusActive,
+			}
+			if err := bookingRepo.Create(booking); err != nil {
+				return err
+			}
+			if err := waitlistRepo.UpdateStatus(entry.ID, model.WaitlistStatusPromoted, &booking.ID); err != nil {
+				return err
+			}
+			entry.BookingID = &booking.ID
+			promoted = append(promoted, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to promote waitlist for meeting room %d on %s: %v", roomID, date.Format("2006-01-02"), err)
+		return
+	}
+
+	for _, entry := range promoted {
+		_ = s.notificationService.Notify(&model.Notification{
+			EmployeeID:  entry.EmployeeID,
+			Type:        model.NotificationTypeBookingPromoted,
+			Title:       "候补预定成功",
+			Content:     fmt.Sprintf("您候补的会议室「%s」%s %s-%s 已空出，已自动为您预定", entry.MeetingRoom.Name, entry.BookingDate.Format("2006-01-02"), entry.StartTime, entry.EndTime),
+			RelatedType: model.NotificationRelatedBooking,
+			RelatedID:   *entry.BookingID,
+		})
+	}
+}
+
+// releaseSlots promotes the waitlists of the rooms and dates freed by cancelling the bookings,
+// once for each room and date
+func (s *MeetingRoomService) releaseSlots(bookings []model.MeetingRoomBooking) {
+	type slot struct {
+		roomID uint
+		date   string
+	}
+	released := make(map[slot]bool)
+	for _, booking := range bookings {
+		key := slot{booking.MeetingRoomID, booking.BookingDate.Format("2006-01-02")}
+		if released[key] {
+			continue
+		}
+		released[key] = true
+		s.promoteWaitlist(booking.MeetingRoomID, booking.BookingDate)
+	}
+}
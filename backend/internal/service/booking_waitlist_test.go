@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+// joinWaitlist puts the employee on the waitlist for a slot of room on day
+func joinWaitlist(t *testing.T, s *MeetingRoomService, employee *model.Employee, room *model.MeetingRoom, day, start, end string) *model.BookingWaitlist {
+	t.Helper()
+	entry, err := s.JoinWaitlist(employee.ID, &CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: day, StartTime: start, EndTime: end})
+	if err != nil {
+		t.Fatalf("JoinWaitlist for %s: %v", employee.Username, err)
+	}
+	return entry
+}
+
+// waitlistEntry reloads a waitlist entry
+func waitlistEntry(t *testing.T, db *gorm.DB, id uint) model.BookingWaitlist {
+	t.Helper()
+	var entry model.BookingWaitlist
+	if err := db.First(&entry, id).Error; err != nil {
+		t.Fatalf("load waitlist entry %d: %v", id, err)
+	}
+	return entry
+}
+
+func TestCancelBlockerPromotesFirstWaitlister(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	room := createRoom(t, db, "A", 6)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	day := Today().AddDate(0, 0, 7)
+	blocker := createBooking(t, db, alice.ID, room.ID, day, "09:00", "11:00")
+
+	// Both want part of the blocked slot, and their windows overlap so only one can win
+	first := joinWaitlist(t, s, bob, room, day.Format("2006-01-02"), "09:00", "10:00")
+	second := joinWaitlist(t, s, carol, room, day.Format("2006-01-02"), "09:30", "10:30")
+
+	if _, err := s.CancelBooking(blocker.ID, alice.ID); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+
+	promoted := waitlistEntry(t, db, first.ID)
+	if promoted.Status != model.WaitlistStatusPromoted || promoted.BookingID == nil {
+		t.Fatalf("first waitlister = %q booking %v, want promoted with a booking", promoted.Status, promoted.BookingID)
+	}
+	var booking model.MeetingRoomBooking
+	if err := db.First(&booking, *promoted.BookingID).Error; err != nil {
+		t.Fatalf("load promoted booking: %v", err)
+	}
+	if booking.EmployeeID != bob.ID || booking.StartTime != "09:00" || booking.EndTime != "10:00" || booking.Status != model.BookingStatusActive {
+		t.Errorf("promoted booking = employee %d %s-%s %q, want bob's active 09:00-10:00", booking.EmployeeID, booking.StartTime, booking.EndTime, booking.Status)
+	}
+	if status := waitlistEntry(t, db, second.ID).Status; status != model.WaitlistStatusWaiting {
+		t.Errorf("overlapping waitlister = %q, want still waiting", status)
+	}
+
+	if notified := countNotifications(t, db, bob.ID, model.NotificationTypeBookingPromoted); notified != 1 {
+		t.Errorf("promotion notifications to bob = %d, want 1", notified)
+	}
+}
+
+func TestWaitlistPromotedWhenSlotsFreed(t *testing.T) {
+	tests := []struct {
+		name    string
+		release func(t *testing.T, db *gorm.DB, s *MeetingRoomService, blocker *model.MeetingRoomBooking)
+	}{
+		{"no-show cancelled", func(t *testing.T, db *gorm.DB, s *MeetingRoomService, blocker *model.MeetingRoomBooking) {
+			if _, err := s.AutoCancelNoShows(blocker.BookingDate.Add(9*time.Hour + 20*time.Minute)); err != nil {
+				t.Fatalf("AutoCancelNoShows: %v", err)
+			}
+		}},
+		{"booker force deleted", func(t *testing.T, db *gorm.DB, s *MeetingRoomService, blocker *model.MeetingRoomBooking) {
+			admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+			if err := newEmployeeService(t, db).Delete(context.Background(), blocker.EmployeeID, admin.ID, true); err != nil {
+				t.Fatalf("forced Delete: %v", err)
+			}
+		}},
+		{"booker offboarded", func(t *testing.T, db *gorm.DB, s *MeetingRoomService, blocker *model.MeetingRoomBooking) {
+			hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+			createTemplate(t, db, model.ContractTypeOffboarding)
+			if _, err := newOnboardingService(t, db).Offboard(context.Background(), blocker.EmployeeID, hr.ID); err != nil {
+				t.Fatalf("Offboard: %v", err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewDB(t)
+			s := NewMeetingRoomService(db, testBookingConfig())
+			room := createRoom(t, db, "A", 6)
+			alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+			bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+			day := Today().AddDate(0, 0, 7)
+			blocker := createBooking(t, db, alice.ID, room.ID, day, "09:00", "11:00")
+			entry := joinWaitlist(t, s, bob, room, day.Format("2006-01-02"), "10:00", "11:00")
+
+			tt.release(t, db, s, blocker)
+
+			if status := bookingStatus(t, db, blocker.ID); status != model.BookingStatusCancelled {
+				t.Fatalf("blocker status = %q, want cancelled", status)
+			}
+			if status := waitlistEntry(t, db, entry.ID).Status; status != model.WaitlistStatusPromoted {
+				t.Errorf("waitlister = %q, want promoted", status)
+			}
+		})
+	}
+}
+
+func TestWaitlistDuringLeave(t *testing.T) {
+	db := testutil.NewDB(t)
+	cfg := testBookingConfig()
+	cfg.BlockDuringLeave = true
+	s := NewMeetingRoomService(db, cfg)
+	room := createRoom(t, db, "A", 6)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	dave := testutil.CreateEmployee(t, db, "dave", model.RoleEmployee)
+	day := Today().AddDate(0, 0, 7)
+	slot := day.Format("2006-01-02")
+	blocker := createBooking(t, db, alice.ID, room.ID, day, "09:00", "10:00")
+
+	createLeave(t, db, bob.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusApproved)
+	_, err := s.JoinWaitlist(bob.ID, &CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: slot, StartTime: "09:00", EndTime: "10:00"})
+	if !errors.Is(err, ErrBookingDuringLeave) {
+		t.Errorf("JoinWaitlist on a leave day: err = %v, want ErrBookingDuringLeave", err)
+	}
+
+	// Carol goes on leave after joining, so the next waitlister gets the slot instead
+	onLeave := joinWaitlist(t, s, carol, room, slot, "09:00", "10:00")
+	next := joinWaitlist(t, s, dave, room, slot, "09:00", "10:00")
+	createLeave(t, db, carol.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusApproved)
+
+	if _, err := s.CancelBooking(blocker.ID, alice.ID); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+	if status := waitlistEntry(t, db, onLeave.ID).Status; status != model.WaitlistStatusWaiting {
+		t.Errorf("waitlister on leave = %q, want still waiting", status)
+	}
+	if status := waitlistEntry(t, db, next.ID).Status; status != model.WaitlistStatusPromoted {
+		t.Errorf("next waitlister = %q, want promoted", status)
+	}
+}
@@ -2,48 +2,94 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
+	"oa-system/config"
 	"oa-system/internal/model"
 	"oa-system/internal/repository"
+	"oa-system/pkg/pagination"
+	"oa-system/pkg/pdf"
 )
 
 var (
-	ErrContractNotFound         = errors.New("contract not found")
-	ErrContractTemplateNotFound = errors.New("contract template not found")
-	ErrContractAlreadySigned    = errors.New("contract already signed")
-	ErrInvalidContractType      = errors.New("invalid contract type")
+	ErrContractNotFound           = errors.New("contract not found")
+	ErrContractTemplateNotFound   = errors.New("contract template not found")
+	ErrContractAlreadySigned      = errors.New("contract already signed")
+	ErrInvalidContractType        = errors.New("invalid contract type")
+	ErrContractTemplateTypeExists = errors.New("contract template type already exists")
+	ErrContractTemplateInUse      = errors.New("contract template is referenced by existing contracts")
+	ErrContractNotPending         = errors.New("contract is not pending")
+	ErrUnresolvedPlaceholder      = errors.New("contract template contains unresolved placeholders")
+	ErrInvalidContractExpiry      = errors.New("invalid contract expiry date, expected a future YYYY-MM-DD")
+	ErrContractNotApproved        = errors.New("contract must be approved before it can be signed")
+	ErrContractApprovalNotNeeded  = errors.New("contract does not require approval")
+	ErrContractAlreadyApproved    = errors.New("contract already approved")
+	ErrContractSelfApproval       = errors.New("cannot approve own contract")
 )
 
 // ContractService handles contract business logic
 type ContractService struct {
-	repo         *repository.ContractRepository
-	employeeRepo *repository.EmployeeRepository
-	db           *gorm.DB
+	repo                *repository.ContractRepository
+	employeeRepo        *repository.EmployeeRepository
+	salaryRepo          *repository.SalaryRepository
+	notificationService *NotificationService
+	pdfGenerator        *pdf.Generator
+	db                  *gorm.DB
+	expiryReminderDays  int
 }
 
 // NewContractService creates a new contract service
-func NewContractService(db *gorm.DB) *ContractService {
+func NewContractService(db *gorm.DB, pdfGenerator *pdf.Generator, cfg *config.ContractConfig) *ContractService {
 	return &ContractService{
-		repo:         repository.NewContractRepository(db),
-		employeeRepo: repository.NewEmployeeRepository(db),
-		db:           db,
+		repo:                repository.NewContractRepository(db),
+		employeeRepo:        repository.NewEmployeeRepository(db),
+		salaryRepo:          repository.NewSalaryRepository(db),
+		notificationService: NewNotificationService(db),
+		pdfGenerator:        pdfGenerator,
+		db:                  db,
+		expiryReminderDays:  cfg.ExpiryReminderDays,
 	}
 }
 
 // CreateContractRequest represents a request to create a contract
 type CreateContractRequest struct {
-	EmployeeID uint   `json:"employee_id" binding:"required"`
-	TemplateID uint   `json:"template_id" binding:"required"`
+	EmployeeID       uint   `json:"employee_id" binding:"required"`
+	TemplateID       uint   `json:"template_id" binding:"required"`
+	ExpiresAt        string `json:"expires_at"`        // optional YYYY-MM-DD end of the contract term
+	RequiresApproval bool   `json:"requires_approval"` // HR or a super admin must approve before the employee signs
 }
 
-// validContractTypes contains all valid contract type values
-var validContractTypes = map[string]bool{
-	model.ContractTypeOnboarding:  true,
-	model.ContractTypeOffboarding: true,
+// DeclineContractRequest represents a request to decline a contract
+type DeclineContractRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// CreateTemplateRequest represents a request to create a contract template
+type CreateTemplateRequest struct {
+	Type    string `json:"type" binding:"required"`
+	Title   string `json:"title" binding:"required"`
+	Content string `json:"content" binding:"required"`
+}
+
+// UpdateTemplateRequest represents a request to update a contract template
+type UpdateTemplateRequest struct {
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// contractTypeRegex validates contract type identifiers. Besides the built-in
+// onboarding/offboarding types, HR may define custom types such as "renewal".
+var contractTypeRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+// validateContractType validates a contract type identifier
+func validateContractType(contractType string) bool {
+	return contractTypeRegex.MatchString(contractType)
 }
 
 
@@ -64,9 +110,112 @@ func (s *ContractService) GetTemplateByID(id uint) (*model.ContractTemplate, err
 	return template, nil
 }
 
+// CreateTemplate creates a new contract template
+func (s *ContractService) CreateTemplate(req *CreateTemplateRequest) (*model.ContractTemplate, error) {
+	if !validateContractType(req.Type) {
+		return nil, ErrInvalidContractType
+	}
+
+	exists, err := s.repo.ExistsTemplateByType(req.Type, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrContractTemplateTypeExists
+	}
+
+	template := &model.ContractTemplate{
+		Type:    req.Type,
+		Title:   req.Title,
+		Content: req.Content,
+	}
+
+	if err := s.repo.CreateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// UpdateTemplate updates a contract template
+// Existing contracts keep the content they were generated with
+func (s *ContractService) UpdateTemplate(id uint, req *UpdateTemplateRequest) (*model.ContractTemplate, error) {
+	template, err := s.repo.GetTemplateByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrContractTemplateNotFound) {
+			return nil, ErrContractTemplateNotFound
+		}
+		return nil, err
+	}
+
+	if req.Type != "" && req.Type != template.Type {
+		if !validateContractType(req.Type) {
+			return nil, ErrInvalidContractType
+		}
+		exists, err := s.repo.ExistsTemplateByType(req.Type, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrContractTemplateTypeExists
+		}
+		template.Type = req.Type
+	}
+
+	// Update fields if provided
+	if req.Title != "" {
+		template.Title = req.Title
+	}
+	if req.Content != "" {
+		template.Content = req.Content
+	}
+
+	if err := s.repo.UpdateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// DeleteTemplate deletes a contract template that is not referenced by any contract
+func (s *ContractService) DeleteTemplate(id uint) error {
+	if _, err := s.repo.GetTemplateByID(id); err != nil {
+		if errors.Is(err, repository.ErrContractTemplateNotFound) {
+			return ErrContractTemplateNotFound
+		}
+		return err
+	}
+
+	count, err := s.repo.CountByTemplateID(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrContractTemplateInUse
+	}
+
+	err = s.repo.DeleteTemplate(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrContractTemplateNotFound) {
+			return ErrContractTemplateNotFound
+		}
+		return err
+	}
+	return nil
+}
+
 // Create creates a new contract based on a template
 // Requirements: 9.1 - HR creates contract for employee, system creates pending contract and notifies employee
 func (s *ContractService) Create(req *CreateContractRequest) (*model.Contract, error) {
+	var expiresAt *time.Time
+	if req.ExpiresAt != "" {
+		date, err := time.Parse("2006-01-02", req.ExpiresAt)
+		if err != nil || !date.After(time.Now()) {
+			return nil, ErrInvalidContractExpiry
+		}
+		expiresAt = &date
+	}
+
 	// Validate employee exists
 	employee, err := s.employeeRepo.GetByID(req.EmployeeID)
 	if err != nil {
@@ -87,14 +236,19 @@ func (s *ContractService) Create(req *CreateContractRequest) (*model.Contract, e
 
 	// Generate contract content from template
 	// Replace placeholders with employee information
-	content := s.generateContractContent(template.Content, employee)
+	content, err := s.generateContractContent(template.Content, employee)
+	if err != nil {
+		return nil, err
+	}
 
 	contract := &model.Contract{
-		EmployeeID: req.EmployeeID,
-		TemplateID: req.TemplateID,
-		Type:       template.Type,
-		Content:    content,
-		Status:     model.ContractStatusPending,
+		EmployeeID:       req.EmployeeID,
+		TemplateID:       req.TemplateID,
+		Type:             template.Type,
+		Content:          content,
+		Status:           model.ContractStatusPending,
+		ExpiresAt:        expiresAt,
+		RequiresApproval: req.RequiresApproval,
 	}
 
 	if err := s.repo.Create(contract); err != nil {
@@ -105,27 +259,84 @@ func (s *ContractService) Create(req *CreateContractRequest) (*model.Contract, e
 	return s.repo.GetByID(contract.ID)
 }
 
+// placeholderRegex matches a {{...}} placeholder token
+var placeholderRegex = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// contractPlaceholders builds the placeholder values available for an employee.
+// Optional values (supervisor, salary) are only included when present, so a
+// template referencing them for an employee without that data is reported as unresolved.
+func contractPlaceholders(employee *model.Employee, salary *model.Salary) map[string]string {
+	today := Today().Format("2006-01-02")
+	hireDate := employee.HireDate.Format("2006-01-02")
+
+	values := map[string]string{
+		"employee_name": employee.Name,
+		"employee_no":   employee.EmployeeNo,
+		"department":    employee.Department,
+		"position":      employee.Position,
+		"email":         employee.Email,
+		"phone":         employee.Phone,
+		"hire_date":     hireDate,
+		"current_date":  today,
+		// start_date uses hire_date for onboarding contracts
+		"start_date": hireDate,
+		// end_date uses current date as default for offboarding contracts
+		"end_date": today,
+	}
+
+	if employee.Supervisor != nil {
+		values["supervisor_name"] = employee.Supervisor.Name
+	}
+	if salary != nil {
+		values["salary"] = fmt.Sprintf("%.2f", salary.BaseSalary)
+	}
+
+	return values
+}
+
 // generateContractContent generates contract content by replacing placeholders
-func (s *ContractService) generateContractContent(templateContent string, employee *model.Employee) string {
-	content := templateContent
-	
-	// Replace common placeholders
-	content = strings.ReplaceAll(content, "{{employee_name}}", employee.Name)
-	content = strings.ReplaceAll(content, "{{employee_no}}", employee.EmployeeNo)
-	content = strings.ReplaceAll(content, "{{department}}", employee.Department)
-	content = strings.ReplaceAll(content, "{{position}}", employee.Position)
-	content = strings.ReplaceAll(content, "{{hire_date}}", employee.HireDate.Format("2006-01-02"))
-	content = strings.ReplaceAll(content, "{{current_date}}", time.Now().Format("2006-01-02"))
-	
-	// Replace date placeholders for contract terms
-	// start_date uses hire_date for onboarding contracts
-	content = strings.ReplaceAll(content, "{{start_date}}", employee.HireDate.Format("2006-01-02"))
-	// end_date uses current date as default for offboarding contracts
-	content = strings.ReplaceAll(content, "{{end_date}}", time.Now().Format("2006-01-02"))
-	
-	return content
+// Returns ErrUnresolvedPlaceholder listing any {{...}} tokens that could not be resolved
+func (s *ContractService) generateContractContent(templateContent string, employee *model.Employee) (string, error) {
+	// Draft salaries are not visible to the employee, so they must not appear in a contract either
+	var salary *model.Salary
+	latest, err := s.salaryRepo.GetLatestPublishedByEmployeeID(employee.ID)
+	if err != nil && !errors.Is(err, repository.ErrSalaryNotFound) {
+		return "", err
+	}
+	if err == nil {
+		salary = latest
+	}
+
+	// Substitute in a single pass so values are never rescanned for placeholders
+	values := contractPlaceholders(employee, salary)
+	var unresolved []string
+	content := placeholderRegex.ReplaceAllStringFunc(templateContent, func(token string) string {
+		if value, ok := values[token[2:len(token)-2]]; ok {
+			return value
+		}
+		unresolved = append(unresolved, token)
+		return token
+	})
+
+	if len(unresolved) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrUnresolvedPlaceholder, strings.Join(uniqueStrings(unresolved), ", "))
+	}
+
+	return content, nil
 }
 
+// uniqueStrings returns the distinct values of a slice, preserving order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
 
 // GetByID retrieves a contract by ID
 func (s *ContractService) GetByID(id uint) (*model.Contract, error) {
@@ -139,10 +350,75 @@ func (s *ContractService) GetByID(id uint) (*model.Contract, error) {
 	return contract, nil
 }
 
-// List retrieves all contracts with optional filters
+// ContractSortOptions whitelists the fields the contract list can be sorted by
+var ContractSortOptions = pagination.SortOptions{
+	Columns: map[string]string{
+		"created_at": "created_at",
+		"signed_at":  "signed_at",
+		"expires_at": "expires_at",
+		"status":     "status",
+	},
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+}
+
+// ContractPage is a page of contracts
+type ContractPage struct {
+	Items []model.Contract `json:"items"`
+	pagination.PageMeta
+}
+
+// List retrieves a page of contracts with optional filters
 // Requirements: 9.4 - HR can view all contracts
-func (s *ContractService) List(filters map[string]interface{}) ([]model.Contract, error) {
-	return s.repo.List(filters)
+func (s *ContractService) List(filters map[string]interface{}, params pagination.Params) (*ContractPage, error) {
+	contracts, total, err := s.repo.List(filters, params)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractPage{
+		Items:    contracts,
+		PageMeta: params.Meta(total),
+	}, nil
+}
+
+// GetExpiring retrieves signed contracts expiring within the next withinDays days, today included
+func (s *ContractService) GetExpiring(withinDays int) ([]model.Contract, error) {
+	today := Today()
+	return s.repo.GetSignedExpiringBetween(today, today.AddDate(0, 0, withinDays))
+}
+
+// SendExpiryReminders notifies HR once about each signed contract entering the reminder window
+// It returns the number of contracts HR was reminded about
+func (s *ContractService) SendExpiryReminders(now time.Time) (int, error) {
+	today := dateOf(now)
+	contracts, err := s.repo.GetSignedExpiringBetween(today, today.AddDate(0, 0, s.expiryReminderDays))
+	if err != nil {
+		return 0, err
+	}
+
+	reminded := 0
+	for _, contract := range contracts {
+		if contract.ExpiryRemindedAt != nil {
+			continue
+		}
+
+		err := s.notificationService.NotifyRoles([]string{model.RoleHR}, model.Notification{
+			Type:        model.NotificationTypeContractExpiring,
+			Title:       "合同即将到期",
+			Content:     contract.Employee.Name + " 的合同「" + contract.Template.Title + "」将于 " + contract.ExpiresAt.Format("2006-01-02") + " 到期，请及时续签",
+			RelatedType: model.NotificationRelatedContract,
+			RelatedID:   contract.ID,
+		})
+		if err != nil {
+			return reminded, err
+		}
+		if err := s.repo.MarkExpiryReminded(contract.ID, now); err != nil {
+			return reminded, err
+		}
+		reminded++
+	}
+
+	return reminded, nil
 }
 
 // GetByEmployeeID retrieves all contracts for a specific employee
@@ -151,6 +427,16 @@ func (s *ContractService) GetByEmployeeID(employeeID uint) ([]model.Contract, er
 	return s.repo.GetByEmployeeID(employeeID)
 }
 
+// GetAwaitingSignature retrieves an employee's pending contracts that are ready for them to sign
+func (s *ContractService) GetAwaitingSignature(employeeID uint) ([]model.Contract, error) {
+	return s.repo.GetAwaitingSignature(employeeID)
+}
+
+// CountAwaitingSignature counts an employee's pending contracts that are ready for them to sign
+func (s *ContractService) CountAwaitingSignature(employeeID uint) (int64, error) {
+	return s.repo.CountAwaitingSignature(employeeID)
+}
+
 // Sign signs a contract
 // Requirements: 9.2, 9.3 - Employee receives notification and can sign, system updates status and records timestamp
 func (s *ContractService) Sign(id uint, employeeID uint) (*model.Contract, error) {
@@ -172,6 +458,15 @@ func (s *ContractService) Sign(id uint, employeeID uint) (*model.Contract, error
 		return nil, ErrContractAlreadySigned
 	}
 
+	// Declined contracts cannot be signed
+	if contract.Status != model.ContractStatusPending {
+		return nil, ErrContractNotPending
+	}
+
+	if contract.RequiresApproval && contract.ApprovedBy == nil {
+		return nil, ErrContractNotApproved
+	}
+
 	// Update contract status
 	now := time.Now()
 	contract.Status = model.ContractStatusSigned
@@ -184,6 +479,96 @@ func (s *ContractService) Sign(id uint, employeeID uint) (*model.Contract, error
 	return contract, nil
 }
 
+// Approve approves a pending contract that requires approval, allowing the employee to sign it
+func (s *ContractService) Approve(id uint, approverID uint) (*model.Contract, error) {
+	contract, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrContractNotFound) {
+			return nil, ErrContractNotFound
+		}
+		return nil, err
+	}
+
+	if contract.EmployeeID == approverID {
+		return nil, ErrContractSelfApproval
+	}
+	if contract.Status != model.ContractStatusPending {
+		return nil, ErrContractNotPending
+	}
+	if !contract.RequiresApproval {
+		return nil, ErrContractApprovalNotNeeded
+	}
+	if contract.ApprovedBy != nil {
+		return nil, ErrContractAlreadyApproved
+	}
+
+	now := time.Now()
+	contract.ApprovedBy = &approverID
+	contract.ApprovedAt = &now
+
+	if err := s.repo.Update(contract); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
+
+// Decline declines a pending contract with a reason and notifies HR
+func (s *ContractService) Decline(id uint, employeeID uint, reason string) (*model.Contract, error) {
+	contract, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrContractNotFound) {
+			return nil, ErrContractNotFound
+		}
+		return nil, err
+	}
+
+	// Verify the contract belongs to the employee
+	if contract.EmployeeID != employeeID {
+		return nil, ErrContractNotFound
+	}
+
+	// Only pending contracts can be declined
+	if contract.Status != model.ContractStatusPending {
+		return nil, ErrContractNotPending
+	}
+
+	contract.Status = model.ContractStatusDeclined
+	contract.DeclineReason = reason
+
+	if err := s.repo.Update(contract); err != nil {
+		return nil, err
+	}
+
+	// Notification failure should not undo the decline
+	_ = s.notificationService.NotifyRoles([]string{model.RoleHR}, model.Notification{
+		Type:        model.NotificationTypeContractDeclined,
+		Title:       "合同被拒签",
+		Content:     contract.Employee.Name + " 拒绝签署合同「" + contract.Template.Title + "」，原因：" + reason,
+		RelatedType: model.NotificationRelatedContract,
+		RelatedID:   contract.ID,
+	})
+
+	return contract, nil
+}
+
+// GeneratePDF renders a contract's content, signing status and signature line as a PDF
+func (s *ContractService) GeneratePDF(contract *model.Contract) ([]byte, error) {
+	signedAt := "未签署"
+	if contract.SignedAt != nil {
+		signedAt = contract.SignedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return s.pdfGenerator.Render(&pdf.Document{
+		Title: contract.Template.Title,
+		Body:  contract.Content,
+		Footer: []string{
+			"签署时间：" + signedAt,
+			"员工签名：" + contract.Employee.Name + "（" + contract.Employee.EmployeeNo + "）",
+		},
+	})
+}
+
 // Delete soft deletes a contract
 func (s *ContractService) Delete(id uint) error {
 	err := s.repo.Delete(id)
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/pagination"
+	"oa-system/pkg/pdf"
+)
+
+func newContractService(db *gorm.DB) *ContractService {
+	return NewContractService(db, pdf.NewGenerator(""), &config.ContractConfig{ExpiryReminderDays: 30})
+}
+
+// createContractFor creates a pending contract for employee from a new template with the given content
+func createContractFor(t *testing.T, s *ContractService, employee *model.Employee, contractType, content string) *model.Contract {
+	t.Helper()
+	template, err := s.CreateTemplate(&CreateTemplateRequest{Type: contractType, Title: "劳动合同", Content: content})
+	if err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	contract, err := s.Create(&CreateContractRequest{EmployeeID: employee.ID, TemplateID: template.ID})
+	if err != nil {
+		t.Fatalf("Create contract: %v", err)
+	}
+	return contract
+}
+
+func TestContractTemplateCRUD(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newContractService(db)
+
+	template, err := s.CreateTemplate(&CreateTemplateRequest{Type: "renewal", Title: "续签合同", Content: "{{employee_name}}"})
+	if err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	if _, err := s.CreateTemplate(&CreateTemplateRequest{Type: "renewal", Title: "x", Content: "x"}); !errors.Is(err, ErrContractTemplateTypeExists) {
+		t.Errorf("duplicate type: err = %v, want ErrContractTemplateTypeExists", err)
+	}
+	if _, err := s.CreateTemplate(&CreateTemplateRequest{Type: "Bad Type", Title: "x", Content: "x"}); !errors.Is(err, ErrInvalidContractType) {
+		t.Errorf("invalid type: err = %v, want ErrInvalidContractType", err)
+	}
+
+	other, err := s.CreateTemplate(&CreateTemplateRequest{Type: "nda", Title: "保密协议", Content: "{{employee_no}}"})
+	if err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	if _, err := s.UpdateTemplate(other.ID, &UpdateTemplateRequest{Type: "renewal"}); !errors.Is(err, ErrContractTemplateTypeExists) {
+		t.Errorf("update to taken type: err = %v, want ErrContractTemplateTypeExists", err)
+	}
+	updated, err := s.UpdateTemplate(other.ID, &UpdateTemplateRequest{Title: "保密协议（新）"})
+	if err != nil {
+		t.Fatalf("UpdateTemplate: %v", err)
+	}
+	if updated.Type != "nda" || updated.Title != "保密协议（新）" || updated.Content != "{{employee_no}}" {
+		t.Errorf("UpdateTemplate = %+v, want only the title changed", updated)
+	}
+
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	if _, err := s.Create(&CreateContractRequest{EmployeeID: employee.ID, TemplateID: template.ID}); err != nil {
+		t.Fatalf("Create contract: %v", err)
+	}
+	if err := s.DeleteTemplate(template.ID); !errors.Is(err, ErrContractTemplateInUse) {
+		t.Errorf("delete referenced template: err = %v, want ErrContractTemplateInUse", err)
+	}
+	if err := s.DeleteTemplate(other.ID); err != nil {
+		t.Fatalf("DeleteTemplate: %v", err)
+	}
+	if _, err := s.GetTemplateByID(other.ID); !errors.Is(err, ErrContractTemplateNotFound) {
+		t.Errorf("deleted template: err = %v, want ErrContractTemplateNotFound", err)
+	}
+}
+
+func TestDeclineContract(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newContractService(db)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	other := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	contract := createContractFor(t, s, employee, "onboarding", "{{employee_name}}")
+
+	if _, err := s.Decline(contract.ID, other.ID, "不是我的"); !errors.Is(err, ErrContractNotFound) {
+		t.Errorf("decline by another employee: err = %v, want ErrContractNotFound", err)
+	}
+
+	declined, err := s.Decline(contract.ID, employee.ID, "薪资不符")
+	if err != nil {
+		t.Fatalf("Decline: %v", err)
+	}
+	if declined.Status != model.ContractStatusDeclined || declined.DeclineReason != "薪资不符" {
+		t.Errorf("Decline = status %q reason %q, want declined with the reason", declined.Status, declined.DeclineReason)
+	}
+	if n := countNotifications(t, db, hr.ID, model.NotificationTypeContractDeclined); n != 1 {
+		t.Errorf("HR notifications = %d, want 1", n)
+	}
+
+	if _, err := s.Sign(contract.ID, employee.ID); !errors.Is(err, ErrContractNotPending) {
+		t.Errorf("sign declined contract: err = %v, want ErrContractNotPending", err)
+	}
+	if _, err := s.Decline(contract.ID, employee.ID, "again"); !errors.Is(err, ErrContractNotPending) {
+		t.Errorf("decline twice: err = %v, want ErrContractNotPending", err)
+	}
+}
+
+func TestApproveThenSignContract(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newContractService(db)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	template, err := s.CreateTemplate(&CreateTemplateRequest{Type: "onboarding", Title: "劳动合同", Content: "{{employee_name}}"})
+	if err != nil {
+		t.Fatalf("CreateTemplate: %v", err)
+	}
+	contract, err := s.Create(&CreateContractRequest{EmployeeID: employee.ID, TemplateID: template.ID, RequiresApproval: true})
+	if err != nil {
+		t.Fatalf("Create contract: %v", err)
+	}
+
+	if _, err := s.Sign(contract.ID, employee.ID); !errors.Is(err, ErrContractNotApproved) {
+		t.Fatalf("sign before approval: err = %v, want ErrContractNotApproved", err)
+	}
+	if _, err := s.Approve(contract.ID, employee.ID); !errors.Is(err, ErrContractSelfApproval) {
+		t.Errorf("approve own contract: err = %v, want ErrContractSelfApproval", err)
+	}
+
+	approved, err := s.Approve(contract.ID, hr.ID)
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if approved.ApprovedBy == nil || *approved.ApprovedBy != hr.ID || approved.ApprovedAt == nil || approved.Status != model.ContractStatusPending {
+		t.Errorf("Approve = approved by %v at %v, status %q; want approved by HR and still pending", approved.ApprovedBy, approved.ApprovedAt, approved.Status)
+	}
+	if _, err := s.Approve(contract.ID, hr.ID); !errors.Is(err, ErrContractAlreadyApproved) {
+		t.Errorf("approve twice: err = %v, want ErrContractAlreadyApproved", err)
+	}
+
+	signed, err := s.Sign(contract.ID, employee.ID)
+	if err != nil {
+		t.Fatalf("Sign after approval: %v", err)
+	}
+	if signed.Status != model.ContractStatusSigned || signed.SignedAt == nil {
+		t.Errorf("Sign = status %q signed at %v, want signed", signed.Status, signed.SignedAt)
+	}
+
+	// A contract that does not need approval has nothing to approve
+	plain := createContractFor(t, s, employee, "nda", "{{employee_no}}")
+	if _, err := s.Approve(plain.ID, hr.ID); !errors.Is(err, ErrContractApprovalNotNeeded) {
+		t.Errorf("approve a contract without the requirement: err = %v, want ErrContractApprovalNotNeeded", err)
+	}
+}
+
+func TestGenerateContractContent(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newContractService(db)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	employee.Name = "{{salary}}"
+	employee.Department = "研发部"
+	createSalary(t, db, employee.ID, "2026-01", 8000, true)
+	createSalary(t, db, employee.ID, "2026-02", 9000, false)
+
+	content, err := s.generateContractContent("{{employee_name}}|{{department}}|{{salary}}|{{employee_no}}", employee)
+	if err != nil {
+		t.Fatalf("generateContractContent: %v", err)
+	}
+	// The name is substituted verbatim and the draft February salary is ignored
+	if want := "{{salary}}|研发部|8000.00|T-alice"; content != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+}
+
+func TestGenerateContractContentUnresolved(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newContractService(db)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	createSalary(t, db, employee.ID, "2026-02", 9000, false)
+
+	_, err := s.generateContractContent("{{employee_name}} {{salary}} {{supervisor_name}} {{unknown}} {{salary}}", employee)
+	if !errors.Is(err, ErrUnresolvedPlaceholder) {
+		t.Fatalf("err = %v, want ErrUnresolvedPlaceholder", err)
+	}
+	if want := "{{salary}}, {{supervisor_name}}, {{unknown}}"; !strings.HasSuffix(err.Error(), ": "+want) {
+		t.Errorf("err = %q, want it to list %s", err, want)
+	}
+}
+
+func TestListContractsPaged(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newContractService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	for i, contractType := range []string{"labor", "nda", "renewal", "internship", "offboarding"} {
+		contract := createContractFor(t, s, alice, contractType, "{{employee_name}}")
+		if i%2 == 0 {
+			db.Model(contract).Update("status", model.ContractStatusSigned)
+		}
+	}
+	createContractFor(t, s, bob, "bonus", "{{employee_name}}")
+
+	seen := map[uint]bool{}
+	for page := 1; page <= 3; page++ {
+		result, err := s.List(map[string]interface{}{"employee_id": alice.ID}, pagination.Params{Page: page, PageSize: 2, Column: "id", Order: "ASC"})
+		if err != nil {
+			t.Fatalf("List page %d: %v", page, err)
+		}
+		if result.Total != 5 || result.TotalPages != 3 {
+			t.Errorf("page %d: total %d in %d pages, want 5 in 3", page, result.Total, result.TotalPages)
+		}
+		if want := min(2, 5-(page-1)*2); len(result.Items) != want {
+			t.Errorf("page %d has %d contracts, want %d", page, len(result.Items), want)
+		}
+		for _, contract := range result.Items {
+			if seen[contract.ID] || contract.EmployeeID != alice.ID {
+				t.Errorf("page %d: contract %d repeated or not alice's", page, contract.ID)
+			}
+			seen[contract.ID] = true
+		}
+	}
+
+	signed, err := s.List(map[string]interface{}{"status": model.ContractStatusSigned}, pagination.Params{Page: 2, PageSize: 2, Column: "id", Order: "ASC"})
+	if err != nil {
+		t.Fatalf("List signed: %v", err)
+	}
+	if signed.Total != 3 || len(signed.Items) != 1 || signed.Items[0].Status != model.ContractStatusSigned {
+		t.Errorf("second page of signed contracts: total %d, %d items, want 3 and 1 signed", signed.Total, len(signed.Items))
+	}
+}
+
+func TestExpiringContracts(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newContractService(db)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	today := Today()
+	expiring := func(contractType string, days int, status string) *model.Contract {
+		t.Helper()
+		contract := createContractFor(t, s, alice, contractType, "{{employee_name}}")
+		db.Model(contract).Updates(map[string]interface{}{"status": status, "expires_at": today.AddDate(0, 0, days)})
+		return contract
+	}
+	lastDay := expiring("labor", 30, model.ContractStatusSigned)
+	first := expiring("nda", 0, model.ContractStatusSigned)
+	expiring("renewal", 31, model.ContractStatusSigned)
+	expiring("internship", -1, model.ContractStatusSigned)
+	expiring("bonus", 10, model.ContractStatusPending)
+
+	contracts, err := s.GetExpiring(30)
+	if err != nil {
+		t.Fatalf("GetExpiring: %v", err)
+	}
+	if len(contracts) != 2 || contracts[0].ID != first.ID || contracts[1].ID != lastDay.ID {
+		t.Errorf("expiring within 30 days = %d contracts, want today's and the 30th day's", len(contracts))
+	}
+
+	reminded, err := s.SendExpiryReminders(time.Now())
+	if err != nil {
+		t.Fatalf("SendExpiryReminders: %v", err)
+	}
+	if reminded != 2 || countNotifications(t, db, hr.ID, model.NotificationTypeContractExpiring) != 2 {
+		t.Errorf("reminded about %d contracts, want 2 with a notification each", reminded)
+	}
+	if reminded, _ := s.SendExpiryReminders(time.Now()); reminded != 0 {
+		t.Errorf("second sweep reminded about %d contracts, want 0", reminded)
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+// DashboardService assembles the role-aware home screen summary
+type DashboardService struct {
+	attendanceService *AttendanceService
+	leaveService      *LeaveService
+	leaveRepo         *repository.LeaveRepository
+	bookingRepo       *repository.MeetingRoomBookingRepository
+	notificationRepo  *repository.NotificationRepository
+	employeeRepo      *repository.EmployeeRepository
+	contractRepo      *repository.ContractRepository
+	salaryRepo        *repository.SalaryRepository
+	deviceRequestRepo *repository.DeviceRequestRepository
+}
+
+// NewDashboardService creates a new dashboard service reusing the given attendance and leave services
+func NewDashboardService(db *gorm.DB, attendanceService *AttendanceService, leaveService *LeaveService) *DashboardService {
+	return &DashboardService{
+		attendanceService: attendanceService,
+		leaveService:      leaveService,
+		leaveRepo:         repository.NewLeaveRepository(db),
+		bookingRepo:       repository.NewMeetingRoomBookingRepository(db),
+		notificationRepo:  repository.NewNotificationRepository(db),
+		employeeRepo:      repository.NewEmployeeRepository(db),
+		contractRepo:      repository.NewContractRepository(db),
+		salaryRepo:        repository.NewSalaryRepository(db),
+		deviceRequestRepo: repository.NewDeviceRequestRepository(db),
+	}
+}
+
+// Dashboard is the home screen summary; role-specific sections are omitted when not applicable
+type Dashboard struct {
+	Role                  string               `json:"role"`
+	TodayAttendance       *TodayStatusResponse `json:"today_attendance"`
+	PendingLeaves         int64                `json:"pending_leaves"`
+	ActiveBookings        int64                `json:"active_bookings"`
+	UnreadNotifications   int64                `json:"unread_notifications"`
+	PendingApprovals      *int64               `json:"pending_approvals,omitempty"`
+	Headcount             *int64               `json:"headcount,omitempty"`
+	PendingContracts      *int64               `json:"pending_contracts,omitempty"`
+	MonthlyPayrollTotal   *float64             `json:"monthly_payroll_total,omitempty"`
+	PendingDeviceRequests *int64               `json:"pending_device_requests,omitempty"`
+}
+
+// GetDashboard builds the summary for the given user and role using count queries only
+func (s *DashboardService) GetDashboard(ctx context.Context, userID uint, role string) (*Dashboard, error) {
+	dashboard := &Dashboard{Role: role}
+
+	today, err := s.attendanceService.GetTodayStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.TodayAttendance = today
+
+	if dashboard.PendingLeaves, err = s.leaveRepo.CountByEmployeeAndStatus(userID, model.LeaveStatusPending); err != nil {
+		return nil, err
+	}
+	if dashboard.ActiveBookings, err = s.bookingRepo.CountActiveByEmployee(userID); err != nil {
+		return nil, err
+	}
+	if dashboard.UnreadNotifications, err = s.notificationRepo.CountUnread(userID); err != nil {
+		return nil, err
+	}
+
+	isSuperAdmin := role == model.RoleSuperAdmin
+
+	if role == model.RoleSupervisor || isSuperAdmin {
+		pendingApprovals, err := s.leaveService.CountPendingForSupervisor(userID)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.PendingApprovals = &pendingApprovals
+	}
+
+	if role == model.RoleHR || isSuperAdmin {
+		headcount, err := s.employeeRepo.CountActive()
+		if err != nil {
+			return nil, err
+		}
+		pendingContracts, err := s.contractRepo.CountByStatus(model.ContractStatusPending)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.Headcount = &headcount
+		dashboard.PendingContracts = &pendingContracts
+	}
+
+	if role == model.RoleFinance || isSuperAdmin {
+		aggregate, err := s.salaryRepo.AggregateByMonth(time.Now().Format("2006-01"), "")
+		if err != nil {
+			return nil, err
+		}
+		dashboard.MonthlyPayrollTotal = &aggregate.Total
+	}
+
+	if role == model.RoleDeviceAdmin || isSuperAdmin {
+		pendingRequests, err := s.deviceRequestRepo.CountByStatus(model.DeviceRequestStatusPending)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.PendingDeviceRequests = &pendingRequests
+	}
+
+	return dashboard, nil
+}
+
+// Kinds of items in the my-requests feed
+const (
+	RequestKindLeave         = "leave"
+	RequestKindDeviceRequest = "device_request"
+	RequestKindBooking       = "booking"
+	RequestKindContract      = "contract"
+)
+
+// ErrInvalidRequestKind is returned when the my-requests feed is filtered by an unknown kind
+var ErrInvalidRequestKind = errors.New("invalid kind, expected leave, device_request, booking or contract")
+
+// MyRequestItem is one pending or active item in the caller's feed, with the fields common to every module
+type MyRequestItem struct {
+	Kind      string    `json:"kind"`
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetMyRequests lists the employee's pending and active leaves, device requests, bookings and
+// contracts newest first; kind, when not empty, limits the feed to one module
+func (s *DashboardService) GetMyRequests(userID uint, kind string) ([]MyRequestItem, error) {
+	collectors := map[string]func(uint) ([]MyRequestItem, error){
+		RequestKindLeave:         s.myLeaves,
+		RequestKindDeviceRequest: s.myDeviceRequests,
+		RequestKindBooking:       s.myBookings,
+		RequestKindContract:      s.myContracts,
+	}
+	if kind != "" && collectors[kind] == nil {
+		return nil, ErrInvalidRequestKind
+	}
+
+	items := []MyRequestItem{}
+	for itemKind, collect := range collectors {
+		if kind != "" && itemKind != kind {
+			continue
+		}
+		collected, err := collect(userID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, collected...)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.After(items[j].CreatedAt)
+		}
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].ID > items[j].ID
+	})
+	return items, nil
+}
+
+// myLeaves returns leaves awaiting a decision and approved leaves that have not ended yet
+func (s *DashboardService) myLeaves(userID uint) ([]MyRequestItem, error) {
+	leaves, err := s.leaveRepo.GetByEmployeeID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := Today()
+	var items []MyRequestItem
+	for _, leave := range leaves {
+		active := leave.Status == model.LeaveStatusPending ||
+			(leave.Status == model.LeaveStatusApproved && !leave.EndDate.Before(today))
+		if !active {
+			continue
+		}
+		items = append(items, MyRequestItem{
+			Kind:      RequestKindLeave,
+			ID:        leave.ID,
+			Title:     fmt.Sprintf("%s %s ~ %s", leave.LeaveType, leave.StartDate.Format("2006-01-02"), leave.EndDate.Format("2006-01-02")),
+			Status:    leave.Status,
+			CreatedAt: leave.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// myDeviceRequests returns device requests that are still open or whose device is still held
+func (s *DashboardService) myDeviceRequests(userID uint) ([]MyRequestItem, error) {
+	requests, err := s.deviceRequestRepo.GetByEmployeeID(userID, nil, "created_at DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []MyRequestItem
+	for _, request := range requests {
+		if !slices.Contains(reservingStatuses, request.Status) {
+			continue
+		}
+		items = append(items, MyRequestItem{
+			Kind:      RequestKindDeviceRequest,
+			ID:        request.ID,
+			Title:     request.Device.Name,
+			Status:    request.Status,
+			CreatedAt: request.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// myBookings returns active meeting room bookings
+func (s *DashboardService) myBookings(userID uint) ([]MyRequestItem, error) {
+	bookings, err := s.bookingRepo.GetActiveByEmployee(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]MyRequestItem, 0, len(bookings))
+	for _, booking := range bookings {
+		items = append(items, MyRequestItem{
+			Kind:      RequestKindBooking,
+			ID:        booking.ID,
+			Title:     fmt.Sprintf("%s %s %s-%s", booking.MeetingRoom.Name, booking.BookingDate.Format("2006-01-02"), booking.StartTime, booking.EndTime),
+			Status:    booking.Status,
+			CreatedAt: booking.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// myContracts returns contracts that are not yet signed or declined
+func (s *DashboardService) myContracts(userID uint) ([]MyRequestItem, error) {
+	contracts, err := s.contractRepo.GetByEmployeeID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []MyRequestItem
+	for _, contract := range contracts {
+		if contract.Status != model.ContractStatusPending {
+			continue
+		}
+		items = append(items, MyRequestItem{
+			Kind:      RequestKindContract,
+			ID:        contract.ID,
+			Title:     contract.Template.Title,
+			Status:    contract.Status,
+			CreatedAt: contract.CreatedAt,
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+func newDashboardService(db *gorm.DB) *DashboardService {
+	return NewDashboardService(db, NewAttendanceService(db, testAttendanceConfig()), NewLeaveService(db, testLeaveConfig()))
+}
+
+func TestDashboardByRole(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDashboardService(db)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	room := createRoom(t, db, "A", 6)
+	createBooking(t, db, employee.ID, room.ID, Today().AddDate(0, 0, 1), "10:00", "11:00")
+	db.Create(&model.Notification{EmployeeID: employee.ID, Type: model.NotificationTypeContractDeclined, Title: "t"})
+	contracts := newContractService(db)
+	createContractFor(t, contracts, employee, "onboarding", "{{employee_name}}")
+
+	dashboard, err := s.GetDashboard(context.Background(), employee.ID, employee.Role)
+	if err != nil {
+		t.Fatalf("GetDashboard(employee): %v", err)
+	}
+	if dashboard.TodayAttendance == nil || dashboard.ActiveBookings != 1 || dashboard.UnreadNotifications != 1 {
+		t.Errorf("employee dashboard = %+v, want today's attendance, 1 booking and 1 unread notification", dashboard)
+	}
+	if dashboard.PendingApprovals != nil || dashboard.Headcount != nil || dashboard.PendingContracts != nil ||
+		dashboard.MonthlyPayrollTotal != nil || dashboard.PendingDeviceRequests != nil {
+		t.Errorf("employee dashboard has role-specific sections: %+v", dashboard)
+	}
+
+	dashboard, err = s.GetDashboard(context.Background(), hr.ID, hr.Role)
+	if err != nil {
+		t.Fatalf("GetDashboard(hr): %v", err)
+	}
+	if dashboard.Headcount == nil || *dashboard.Headcount != 2 {
+		t.Errorf("HR headcount = %v, want 2", dashboard.Headcount)
+	}
+	if dashboard.PendingContracts == nil || *dashboard.PendingContracts != 1 {
+		t.Errorf("HR pending contracts = %v, want 1", dashboard.PendingContracts)
+	}
+	if dashboard.ActiveBookings != 0 || dashboard.PendingApprovals != nil || dashboard.MonthlyPayrollTotal != nil {
+		t.Errorf("HR dashboard = %+v, want no bookings and no supervisor or finance sections", dashboard)
+	}
+}
+
+func TestGetMyRequests(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDashboardService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	day := Today().AddDate(0, 0, 7)
+	leave := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+	rejected := createLeave(t, db, alice.ID, model.LeaveTypeSick, day, day, model.LeaveStatusRejected)
+	request := createDeviceRequest(t, db, alice.ID, createDevice(t, db, "ThinkPad", 1).ID, model.DeviceRequestStatusPending)
+	booking := createBooking(t, db, alice.ID, createRoom(t, db, "A", 6).ID, day, "10:00", "11:00")
+
+	// The device request is the newest and the leave the oldest
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	db.Model(leave).Update("created_at", base)
+	db.Model(rejected).Update("created_at", base.Add(time.Hour))
+	db.Model(booking).Update("created_at", base.Add(2*time.Hour))
+	db.Model(request).Update("created_at", base.Add(3*time.Hour))
+
+	items, err := s.GetMyRequests(alice.ID, "")
+	if err != nil {
+		t.Fatalf("GetMyRequests: %v", err)
+	}
+	type entry struct {
+		kind string
+		id   uint
+	}
+	var got []entry
+	for _, item := range items {
+		got = append(got, entry{item.Kind, item.ID})
+	}
+	want := []entry{{RequestKindDeviceRequest, request.ID}, {RequestKindBooking, booking.ID}, {RequestKindLeave, leave.ID}}
+	if !slices.Equal(got, want) {
+		t.Errorf("feed = %v, want %v", got, want)
+	}
+
+	items, err = s.GetMyRequests(alice.ID, RequestKindLeave)
+	if err != nil {
+		t.Fatalf("GetMyRequests for leaves: %v", err)
+	}
+	if len(items) != 1 || items[0].Kind != RequestKindLeave || items[0].ID != leave.ID || items[0].Status != model.LeaveStatusPending {
+		t.Errorf("leave feed = %+v, want only the pending leave", items)
+	}
+	if _, err := s.GetMyRequests(alice.ID, "salary"); !errors.Is(err, ErrInvalidRequestKind) {
+		t.Errorf("unknown kind: err = %v, want ErrInvalidRequestKind", err)
+	}
+}
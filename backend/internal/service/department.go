@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+var (
+	ErrDepartmentNotFound     = errors.New("department not found")
+	ErrDepartmentNameExists   = errors.New("department name already exists")
+	ErrDepartmentCodeExists   = errors.New("department code already exists")
+	ErrDepartmentHeadNotFound = errors.New("department head not found")
+	ErrDepartmentHasEmployees = errors.New("department still has employees")
+)
+
+// DepartmentService handles department business logic
+type DepartmentService struct {
+	repo         *repository.DepartmentRepository
+	employeeRepo *repository.EmployeeRepository
+	db           *gorm.DB
+}
+
+// NewDepartmentService creates a new department service
+func NewDepartmentService(db *gorm.DB) *DepartmentService {
+	return &DepartmentService{
+		repo:         repository.NewDepartmentRepository(db),
+		employeeRepo: repository.NewEmployeeRepository(db),
+		db:           db,
+	}
+}
+
+// CreateDepartmentRequest represents the request to create a department
+type CreateDepartmentRequest struct {
+	Name           string `json:"name" binding:"required,max=100"`
+	Code           string `json:"code" binding:"required,max=20"`
+	HeadEmployeeID *uint  `json:"head_employee_id"`
+}
+
+// UpdateDepartmentRequest represents the request to update a department
+type UpdateDepartmentRequest struct {
+	Name           string `json:"name" binding:"max=100"`
+	Code           string `json:"code" binding:"max=20"`
+	HeadEmployeeID *uint  `json:"head_employee_id"` // replaces the current head; null clears it
+}
+
+// Create creates a new department
+func (s *DepartmentService) Create(req *CreateDepartmentRequest) (*model.Department, error) {
+	department := &model.Department{
+		Name:           strings.TrimSpace(req.Name),
+		Code:           strings.ToUpper(strings.TrimSpace(req.Code)),
+		HeadEmployeeID: req.HeadEmployeeID,
+	}
+	if err := s.validate(department); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(department); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(department.ID)
+}
+
+// GetByID retrieves a department by ID
+func (s *DepartmentService) GetByID(id uint) (*model.Department, error) {
+	department, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrDepartmentNotFound) {
+			return nil, ErrDepartmentNotFound
+		}
+		return nil, err
+	}
+	return department, nil
+}
+
+// List retrieves all departments
+func (s *DepartmentService) List() ([]model.Department, error) {
+	return s.repo.GetAll()
+}
+
+// Update updates a department; renaming it also renames the department on its employees
+func (s *DepartmentService) Update(id uint, req *UpdateDepartmentRequest) (*model.Department, error) {
+	department, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		department.Name = name
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		department.Code = strings.ToUpper(code)
+	}
+	department.HeadEmployeeID = req.HeadEmployeeID
+	if err := s.validate(department); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(department); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// Delete deletes a department that no employee belongs to
+func (s *DepartmentService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountEmployees(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDepartmentHasEmployees
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		if errors.Is(err, repository.ErrDepartmentNotFound) {
+			return ErrDepartmentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListEmployees retrieves the employees of a department
+func (s *DepartmentService) ListEmployees(id uint) ([]model.Employee, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetEmployees(id)
+}
+
+// validate checks that the department's name and code are unique and its head exists
+func (s *DepartmentService) validate(department *model.Department) error {
+	exists, err := s.repo.ExistsByName(department.Name, department.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrDepartmentNameExists
+	}
+
+	exists, err = s.repo.ExistsByCode(department.Code, department.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrDepartmentCodeExists
+	}
+
+	if department.HeadEmployeeID != nil {
+		if _, err := s.employeeRepo.GetByID(*department.HeadEmployeeID); err != nil {
+			if errors.Is(err, repository.ErrEmployeeNotFound) {
+				return ErrDepartmentHeadNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+func TestDepartmentCRUD(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewDepartmentService(db)
+	head := testutil.CreateEmployee(t, db, "head", model.RoleSupervisor)
+
+	department, err := s.Create(&CreateDepartmentRequest{Name: " Engineering ", Code: "eng", HeadEmployeeID: &head.ID})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if department.Name != "Engineering" || department.Code != "ENG" {
+		t.Errorf("created = %q/%q, want Engineering/ENG", department.Name, department.Code)
+	}
+	if _, err := s.Create(&CreateDepartmentRequest{Name: "Engineering", Code: "ENG2"}); !errors.Is(err, ErrDepartmentNameExists) {
+		t.Errorf("duplicate name: err = %v, want ErrDepartmentNameExists", err)
+	}
+	if _, err := s.Create(&CreateDepartmentRequest{Name: "Platform", Code: "Eng"}); !errors.Is(err, ErrDepartmentCodeExists) {
+		t.Errorf("duplicate code: err = %v, want ErrDepartmentCodeExists", err)
+	}
+	missing := uint(9999)
+	if _, err := s.Create(&CreateDepartmentRequest{Name: "Sales", Code: "SAL", HeadEmployeeID: &missing}); !errors.Is(err, ErrDepartmentHeadNotFound) {
+		t.Errorf("unknown head: err = %v, want ErrDepartmentHeadNotFound", err)
+	}
+
+	updated, err := s.Update(department.ID, &UpdateDepartmentRequest{Name: "R&D"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "R&D" || updated.Code != "ENG" || updated.HeadEmployeeID != nil {
+		t.Errorf("updated = %q/%q head %v, want R&D/ENG without a head", updated.Name, updated.Code, updated.HeadEmployeeID)
+	}
+
+	departments, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(departments) != 1 {
+		t.Errorf("departments = %d, want 1", len(departments))
+	}
+
+	if err := s.Delete(department.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.GetByID(department.ID); !errors.Is(err, ErrDepartmentNotFound) {
+		t.Errorf("after Delete: err = %v, want ErrDepartmentNotFound", err)
+	}
+}
+
+func TestReassignDepartment(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewDepartmentService(db)
+	employees := newEmployeeService(t, db)
+	ctx := context.Background()
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	engineering, err := s.Create(&CreateDepartmentRequest{Name: "Engineering", Code: "ENG"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	sales, err := s.Create(&CreateDepartmentRequest{Name: "Sales", Code: "SAL"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	members := func(id uint) []string {
+		t.Helper()
+		list, err := s.ListEmployees(id)
+		if err != nil {
+			t.Fatalf("ListEmployees: %v", err)
+		}
+		names := []string{}
+		for _, employee := range list {
+			names = append(names, employee.Username)
+		}
+		return names
+	}
+
+	if _, err := employees.AdminUpdate(ctx, alice.ID, hr.ID, &AdminUpdateEmployeeRequest{DepartmentID: &engineering.ID}); err != nil {
+		t.Fatalf("assign to Engineering: %v", err)
+	}
+	if got := members(engineering.ID); !slices.Equal(got, []string{"alice"}) {
+		t.Errorf("Engineering = %v, want [alice]", got)
+	}
+	if err := s.Delete(engineering.ID); !errors.Is(err, ErrDepartmentHasEmployees) {
+		t.Errorf("delete with members: err = %v, want ErrDepartmentHasEmployees", err)
+	}
+
+	moved, err := employees.AdminUpdate(ctx, alice.ID, hr.ID, &AdminUpdateEmployeeRequest{DepartmentID: &sales.ID})
+	if err != nil {
+		t.Fatalf("reassign to Sales: %v", err)
+	}
+	if moved.Department != "Sales" {
+		t.Errorf("department name = %q, want Sales", moved.Department)
+	}
+	if got := members(engineering.ID); len(got) != 0 {
+		t.Errorf("Engineering after reassignment = %v, want none", got)
+	}
+	if got := members(sales.ID); !slices.Equal(got, []string{"alice"}) {
+		t.Errorf("Sales = %v, want [alice]", got)
+	}
+}
@@ -2,59 +2,113 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/skip2/go-qrcode"
 	"gorm.io/gorm"
 
+	"oa-system/config"
 	"oa-system/internal/model"
 	"oa-system/internal/repository"
 )
 
 var (
-	ErrDeviceNotFound              = errors.New("device not found")
-	ErrDeviceRequestNotFound       = errors.New("device request not found")
-	ErrDeviceRequestInvalidStatus  = errors.New("device request status does not allow this operation")
-	ErrDeviceNotAvailable          = errors.New("device not available")
-	ErrDeviceRequestNotOwner       = errors.New("can only operate on own device request")
+	ErrDeviceNotFound             = errors.New("device not found")
+	ErrDeviceRequestNotFound      = errors.New("device request not found")
+	ErrDeviceRequestInvalidStatus = errors.New("device request status does not allow this operation")
+	ErrDeviceNotAvailable         = errors.New("device not available")
+	ErrDeviceRequestNotOwner      = errors.New("can only operate on own device request")
+	ErrDeviceRequestInvalidSort   = errors.New("invalid sort option")
+	ErrDeviceReservationInvalid   = errors.New("needed_from and needed_until must be given together as YYYY-MM-DD, not in the past, with needed_until on or after needed_from")
+	ErrDeviceReserved             = errors.New("device is fully reserved for the requested dates")
+	ErrDeviceTransferTarget       = errors.New("device can only be transferred to another active employee")
 )
 
 // DeviceService handles device business logic
 type DeviceService struct {
-	deviceRepo        *repository.DeviceRepository
-	deviceRequestRepo *repository.DeviceRequestRepository
-	db                *gorm.DB
+	deviceRepo          *repository.DeviceRepository
+	deviceRequestRepo   *repository.DeviceRequestRepository
+	employeeRepo        *repository.EmployeeRepository
+	notificationService *NotificationService
+	db                  *gorm.DB
+	lowStockThreshold   int
+	qrLinkBase          string
 }
 
 // NewDeviceService creates a new device service
-func NewDeviceService(db *gorm.DB) *DeviceService {
+func NewDeviceService(db *gorm.DB, cfg *config.DeviceConfig) *DeviceService {
 	return &DeviceService{
-		deviceRepo:        repository.NewDeviceRepository(db),
-		deviceRequestRepo: repository.NewDeviceRequestRepository(db),
-		db:                db,
+		deviceRepo:          repository.NewDeviceRepository(db),
+		deviceRequestRepo:   repository.NewDeviceRequestRepository(db),
+		employeeRepo:        repository.NewEmployeeRepository(db),
+		notificationService: NewNotificationService(db),
+		db:                  db,
+		lowStockThreshold:   cfg.LowStockThreshold,
+		qrLinkBase:          strings.TrimSuffix(cfg.QRLinkBase, "/"),
 	}
 }
 
+// deviceQRCodeSize is the width and height in pixels of device QR code images
+const deviceQRCodeSize = 256
+
 // CreateDeviceRequest represents the request to create a device
 type CreateDeviceRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Type        string `json:"type"`
-	Quantity    int    `json:"quantity" binding:"required,min=1"`
-	Description string `json:"description"`
+	Name              string `json:"name" binding:"required"`
+	Type              string `json:"type"`
+	Quantity          int    `json:"quantity" binding:"required,min=1"`
+	Description       string `json:"description"`
+	LowStockThreshold *int   `json:"low_stock_threshold" binding:"omitempty,min=0"`
+	AutoApprove       bool   `json:"auto_approve"`
 }
 
 // UpdateDeviceRequest represents the request to update a device
 type UpdateDeviceRequest struct {
-	Name        string `json:"name"`
-	Type        string `json:"type"`
-	Quantity    int    `json:"quantity"`
-	Description string `json:"description"`
+	Name              string `json:"name"`
+	Type              string `json:"type"`
+	Quantity          int    `json:"quantity"`
+	Description       string `json:"description"`
+	LowStockThreshold *int   `json:"low_stock_threshold" binding:"omitempty,min=0"`
+	AutoApprove       *bool  `json:"auto_approve"`
+}
+
+
+// InitiateReturnInput represents the employee's self-reported condition of a returned device
+type InitiateReturnInput struct {
+	ReturnCondition string `json:"return_condition" binding:"max=500"`
+	IsDamaged       bool   `json:"is_damaged"`
 }
 
+// ConfirmReturnInput lets the device admin override the reported return condition
+type ConfirmReturnInput struct {
+	ReturnCondition *string `json:"return_condition" binding:"omitempty,max=500"`
+	IsDamaged       *bool   `json:"is_damaged"`
+}
+
+// DeviceRequestPage is a page of device requests
+type DeviceRequestPage struct {
+	Items    []model.DeviceRequest `json:"items"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+// ApproveDeviceRequestInput represents the optional comment left when approving a device request
+type ApproveDeviceRequestInput struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
 
 // RejectDeviceRequestInput represents the request to reject a device request
 type RejectDeviceRequestInput struct {
 	RejectReason string `json:"reject_reason" binding:"required"`
 }
 
+// TransferDeviceRequestInput represents the employee a collected device is handed over to
+type TransferDeviceRequestInput struct {
+	EmployeeID uint `json:"employee_id" binding:"required"`
+}
+
 // ===== Device Management (Device Admin) =====
 
 // CreateDevice creates a new device
@@ -66,6 +120,8 @@ func (s *DeviceService) CreateDevice(req *CreateDeviceRequest) (*model.Device, e
 		TotalQuantity:     req.Quantity,
 		AvailableQuantity: req.Quantity,
 		Description:       req.Description,
+		LowStockThreshold: req.LowStockThreshold,
+		AutoApprove:       req.AutoApprove,
 	}
 
 	if err := s.deviceRepo.Create(device); err != nil {
@@ -87,10 +143,22 @@ func (s *DeviceService) GetDeviceByID(id uint) (*model.Device, error) {
 	return device, nil
 }
 
-// GetAllDevices retrieves all devices
+// GetDeviceQRCode renders a PNG QR code for a device label
+// The code holds a link to the device list that opens the request dialog for the device, so scanning it starts a request
+func (s *DeviceService) GetDeviceQRCode(id uint) ([]byte, error) {
+	device, err := s.GetDeviceByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	link := fmt.Sprintf("%s/devices?device_id=%d", s.qrLinkBase, device.ID)
+	return qrcode.Encode(link, qrcode.Medium, deviceQRCodeSize)
+}
+
+// GetAllDevices retrieves all devices matching the filters
 // Implements Requirement 6.4: Device admin views all devices
-func (s *DeviceService) GetAllDevices() ([]model.Device, error) {
-	return s.deviceRepo.GetAll()
+func (s *DeviceService) GetAllDevices(filters map[string]interface{}) ([]model.Device, error) {
+	return s.deviceRepo.GetAll(filters)
 }
 
 // GetAvailableDevices retrieves all available devices
@@ -99,6 +167,19 @@ func (s *DeviceService) GetAvailableDevices() ([]model.Device, error) {
 	return s.deviceRepo.GetAvailable()
 }
 
+// GetLowStockDevices retrieves devices at or below their low-stock threshold
+func (s *DeviceService) GetLowStockDevices() ([]model.Device, error) {
+	return s.deviceRepo.GetLowStock(s.lowStockThreshold)
+}
+
+// thresholdFor returns the device's own low-stock threshold, or the global default
+func (s *DeviceService) thresholdFor(device *model.Device) int {
+	if device.LowStockThreshold != nil {
+		return *device.LowStockThreshold
+	}
+	return s.lowStockThreshold
+}
+
 
 // UpdateDevice updates a device
 // Implements Requirement 6.2: Device admin updates device info
@@ -121,6 +202,12 @@ func (s *DeviceService) UpdateDevice(id uint, req *UpdateDeviceRequest) (*model.
 	if req.Description != "" {
 		device.Description = req.Description
 	}
+	if req.LowStockThreshold != nil {
+		device.LowStockThreshold = req.LowStockThreshold
+	}
+	if req.AutoApprove != nil {
+		device.AutoApprove = *req.AutoApprove
+	}
 	if req.Quantity > 0 {
 		// Calculate the difference and adjust available quantity
 		diff := req.Quantity - device.TotalQuantity
@@ -155,7 +242,9 @@ func (s *DeviceService) DeleteDevice(id uint) error {
 
 // CreateDeviceRequestInput represents the input for creating a device request
 type CreateDeviceRequestInput struct {
-	DeviceID uint `json:"device_id" binding:"required"`
+	DeviceID    uint   `json:"device_id" binding:"required"`
+	NeededFrom  string `json:"needed_from"`  // YYYY-MM-DD; set with NeededUntil to reserve the device for later pickup
+	NeededUntil string `json:"needed_until"` // YYYY-MM-DD, inclusive
 }
 
 // CreateRequest creates a new device request
@@ -170,14 +259,26 @@ func (s *DeviceService) CreateRequest(employeeID uint, req *CreateDeviceRequestI
 		return nil, err
 	}
 
-	if device.AvailableQuantity <= 0 {
-		return nil, ErrDeviceNotAvailable
+	neededFrom, neededUntil, err := parseReservationWindow(req.NeededFrom, req.NeededUntil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkReservation(device, neededFrom, neededUntil); err != nil {
+		return nil, err
 	}
 
 	request := &model.DeviceRequest{
-		EmployeeID: employeeID,
-		DeviceID:   req.DeviceID,
-		Status:     model.DeviceRequestStatusPending,
+		EmployeeID:  employeeID,
+		DeviceID:    req.DeviceID,
+		Status:      model.DeviceRequestStatusPending,
+		NeededFrom:  neededFrom,
+		NeededUntil: neededUntil,
+	}
+	// Auto-approve devices skip the pending queue but still have to be collected
+	if device.AutoApprove {
+		now := time.Now()
+		request.Status = model.DeviceRequestStatusApproved
+		request.ApprovedAt = &now
 	}
 
 	if err := s.deviceRequestRepo.Create(request); err != nil {
@@ -185,10 +286,123 @@ func (s *DeviceService) CreateRequest(employeeID uint, req *CreateDeviceRequestI
 	}
 
 	// Reload with associations
+	request, err = s.deviceRequestRepo.GetByID(request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.AutoApprove {
+		// Device admins are told for visibility only; notification failure should not undo the request
+		_ = s.notificationService.NotifyRoles([]string{model.RoleDeviceAdmin}, model.Notification{
+			Type:        model.NotificationTypeDeviceAutoApproved,
+			Title:       "设备申请已自动通过",
+			Content:     fmt.Sprintf("%s 申请的设备「%s」已自动通过审批，无需处理", request.Employee.Name, device.Name),
+			RelatedType: model.NotificationRelatedDeviceRequest,
+			RelatedID:   request.ID,
+		})
+	}
+
+	return request, nil
+}
+
+// createApprovedRequest creates an already approved request for immediate use, skipping the approval step
+// Used when the request is issued on the employee's behalf, such as equipment provisioned during onboarding
+func (s *DeviceService) createApprovedRequest(employeeID, deviceID uint, comment string) (*model.DeviceRequest, error) {
+	device, err := s.deviceRepo.GetByID(deviceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.checkReservation(device, nil, nil); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request := &model.DeviceRequest{
+		EmployeeID:      employeeID,
+		DeviceID:        deviceID,
+		Status:          model.DeviceRequestStatusApproved,
+		ApprovedAt:      &now,
+		ApprovalComment: comment,
+	}
+
+	if err := s.deviceRequestRepo.Create(request); err != nil {
+		return nil, err
+	}
+
 	return s.deviceRequestRepo.GetByID(request.ID)
 }
 
 
+// reservingStatuses are the device request statuses that hold on to a reserved unit
+var reservingStatuses = []string{
+	model.DeviceRequestStatusPending,
+	model.DeviceRequestStatusApproved,
+	model.DeviceRequestStatusCollected,
+	model.DeviceRequestStatusReturnPending,
+}
+
+// parseReservationWindow parses the optional reservation window of a device request
+// Both dates are nil for a request for immediate use
+func parseReservationWindow(from, until string) (*time.Time, *time.Time, error) {
+	if from == "" && until == "" {
+		return nil, nil, nil
+	}
+	if from == "" || until == "" {
+		return nil, nil, ErrDeviceReservationInvalid
+	}
+
+	neededFrom, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, nil, ErrDeviceReservationInvalid
+	}
+	neededUntil, err := time.Parse("2006-01-02", until)
+	if err != nil {
+		return nil, nil, ErrDeviceReservationInvalid
+	}
+	if neededUntil.Before(neededFrom) || neededFrom.Before(Today()) {
+		return nil, nil, ErrDeviceReservationInvalid
+	}
+	return &neededFrom, &neededUntil, nil
+}
+
+// checkReservation verifies a unit of the device is free for the request
+// A reservation window must not overlap as many active reservations as the device has units. A request
+// needed today, with or without a window, also needs a unit in stock that no one else has reserved for today
+// The stock itself is only claimed by the atomic decrement when the device is collected
+func (s *DeviceService) checkReservation(device *model.Device, neededFrom, neededUntil *time.Time) error {
+	if neededFrom != nil {
+		reserved, err := s.deviceRequestRepo.CountReservationsOverlapping(device.ID, *neededFrom, *neededUntil, reservingStatuses)
+		if err != nil {
+			return err
+		}
+		if reserved >= int64(device.TotalQuantity) {
+			return ErrDeviceReserved
+		}
+	}
+
+	today := Today()
+	if neededFrom != nil && neededFrom.After(today) {
+		return nil
+	}
+
+	// Reservations that are collected have already left the available stock
+	reservedToday, err := s.deviceRequestRepo.CountReservationsOverlapping(device.ID, today, today, []string{
+		model.DeviceRequestStatusPending,
+		model.DeviceRequestStatusApproved,
+	})
+	if err != nil {
+		return err
+	}
+	if int64(device.AvailableQuantity)-reservedToday <= 0 {
+		return ErrDeviceNotAvailable
+	}
+	return nil
+}
+
 // GetRequestByID retrieves a device request by ID
 func (s *DeviceService) GetRequestByID(id uint) (*model.DeviceRequest, error) {
 	request, err := s.deviceRequestRepo.GetByID(id)
@@ -201,10 +415,73 @@ func (s *DeviceService) GetRequestByID(id uint) (*model.DeviceRequest, error) {
 	return request, nil
 }
 
-// GetMyRequests retrieves all device requests for an employee
+// deviceRequestSortColumns maps the accepted sort options to their columns
+var deviceRequestSortColumns = map[string]string{
+	"created_at":   "created_at",
+	"collected_at": "collected_at",
+	"returned_at":  "returned_at",
+}
+
+// GetMyRequests retrieves an employee's device request history, optionally filtered by status
+// sortBy is one of created_at, collected_at or returned_at (default created_at); order is asc or desc (default desc)
 // Implements Requirement 7.8: Employee views their device requests
-func (s *DeviceService) GetMyRequests(employeeID uint) ([]model.DeviceRequest, error) {
-	return s.deviceRequestRepo.GetByEmployeeID(employeeID)
+func (s *DeviceService) GetMyRequests(employeeID uint, status string, sortBy string, order string) ([]model.DeviceRequest, error) {
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	column, ok := deviceRequestSortColumns[sortBy]
+	if !ok {
+		return nil, ErrDeviceRequestInvalidSort
+	}
+	switch order {
+	case "":
+		order = "desc"
+	case "asc", "desc":
+	default:
+		return nil, ErrDeviceRequestInvalidSort
+	}
+
+	filters := map[string]interface{}{"status": status}
+	requests, err := s.deviceRequestRepo.GetByEmployeeID(employeeID, filters, column+" "+order+", id "+order)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range requests {
+		applyLoanDuration(&requests[i])
+	}
+	return requests, nil
+}
+
+// applyLoanDuration sets the whole days a returned device was held, from collection to return
+func applyLoanDuration(request *model.DeviceRequest) {
+	request.LoanDurationDays = nil
+	if request.Status != model.DeviceRequestStatusReturned || request.CollectedAt == nil || request.ReturnedAt == nil {
+		return
+	}
+	days := int(request.ReturnedAt.Sub(*request.CollectedAt).Hours() / 24)
+	request.LoanDurationDays = &days
+}
+
+// GetDeviceHistory retrieves the full request timeline of a device in the order the requests were made,
+// optionally filtered by status
+func (s *DeviceService) GetDeviceHistory(deviceID uint, status string) ([]model.DeviceRequest, error) {
+	if _, err := s.deviceRepo.GetByID(deviceID); err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	requests, err := s.deviceRequestRepo.GetByDeviceID(deviceID, map[string]interface{}{"status": status})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range requests {
+		applyLoanDuration(&requests[i])
+	}
+	return requests, nil
 }
 
 // GetPendingRequests retrieves all pending device requests
@@ -219,10 +496,25 @@ func (s *DeviceService) GetReturnPendingRequests() ([]model.DeviceRequest, error
 	return s.deviceRequestRepo.GetReturnPending()
 }
 
+// ListRequests retrieves a page of all device requests matching the filters
+// Supported filters: status, employee_id, device_id
+func (s *DeviceService) ListRequests(filters map[string]interface{}, page, pageSize int) (*DeviceRequestPage, error) {
+	requests, total, err := s.deviceRequestRepo.List(filters, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceRequestPage{
+		Items:    requests,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
 // ApproveRequest approves a device request
 // Implements Property 11: 设备申请状态机 - pending → approved
 // Implements Requirement 7.3: Device admin approves request
-func (s *DeviceService) ApproveRequest(requestID uint) (*model.DeviceRequest, error) {
+func (s *DeviceService) ApproveRequest(requestID uint, comment string) (*model.DeviceRequest, error) {
 	request, err := s.deviceRequestRepo.GetByID(requestID)
 	if err != nil {
 		if errors.Is(err, repository.ErrDeviceRequestNotFound) {
@@ -236,12 +528,27 @@ func (s *DeviceService) ApproveRequest(requestID uint) (*model.DeviceRequest, er
 		return nil, ErrDeviceRequestInvalidStatus
 	}
 
+	now := time.Now()
 	request.Status = model.DeviceRequestStatusApproved
+	request.ApprovedAt = &now
+	request.ApprovalComment = comment
 	if err := s.deviceRequestRepo.Update(request); err != nil {
 		return nil, err
 	}
 
-	// TODO: Notify employee (notification module is optional)
+	content := "您申请的设备「" + request.Device.Name + "」已通过审批，请及时领取"
+	if comment != "" {
+		content += "，备注：" + comment
+	}
+	// Notification failure should not undo the approval
+	_ = s.notificationService.Notify(&model.Notification{
+		EmployeeID:  request.EmployeeID,
+		Type:        model.NotificationTypeDeviceRequestApproved,
+		Title:       "设备申请已通过",
+		Content:     content,
+		RelatedType: model.NotificationRelatedDeviceRequest,
+		RelatedID:   request.ID,
+	})
 
 	return request, nil
 }
@@ -270,7 +577,15 @@ func (s *DeviceService) RejectRequest(requestID uint, reason string) (*model.Dev
 		return nil, err
 	}
 
-	// TODO: Notify employee (notification module is optional)
+	// Notification failure should not undo the rejection
+	_ = s.notificationService.Notify(&model.Notification{
+		EmployeeID:  request.EmployeeID,
+		Type:        model.NotificationTypeDeviceRequestRejected,
+		Title:       "设备申请被驳回",
+		Content:     "您申请的设备「" + request.Device.Name + "」被驳回，原因：" + reason,
+		RelatedType: model.NotificationRelatedDeviceRequest,
+		RelatedID:   request.ID,
+	})
 
 	return request, nil
 }
@@ -301,7 +616,9 @@ func (s *DeviceService) CollectDevice(requestID uint, employeeID uint) (*model.D
 	// Use transaction to ensure consistency (Property 10)
 	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Update request status
+		now := time.Now()
 		request.Status = model.DeviceRequestStatusCollected
+		request.CollectedAt = &now
 		if err := tx.Save(request).Error; err != nil {
 			return err
 		}
@@ -317,21 +634,98 @@ func (s *DeviceService) CollectDevice(requestID uint, employeeID uint) (*model.D
 			return ErrDeviceNotAvailable
 		}
 
-		return nil
+		return tx.First(&request.Device, request.DeviceID).Error
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
+	// Alert device admins only when this collect crossed the threshold, not on every collect below it
+	device := &request.Device
+	threshold := s.thresholdFor(device)
+	if device.AvailableQuantity <= threshold && device.AvailableQuantity+1 > threshold {
+		// Notification failure should not undo the collect
+		_ = s.notificationService.NotifyRoles([]string{model.RoleDeviceAdmin}, model.Notification{
+			Type:        model.NotificationTypeDeviceLowStock,
+			Title:       "设备库存不足",
+			Content:     fmt.Sprintf("设备「%s」可用数量仅剩 %d 台", device.Name, device.AvailableQuantity),
+			RelatedType: model.NotificationRelatedDevice,
+			RelatedID:   device.ID,
+		})
+	}
+
 	return request, nil
 }
 
+// TransferRequest hands a collected device over to another employee without returning it
+// The unit stays out, so the available quantity is untouched
+func (s *DeviceService) TransferRequest(requestID uint, toEmployeeID uint, actorID uint) (*model.DeviceRequest, error) {
+	request, err := s.deviceRequestRepo.GetByID(requestID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceRequestNotFound) {
+			return nil, ErrDeviceRequestNotFound
+		}
+		return nil, err
+	}
+
+	if request.Status != model.DeviceRequestStatusCollected {
+		return nil, ErrDeviceRequestInvalidStatus
+	}
+	if toEmployeeID == request.EmployeeID {
+		return nil, ErrDeviceTransferTarget
+	}
+	recipient, err := s.employeeRepo.GetByID(toEmployeeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return nil, ErrDeviceTransferTarget
+		}
+		return nil, err
+	}
+	if !recipient.IsActive {
+		return nil, ErrDeviceTransferTarget
+	}
+
+	fromEmployeeID := request.EmployeeID
+	transfer := &model.DeviceTransfer{
+		DeviceRequestID: request.ID,
+		FromEmployeeID:  fromEmployeeID,
+		ToEmployeeID:    toEmployeeID,
+		TransferredBy:   actorID,
+	}
+	if err := s.deviceRequestRepo.TransferTo(request, transfer); err != nil {
+		if errors.Is(err, repository.ErrDeviceRequestChanged) {
+			return nil, ErrDeviceRequestInvalidStatus
+		}
+		return nil, err
+	}
+
+	// Notification failure should not undo the transfer
+	_ = s.notificationService.Notify(&model.Notification{
+		EmployeeID:  fromEmployeeID,
+		Type:        model.NotificationTypeDeviceTransferred,
+		Title:       "设备已转交",
+		Content:     "您领用的设备「" + request.Device.Name + "」已转交给" + recipient.Name,
+		RelatedType: model.NotificationRelatedDeviceRequest,
+		RelatedID:   request.ID,
+	})
+	_ = s.notificationService.Notify(&model.Notification{
+		EmployeeID:  toEmployeeID,
+		Type:        model.NotificationTypeDeviceTransferred,
+		Title:       "设备已转交",
+		Content:     request.Employee.Name + "领用的设备「" + request.Device.Name + "」已转交给您",
+		RelatedType: model.NotificationRelatedDeviceRequest,
+		RelatedID:   request.ID,
+	})
+
+	return s.deviceRequestRepo.GetByID(request.ID)
+}
+
 
 // InitiateReturn initiates a device return by the employee
 // Implements Property 11: 设备申请状态机 - collected → return_pending
 // Implements Requirement 7.6: Employee initiates device return
-func (s *DeviceService) InitiateReturn(requestID uint, employeeID uint) (*model.DeviceRequest, error) {
+func (s *DeviceService) InitiateReturn(requestID uint, employeeID uint, input *InitiateReturnInput) (*model.DeviceRequest, error) {
 	request, err := s.deviceRequestRepo.GetByID(requestID)
 	if err != nil {
 		if errors.Is(err, repository.ErrDeviceRequestNotFound) {
@@ -350,7 +744,11 @@ func (s *DeviceService) InitiateReturn(requestID uint, employeeID uint) (*model.
 		return nil, ErrDeviceRequestInvalidStatus
 	}
 
+	now := time.Now()
 	request.Status = model.DeviceRequestStatusReturnPending
+	request.ReturnInitiatedAt = &now
+	request.ReturnCondition = input.ReturnCondition
+	request.IsDamaged = input.IsDamaged
 	if err := s.deviceRequestRepo.Update(request); err != nil {
 		return nil, err
 	}
@@ -364,7 +762,7 @@ func (s *DeviceService) InitiateReturn(requestID uint, employeeID uint) (*model.
 // Implements Property 11: 设备申请状态机 - return_pending → returned
 // Implements Property 10: 设备可用数量一致性 - increments available quantity
 // Implements Requirement 7.7: Device admin confirms device return
-func (s *DeviceService) ConfirmReturn(requestID uint) (*model.DeviceRequest, error) {
+func (s *DeviceService) ConfirmReturn(requestID uint, input *ConfirmReturnInput) (*model.DeviceRequest, error) {
 	request, err := s.deviceRequestRepo.GetByID(requestID)
 	if err != nil {
 		if errors.Is(err, repository.ErrDeviceRequestNotFound) {
@@ -380,12 +778,25 @@ func (s *DeviceService) ConfirmReturn(requestID uint) (*model.DeviceRequest, err
 
 	// Use transaction to ensure consistency (Property 10)
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Update request status
+		// Update request status, applying the admin's assessment of the device
+		now := time.Now()
 		request.Status = model.DeviceRequestStatusReturned
+		request.ReturnedAt = &now
+		if input.ReturnCondition != nil {
+			request.ReturnCondition = *input.ReturnCondition
+		}
+		if input.IsDamaged != nil {
+			request.IsDamaged = *input.IsDamaged
+		}
 		if err := tx.Save(request).Error; err != nil {
 			return err
 		}
 
+		// A damaged device is held back for repair rather than returned to the available pool
+		if request.IsDamaged {
+			return nil
+		}
+
 		// Increment available quantity (Property 10)
 		result := tx.Model(&model.Device{}).
 			Where("id = ?", request.DeviceID).
@@ -437,6 +848,21 @@ func (s *DeviceService) CancelRequestByEmployee(requestID uint, employeeID uint)
 	return request, nil
 }
 
+// CancelAllPendingByEmployee cancels every pending device request of the employee in one transaction
+// and returns how many were cancelled; requests in any other status are left untouched
+func (s *DeviceService) CancelAllPendingByEmployee(employeeID uint) (int64, error) {
+	var cancelled int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		cancelled, err = repository.NewDeviceRequestRepository(tx).CancelPendingByEmployee(employeeID)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return cancelled, nil
+}
+
 // CancelRequestByAdmin cancels a device request by the device admin
 // Implements Property 11: 设备申请状态机 - pending → cancelled
 // Implements Requirement 7.10: Device admin cancels pending request
@@ -0,0 +1,520 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+// newDeviceService flags devices with two or fewer units left as low on stock
+func newDeviceService(db *gorm.DB) *DeviceService {
+	return NewDeviceService(db, &config.DeviceConfig{LowStockThreshold: 2})
+}
+
+func TestListDeviceRequests(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	laptop := createDevice(t, db, "ThinkPad", 5)
+	monitor := createDevice(t, db, "Monitor", 5)
+	createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusReturned)
+	createDeviceRequest(t, db, alice.ID, monitor.ID, model.DeviceRequestStatusReturned)
+	createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusPending)
+	createDeviceRequest(t, db, bob.ID, laptop.ID, model.DeviceRequestStatusReturned)
+	createDeviceRequest(t, db, bob.ID, monitor.ID, model.DeviceRequestStatusRejected)
+
+	returned, err := s.ListRequests(map[string]interface{}{"status": model.DeviceRequestStatusReturned}, 1, 2)
+	if err != nil {
+		t.Fatalf("ListRequests: %v", err)
+	}
+	if returned.Total != 3 || len(returned.Items) != 2 {
+		t.Errorf("returned requests: total %d, page of %d, want 3 and 2", returned.Total, len(returned.Items))
+	}
+	for _, request := range returned.Items {
+		if request.Status != model.DeviceRequestStatusReturned || request.Employee.ID == 0 || request.Device.ID == 0 {
+			t.Errorf("request %d: status %q, employee %d, device %d", request.ID, request.Status, request.Employee.ID, request.Device.ID)
+		}
+	}
+
+	alices, err := s.ListRequests(map[string]interface{}{"employee_id": alice.ID, "device_id": laptop.ID}, 1, 20)
+	if err != nil {
+		t.Fatalf("ListRequests by employee: %v", err)
+	}
+	if alices.Total != 2 || len(alices.Items) != 2 {
+		t.Errorf("alice's laptop requests: total %d, want 2", alices.Total)
+	}
+	for _, request := range alices.Items {
+		if request.EmployeeID != alice.ID || request.DeviceID != laptop.ID {
+			t.Errorf("request %d of employee %d for device %d returned", request.ID, request.EmployeeID, request.DeviceID)
+		}
+	}
+}
+
+// availableQuantity reloads a device's available quantity
+func availableQuantity(t *testing.T, db *gorm.DB, deviceID uint) int {
+	t.Helper()
+	var device model.Device
+	if err := db.First(&device, deviceID).Error; err != nil {
+		t.Fatalf("load device %d: %v", deviceID, err)
+	}
+	return device.AvailableQuantity
+}
+
+func TestDamagedReturn(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	laptop := createDevice(t, db, "ThinkPad", 3)
+	db.Model(laptop).Update("available_quantity", 0)
+
+	returnDevice := func(damaged bool, override *ConfirmReturnInput) {
+		t.Helper()
+		request := createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusCollected)
+		if _, err := s.InitiateReturn(request.ID, alice.ID, &InitiateReturnInput{ReturnCondition: "屏幕有划痕", IsDamaged: damaged}); err != nil {
+			t.Fatalf("InitiateReturn: %v", err)
+		}
+		if _, err := s.ConfirmReturn(request.ID, override); err != nil {
+			t.Fatalf("ConfirmReturn: %v", err)
+		}
+	}
+
+	returnDevice(true, &ConfirmReturnInput{})
+	if got := availableQuantity(t, db, laptop.ID); got != 0 {
+		t.Errorf("after a damaged return available = %d, want 0", got)
+	}
+	notDamaged := false
+	returnDevice(true, &ConfirmReturnInput{IsDamaged: &notDamaged})
+	if got := availableQuantity(t, db, laptop.ID); got != 1 {
+		t.Errorf("after the admin cleared the damage flag available = %d, want 1", got)
+	}
+	damaged := true
+	returnDevice(false, &ConfirmReturnInput{IsDamaged: &damaged})
+	if got := availableQuantity(t, db, laptop.ID); got != 1 {
+		t.Errorf("after the admin flagged damage available = %d, want 1", got)
+	}
+	returnDevice(false, &ConfirmReturnInput{})
+	if got := availableQuantity(t, db, laptop.ID); got != 2 {
+		t.Errorf("after an intact return available = %d, want 2", got)
+	}
+}
+
+func TestDeviceRequestDecisionComments(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	laptop := createDevice(t, db, "ThinkPad", 3)
+
+	approved, err := s.ApproveRequest(createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusPending).ID, "前台领取")
+	if err != nil {
+		t.Fatalf("ApproveRequest: %v", err)
+	}
+	stored, err := s.GetRequestByID(approved.ID)
+	if err != nil {
+		t.Fatalf("GetRequestByID: %v", err)
+	}
+	if stored.ApprovalComment != "前台领取" {
+		t.Errorf("approval comment = %q, want 前台领取", stored.ApprovalComment)
+	}
+
+	rejected, err := s.RejectRequest(createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusPending).ID, "库存预留给新员工")
+	if err != nil {
+		t.Fatalf("RejectRequest: %v", err)
+	}
+	var notification model.Notification
+	err = db.Where("employee_id = ? AND type = ? AND related_id = ?", alice.ID, model.NotificationTypeDeviceRequestRejected, rejected.ID).First(&notification).Error
+	if err != nil {
+		t.Fatalf("rejection notification: %v", err)
+	}
+	if !strings.Contains(notification.Content, "库存预留给新员工") {
+		t.Errorf("notification content %q lacks the reject reason", notification.Content)
+	}
+}
+
+func TestLowStockDevices(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleDeviceAdmin)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	atDefault := createDevice(t, db, "at default", 2)
+	aboveDefault := createDevice(t, db, "above default", 3)
+	ownThreshold := createDevice(t, db, "own threshold", 5)
+	db.Model(ownThreshold).Update("low_stock_threshold", 5)
+
+	devices, err := s.GetLowStockDevices()
+	if err != nil {
+		t.Fatalf("GetLowStockDevices: %v", err)
+	}
+	low := map[uint]bool{}
+	for _, device := range devices {
+		low[device.ID] = true
+	}
+	if len(low) != 2 || !low[atDefault.ID] || !low[ownThreshold.ID] {
+		t.Errorf("low-stock devices = %v, want %d and %d", low, atDefault.ID, ownThreshold.ID)
+	}
+
+	collect := func() {
+		t.Helper()
+		request := createDeviceRequest(t, db, alice.ID, aboveDefault.ID, model.DeviceRequestStatusApproved)
+		if _, err := s.CollectDevice(request.ID, alice.ID); err != nil {
+			t.Fatalf("CollectDevice: %v", err)
+		}
+	}
+	collect()
+	if got := countNotifications(t, db, admin.ID, model.NotificationTypeDeviceLowStock); got != 1 {
+		t.Errorf("notifications after dropping to the threshold = %d, want 1", got)
+	}
+	collect()
+	if got := countNotifications(t, db, admin.ID, model.NotificationTypeDeviceLowStock); got != 1 {
+		t.Errorf("notifications after a collect already below the threshold = %d, want 1", got)
+	}
+}
+
+func TestLoanDuration(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	laptop := createDevice(t, db, "ThinkPad", 3)
+	collectedAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	returnedAt := collectedAt.Add(5*24*time.Hour + 3*time.Hour)
+
+	returned := createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusReturned)
+	db.Model(returned).Updates(map[string]interface{}{"collected_at": collectedAt, "returned_at": returnedAt})
+	held := createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusCollected)
+	db.Model(held).Update("collected_at", collectedAt)
+
+	requests, err := s.GetMyRequests(alice.ID, "", "", "")
+	if err != nil {
+		t.Fatalf("GetMyRequests: %v", err)
+	}
+	for _, request := range requests {
+		switch request.ID {
+		case returned.ID:
+			if request.LoanDurationDays == nil || *request.LoanDurationDays != 5 {
+				t.Errorf("returned request duration = %v, want 5 days", request.LoanDurationDays)
+			}
+		case held.ID:
+			if request.LoanDurationDays != nil {
+				t.Errorf("collected request duration = %d, want none", *request.LoanDurationDays)
+			}
+		}
+	}
+
+	filtered, err := s.GetMyRequests(alice.ID, model.DeviceRequestStatusReturned, "returned_at", "asc")
+	if err != nil {
+		t.Fatalf("GetMyRequests returned: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != returned.ID {
+		t.Errorf("returned requests = %d, want only %d", len(filtered), returned.ID)
+	}
+	if _, err := s.GetMyRequests(alice.ID, "", "name", ""); !errors.Is(err, ErrDeviceRequestInvalidSort) {
+		t.Errorf("unknown sort: err = %v, want ErrDeviceRequestInvalidSort", err)
+	}
+}
+
+func TestDeviceRequestTransitionTimestamps(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	laptop := createDevice(t, db, "ThinkPad", 3)
+	request := createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusPending)
+
+	// stamped lists which of the four transition timestamps are set, in transition order
+	stamped := func() [4]bool {
+		t.Helper()
+		stored, err := s.GetRequestByID(request.ID)
+		if err != nil {
+			t.Fatalf("GetRequestByID: %v", err)
+		}
+		return [4]bool{stored.ApprovedAt != nil, stored.CollectedAt != nil, stored.ReturnInitiatedAt != nil, stored.ReturnedAt != nil}
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+		want [4]bool
+	}{
+		{"approve", func() error { _, err := s.ApproveRequest(request.ID, ""); return err }, [4]bool{true, false, false, false}},
+		{"collect", func() error { _, err := s.CollectDevice(request.ID, alice.ID); return err }, [4]bool{true, true, false, false}},
+		{"initiate return", func() error {
+			_, err := s.InitiateReturn(request.ID, alice.ID, &InitiateReturnInput{})
+			return err
+		}, [4]bool{true, true, true, false}},
+		{"confirm return", func() error { _, err := s.ConfirmReturn(request.ID, &ConfirmReturnInput{}); return err }, [4]bool{true, true, true, true}},
+	}
+	if got := stamped(); got != [4]bool{} {
+		t.Fatalf("pending request stamped = %v, want none", got)
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := stamped(); got != step.want {
+			t.Errorf("after %s stamped = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestDeviceReservationWindows(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	projector := createDevice(t, db, "Projector", 1)
+
+	reserve := func(employee *model.Employee, from, until int) error {
+		t.Helper()
+		day := func(offset int) string { return Today().AddDate(0, 0, offset).Format("2006-01-02") }
+		_, err := s.CreateRequest(employee.ID, &CreateDeviceRequestInput{DeviceID: projector.ID, NeededFrom: day(from), NeededUntil: day(until)})
+		return err
+	}
+
+	if err := reserve(alice, 7, 9); err != nil {
+		t.Fatalf("first window: %v", err)
+	}
+	if err := reserve(bob, 9, 10); !errors.Is(err, ErrDeviceReserved) {
+		t.Errorf("window sharing its first day with a reservation: err = %v, want ErrDeviceReserved", err)
+	}
+	if err := reserve(bob, 5, 12); !errors.Is(err, ErrDeviceReserved) {
+		t.Errorf("window enclosing a reservation: err = %v, want ErrDeviceReserved", err)
+	}
+	if err := reserve(bob, 10, 12); err != nil {
+		t.Errorf("window starting the day after a reservation: %v", err)
+	}
+	if err := reserve(bob, 3, 6); err != nil {
+		t.Errorf("window ending the day before a reservation: %v", err)
+	}
+	if err := reserve(bob, 9, 8); !errors.Is(err, ErrDeviceReservationInvalid) {
+		t.Errorf("window ending before it starts: err = %v, want ErrDeviceReservationInvalid", err)
+	}
+
+	// Reserving future windows leaves the unit in stock until it is collected
+	if got := availableQuantity(t, db, projector.ID); got != 1 {
+		t.Errorf("available = %d, want 1", got)
+	}
+}
+
+func TestTransferDeviceRequest(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleDeviceAdmin)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	device := createDevice(t, db, "ThinkPad", 1)
+	db.Model(device).Update("available_quantity", 0)
+	request := createDeviceRequest(t, db, alice.ID, device.ID, model.DeviceRequestStatusCollected)
+
+	if _, err := s.TransferRequest(request.ID, alice.ID, admin.ID); !errors.Is(err, ErrDeviceTransferTarget) {
+		t.Errorf("transfer to the current borrower: err = %v, want ErrDeviceTransferTarget", err)
+	}
+
+	transferred, err := s.TransferRequest(request.ID, bob.ID, admin.ID)
+	if err != nil {
+		t.Fatalf("TransferRequest: %v", err)
+	}
+	if transferred.EmployeeID != bob.ID || transferred.Status != model.DeviceRequestStatusCollected {
+		t.Errorf("transferred request = employee %d %q, want bob's and still collected", transferred.EmployeeID, transferred.Status)
+	}
+	var transfers []model.DeviceTransfer
+	if err := db.Where("device_request_id = ?", request.ID).Find(&transfers).Error; err != nil {
+		t.Fatalf("load transfers: %v", err)
+	}
+	if len(transfers) != 1 || transfers[0].FromEmployeeID != alice.ID || transfers[0].ToEmployeeID != bob.ID || transfers[0].TransferredBy != admin.ID {
+		t.Errorf("transfers = %+v, want one from alice to bob by the admin", transfers)
+	}
+	for _, employee := range []*model.Employee{alice, bob} {
+		if n := countNotifications(t, db, employee.ID, model.NotificationTypeDeviceTransferred); n != 1 {
+			t.Errorf("transfer notifications to %s = %d, want 1", employee.Username, n)
+		}
+	}
+
+	// The unit stays out with the new borrower
+	var stored model.Device
+	if err := db.First(&stored, device.ID).Error; err != nil {
+		t.Fatalf("load device: %v", err)
+	}
+	if stored.AvailableQuantity != 0 {
+		t.Errorf("available quantity = %d, want 0", stored.AvailableQuantity)
+	}
+}
+
+func TestAutoApproveDeviceRequest(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleDeviceAdmin)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	mouse := createDevice(t, db, "Mouse", 5)
+	db.Model(mouse).Update("auto_approve", true)
+	laptop := createDevice(t, db, "ThinkPad", 5)
+
+	request, err := s.CreateRequest(alice.ID, &CreateDeviceRequestInput{DeviceID: mouse.ID})
+	if err != nil {
+		t.Fatalf("request an auto-approve device: %v", err)
+	}
+	if request.Status != model.DeviceRequestStatusApproved || request.ApprovedAt == nil {
+		t.Errorf("auto-approve request = %q approved at %v, want approved", request.Status, request.ApprovedAt)
+	}
+	if n := countNotifications(t, db, admin.ID, model.NotificationTypeDeviceAutoApproved); n != 1 {
+		t.Errorf("device admin notifications = %d, want 1", n)
+	}
+
+	request, err = s.CreateRequest(alice.ID, &CreateDeviceRequestInput{DeviceID: laptop.ID})
+	if err != nil {
+		t.Fatalf("request a normal device: %v", err)
+	}
+	if request.Status != model.DeviceRequestStatusPending {
+		t.Errorf("normal request = %q, want pending", request.Status)
+	}
+
+	// Collection is still required, so the stock is untouched until then
+	var stored model.Device
+	if err := db.First(&stored, mouse.ID).Error; err != nil {
+		t.Fatalf("load device: %v", err)
+	}
+	if stored.AvailableQuantity != 5 {
+		t.Errorf("available mice = %d, want 5", stored.AvailableQuantity)
+	}
+}
+
+func TestSearchDevices(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	for _, device := range []*model.Device{
+		{Name: "ThinkPad X1", Type: "laptop", TotalQuantity: 1, AvailableQuantity: 1},
+		{Name: "MacBook Pro", Type: "Laptop", TotalQuantity: 1, AvailableQuantity: 1},
+		{Name: "Dell U2720", Type: "monitor", TotalQuantity: 1, AvailableQuantity: 1},
+	} {
+		if err := db.Create(device).Error; err != nil {
+			t.Fatalf("create device: %v", err)
+		}
+	}
+
+	names := func(q string) []string {
+		t.Helper()
+		devices, err := s.GetAllDevices(map[string]interface{}{"q": q})
+		if err != nil {
+			t.Fatalf("GetAllDevices(%q): %v", q, err)
+		}
+		names := []string{}
+		for _, device := range devices {
+			names = append(names, device.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	if got := names("pad"); !slices.Equal(got, []string{"ThinkPad X1"}) {
+		t.Errorf("devices matching pad = %v, want [ThinkPad X1]", got)
+	}
+	if got := names("LAPTOP"); !slices.Equal(got, []string{"MacBook Pro", "ThinkPad X1"}) {
+		t.Errorf("devices matching LAPTOP = %v, want [MacBook Pro ThinkPad X1]", got)
+	}
+	if got := names(""); len(got) != 3 {
+		t.Errorf("devices without a search = %v, want all 3", got)
+	}
+}
+
+func TestGetDeviceHistory(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	laptop := createDevice(t, db, "ThinkPad", 5)
+	monitor := createDevice(t, db, "Monitor", 5)
+	latest := createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusPending)
+	first := createDeviceRequest(t, db, bob.ID, laptop.ID, model.DeviceRequestStatusReturned)
+	second := createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusReturned)
+	createDeviceRequest(t, db, alice.ID, monitor.ID, model.DeviceRequestStatusReturned)
+	// Made in a different order than inserted, so the timeline must follow created_at
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	for i, request := range []*model.DeviceRequest{first, second, latest} {
+		if err := db.Model(request).Update("created_at", start.AddDate(0, 0, i)).Error; err != nil {
+			t.Fatalf("set created_at: %v", err)
+		}
+	}
+
+	// ids lists the history of laptop under status
+	ids := func(status string) []uint {
+		t.Helper()
+		requests, err := s.GetDeviceHistory(laptop.ID, status)
+		if err != nil {
+			t.Fatalf("GetDeviceHistory: %v", err)
+		}
+		var got []uint
+		for _, request := range requests {
+			if request.Employee.ID != request.EmployeeID {
+				t.Errorf("request %d: employee not loaded", request.ID)
+			}
+			got = append(got, request.ID)
+		}
+		return got
+	}
+
+	if got, want := ids(""), []uint{first.ID, second.ID, latest.ID}; !slices.Equal(got, want) {
+		t.Errorf("history = %v, want %v", got, want)
+	}
+	if got, want := ids(model.DeviceRequestStatusReturned), []uint{first.ID, second.ID}; !slices.Equal(got, want) {
+		t.Errorf("returned history = %v, want %v", got, want)
+	}
+	if _, err := s.GetDeviceHistory(monitor.ID+100, ""); !errors.Is(err, ErrDeviceNotFound) {
+		t.Errorf("unknown device: err = %v, want ErrDeviceNotFound", err)
+	}
+}
+
+func TestCancelAllPendingByEmployee(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newDeviceService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	laptop := createDevice(t, db, "ThinkPad", 5)
+	pending := []*model.DeviceRequest{
+		createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusPending),
+		createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusPending),
+	}
+	untouched := map[*model.DeviceRequest]string{
+		createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusApproved):  model.DeviceRequestStatusApproved,
+		createDeviceRequest(t, db, alice.ID, laptop.ID, model.DeviceRequestStatusCollected): model.DeviceRequestStatusCollected,
+		createDeviceRequest(t, db, bob.ID, laptop.ID, model.DeviceRequestStatusPending):     model.DeviceRequestStatusPending,
+	}
+
+	cancelled, err := s.CancelAllPendingByEmployee(alice.ID)
+	if err != nil {
+		t.Fatalf("CancelAllPendingByEmployee: %v", err)
+	}
+	if cancelled != 2 {
+		t.Errorf("cancelled = %d, want 2", cancelled)
+	}
+
+	// status reloads a request's status
+	status := func(request *model.DeviceRequest) string {
+		t.Helper()
+		var stored model.DeviceRequest
+		if err := db.First(&stored, request.ID).Error; err != nil {
+			t.Fatalf("load device request: %v", err)
+		}
+		return stored.Status
+	}
+	for _, request := range pending {
+		if got := status(request); got != model.DeviceRequestStatusCancelled {
+			t.Errorf("pending request %d = %q, want cancelled", request.ID, got)
+		}
+	}
+	for request, want := range untouched {
+		if got := status(request); got != want {
+			t.Errorf("request %d = %q, want %q untouched", request.ID, got, want)
+		}
+	}
+
+	// Nothing is left to cancel the second time
+	if cancelled, err := s.CancelAllPendingByEmployee(alice.ID); err != nil || cancelled != 0 {
+		t.Errorf("second call = %d, %v; want 0", cancelled, err)
+	}
+}
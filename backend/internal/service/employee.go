@@ -1,47 +1,100 @@
 package service
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
+	"oa-system/config"
 	"oa-system/internal/model"
 	"oa-system/internal/repository"
+	"oa-system/pkg/pagination"
 	"oa-system/pkg/password"
 )
 
 var (
-	ErrEmployeeNotFound      = errors.New("employee not found")
-	ErrEmployeeNoExists      = errors.New("employee number already exists")
-	ErrUsernameExists        = errors.New("username already exists")
-	ErrSupervisorNotFound    = errors.New("supervisor not found")
-	ErrInvalidRole           = errors.New("invalid role")
-	ErrCannotModifySelf      = errors.New("cannot modify own account status")
-	ErrCannotDisableSuperAdmin = errors.New("cannot disable super admin account")
+	ErrEmployeeNotFound           = errors.New("employee not found")
+	ErrEmployeeNoExists           = errors.New("employee number already exists")
+	ErrUsernameExists             = errors.New("username already exists")
+	ErrSupervisorNotFound         = errors.New("supervisor not found")
+	ErrInvalidRole                = errors.New("invalid role")
+	ErrCannotModifySelf           = errors.New("cannot modify own account status")
+	ErrCannotDisableSuperAdmin    = errors.New("cannot disable super admin account")
+	ErrEmailExists                = errors.New("email already exists")
+	ErrEmployeeNotDeleted         = errors.New("employee is not deleted")
+	ErrEmployeeHasActiveAssets    = errors.New("employee still holds devices or active bookings")
+	ErrInvalidDelegate            = errors.New("delegate must be another active employee")
+	ErrEmployeeDepartmentNotFound = errors.New("specified department not found")
+	ErrSelfSupervisor             = errors.New("cannot set self as supervisor")
+	ErrCircularSupervisor         = errors.New("supervisor assignment would create a reporting cycle")
+	ErrLastSuperAdmin             = errors.New("cannot remove the last active super admin")
+	ErrAvatarTooLarge             = errors.New("avatar exceeds the maximum size")
+	ErrAvatarTypeNotAllowed       = errors.New("avatar content type is not allowed")
+	ErrAvatarNotFound             = errors.New("employee has no avatar")
 )
 
 // EmployeeService handles employee business logic
 type EmployeeService struct {
-	repo *repository.EmployeeRepository
-	db   *gorm.DB
+	repo                *repository.EmployeeRepository
+	roleRepo            *repository.RoleRepository
+	departmentRepo      *repository.DepartmentRepository
+	deviceRequestRepo   *repository.DeviceRequestRepository
+	bookingRepo         *repository.MeetingRoomBookingRepository
+	meetingRoomService  *MeetingRoomService
+	auditService        *AuditService
+	notificationService *NotificationService
+	avatarDir           string
+	avatarMaxSize       int64
+	revokeOnDisable     bool
+	db                  *gorm.DB
+}
+
+// allowedAvatarTypes maps accepted profile photo content types to the extension used on disk
+var allowedAvatarTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
 }
 
 // NewEmployeeService creates a new employee service
-func NewEmployeeService(db *gorm.DB) *EmployeeService {
+func NewEmployeeService(db *gorm.DB, avatarCfg *config.AvatarConfig, bookingCfg *config.BookingConfig) *EmployeeService {
 	return &EmployeeService{
-		repo: repository.NewEmployeeRepository(db),
-		db:   db,
+		repo:                repository.NewEmployeeRepository(db),
+		roleRepo:            repository.NewRoleRepository(db),
+		departmentRepo:      repository.NewDepartmentRepository(db),
+		deviceRequestRepo:   repository.NewDeviceRequestRepository(db),
+		bookingRepo:         repository.NewMeetingRoomBookingRepository(db),
+		meetingRoomService:  NewMeetingRoomService(db, bookingCfg),
+		auditService:        NewAuditService(db),
+		notificationService: NewNotificationService(db),
+		avatarDir:           avatarCfg.StorageDir,
+		avatarMaxSize:       int64(avatarCfg.MaxSizeMB) << 20,
+		db:                  db,
 	}
 }
 
+// SetRevokeTokensOnDisable makes disabling an account revoke every token issued to it so far
+func (s *EmployeeService) SetRevokeTokensOnDisable(enabled bool) {
+	s.revokeOnDisable = enabled
+}
+
 // CreateEmployeeRequest represents a request to create an employee
 type CreateEmployeeRequest struct {
 	Name         string `json:"name" binding:"required"`
-	Department   string `json:"department"`
+	Department   string `json:"department"` // deprecated, use DepartmentID
+	DepartmentID *uint  `json:"department_id"`
 	Position     string `json:"position"`
 	Phone        string `json:"phone"`
 	Email        string `json:"email"`
@@ -55,7 +108,6 @@ type CreateEmployeeResponse struct {
 	InitialPassword string          `json:"initial_password"`
 }
 
-
 // UpdateEmployeeRequest represents a request to update employee info (by employee themselves)
 type UpdateEmployeeRequest struct {
 	Phone string `json:"phone"`
@@ -65,11 +117,13 @@ type UpdateEmployeeRequest struct {
 // AdminUpdateEmployeeRequest represents a request to update employee info (by HR/Admin)
 type AdminUpdateEmployeeRequest struct {
 	Name         string `json:"name"`
-	Department   string `json:"department"`
+	Department   string `json:"department"` // deprecated, use DepartmentID
+	DepartmentID *uint  `json:"department_id"`
 	Position     string `json:"position"`
 	Phone        string `json:"phone"`
 	Email        string `json:"email"`
 	SupervisorID *uint  `json:"supervisor_id"`
+	Version      *int   `json:"version"` // version the edit was based on; a stale one is rejected
 }
 
 // UpdateRoleRequest represents a request to update employee role
@@ -82,35 +136,101 @@ type UpdateSupervisorRequest struct {
 	SupervisorID *uint `json:"supervisor_id"`
 }
 
+// UpdateDelegateRequest represents a request to set or clear a supervisor's leave approval delegate
+type UpdateDelegateRequest struct {
+	DelegateApproverID *uint `json:"delegate_approver_id"`
+}
+
 // UpdateStatusRequest represents a request to enable/disable employee account
 type UpdateStatusRequest struct {
 	IsActive bool `json:"is_active"`
 }
 
-// validRoles contains all valid role values
-var validRoles = map[string]bool{
-	model.RoleSuperAdmin:  true,
-	model.RoleHR:          true,
-	model.RoleFinance:     true,
-	model.RoleDeviceAdmin: true,
-	model.RoleSupervisor:  true,
-	model.RoleEmployee:    true,
+// validateRole checks that the role is defined in the roles table
+func (s *EmployeeService) validateRole(ctx context.Context, role string) error {
+	exists, err := s.roleRepo.WithContext(ctx).ExistsByName(role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// validateEmail checks that a non-empty email is not used by another employee
+func (s *EmployeeService) validateEmail(ctx context.Context, email string, excludeID uint) error {
+	if email == "" {
+		return nil
+	}
+	exists, err := s.repo.WithContext(ctx).ExistsByEmail(email, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrEmailExists
+	}
+	return nil
+}
+
+// translateEmployeeSaveError maps the repository errors of a failed employee write to service errors
+func translateEmployeeSaveError(err error) error {
+	if errors.Is(err, repository.ErrEmailExists) {
+		return ErrEmailExists
+	}
+	return translateVersionConflict(err)
+}
+
+// resolveDepartment returns the department ID and name to store on an employee. An ID must refer
+// to an existing department; a bare name is linked to the department of that name when one exists
+// and is otherwise kept as free text
+func (s *EmployeeService) resolveDepartment(ctx context.Context, departmentID *uint, name string) (*uint, string, error) {
+	repo := s.departmentRepo.WithContext(ctx)
+
+	if departmentID != nil {
+		department, err := repo.GetByID(*departmentID)
+		if err != nil {
+			if errors.Is(err, repository.ErrDepartmentNotFound) {
+				return nil, "", ErrEmployeeDepartmentNotFound
+			}
+			return nil, "", err
+		}
+		return &department.ID, department.Name, nil
+	}
+	if name == "" {
+		return nil, "", nil
+	}
+
+	department, err := repo.GetByName(name)
+	if err != nil {
+		if errors.Is(err, repository.ErrDepartmentNotFound) {
+			return nil, name, nil
+		}
+		return nil, "", err
+	}
+	return &department.ID, department.Name, nil
 }
 
 // Create creates a new employee with auto-generated employee number and password
-func (s *EmployeeService) Create(req *CreateEmployeeRequest) (*CreateEmployeeResponse, error) {
+func (s *EmployeeService) Create(ctx context.Context, req *CreateEmployeeRequest) (*CreateEmployeeResponse, error) {
+	repo := s.repo.WithContext(ctx)
+
 	// Validate role if provided
 	role := model.RoleEmployee
 	if req.Role != "" {
-		if !validRoles[req.Role] {
-			return nil, ErrInvalidRole
+		if err := s.validateRole(ctx, req.Role); err != nil {
+			return nil, err
 		}
 		role = req.Role
 	}
 
+	if err := s.validateEmail(ctx, req.Email, 0); err != nil {
+		return nil, err
+	}
+
 	// Validate supervisor if provided
 	if req.SupervisorID != nil {
-		_, err := s.repo.GetByID(*req.SupervisorID)
+		_, err := repo.GetByID(*req.SupervisorID)
 		if err != nil {
 			if errors.Is(err, repository.ErrEmployeeNotFound) {
 				return nil, ErrSupervisorNotFound
@@ -119,15 +239,11 @@ func (s *EmployeeService) Create(req *CreateEmployeeRequest) (*CreateEmployeeRes
 		}
 	}
 
-	// Generate unique employee number
-	employeeNo, err := s.generateEmployeeNo()
+	departmentID, department, err := s.resolveDepartment(ctx, req.DepartmentID, req.Department)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate username from employee number
-	username := strings.ToLower(employeeNo)
-
 	// Generate random initial password
 	initialPassword, err := password.GenerateRandom(8)
 	if err != nil {
@@ -141,10 +257,9 @@ func (s *EmployeeService) Create(req *CreateEmployeeRequest) (*CreateEmployeeRes
 	}
 
 	employee := &model.Employee{
-		Username:     username,
-		EmployeeNo:   employeeNo,
 		Name:         req.Name,
-		Department:   req.Department,
+		Department:   department,
+		DepartmentID: departmentID,
 		Position:     req.Position,
 		Phone:        req.Phone,
 		Email:        req.Email,
@@ -156,8 +271,28 @@ func (s *EmployeeService) Create(req *CreateEmployeeRequest) (*CreateEmployeeRes
 		IsActive:     true,
 	}
 
-	if err := s.repo.Create(employee); err != nil {
-		return nil, err
+	// Claim the next employee number, with the username derived from it. A concurrent create may
+	// take the number first, in which case the insert fails and the number after it is tried
+	number := 0
+	for attempt := 1; ; attempt++ {
+		number, err = s.nextEmployeeNumber(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+		employee.ID = 0
+		employee.EmployeeNo = formatEmployeeNo(number)
+		employee.Username = strings.ToLower(employee.EmployeeNo)
+
+		err = repo.Create(employee)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repository.ErrEmployeeNoExists) {
+			return nil, translateEmployeeSaveError(err)
+		}
+		if attempt == employeeNoAttempts {
+			return nil, ErrEmployeeNoExists
+		}
 	}
 
 	return &CreateEmployeeResponse{
@@ -166,39 +301,97 @@ func (s *EmployeeService) Create(req *CreateEmployeeRequest) (*CreateEmployeeRes
 	}, nil
 }
 
+// employeeNoAttempts bounds how many employee numbers Create tries before giving up on a busy sequence
+const employeeNoAttempts = 10
 
-// generateEmployeeNo generates a unique employee number in format EMP + 5 digits
-func (s *EmployeeService) generateEmployeeNo() (string, error) {
-	maxNo, err := s.repo.GetMaxEmployeeNo()
+// Employee numbers are EMP followed by at least 5 digits; employeeNoValuePattern also accepts
+// shorter padding so that such numbers can still be compared by value
+var (
+	employeeNoPattern      = regexp.MustCompile(`^EMP\d{5,}$`)
+	employeeNoValuePattern = regexp.MustCompile(`^EMP(\d+)$`)
+)
+
+// formatEmployeeNo formats an employee number as EMP + at least 5 digits
+func formatEmployeeNo(number int) string {
+	return fmt.Sprintf("EMP%05d", number)
+}
+
+// nextEmployeeNumber returns the number after the highest one in use and after floor. Passing the
+// number that just failed as floor moves a retry past it even when, inside a transaction, the read
+// does not yet see the row that took it
+func (s *EmployeeService) nextEmployeeNumber(ctx context.Context, floor int) (int, error) {
+	highest, err := s.repo.WithContext(ctx).GetMaxEmployeeNumber()
+	if err != nil {
+		return 0, err
+	}
+	return max(highest, floor) + 1, nil
+}
+
+// PreviewNextEmployeeNo returns the employee number the next created employee would get
+// A concurrent create may still claim it first
+func (s *EmployeeService) PreviewNextEmployeeNo(ctx context.Context) (string, error) {
+	number, err := s.nextEmployeeNumber(ctx, 0)
 	if err != nil {
 		return "", err
 	}
+	return formatEmployeeNo(number), nil
+}
+
+// EmployeeNumberReport lists employee numbers that do not fit the EMP + 5 digits scheme
+type EmployeeNumberReport struct {
+	NextEmployeeNo string                        `json:"next_employee_no"`
+	Malformed      []repository.EmployeeNumber   `json:"malformed"`  // not EMP followed by at least 5 digits
+	Duplicates     [][]repository.EmployeeNumber `json:"duplicates"` // groups whose numbers differ only in padding, e.g. EMP0042 and EMP00042
+}
 
-	var nextNum int
-	if maxNo == "" {
-		nextNum = 1
-	} else {
-		// Extract number from employee number (e.g., "EMP00001" -> 1)
-		numStr := strings.TrimPrefix(maxNo, "EMP")
-		num, err := strconv.Atoi(numStr)
+// GetEmployeeNumberReport checks every employee number, soft-deleted employees included, for
+// malformed numbers and for numbers that denote the same value. It only reports; nothing is changed
+func (s *EmployeeService) GetEmployeeNumberReport(ctx context.Context) (*EmployeeNumberReport, error) {
+	numbers, err := s.repo.WithContext(ctx).ListEmployeeNumbers()
+	if err != nil {
+		return nil, err
+	}
+	next, err := s.PreviewNextEmployeeNo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &EmployeeNumberReport{
+		NextEmployeeNo: next,
+		Malformed:      []repository.EmployeeNumber{},
+		Duplicates:     [][]repository.EmployeeNumber{},
+	}
+	byValue := make(map[int][]repository.EmployeeNumber)
+	var values []int
+	for _, number := range numbers {
+		if !employeeNoPattern.MatchString(number.EmployeeNo) {
+			report.Malformed = append(report.Malformed, number)
+		}
+		match := employeeNoValuePattern.FindStringSubmatch(number.EmployeeNo)
+		if match == nil {
+			continue
+		}
+		value, err := strconv.Atoi(match[1])
 		if err != nil {
-			// If parsing fails, count employees and add 1
-			count, err := s.repo.Count()
-			if err != nil {
-				return "", err
-			}
-			nextNum = int(count) + 1
-		} else {
-			nextNum = num + 1
+			continue
+		}
+		if _, seen := byValue[value]; !seen {
+			values = append(values, value)
+		}
+		byValue[value] = append(byValue[value], number)
+	}
+	for _, value := range values {
+		if len(byValue[value]) > 1 {
+			report.Duplicates = append(report.Duplicates, byValue[value])
 		}
 	}
 
-	return fmt.Sprintf("EMP%05d", nextNum), nil
+	return report, nil
 }
 
 // GetByID retrieves an employee by ID
-func (s *EmployeeService) GetByID(id uint) (*model.Employee, error) {
-	employee, err := s.repo.GetByID(id)
+func (s *EmployeeService) GetByID(ctx context.Context, id uint) (*model.Employee, error) {
+	employee, err := s.repo.WithContext(ctx).GetByID(id)
 	if err != nil {
 		if errors.Is(err, repository.ErrEmployeeNotFound) {
 			return nil, ErrEmployeeNotFound
@@ -208,15 +401,79 @@ func (s *EmployeeService) GetByID(id uint) (*model.Employee, error) {
 	return employee, nil
 }
 
-// List retrieves all employees with optional filters
-func (s *EmployeeService) List(filters map[string]interface{}) ([]model.Employee, error) {
-	return s.repo.List(filters)
+// EmployeeSortOptions whitelists the fields the employee list can be sorted by
+var EmployeeSortOptions = pagination.SortOptions{
+	Columns: map[string]string{
+		"id":          "id",
+		"employee_no": "employee_no",
+		"name":        "name",
+		"department":  "department",
+		"hire_date":   "hire_date",
+	},
+	DefaultSort:  "id",
+	DefaultOrder: "asc",
+}
+
+// EmployeePage is a page of employees
+type EmployeePage struct {
+	Items []model.Employee `json:"items"`
+	pagination.PageMeta
+}
+
+// List retrieves a page of employees with optional filters
+func (s *EmployeeService) List(ctx context.Context, filters map[string]interface{}, params pagination.Params) (*EmployeePage, error) {
+	employees, total, err := s.repo.WithContext(ctx).ListPage(filters, params)
+	if err != nil {
+		return nil, err
+	}
+	return &EmployeePage{
+		Items:    employees,
+		PageMeta: params.Meta(total),
+	}, nil
+}
+
+// EmployeeStatistics summarizes headcount for HR
+type EmployeeStatistics struct {
+	Total        int64                      `json:"total"`
+	Active       int64                      `json:"active"`
+	Inactive     int64                      `json:"inactive"`
+	ByDepartment []repository.EmployeeCount `json:"by_department"`
+	ByRole       []repository.EmployeeCount `json:"by_role"`
+}
+
+// GetStatistics computes headcount overall, per department and per role
+// Employees without a department are grouped under an empty name
+func (s *EmployeeService) GetStatistics(ctx context.Context) (*EmployeeStatistics, error) {
+	repo := s.repo.WithContext(ctx)
+
+	total, err := repo.CountByStatus()
+	if err != nil {
+		return nil, err
+	}
+	byDepartment, err := repo.CountByDepartment()
+	if err != nil {
+		return nil, err
+	}
+	byRole, err := repo.CountByRole()
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmployeeStatistics{
+		Total:        total.Total,
+		Active:       total.Active,
+		Inactive:     total.Inactive,
+		ByDepartment: byDepartment,
+		ByRole:       byRole,
+	}, nil
 }
 
 // Update updates an employee's personal information (limited fields for self-update)
 // This enforces Property 3: System fields cannot be modified by the employee
-func (s *EmployeeService) Update(id uint, req *UpdateEmployeeRequest) (*model.Employee, error) {
-	employee, err := s.repo.GetByID(id)
+func (s *EmployeeService) Update(ctx context.Context, id uint, req *UpdateEmployeeRequest) (*model.Employee, error) {
+	repo := s.repo.WithContext(ctx)
+
+	employee, err := repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, repository.ErrEmployeeNotFound) {
 			return nil, ErrEmployeeNotFound
@@ -224,67 +481,96 @@ func (s *EmployeeService) Update(id uint, req *UpdateEmployeeRequest) (*model.Em
 		return nil, err
 	}
 
+	if err := s.validateEmail(ctx, req.Email, employee.ID); err != nil {
+		return nil, err
+	}
+
 	// Only allow updating phone and email (non-system fields)
 	employee.Phone = req.Phone
 	employee.Email = req.Email
 
-	if err := s.repo.Update(employee); err != nil {
-		return nil, err
+	if err := repo.Update(employee); err != nil {
+		return nil, translateEmployeeSaveError(err)
 	}
 
 	return employee, nil
 }
 
 // AdminUpdate updates an employee's information (by HR/Admin)
-func (s *EmployeeService) AdminUpdate(id uint, req *AdminUpdateEmployeeRequest) (*model.Employee, error) {
-	employee, err := s.repo.GetByID(id)
+func (s *EmployeeService) AdminUpdate(ctx context.Context, id uint, actorID uint, req *AdminUpdateEmployeeRequest) (*model.Employee, error) {
+	repo := s.repo.WithContext(ctx)
+
+	employee, err := repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, repository.ErrEmployeeNotFound) {
 			return nil, ErrEmployeeNotFound
 		}
 		return nil, err
 	}
+	if err := checkVersion(employee.Version, req.Version); err != nil {
+		return nil, err
+	}
 
-	// Validate supervisor if provided
-	if req.SupervisorID != nil {
-		_, err := s.repo.GetByID(*req.SupervisorID)
-		if err != nil {
-			if errors.Is(err, repository.ErrEmployeeNotFound) {
-				return nil, ErrSupervisorNotFound
-			}
-			return nil, err
-		}
+	if err := s.validateSupervisor(ctx, id, req.SupervisorID); err != nil {
+		return nil, err
 	}
 
 	// Update allowed fields
 	if req.Name != "" {
 		employee.Name = req.Name
 	}
-	if req.Department != "" {
-		employee.Department = req.Department
+	if req.DepartmentID != nil || req.Department != "" {
+		departmentID, department, err := s.resolveDepartment(ctx, req.DepartmentID, req.Department)
+		if err != nil {
+			return nil, err
+		}
+		employee.DepartmentID = departmentID
+		employee.Department = department
 	}
 	if req.Position != "" {
 		employee.Position = req.Position
 	}
+	if err := s.validateEmail(ctx, req.Email, employee.ID); err != nil {
+		return nil, err
+	}
 	employee.Phone = req.Phone
 	employee.Email = req.Email
+	oldSupervisorID := employee.SupervisorID
 	employee.SupervisorID = req.SupervisorID
 
-	if err := s.repo.Update(employee); err != nil {
+	if err := s.saveWithSupervisorChange(ctx, employee, oldSupervisorID, actorID); err != nil {
 		return nil, err
 	}
 
 	return employee, nil
 }
 
+// ensureNotLastSuperAdmin rejects taking the employee out of the super admin role when they are
+// the only active super admin left, which would lock everyone out of admin functions
+func (s *EmployeeService) ensureNotLastSuperAdmin(ctx context.Context, employee *model.Employee) error {
+	if employee.Role != model.RoleSuperAdmin || !employee.IsActive {
+		return nil
+	}
+
+	count, err := s.repo.WithContext(ctx).CountActiveByRole(model.RoleSuperAdmin)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return ErrLastSuperAdmin
+	}
+	return nil
+}
 
 // UpdateRole updates an employee's role
-func (s *EmployeeService) UpdateRole(id uint, req *UpdateRoleRequest) (*model.Employee, error) {
-	if !validRoles[req.Role] {
-		return nil, ErrInvalidRole
+func (s *EmployeeService) UpdateRole(ctx context.Context, id uint, actorID uint, req *UpdateRoleRequest) (*model.Employee, error) {
+	repo := s.repo.WithContext(ctx)
+
+	if err := s.validateRole(ctx, req.Role); err != nil {
+		return nil, err
 	}
 
-	employee, err := s.repo.GetByID(id)
+	employee, err := repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, repository.ErrEmployeeNotFound) {
 			return nil, ErrEmployeeNotFound
@@ -292,18 +578,131 @@ func (s *EmployeeService) UpdateRole(id uint, req *UpdateRoleRequest) (*model.Em
 		return nil, err
 	}
 
+	if req.Role != model.RoleSuperAdmin {
+		if err := s.ensureNotLastSuperAdmin(ctx, employee); err != nil {
+			return nil, err
+		}
+	}
+
+	oldRole := employee.Role
 	employee.Role = req.Role
 
-	if err := s.repo.Update(employee); err != nil {
-		return nil, err
+	if err := repo.Update(employee); err != nil {
+		return nil, translateVersionConflict(err)
 	}
 
+	s.auditService.Record(actorID, model.AuditActionEmployeeRoleUpdate, model.AuditTargetEmployee, employee.ID, map[string]interface{}{
+		"old_role": oldRole,
+		"new_role": employee.Role,
+	})
+
 	return employee, nil
 }
 
+// validateSupervisor checks that supervisorID, if set, is an existing employee other than id and
+// that id does not already appear in their reporting chain
+func (s *EmployeeService) validateSupervisor(ctx context.Context, id uint, supervisorID *uint) error {
+	if supervisorID == nil {
+		return nil
+	}
+	if *supervisorID == id {
+		return ErrSelfSupervisor
+	}
+
+	repo := s.repo.WithContext(ctx)
+	supervisor, err := repo.GetByID(*supervisorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return ErrSupervisorNotFound
+		}
+		return err
+	}
+
+	// Walk up from the new supervisor; reaching id means the assignment closes a loop
+	visited := map[uint]bool{supervisor.ID: true}
+	for next := supervisor.SupervisorID; next != nil; {
+		if *next == id {
+			return ErrCircularSupervisor
+		}
+		if visited[*next] {
+			// An existing cycle above the new supervisor that id is not part of
+			return nil
+		}
+		visited[*next] = true
+
+		manager, err := repo.GetByID(*next)
+		if err != nil {
+			if errors.Is(err, repository.ErrEmployeeNotFound) {
+				return nil
+			}
+			return err
+		}
+		next = manager.SupervisorID
+	}
+	return nil
+}
+
+// saveWithSupervisorChange persists the employee and, when its supervisor differs from
+// oldSupervisorID, records the change in the reporting line history and notifies the new supervisor
+func (s *EmployeeService) saveWithSupervisorChange(ctx context.Context, employee *model.Employee, oldSupervisorID *uint, actorID uint) error {
+	repo := s.repo.WithContext(ctx)
+
+	if sameID(oldSupervisorID, employee.SupervisorID) {
+		return translateEmployeeSaveError(repo.Update(employee))
+	}
+
+	// The preloaded supervisor no longer matches the new supervisor ID
+	employee.Supervisor = nil
+	change := &model.SupervisorChange{
+		EmployeeID:      employee.ID,
+		OldSupervisorID: oldSupervisorID,
+		NewSupervisorID: employee.SupervisorID,
+		ChangedBy:       actorID,
+	}
+	if err := repo.UpdateWithSupervisorChange(employee, change); err != nil {
+		return translateEmployeeSaveError(err)
+	}
+
+	if employee.SupervisorID != nil {
+		// Notification failure should not undo the reassignment
+		_ = s.notificationService.Notify(&model.Notification{
+			EmployeeID:  *employee.SupervisorID,
+			Type:        model.NotificationTypeSupervisorAssigned,
+			Title:       "新增直属下属",
+			Content:     "您已被指定为「" + employee.Name + "（" + employee.EmployeeNo + "）」的直属上级",
+			RelatedType: model.NotificationRelatedEmployee,
+			RelatedID:   employee.ID,
+		})
+	}
+	return nil
+}
+
+// sameID reports whether two optional IDs are both unset or equal
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// GetSupervisorHistory retrieves an employee's reporting line changes, newest first
+func (s *EmployeeService) GetSupervisorHistory(ctx context.Context, id uint) ([]model.SupervisorChange, error) {
+	repo := s.repo.WithContext(ctx)
+
+	if _, err := repo.GetByIDUnscoped(id); err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+	return repo.GetSupervisorChanges(id)
+}
+
 // UpdateSupervisor updates an employee's supervisor
-func (s *EmployeeService) UpdateSupervisor(id uint, req *UpdateSupervisorRequest) (*model.Employee, error) {
-	employee, err := s.repo.GetByID(id)
+func (s *EmployeeService) UpdateSupervisor(ctx context.Context, id uint, actorID uint, req *UpdateSupervisorRequest) (*model.Employee, error) {
+	repo := s.repo.WithContext(ctx)
+
+	employee, err := repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, repository.ErrEmployeeNotFound) {
 			return nil, ErrEmployeeNotFound
@@ -311,38 +710,72 @@ func (s *EmployeeService) UpdateSupervisor(id uint, req *UpdateSupervisorRequest
 		return nil, err
 	}
 
-	// Validate supervisor if provided
-	if req.SupervisorID != nil {
-		// Cannot set self as supervisor
-		if *req.SupervisorID == id {
-			return nil, errors.New("cannot set self as supervisor")
+	if err := s.validateSupervisor(ctx, id, req.SupervisorID); err != nil {
+		return nil, err
+	}
+
+	oldSupervisorID := employee.SupervisorID
+	employee.SupervisorID = req.SupervisorID
+
+	if err := s.saveWithSupervisorChange(ctx, employee, oldSupervisorID, actorID); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(actorID, model.AuditActionEmployeeSupervisorUpdate, model.AuditTargetEmployee, employee.ID, map[string]interface{}{
+		"old_supervisor_id": oldSupervisorID,
+		"new_supervisor_id": employee.SupervisorID,
+	})
+
+	return employee, nil
+}
+
+// UpdateDelegate sets or clears the employee who approves the supervisor's subordinates' leave
+func (s *EmployeeService) UpdateDelegate(ctx context.Context, id uint, req *UpdateDelegateRequest) (*model.Employee, error) {
+	repo := s.repo.WithContext(ctx)
+
+	employee, err := repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return nil, ErrEmployeeNotFound
 		}
-		_, err := s.repo.GetByID(*req.SupervisorID)
+		return nil, err
+	}
+
+	if req.DelegateApproverID != nil {
+		if *req.DelegateApproverID == id {
+			return nil, ErrInvalidDelegate
+		}
+		delegate, err := repo.GetByID(*req.DelegateApproverID)
 		if err != nil {
 			if errors.Is(err, repository.ErrEmployeeNotFound) {
-				return nil, ErrSupervisorNotFound
+				return nil, ErrInvalidDelegate
 			}
 			return nil, err
 		}
+		if !delegate.IsActive {
+			return nil, ErrInvalidDelegate
+		}
 	}
 
-	employee.SupervisorID = req.SupervisorID
+	employee.DelegateApproverID = req.DelegateApproverID
 
-	if err := s.repo.Update(employee); err != nil {
-		return nil, err
+	if err := repo.Update(employee); err != nil {
+		return nil, translateVersionConflict(err)
 	}
 
 	return employee, nil
 }
 
 // UpdateStatus enables or disables an employee account
-func (s *EmployeeService) UpdateStatus(id uint, currentUserID uint, req *UpdateStatusRequest) (*model.Employee, error) {
+func (s *EmployeeService) UpdateStatus(ctx context.Context, id uint, currentUserID uint, req *UpdateStatusRequest) (*model.Employee, error) {
+	repo := s.repo.WithContext(ctx)
+
 	// Cannot modify own account status
 	if id == currentUserID {
 		return nil, ErrCannotModifySelf
 	}
 
-	employee, err := s.repo.GetByID(id)
+	employee, err := repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, repository.ErrEmployeeNotFound) {
 			return nil, ErrEmployeeNotFound
@@ -355,28 +788,276 @@ func (s *EmployeeService) UpdateStatus(id uint, currentUserID uint, req *UpdateS
 		return nil, ErrCannotDisableSuperAdmin
 	}
 
-	employee.IsActive = req.IsActive
-
-	if err := s.repo.Update(employee); err != nil {
+	revoked, err := s.setActive(repo, employee, req.IsActive)
+	if err != nil {
 		return nil, err
 	}
 
+	s.auditService.Record(currentUserID, model.AuditActionEmployeeStatusUpdate, model.AuditTargetEmployee, employee.ID, map[string]interface{}{
+		"is_active": employee.IsActive,
+	})
+	if revoked {
+		s.recordTokensRevoked(currentUserID, employee, "account_disabled")
+	}
+
 	return employee, nil
 }
 
+// setActive enables or disables the employee through repo, which may be bound to a transaction.
+// Disabling revokes every token issued so far when that is enabled; the result reports whether it did
+func (s *EmployeeService) setActive(repo *repository.EmployeeRepository, employee *model.Employee, active bool) (bool, error) {
+	employee.IsActive = active
+
+	// Revoked tokens stay invalid when the account is enabled again; the employee has to log in anew
+	revokeTokens := !active && s.revokeOnDisable
+	if revokeTokens {
+		now := time.Now()
+		employee.TokensRevokedAt = &now
+	}
+
+	if err := repo.Update(employee); err != nil {
+		return false, translateVersionConflict(err)
+	}
+	return revokeTokens, nil
+}
+
+// recordTokensRevoked audits the revocation of the employee's tokens by setActive
+func (s *EmployeeService) recordTokensRevoked(actorID uint, employee *model.Employee, reason string) {
+	s.auditService.Record(actorID, model.AuditActionTokensRevoke, model.AuditTargetEmployee, employee.ID, map[string]interface{}{
+		"revoked_at": employee.TokensRevokedAt,
+		"reason":     reason,
+	})
+}
+
 // GetSubordinates retrieves all direct subordinates of a supervisor
-func (s *EmployeeService) GetSubordinates(supervisorID uint) ([]model.Employee, error) {
-	return s.repo.GetSubordinates(supervisorID)
+func (s *EmployeeService) GetSubordinates(ctx context.Context, supervisorID uint) ([]model.Employee, error) {
+	return s.repo.WithContext(ctx).GetSubordinates(supervisorID)
 }
 
-// Delete soft deletes an employee
-func (s *EmployeeService) Delete(id uint) error {
-	err := s.repo.Delete(id)
+// GetAllSubordinates retrieves direct and indirect subordinates of a supervisor,
+// walking the reporting tree one level per query and guarding against cycles
+func (s *EmployeeService) GetAllSubordinates(ctx context.Context, supervisorID uint) ([]model.Employee, error) {
+	visited := map[uint]bool{supervisorID: true}
+	subordinates := []model.Employee{}
+	level := []uint{supervisorID}
+
+	for len(level) > 0 {
+		reports, err := s.repo.WithContext(ctx).GetSubordinatesOf(level)
+		if err != nil {
+			return nil, err
+		}
+
+		level = level[:0]
+		for _, report := range reports {
+			if visited[report.ID] {
+				continue
+			}
+			visited[report.ID] = true
+			subordinates = append(subordinates, report)
+			level = append(level, report.ID)
+		}
+	}
+
+	return subordinates, nil
+}
+
+// Delete soft deletes an employee, refusing while they hold devices or active bookings;
+// with force, those bookings are cancelled and collected devices flagged for return first
+func (s *EmployeeService) Delete(ctx context.Context, id uint, actorID uint, force bool) error {
+	employee, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureNotLastSuperAdmin(ctx, employee); err != nil {
+		return err
+	}
+
+	blockers, err := s.activeAssets(ctx, id)
 	if err != nil {
+		return err
+	}
+	if len(blockers) > 0 && !force {
+		return fmt.Errorf("%w: %s", ErrEmployeeHasActiveAssets, strings.Join(blockers, "; "))
+	}
+
+	var cancelled []model.MeetingRoomBooking
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(blockers) > 0 {
+			bookingRepo := repository.NewMeetingRoomBookingRepository(tx)
+			var err error
+			if cancelled, err = bookingRepo.GetActiveByEmployee(id); err != nil {
+				return err
+			}
+			if err := bookingRepo.CancelActiveByEmployee(id); err != nil {
+				return err
+			}
+			if err := repository.NewDeviceRequestRepository(tx).FlagCollectedForReturn(id); err != nil {
+				return err
+			}
+		}
+
+		err := repository.NewEmployeeRepository(tx).Delete(id)
 		if errors.Is(err, repository.ErrEmployeeNotFound) {
 			return ErrEmployeeNotFound
 		}
 		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.meetingRoomService.releaseSlots(cancelled)
+
+	s.auditService.Record(actorID, model.AuditActionEmployeeDelete, model.AuditTargetEmployee, id, map[string]interface{}{
+		"force":           force,
+		"released_assets": blockers,
+	})
+	return nil
+}
+
+// activeAssets describes the devices and bookings that block deleting an employee
+func (s *EmployeeService) activeAssets(ctx context.Context, id uint) ([]string, error) {
+	var blockers []string
+
+	requests, err := s.deviceRequestRepo.WithContext(ctx).GetHeldByEmployee(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range requests {
+		blockers = append(blockers, fmt.Sprintf("device %q (request #%d, %s)", request.Device.Name, request.ID, request.Status))
+	}
+
+	bookings, err := s.bookingRepo.WithContext(ctx).GetActiveByEmployee(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, booking := range bookings {
+		blockers = append(blockers, fmt.Sprintf("booking #%d of %q on %s %s-%s",
+			booking.ID, booking.MeetingRoom.Name, booking.BookingDate.Format("2006-01-02"), booking.StartTime, booking.EndTime))
+	}
+
+	return blockers, nil
+}
+
+// Restore brings back a soft-deleted employee
+func (s *EmployeeService) Restore(ctx context.Context, id uint) (*model.Employee, error) {
+	repo := s.repo.WithContext(ctx)
+
+	employee, err := repo.GetByIDUnscoped(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+	if !employee.DeletedAt.Valid {
+		return nil, ErrEmployeeNotDeleted
+	}
+
+	if err := repo.Restore(id); err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return nil, ErrEmployeeNotDeleted
+		}
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// UploadAvatar validates and stores an employee's profile photo, replacing any previous one
+// The content type is sniffed from the file contents rather than trusted from the client
+func (s *EmployeeService) UploadAvatar(ctx context.Context, id uint, file *multipart.FileHeader) (*model.Employee, error) {
+	repo := s.repo.WithContext(ctx)
+
+	employee, err := repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	if file.Size > s.avatarMaxSize {
+		return nil, ErrAvatarTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	ext, ok := allowedAvatarTypes[http.DetectContentType(head)]
+	if !ok {
+		return nil, ErrAvatarTypeNotAllowed
+	}
+
+	name, err := randomFileName(ext)
+	if err != nil {
+		return nil, err
+	}
+	relPath := filepath.Join(strconv.FormatUint(uint64(id), 10), name)
+	absPath := filepath.Join(s.avatarDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+		return nil, err
+	}
+	if err := s.writeAvatar(absPath, io.MultiReader(bytes.NewReader(head), src)); err != nil {
+		os.Remove(absPath)
+		return nil, err
+	}
+
+	if err := repo.UpdateFields(id, map[string]interface{}{"avatar_path": relPath}); err != nil {
+		os.Remove(absPath)
+		return nil, err
+	}
+	// The previous photo is no longer referenced; failing to remove it only leaves a stray file
+	if employee.AvatarPath != "" {
+		os.Remove(filepath.Join(s.avatarDir, employee.AvatarPath))
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// GetAvatarPath returns the on-disk location of an employee's profile photo
+func (s *EmployeeService) GetAvatarPath(ctx context.Context, id uint) (string, error) {
+	employee, err := s.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if employee.AvatarPath == "" {
+		return "", ErrAvatarNotFound
+	}
+
+	absPath := filepath.Join(s.avatarDir, employee.AvatarPath)
+	if _, err := os.Stat(absPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrAvatarNotFound
+		}
+		return "", err
+	}
+	return absPath, nil
+}
+
+// writeAvatar copies at most avatarMaxSize bytes to path, failing if the source is larger
+func (s *EmployeeService) writeAvatar(path string, src io.Reader) error {
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	size, err := io.Copy(dst, io.LimitReader(src, s.avatarMaxSize+1))
+	if err != nil {
+		return err
+	}
+	if size > s.avatarMaxSize {
+		return ErrAvatarTooLarge
 	}
 	return nil
 }
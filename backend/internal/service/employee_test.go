@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/pagination"
+)
+
+func newEmployeeService(t *testing.T, db *gorm.DB) *EmployeeService {
+	return NewEmployeeService(db, &config.AvatarConfig{StorageDir: t.TempDir(), MaxSizeMB: 1}, &config.BookingConfig{})
+}
+
+func TestDuplicateEmail(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	ctx := context.Background()
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+
+	first, err := s.Create(ctx, &CreateEmployeeRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := s.Create(ctx, &CreateEmployeeRequest{Name: "Alice Two", Email: "alice@example.com"}); !errors.Is(err, ErrEmailExists) {
+		t.Errorf("duplicate email: err = %v, want ErrEmailExists", err)
+	}
+	second, err := s.Create(ctx, &CreateEmployeeRequest{Name: "Bob"})
+	if err != nil {
+		t.Fatalf("Create without email: %v", err)
+	}
+	if _, err := s.Create(ctx, &CreateEmployeeRequest{Name: "Carol"}); err != nil {
+		t.Errorf("second employee without email: %v", err)
+	}
+	if _, err := s.AdminUpdate(ctx, second.Employee.ID, hr.ID, &AdminUpdateEmployeeRequest{Email: first.Employee.Email}); !errors.Is(err, ErrEmailExists) {
+		t.Errorf("AdminUpdate to a taken email: err = %v, want ErrEmailExists", err)
+	}
+
+	// An insert that slips past the service check, as a concurrent create would, hits the unique index
+	racer := &model.Employee{Username: "racer", EmployeeNo: "T-racer", Name: "Racer", Email: "alice@example.com", IsActive: true}
+	if err := repository.NewEmployeeRepository(db).Create(racer); !errors.Is(err, repository.ErrEmailExists) {
+		t.Errorf("racing insert: err = %v, want repository.ErrEmailExists", err)
+	}
+}
+
+func TestRestoreEmployee(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	ctx := context.Background()
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	listed := func() bool {
+		t.Helper()
+		page, err := s.List(ctx, map[string]interface{}{}, pagination.Params{Page: 1, PageSize: 20, Column: "id", Order: "ASC"})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		for _, employee := range page.Items {
+			if employee.ID == alice.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	if _, err := s.Restore(ctx, alice.ID); !errors.Is(err, ErrEmployeeNotDeleted) {
+		t.Errorf("restore of an active employee: err = %v, want ErrEmployeeNotDeleted", err)
+	}
+	if err := s.Delete(ctx, alice.ID, hr.ID, false); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if listed() {
+		t.Error("deleted employee is still listed")
+	}
+	restored, err := s.Restore(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.ID != alice.ID || !listed() {
+		t.Error("restored employee is not listed")
+	}
+}
+
+func TestDeleteEmployeeWithActiveAssets(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	ctx := context.Background()
+	admin := testutil.CreateEmployee(t, db, "admin", model.RoleSuperAdmin)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	request := createDeviceRequest(t, db, alice.ID, createDevice(t, db, "ThinkPad", 1).ID, model.DeviceRequestStatusCollected)
+	booking := createBooking(t, db, alice.ID, createRoom(t, db, "A", 6).ID, Today().AddDate(0, 0, 1), "10:00", "11:00")
+
+	if err := s.Delete(ctx, alice.ID, admin.ID, false); !errors.Is(err, ErrEmployeeHasActiveAssets) {
+		t.Fatalf("Delete: err = %v, want ErrEmployeeHasActiveAssets", err)
+	}
+	if _, err := s.GetByID(ctx, alice.ID); err != nil {
+		t.Errorf("blocked delete removed the employee: %v", err)
+	}
+
+	if err := s.Delete(ctx, alice.ID, admin.ID, true); err != nil {
+		t.Fatalf("forced Delete: %v", err)
+	}
+	if _, err := s.GetByID(ctx, alice.ID); !errors.Is(err, ErrEmployeeNotFound) {
+		t.Errorf("after forced delete: err = %v, want ErrEmployeeNotFound", err)
+	}
+	if status := bookingStatus(t, db, booking.ID); status != model.BookingStatusCancelled {
+		t.Errorf("booking status = %q, want cancelled", status)
+	}
+	var stored model.DeviceRequest
+	if err := db.First(&stored, request.ID).Error; err != nil {
+		t.Fatalf("load request: %v", err)
+	}
+	if stored.Status != model.DeviceRequestStatusReturnPending {
+		t.Errorf("device request status = %q, want return_pending", stored.Status)
+	}
+}
+
+func TestEmployeeStatistics(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	for _, fixture := range []struct {
+		username, role, department string
+		active                     bool
+	}{
+		{"alice", model.RoleEmployee, "Engineering", true},
+		{"bob", model.RoleEmployee, "Engineering", false},
+		{"carol", model.RoleSupervisor, "Engineering", true},
+		{"dave", model.RoleEmployee, "Sales", true},
+		{"erin", model.RoleHR, "", false},
+	} {
+		employee := testutil.CreateEmployee(t, db, fixture.username, fixture.role)
+		if err := db.Model(employee).Updates(map[string]interface{}{"department": fixture.department, "is_active": fixture.active}).Error; err != nil {
+			t.Fatalf("update %s: %v", fixture.username, err)
+		}
+	}
+	// Deleted employees are left out of every count
+	gone := testutil.CreateEmployee(t, db, "gone", model.RoleEmployee)
+	if err := db.Delete(gone).Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	stats, err := s.GetStatistics(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatistics: %v", err)
+	}
+	if stats.Total != 5 || stats.Active != 3 || stats.Inactive != 2 {
+		t.Errorf("totals = %d/%d/%d, want 5/3/2", stats.Total, stats.Active, stats.Inactive)
+	}
+	wantDepartments := []repository.EmployeeCount{
+		{Name: "", Total: 1, Active: 0, Inactive: 1},
+		{Name: "Engineering", Total: 3, Active: 2, Inactive: 1},
+		{Name: "Sales", Total: 1, Active: 1, Inactive: 0},
+	}
+	if !slices.Equal(stats.ByDepartment, wantDepartments) {
+		t.Errorf("by department = %+v, want %+v", stats.ByDepartment, wantDepartments)
+	}
+	wantRoles := []repository.EmployeeCount{
+		{Name: model.RoleEmployee, Total: 3, Active: 2, Inactive: 1},
+		{Name: model.RoleHR, Total: 1, Active: 0, Inactive: 1},
+		{Name: model.RoleSupervisor, Total: 1, Active: 1, Inactive: 0},
+	}
+	if !slices.Equal(stats.ByRole, wantRoles) {
+		t.Errorf("by role = %+v, want %+v", stats.ByRole, wantRoles)
+	}
+}
+
+func TestAdminUpdateSupervisorCycle(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	ctx := context.Background()
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleSupervisor)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+
+	if _, err := s.AdminUpdate(ctx, alice.ID, hr.ID, &AdminUpdateEmployeeRequest{SupervisorID: &alice.ID}); !errors.Is(err, ErrSelfSupervisor) {
+		t.Errorf("self as supervisor: err = %v, want ErrSelfSupervisor", err)
+	}
+	if _, err := s.AdminUpdate(ctx, bob.ID, hr.ID, &AdminUpdateEmployeeRequest{SupervisorID: &alice.ID}); err != nil {
+		t.Fatalf("bob reports to alice: %v", err)
+	}
+	if _, err := s.AdminUpdate(ctx, alice.ID, hr.ID, &AdminUpdateEmployeeRequest{SupervisorID: &bob.ID}); !errors.Is(err, ErrCircularSupervisor) {
+		t.Errorf("two-node cycle: err = %v, want ErrCircularSupervisor", err)
+	}
+
+	var stored model.Employee
+	if err := db.First(&stored, alice.ID).Error; err != nil {
+		t.Fatalf("load alice: %v", err)
+	}
+	if stored.SupervisorID != nil {
+		t.Errorf("rejected updates left alice reporting to %d", *stored.SupervisorID)
+	}
+}
+
+func TestSupervisorHistory(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	ctx := context.Background()
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	oldBoss := testutil.CreateEmployee(t, db, "old", model.RoleSupervisor)
+	newBoss := testutil.CreateEmployee(t, db, "new", model.RoleSupervisor)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	if err := db.Model(alice).Update("supervisor_id", oldBoss.ID).Error; err != nil {
+		t.Fatalf("set supervisor: %v", err)
+	}
+
+	if _, err := s.UpdateSupervisor(ctx, alice.ID, hr.ID, &UpdateSupervisorRequest{SupervisorID: &newBoss.ID}); err != nil {
+		t.Fatalf("UpdateSupervisor: %v", err)
+	}
+	// Saving the same supervisor again is not a reassignment
+	if _, err := s.AdminUpdate(ctx, alice.ID, hr.ID, &AdminUpdateEmployeeRequest{SupervisorID: &newBoss.ID}); err != nil {
+		t.Fatalf("AdminUpdate: %v", err)
+	}
+
+	history, err := s.GetSupervisorHistory(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("GetSupervisorHistory: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("history rows = %d, want 1", len(history))
+	}
+	change := history[0]
+	if change.OldSupervisorID == nil || *change.OldSupervisorID != oldBoss.ID || change.NewSupervisorID == nil || *change.NewSupervisorID != newBoss.ID || change.ChangedBy != hr.ID {
+		t.Errorf("change = %v -> %v by %d, want %d -> %d by %d", change.OldSupervisorID, change.NewSupervisorID, change.ChangedBy, oldBoss.ID, newBoss.ID, hr.ID)
+	}
+	if got := countNotifications(t, db, newBoss.ID, model.NotificationTypeSupervisorAssigned); got != 1 {
+		t.Errorf("new supervisor notifications = %d, want 1", got)
+	}
+	if got := countNotifications(t, db, oldBoss.ID, model.NotificationTypeSupervisorAssigned); got != 0 {
+		t.Errorf("old supervisor notifications = %d, want 0", got)
+	}
+}
+
+func TestLastSuperAdmin(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	ctx := context.Background()
+	if err := db.Create(&model.Role{Name: model.RoleEmployee, IsSystem: true}).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	root := testutil.CreateEmployee(t, db, "root", model.RoleSuperAdmin)
+	demote := &UpdateRoleRequest{Role: model.RoleEmployee}
+
+	if _, err := s.UpdateRole(ctx, root.ID, root.ID, demote); !errors.Is(err, ErrLastSuperAdmin) {
+		t.Errorf("demote the sole super admin: err = %v, want ErrLastSuperAdmin", err)
+	}
+	if err := s.Delete(ctx, root.ID, root.ID, false); !errors.Is(err, ErrLastSuperAdmin) {
+		t.Errorf("delete the sole super admin: err = %v, want ErrLastSuperAdmin", err)
+	}
+
+	second := testutil.CreateEmployee(t, db, "second", model.RoleSuperAdmin)
+	demoted, err := s.UpdateRole(ctx, second.ID, root.ID, demote)
+	if err != nil {
+		t.Fatalf("demote one of two super admins: %v", err)
+	}
+	if demoted.Role != model.RoleEmployee {
+		t.Errorf("role = %q, want employee", demoted.Role)
+	}
+	if _, err := s.UpdateRole(ctx, root.ID, root.ID, demote); !errors.Is(err, ErrLastSuperAdmin) {
+		t.Errorf("demote the remaining super admin: err = %v, want ErrLastSuperAdmin", err)
+	}
+}
+
+// numberEmployee inserts an employee and gives them the employee number no
+func numberEmployee(t *testing.T, db *gorm.DB, username, no string) *model.Employee {
+	t.Helper()
+	employee := testutil.CreateEmployee(t, db, username, model.RoleEmployee)
+	if err := db.Model(employee).Update("employee_no", no).Error; err != nil {
+		t.Fatalf("set employee number of %s: %v", username, err)
+	}
+	employee.EmployeeNo = no
+	return employee
+}
+
+func TestEmployeeNumberGeneration(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	ctx := context.Background()
+	numberEmployee(t, db, "nine", "EMP09999")
+	// A string sort would rank EMP09999 first; the numbers stay reserved after a soft delete
+	gone := numberEmployee(t, db, "gone", "EMP99999")
+	if err := db.Delete(gone).Error; err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+
+	if next, err := s.PreviewNextEmployeeNo(ctx); err != nil || next != "EMP100000" {
+		t.Fatalf("PreviewNextEmployeeNo = %q, %v; want EMP100000", next, err)
+	}
+	created, err := s.Create(ctx, &CreateEmployeeRequest{Name: "Alice"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.Employee.EmployeeNo != "EMP100000" || created.Employee.Username != "emp100000" {
+		t.Errorf("created = %s/%s, want EMP100000/emp100000", created.Employee.EmployeeNo, created.Employee.Username)
+	}
+
+	// Someone already holds the username the next number derives, so Create moves past it
+	testutil.CreateEmployee(t, db, "emp100001", model.RoleEmployee)
+	created, err = s.Create(ctx, &CreateEmployeeRequest{Name: "Bob"})
+	if err != nil {
+		t.Fatalf("Create past a taken username: %v", err)
+	}
+	if created.Employee.EmployeeNo != "EMP100002" {
+		t.Errorf("employee number = %s, want EMP100002", created.Employee.EmployeeNo)
+	}
+}
+
+func TestEmployeeNumberReport(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newEmployeeService(t, db)
+	short := numberEmployee(t, db, "short", "EMP0042")
+	padded := numberEmployee(t, db, "padded", "EMP00042")
+	odd := numberEmployee(t, db, "odd", "X-7")
+	numberEmployee(t, db, "fine", "EMP00043")
+	if err := db.Delete(padded).Error; err != nil {
+		t.Fatalf("delete employee: %v", err)
+	}
+
+	report, err := s.GetEmployeeNumberReport(context.Background())
+	if err != nil {
+		t.Fatalf("GetEmployeeNumberReport: %v", err)
+	}
+	if report.NextEmployeeNo != "EMP00044" {
+		t.Errorf("next = %s, want EMP00044", report.NextEmployeeNo)
+	}
+	var malformed []string
+	for _, number := range report.Malformed {
+		malformed = append(malformed, number.EmployeeNo)
+	}
+	if !slices.Equal(malformed, []string{short.EmployeeNo, odd.EmployeeNo}) {
+		t.Errorf("malformed = %v, want [EMP0042 X-7]", malformed)
+	}
+	if len(report.Duplicates) != 1 || len(report.Duplicates[0]) != 2 || report.Duplicates[0][0].ID != short.ID || report.Duplicates[0][1].ID != padded.ID || !report.Duplicates[0][1].Deleted {
+		t.Errorf("duplicates = %+v, want EMP0042 with the deleted EMP00042", report.Duplicates)
+	}
+
+	// The report only reads
+	var stored model.Employee
+	if err := db.First(&stored, short.ID).Error; err != nil {
+		t.Fatalf("load employee: %v", err)
+	}
+	if stored.EmployeeNo != "EMP0042" {
+		t.Errorf("employee number = %s, want EMP0042 unchanged", stored.EmployeeNo)
+	}
+}
+
+func TestConcurrentCreateEmployee(t *testing.T) {
+	db := testutil.NewFileDB(t)
+	s := newEmployeeService(t, db)
+	const creates = 8
+
+	var wg sync.WaitGroup
+	numbers := make([]string, creates)
+	errs := make([]error, creates)
+	for i := range creates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			created, err := s.Create(context.Background(), &CreateEmployeeRequest{Name: fmt.Sprintf("Employee %d", i)})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			numbers[i] = created.Employee.EmployeeNo
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("create %d: %v", i, err)
+		}
+	}
+	slices.Sort(numbers)
+	if got := slices.Compact(slices.Clone(numbers)); len(got) != creates {
+		t.Errorf("employee numbers = %v, want %d distinct", numbers, creates)
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+var (
+	ErrHolidayNotFound    = errors.New("holiday not found")
+	ErrHolidayDateExists  = errors.New("a holiday is already defined for this date")
+	ErrHolidayInvalidDate = errors.New("invalid holiday date, expected YYYY-MM-DD")
+)
+
+// HolidayService handles the holiday calendar and working-day calculations
+type HolidayService struct {
+	repo *repository.HolidayRepository
+	db   *gorm.DB
+}
+
+// NewHolidayService creates a new holiday service
+func NewHolidayService(db *gorm.DB) *HolidayService {
+	return &HolidayService{
+		repo: repository.NewHolidayRepository(db),
+		db:   db,
+	}
+}
+
+// CreateHolidayRequest represents the request to create a holiday
+type CreateHolidayRequest struct {
+	Date      string `json:"date" binding:"required"` // YYYY-MM-DD format
+	Name      string `json:"name" binding:"required,max=100"`
+	IsWorkday bool   `json:"is_workday"` // marks a make-up working day instead of a day off
+}
+
+// UpdateHolidayRequest represents the request to update a holiday
+type UpdateHolidayRequest struct {
+	Date      string `json:"date"` // YYYY-MM-DD format
+	Name      string `json:"name" binding:"max=100"`
+	IsWorkday *bool  `json:"is_workday"`
+}
+
+// Create creates a new holiday
+func (s *HolidayService) Create(req *CreateHolidayRequest) (*model.Holiday, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, ErrHolidayInvalidDate
+	}
+
+	exists, err := s.repo.ExistsByDate(date, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrHolidayDateExists
+	}
+
+	holiday := &model.Holiday{
+		Date:      date,
+		Name:      req.Name,
+		IsWorkday: req.IsWorkday,
+	}
+	if err := s.repo.Create(holiday); err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// List retrieves the holidays of a year, defaulting to the current year
+func (s *HolidayService) List(year int) ([]model.Holiday, error) {
+	if year == 0 {
+		year = Today().Year()
+	}
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	return s.repo.GetInRange(start, end)
+}
+
+// GetByID retrieves a holiday by ID
+func (s *HolidayService) GetByID(id uint) (*model.Holiday, error) {
+	holiday, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrHolidayNotFound) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// Update updates a holiday
+func (s *HolidayService) Update(id uint, req *UpdateHolidayRequest) (*model.Holiday, error) {
+	holiday, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrHolidayNotFound) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, err
+	}
+
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, ErrHolidayInvalidDate
+		}
+		exists, err := s.repo.ExistsByDate(date, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrHolidayDateExists
+		}
+		holiday.Date = date
+	}
+	if req.Name != "" {
+		holiday.Name = req.Name
+	}
+	if req.IsWorkday != nil {
+		holiday.IsWorkday = *req.IsWorkday
+	}
+
+	if err := s.repo.Update(holiday); err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// Delete deletes a holiday
+func (s *HolidayService) Delete(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		if errors.Is(err, repository.ErrHolidayNotFound) {
+			return ErrHolidayNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// CalculateWorkingDays counts the working days between start and end inclusive
+// Weekdays are working days and weekends are not, unless a holiday record overrides the date:
+// a public holiday removes a weekday, a make-up day (IsWorkday) adds a weekend day
+func (s *HolidayService) CalculateWorkingDays(start, end time.Time) (int, error) {
+	dates, err := s.WorkingDates(start, end)
+	return len(dates), err
+}
+
+// WorkingDates lists the working days between start and end inclusive, by the same rules as CalculateWorkingDays
+func (s *HolidayService) WorkingDates(start, end time.Time) ([]time.Time, error) {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if end.Before(start) {
+		return nil, nil
+	}
+
+	holidays, err := s.repo.GetInRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]bool, len(holidays))
+	for _, h := range holidays {
+		overrides[h.Date.Format("2006-01-02")] = h.IsWorkday
+	}
+
+	var dates []time.Time
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if isWorkday(d, overrides) {
+			dates = append(dates, d)
+		}
+	}
+	return dates, nil
+}
+
+// isWorkday reports whether the date is a working day given the holiday overrides keyed by YYYY-MM-DD
+func isWorkday(date time.Time, overrides map[string]bool) bool {
+	if workday, ok := overrides[date.Format("2006-01-02")]; ok {
+		return workday
+	}
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
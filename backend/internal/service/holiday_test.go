@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+func TestHolidayWorkingDays(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewHolidayService(db)
+
+	// Monday 2026-03-02 to Friday 2026-03-13: ten weekdays
+	if days, err := s.CalculateWorkingDays(date(2026, 3, 2), date(2026, 3, 13)); err != nil || days != 10 {
+		t.Fatalf("without holidays = %d (%v), want 10", days, err)
+	}
+	for _, req := range []CreateHolidayRequest{
+		{Date: "2026-03-04", Name: "公共假日"},
+		{Date: "2026-03-13", Name: "公共假日"},
+		{Date: "2026-03-07", Name: "调休上班", IsWorkday: true},
+	} {
+		if _, err := s.Create(&req); err != nil {
+			t.Fatalf("Create %s: %v", req.Date, err)
+		}
+	}
+	if _, err := s.Create(&CreateHolidayRequest{Date: "2026-03-04", Name: "重复"}); !errors.Is(err, ErrHolidayDateExists) {
+		t.Errorf("duplicate date: err = %v, want ErrHolidayDateExists", err)
+	}
+
+	// Two holidays drop out, the Saturday make-up day counts
+	if days, err := s.CalculateWorkingDays(date(2026, 3, 2), date(2026, 3, 13)); err != nil || days != 9 {
+		t.Errorf("with holidays = %d (%v), want 9", days, err)
+	}
+	if days, err := s.CalculateWorkingDays(date(2026, 3, 7), date(2026, 3, 8)); err != nil || days != 1 {
+		t.Errorf("make-up weekend = %d (%v), want 1", days, err)
+	}
+}
+
+func TestLeaveWorkingDaysSkipHolidays(t *testing.T) {
+	db := testutil.NewDB(t)
+	leaves := newLeaveService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	monday := Today().AddDate(0, 0, 14)
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, 1)
+	}
+	wednesday, friday := monday.AddDate(0, 0, 2), monday.AddDate(0, 0, 4)
+	if _, err := NewHolidayService(db).Create(&CreateHolidayRequest{Date: wednesday.Format("2006-01-02"), Name: "公共假日"}); err != nil {
+		t.Fatalf("Create holiday: %v", err)
+	}
+
+	leave, err := leaves.Create(alice.ID, &CreateLeaveRequest{LeaveType: model.LeaveTypeAnnual, StartDate: monday.Format("2006-01-02"), EndDate: friday.Format("2006-01-02")})
+	if err != nil {
+		t.Fatalf("Create leave: %v", err)
+	}
+	if leave.WorkingDays != 4 {
+		t.Errorf("working days = %d, want 4", leave.WorkingDays)
+	}
+}
@@ -2,35 +2,64 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 
+	"oa-system/config"
 	"oa-system/internal/model"
 	"oa-system/internal/repository"
 )
 
 var (
-	ErrLeaveRequestNotFound     = errors.New("leave request not found")
-	ErrLeaveInvalidStatus       = errors.New("leave request status does not allow this operation")
-	ErrLeaveInvalidDateRange    = errors.New("invalid date range: end date must be after or equal to start date")
-	ErrLeaveNotSubordinate      = errors.New("can only approve/reject leave requests from subordinates")
-	ErrLeaveSelfApproval        = errors.New("cannot approve/reject own leave request")
+	ErrLeaveRequestNotFound       = errors.New("leave request not found")
+	ErrLeaveInvalidStatus         = errors.New("leave request status does not allow this operation")
+	ErrLeaveInvalidDateRange      = errors.New("invalid date range: end date must be after or equal to start date")
+	ErrLeaveNotSubordinate        = errors.New("can only approve/reject leave requests from subordinates")
+	ErrLeaveSelfApproval          = errors.New("cannot approve/reject own leave request")
+	ErrLeaveInvalidDateFormat     = errors.New("invalid date format, expected YYYY-MM-DD")
+	ErrLeaveAccessDenied          = errors.New("no access to this leave request")
+	ErrLeaveStartInPast           = errors.New("leave cannot start in the past")
+	ErrLeaveExceedsMax            = errors.New("leave exceeds the maximum consecutive days for its type")
+	ErrAttendanceIssuesBlockLeave = errors.New("unresolved attendance issues block new leave requests")
+	ErrLeaveOverlap               = errors.New("leave overlaps another pending or approved leave request")
 )
 
 // LeaveService handles leave request business logic
 type LeaveService struct {
-	leaveRepo    *repository.LeaveRepository
-	employeeRepo *repository.EmployeeRepository
-	db           *gorm.DB
+	leaveRepo      *repository.LeaveRepository
+	employeeRepo   *repository.EmployeeRepository
+	attendanceRepo *repository.AttendanceRepository
+	holidayService *HolidayService
+	db             *gorm.DB
+	backdateDays   int
+	backdateTypes  map[string]bool // leave types that may start up to backdateDays before today
+	maxDays        map[string]int  // working-day limit per request by leave type; types without a positive limit are unlimited
+
+	blockOnMissingSignOuts  bool
+	missingSignOutThreshold int
 }
 
 // NewLeaveService creates a new leave service
-func NewLeaveService(db *gorm.DB) *LeaveService {
+func NewLeaveService(db *gorm.DB, cfg *config.LeaveConfig) *LeaveService {
+	backdateTypes := make(map[string]bool, len(cfg.BackdateTypes))
+	for _, leaveType := range cfg.BackdateTypes {
+		backdateTypes[leaveType] = true
+	}
+
 	return &LeaveService{
-		leaveRepo:    repository.NewLeaveRepository(db),
-		employeeRepo: repository.NewEmployeeRepository(db),
-		db:           db,
+		leaveRepo:      repository.NewLeaveRepository(db),
+		employeeRepo:   repository.NewEmployeeRepository(db),
+		attendanceRepo: repository.NewAttendanceRepository(db),
+		holidayService: NewHolidayService(db),
+		db:             db,
+		backdateDays:   cfg.BackdateDays,
+		backdateTypes:  backdateTypes,
+		maxDays:        cfg.MaxDays,
+
+		blockOnMissingSignOuts:  cfg.BlockOnMissingSignOuts,
+		missingSignOutThreshold: cfg.MissingSignOutThreshold,
 	}
 }
 
@@ -42,33 +71,70 @@ type CreateLeaveRequest struct {
 	Reason    string `json:"reason"`
 }
 
+// UpdateLeaveRequest represents the request to edit a pending leave
+type UpdateLeaveRequest struct {
+	LeaveType string `json:"leave_type" binding:"required"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Reason    string `json:"reason"`
+}
+
 // RejectLeaveRequest represents the request to reject a leave
 type RejectLeaveRequest struct {
 	RejectReason string `json:"reject_reason" binding:"required"`
 }
 
+// BulkApproveLeaveRequest represents the request to approve many leave requests at once
+type BulkApproveLeaveRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1"`
+}
+
+// BulkRejectLeaveRequest represents the request to reject many leave requests with a shared reason
+type BulkRejectLeaveRequest struct {
+	IDs          []uint `json:"ids" binding:"required,min=1"`
+	RejectReason string `json:"reject_reason" binding:"required"`
+}
+
+// BulkLeaveResult represents the outcome for a single leave request in a bulk decision
+// Status is the leave's new status, or BatchEntryError when it was left unchanged
+type BulkLeaveResult struct {
+	ID     uint                `json:"id"`
+	Status string              `json:"status"`
+	Error  string              `json:"error,omitempty"`
+	Leave  *model.LeaveRequest `json:"leave,omitempty"`
+}
+
+// BulkLeaveResponse summarizes a bulk approval or rejection
+type BulkLeaveResponse struct {
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BulkLeaveResult `json:"results"`
+}
+
+
+// CalendarLeave is a single approved leave in the team calendar
+type CalendarLeave struct {
+	ID        uint   `json:"id"`
+	LeaveType string `json:"leave_type"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// CalendarEntry groups an employee's approved leaves in the team calendar
+type CalendarEntry struct {
+	EmployeeID   uint            `json:"employee_id"`
+	EmployeeNo   string          `json:"employee_no"`
+	EmployeeName string          `json:"employee_name"`
+	Department   string          `json:"department"`
+	Leaves       []CalendarLeave `json:"leaves"`
+}
 
 // Create creates a new leave request
 // Implements Requirement 5.1: Employee submits leave request with type, dates, and reason
 func (s *LeaveService) Create(employeeID uint, req *CreateLeaveRequest) (*model.LeaveRequest, error) {
-	// Parse date strings
-	startDate, err := time.Parse("2006-01-02", req.StartDate)
-	if err != nil {
-		return nil, errors.New("invalid start date format, expected YYYY-MM-DD")
-	}
-	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	startDate, endDate, workingDays, err := s.validateLeave(req.LeaveType, req.StartDate, req.EndDate)
 	if err != nil {
-		return nil, errors.New("invalid end date format, expected YYYY-MM-DD")
-	}
-
-	// Validate date range
-	if endDate.Before(startDate) {
-		return nil, ErrLeaveInvalidDateRange
-	}
-
-	// Validate leave type
-	if !isValidLeaveType(req.LeaveType) {
-		return nil, errors.New("invalid leave type")
+		return nil, err
 	}
 
 	// Check if the employee is a super admin
@@ -77,6 +143,10 @@ func (s *LeaveService) Create(employeeID uint, req *CreateLeaveRequest) (*model.
 		return nil, err
 	}
 
+	if err := s.checkMissingSignOuts(employeeID, Today()); err != nil {
+		return nil, err
+	}
+
 	// Super admin's leave requests are auto-approved
 	status := model.LeaveStatusPending
 	if employee.Role == model.RoleSuperAdmin {
@@ -84,12 +154,13 @@ func (s *LeaveService) Create(employeeID uint, req *CreateLeaveRequest) (*model.
 	}
 
 	leave := &model.LeaveRequest{
-		EmployeeID: employeeID,
-		LeaveType:  req.LeaveType,
-		StartDate:  startDate,
-		EndDate:    endDate,
-		Reason:     req.Reason,
-		Status:     status,
+		EmployeeID:  employeeID,
+		LeaveType:   req.LeaveType,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Reason:      req.Reason,
+		Status:      status,
+		WorkingDays: workingDays,
 	}
 
 	if err := s.leaveRepo.Create(leave); err != nil {
@@ -101,6 +172,105 @@ func (s *LeaveService) Create(employeeID uint, req *CreateLeaveRequest) (*model.
 	return leave, nil
 }
 
+// Update edits the type, dates and reason of one of the employee's pending leave requests,
+// re-running the date and maximum-length checks a new request goes through. The new dates may
+// not overlap another of the employee's pending or approved requests
+func (s *LeaveService) Update(leaveID uint, employeeID uint, req *UpdateLeaveRequest) (*model.LeaveRequest, error) {
+	leave, err := s.CheckOwner(leaveID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Property 8: Decided or cancelled requests are final
+	if leave.Status != model.LeaveStatusPending {
+		return nil, ErrLeaveInvalidStatus
+	}
+
+	startDate, endDate, workingDays, err := s.validateLeave(req.LeaveType, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	overlaps, err := s.leaveRepo.HasOverlapping(employeeID, leave.ID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if overlaps {
+		return nil, ErrLeaveOverlap
+	}
+
+	leave.LeaveType = req.LeaveType
+	leave.StartDate = startDate
+	leave.EndDate = endDate
+	leave.Reason = req.Reason
+	leave.WorkingDays = workingDays
+	if err := s.leaveRepo.UpdatePending(leave); err != nil {
+		return nil, translateLeaveNotPending(err)
+	}
+
+	return leave, nil
+}
+
+// validateLeave checks a leave's type and dates and returns the parsed dates with the number of
+// working days they span
+func (s *LeaveService) validateLeave(leaveType, start, end string) (time.Time, time.Time, int, error) {
+	// Parse date strings
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, errors.New("invalid start date format, expected YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, errors.New("invalid end date format, expected YYYY-MM-DD")
+	}
+
+	// Validate date range
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, 0, ErrLeaveInvalidDateRange
+	}
+
+	// Validate leave type
+	if !isValidLeaveType(leaveType) {
+		return time.Time{}, time.Time{}, 0, errors.New("invalid leave type")
+	}
+
+	// Retroactive leave is only allowed for backdatable types within the window
+	today := Today()
+	if startDate.Before(today) && (!s.backdateTypes[leaveType] || startDate.Before(today.AddDate(0, 0, -s.backdateDays))) {
+		return time.Time{}, time.Time{}, 0, ErrLeaveStartInPast
+	}
+
+	// Weekends and public holidays inside the range are not counted
+	workingDays, err := s.holidayService.CalculateWorkingDays(startDate, endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+	if limit := s.maxDays[leaveType]; limit > 0 && workingDays > limit {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("%w: %s leave is limited to %d working days, requested %d", ErrLeaveExceedsMax, leaveType, limit, workingDays)
+	}
+
+	return startDate, endDate, workingDays, nil
+}
+
+// checkMissingSignOuts refuses new leave, when the rule is enabled, from an employee who failed to
+// sign out on at least the threshold number of days in the month before today's
+// A threshold below 1 would block everyone, so it disables the rule like the switch does
+func (s *LeaveService) checkMissingSignOuts(employeeID uint, today time.Time) error {
+	if !s.blockOnMissingSignOuts || s.missingSignOutThreshold < 1 {
+		return nil
+	}
+
+	monthStart := today.AddDate(0, 0, 1-today.Day())
+	missing, err := s.attendanceRepo.CountMissingSignOuts(employeeID, monthStart.AddDate(0, -1, 0), monthStart.AddDate(0, 0, -1))
+	if err != nil {
+		return err
+	}
+	if missing >= int64(s.missingSignOutThreshold) {
+		return fmt.Errorf("%w: %d missing sign-outs last month, leave is blocked from %d", ErrAttendanceIssuesBlockLeave, missing, s.missingSignOutThreshold)
+	}
+	return nil
+}
+
 // GetByID retrieves a leave request by ID
 func (s *LeaveService) GetByID(id uint) (*model.LeaveRequest, error) {
 	leave, err := s.leaveRepo.GetByID(id)
@@ -113,16 +283,59 @@ func (s *LeaveService) GetByID(id uint) (*model.LeaveRequest, error) {
 	return leave, nil
 }
 
-// GetMyLeaves retrieves all leave requests for an employee
+// GetMyLeaves retrieves an employee's leave requests, optionally filtered by
+// status, leave_type and a start/end date range (YYYY-MM-DD)
 // Implements Requirement 5.5, 5.6: Employee views their leave requests and history
-func (s *LeaveService) GetMyLeaves(employeeID uint) ([]model.LeaveRequest, error) {
-	return s.leaveRepo.GetByEmployeeID(employeeID)
+func (s *LeaveService) GetMyLeaves(employeeID uint, filters map[string]interface{}) ([]model.LeaveRequest, error) {
+	var startDate, endDate time.Time
+	var err error
+	if start, ok := filters["start"].(string); ok && start != "" {
+		if startDate, err = time.Parse("2006-01-02", start); err != nil {
+			return nil, ErrLeaveInvalidDateFormat
+		}
+		// Compare as dates so the bounds match the stored date columns on every driver
+		filters["start"] = startDate
+	}
+	if end, ok := filters["end"].(string); ok && end != "" {
+		if endDate, err = time.Parse("2006-01-02", end); err != nil {
+			return nil, ErrLeaveInvalidDateFormat
+		}
+		filters["end"] = endDate
+	}
+	if !startDate.IsZero() && !endDate.IsZero() && endDate.Before(startDate) {
+		return nil, ErrLeaveInvalidDateRange
+	}
+
+	// Always scope to the current employee regardless of filters
+	filters["employee_id"] = employeeID
+	return s.leaveRepo.List(filters)
 }
 
 // GetPendingForSupervisor retrieves all pending leave requests from subordinates
 // Implements Property 9: 主管只能查看下属请假 - Supervisor can only view subordinates' leaves
 // Super admin can also see leave requests from employees without a supervisor
 func (s *LeaveService) GetPendingForSupervisor(supervisorID uint) ([]model.LeaveRequest, error) {
+	subordinateIDs, err := s.approvableEmployeeIDs(supervisorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get pending leave requests from subordinates only (Property 9)
+	return s.leaveRepo.GetPendingBySubordinates(subordinateIDs)
+}
+
+// CountPendingForSupervisor counts the pending leave requests GetPendingForSupervisor would return
+func (s *LeaveService) CountPendingForSupervisor(supervisorID uint) (int64, error) {
+	subordinateIDs, err := s.approvableEmployeeIDs(supervisorID)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.leaveRepo.CountPendingBySubordinates(subordinateIDs)
+}
+
+// approvableEmployeeIDs returns the IDs of employees whose leave the supervisor may approve
+func (s *LeaveService) approvableEmployeeIDs(supervisorID uint) ([]uint, error) {
 	// Check if the current user is a super admin
 	supervisor, err := s.employeeRepo.GetByID(supervisorID)
 	if err != nil {
@@ -135,10 +348,24 @@ func (s *LeaveService) GetPendingForSupervisor(supervisorID uint) ([]model.Leave
 		return nil, err
 	}
 
+	// Include subordinates of supervisors who delegated their approvals
+	delegatingIDs, err := s.employeeRepo.GetDelegatingSupervisorIDs(supervisorID)
+	if err != nil {
+		return nil, err
+	}
+	delegated, err := s.employeeRepo.GetSubordinatesOf(delegatingIDs)
+	if err != nil {
+		return nil, err
+	}
+	subordinates = append(subordinates, delegated...)
+
 	// Extract subordinate IDs
-	subordinateIDs := make([]uint, len(subordinates))
-	for i, sub := range subordinates {
-		subordinateIDs[i] = sub.ID
+	subordinateIDs := make([]uint, 0, len(subordinates))
+	for _, sub := range subordinates {
+		// Don't include self
+		if sub.ID != supervisorID {
+			subordinateIDs = append(subordinateIDs, sub.ID)
+		}
 	}
 
 	// If super admin, also include employees without a supervisor
@@ -155,8 +382,7 @@ func (s *LeaveService) GetPendingForSupervisor(supervisorID uint) ([]model.Leave
 		}
 	}
 
-	// Get pending leave requests from subordinates only (Property 9)
-	return s.leaveRepo.GetPendingBySubordinates(subordinateIDs)
+	return subordinateIDs, nil
 }
 
 
@@ -165,108 +391,184 @@ func (s *LeaveService) GetPendingForSupervisor(supervisorID uint) ([]model.Leave
 // Implements Requirement 5.3: Supervisor approves leave request
 // Super admin can approve leave requests from employees without a supervisor
 func (s *LeaveService) Approve(leaveID uint, supervisorID uint) (*model.LeaveRequest, error) {
-	leave, err := s.leaveRepo.GetByID(leaveID)
+	leave, approver, err := s.checkDecision(leaveID, supervisorID)
 	if err != nil {
-		if errors.Is(err, repository.ErrLeaveRequestNotFound) {
-			return nil, ErrLeaveRequestNotFound
-		}
 		return nil, err
 	}
 
-	// Cannot approve own leave request
-	if leave.EmployeeID == supervisorID {
-		return nil, ErrLeaveSelfApproval
+	applyDecision(leave, approver, model.LeaveStatusApproved, "")
+	if err := s.leaveRepo.DecidePending(leave); err != nil {
+		return nil, translateLeaveNotPending(err)
 	}
+	leave.Approver = approver
 
-	// Get the approver info
-	approver, err := s.employeeRepo.GetByID(supervisorID)
+	// TODO: Notify employee (notification module is optional)
+
+	return leave, nil
+}
+
+// Reject rejects a leave request
+// Implements Property 8: 请假申请状态机 - Status transitions: pending → rejected
+// Implements Requirement 5.4: Supervisor rejects leave request with reason
+// Super admin can reject leave requests from employees without a supervisor
+func (s *LeaveService) Reject(leaveID uint, supervisorID uint, reason string) (*model.LeaveRequest, error) {
+	leave, approver, err := s.checkDecision(leaveID, supervisorID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify the employee is a subordinate of the supervisor (Property 9)
-	employee, err := s.employeeRepo.GetByID(leave.EmployeeID)
-	if err != nil {
-		return nil, err
+	applyDecision(leave, approver, model.LeaveStatusRejected, reason)
+	if err := s.leaveRepo.DecidePending(leave); err != nil {
+		return nil, translateLeaveNotPending(err)
 	}
+	leave.Approver = approver
 
-	// Check authorization: either direct supervisor or super admin for employees without supervisor
-	isDirectSupervisor := employee.SupervisorID != nil && *employee.SupervisorID == supervisorID
-	isSuperAdminForOrphan := approver.Role == model.RoleSuperAdmin && employee.SupervisorID == nil
+	// TODO: Notify employee (notification module is optional)
 
-	if !isDirectSupervisor && !isSuperAdminForOrphan {
-		return nil, ErrLeaveNotSubordinate
-	}
+	return leave, nil
+}
 
-	// Property 8: Only pending status can transition to approved
-	if leave.Status != model.LeaveStatusPending {
-		return nil, ErrLeaveInvalidStatus
-	}
+// BulkApprove approves each of the leave requests the supervisor may decide on
+// Requests failing the subordinate or status rules are reported individually and do not stop the rest
+func (s *LeaveService) BulkApprove(supervisorID uint, req *BulkApproveLeaveRequest) (*BulkLeaveResponse, error) {
+	return s.bulkDecide(supervisorID, req.IDs, model.LeaveStatusApproved, "")
+}
 
-	leave.Status = model.LeaveStatusApproved
-	if err := s.leaveRepo.Update(leave); err != nil {
-		return nil, err
+// BulkReject rejects each of the leave requests the supervisor may decide on with a shared reason
+// Requests failing the subordinate or status rules are reported individually and do not stop the rest
+func (s *LeaveService) BulkReject(supervisorID uint, req *BulkRejectLeaveRequest) (*BulkLeaveResponse, error) {
+	return s.bulkDecide(supervisorID, req.IDs, model.LeaveStatusRejected, req.RejectReason)
+}
+
+// bulkDecide validates every leave request, then applies the decision to the valid ones in one transaction
+// A request decided or cancelled by someone else in the meantime is reported as an error for that entry
+func (s *LeaveService) bulkDecide(supervisorID uint, ids []uint, status string, reason string) (*BulkLeaveResponse, error) {
+	resp := &BulkLeaveResponse{Results: make([]BulkLeaveResult, len(ids))}
+	var decided []int             // indexes of the results whose request passed validation
+	var decidedBy *model.Employee // the supervisor, loaded once any request passes validation
+	seen := make(map[uint]bool, len(ids))
+
+	for i, id := range ids {
+		result := BulkLeaveResult{ID: id}
+
+		if seen[id] {
+			result.Status = BatchEntryError
+			result.Error = "duplicate leave request in batch"
+		} else {
+			leave, approver, err := s.checkDecision(id, supervisorID)
+			switch {
+			case err == nil:
+				applyDecision(leave, approver, status, reason)
+				result.Status = status
+				result.Leave = leave
+				decided = append(decided, i)
+				decidedBy = approver
+			case errors.Is(err, ErrLeaveRequestNotFound), errors.Is(err, ErrLeaveSelfApproval),
+				errors.Is(err, ErrLeaveNotSubordinate), errors.Is(err, ErrLeaveInvalidStatus):
+				result.Status = BatchEntryError
+				result.Error = err.Error()
+			default:
+				return nil, err
+			}
+		}
+		seen[id] = true
+		resp.Results[i] = result
+	}
+
+	if len(decided) > 0 {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			leaveRepo := repository.NewLeaveRepository(tx)
+			for _, i := range decided {
+				result := &resp.Results[i]
+				err := leaveRepo.DecidePending(result.Leave)
+				if errors.Is(err, repository.ErrLeaveNotPending) {
+					*result = BulkLeaveResult{ID: result.ID, Status: BatchEntryError, Error: ErrLeaveInvalidStatus.Error()}
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				result.Leave.Approver = decidedBy
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// TODO: Notify employee (notification module is optional)
+	for _, result := range resp.Results {
+		if result.Status == BatchEntryError {
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+	}
+	return resp, nil
+}
 
-	return leave, nil
+// translateLeaveNotPending maps a leave request decided or cancelled concurrently to ErrLeaveInvalidStatus
+func translateLeaveNotPending(err error) error {
+	if errors.Is(err, repository.ErrLeaveNotPending) {
+		return ErrLeaveInvalidStatus
+	}
+	return err
 }
 
-// Reject rejects a leave request
-// Implements Property 8: 请假申请状态机 - Status transitions: pending → rejected
-// Implements Requirement 5.4: Supervisor rejects leave request with reason
-// Super admin can reject leave requests from employees without a supervisor
-func (s *LeaveService) Reject(leaveID uint, supervisorID uint, reason string) (*model.LeaveRequest, error) {
+// checkDecision loads a leave request and the approver, verifying the approver may approve or reject it
+func (s *LeaveService) checkDecision(leaveID uint, supervisorID uint) (*model.LeaveRequest, *model.Employee, error) {
 	leave, err := s.leaveRepo.GetByID(leaveID)
 	if err != nil {
 		if errors.Is(err, repository.ErrLeaveRequestNotFound) {
-			return nil, ErrLeaveRequestNotFound
+			return nil, nil, ErrLeaveRequestNotFound
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Cannot reject own leave request
+	// Cannot approve or reject own leave request
 	if leave.EmployeeID == supervisorID {
-		return nil, ErrLeaveSelfApproval
+		return nil, nil, ErrLeaveSelfApproval
 	}
 
 	// Get the approver info
 	approver, err := s.employeeRepo.GetByID(supervisorID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Verify the employee is a subordinate of the supervisor (Property 9)
 	employee, err := s.employeeRepo.GetByID(leave.EmployeeID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Check authorization: either direct supervisor or super admin for employees without supervisor
-	isDirectSupervisor := employee.SupervisorID != nil && *employee.SupervisorID == supervisorID
-	isSuperAdminForOrphan := approver.Role == model.RoleSuperAdmin && employee.SupervisorID == nil
-
-	if !isDirectSupervisor && !isSuperAdminForOrphan {
-		return nil, ErrLeaveNotSubordinate
+	// Check authorization: direct supervisor, their delegate, or super admin for employees without supervisor
+	authorized, err := s.canApprove(approver, employee)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	// Property 8: Only pending status can transition to rejected
-	if leave.Status != model.LeaveStatusPending {
-		return nil, ErrLeaveInvalidStatus
+	if !authorized {
+		return nil, nil, ErrLeaveNotSubordinate
 	}
 
-	leave.Status = model.LeaveStatusRejected
-	leave.RejectReason = reason
-	if err := s.leaveRepo.Update(leave); err != nil {
-		return nil, err
+	// Property 8: Only pending status can transition to approved or rejected
+	if leave.Status != model.LeaveStatusPending {
+		return nil, nil, ErrLeaveInvalidStatus
 	}
 
-	// TODO: Notify employee (notification module is optional)
-
-	return leave, nil
+	return leave, approver, nil
 }
 
+// applyDecision moves a pending leave request to the approved or rejected status on behalf of the approver
+func applyDecision(leave *model.LeaveRequest, approver *model.Employee, status string, reason string) {
+	now := time.Now().UTC()
+	leave.Status = status
+	if status == model.LeaveStatusRejected {
+		leave.RejectReason = reason
+	}
+	leave.ApprovedBy = &approver.ID
+	leave.ActionedAt = &now
+}
 
 // CancelByEmployee cancels a leave request by the employee
 // Implements Property 8: 请假申请状态机 - Status transitions: pending → cancelled
@@ -338,16 +640,132 @@ func (s *LeaveService) CancelBySupervisor(leaveID uint, supervisorID uint) (*mod
 		return nil, ErrLeaveInvalidStatus
 	}
 
+	now := time.Now().UTC()
 	leave.Status = model.LeaveStatusCancelled
+	leave.ApprovedBy = &approver.ID
+	leave.ActionedAt = &now
 	if err := s.leaveRepo.Update(leave); err != nil {
 		return nil, err
 	}
+	leave.Approver = approver
 
 	// TODO: Notify employee (notification module is optional)
 
 	return leave, nil
 }
 
+// GetCalendar returns approved leaves overlapping the month, grouped by employee
+// HR and super admin see all employees; everyone else sees their direct subordinates
+func (s *LeaveService) GetCalendar(userID uint, role string, year int, month int) ([]CalendarEntry, error) {
+	// Default to current month if not specified
+	if year == 0 || month == 0 {
+		today := Today()
+		year = today.Year()
+		month = int(today.Month())
+	}
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	var employeeIDs []uint
+	if role != model.RoleHR && role != model.RoleSuperAdmin {
+		subordinates, err := s.employeeRepo.GetSubordinates(userID)
+		if err != nil {
+			return nil, err
+		}
+		employeeIDs = make([]uint, len(subordinates))
+		for i, sub := range subordinates {
+			employeeIDs[i] = sub.ID
+		}
+	}
+
+	leaves, err := s.leaveRepo.GetApprovedOverlapping(employeeIDs, monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leaves are ordered by employee, so consecutive rows share an entry
+	entries := []CalendarEntry{}
+	for _, leave := range leaves {
+		if len(entries) == 0 || entries[len(entries)-1].EmployeeID != leave.EmployeeID {
+			entries = append(entries, CalendarEntry{
+				EmployeeID:   leave.EmployeeID,
+				EmployeeNo:   leave.Employee.EmployeeNo,
+				EmployeeName: leave.Employee.Name,
+				Department:   leave.Employee.Department,
+			})
+		}
+		entry := &entries[len(entries)-1]
+		entry.Leaves = append(entry.Leaves, CalendarLeave{
+			ID:        leave.ID,
+			LeaveType: leave.LeaveType,
+			StartDate: leave.StartDate.Format("2006-01-02"),
+			EndDate:   leave.EndDate.Format("2006-01-02"),
+		})
+	}
+
+	return entries, nil
+}
+
+// CheckOwner verifies the leave request belongs to the employee
+func (s *LeaveService) CheckOwner(leaveID uint, employeeID uint) (*model.LeaveRequest, error) {
+	leave, err := s.GetByID(leaveID)
+	if err != nil {
+		return nil, err
+	}
+	if leave.EmployeeID != employeeID {
+		return nil, ErrLeaveAccessDenied
+	}
+	return leave, nil
+}
+
+// CheckViewer verifies the user is the leave request's owner or one of its approvers
+func (s *LeaveService) CheckViewer(leaveID uint, userID uint) (*model.LeaveRequest, error) {
+	leave, err := s.GetByID(leaveID)
+	if err != nil {
+		return nil, err
+	}
+	if leave.EmployeeID == userID {
+		return leave, nil
+	}
+
+	viewer, err := s.employeeRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	employee, err := s.employeeRepo.GetByID(leave.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	authorized, err := s.canApprove(viewer, employee)
+	if err != nil {
+		return nil, err
+	}
+	if !authorized {
+		return nil, ErrLeaveAccessDenied
+	}
+	return leave, nil
+}
+
+// canApprove reports whether the approver may approve or reject the employee's leave:
+// the direct supervisor, the supervisor's delegate, or super admin for employees without supervisor
+func (s *LeaveService) canApprove(approver, employee *model.Employee) (bool, error) {
+	if employee.SupervisorID == nil {
+		return approver.Role == model.RoleSuperAdmin, nil
+	}
+	if *employee.SupervisorID == approver.ID {
+		return true, nil
+	}
+
+	supervisor, err := s.employeeRepo.GetByID(*employee.SupervisorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return supervisor.DelegateApproverID != nil && *supervisor.DelegateApproverID == approver.ID, nil
+}
+
 // isValidLeaveType checks if the leave type is valid
 func isValidLeaveType(leaveType string) bool {
 	validTypes := []string{
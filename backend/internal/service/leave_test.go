@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+func newLeaveService(db *gorm.DB) *LeaveService {
+	return NewLeaveService(db, testLeaveConfig())
+}
+
+func TestCountPendingForSupervisor(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	outsider := testutil.CreateEmployee(t, db, "outsider", model.RoleEmployee)
+	reportTo(t, db, boss, alice, bob)
+	day := date(2026, 3, 2)
+	createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+	createLeave(t, db, alice.ID, model.LeaveTypeSick, day.AddDate(0, 0, 7), day.AddDate(0, 0, 7), model.LeaveStatusPending)
+	createLeave(t, db, bob.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+	createLeave(t, db, bob.ID, model.LeaveTypeAnnual, day.AddDate(0, 0, 14), day.AddDate(0, 0, 14), model.LeaveStatusApproved)
+	createLeave(t, db, outsider.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+
+	pending, err := s.GetPendingForSupervisor(boss.ID)
+	if err != nil {
+		t.Fatalf("GetPendingForSupervisor: %v", err)
+	}
+	count, err := s.CountPendingForSupervisor(boss.ID)
+	if err != nil {
+		t.Fatalf("CountPendingForSupervisor: %v", err)
+	}
+	if len(pending) != 3 || count != int64(len(pending)) {
+		t.Errorf("count = %d, list has %d, want both 3", count, len(pending))
+	}
+}
+
+func TestLeaveStartInPast(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	today := Today()
+	request := func(leaveType string, start time.Time) *CreateLeaveRequest {
+		day := start.Format("2006-01-02")
+		return &CreateLeaveRequest{LeaveType: leaveType, StartDate: day, EndDate: day}
+	}
+
+	if _, err := s.Create(alice.ID, request(model.LeaveTypeAnnual, today.AddDate(0, 0, 3))); err != nil {
+		t.Errorf("future annual leave: %v", err)
+	}
+	if _, err := s.Create(alice.ID, request(model.LeaveTypeAnnual, today.AddDate(0, 0, -2))); !errors.Is(err, ErrLeaveStartInPast) {
+		t.Errorf("past annual leave: err = %v, want ErrLeaveStartInPast", err)
+	}
+	// Sick leave may be filed afterwards, but only within the configured 7 days
+	if _, err := s.Create(alice.ID, request(model.LeaveTypeSick, today.AddDate(0, 0, -3))); err != nil {
+		t.Errorf("sick leave 3 days ago: %v", err)
+	}
+	if _, err := s.Create(alice.ID, request(model.LeaveTypeSick, today.AddDate(0, 0, -8))); !errors.Is(err, ErrLeaveStartInPast) {
+		t.Errorf("sick leave 8 days ago: err = %v, want ErrLeaveStartInPast", err)
+	}
+}
+
+func TestLeaveExceedsMax(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	monday := Today().AddDate(0, 0, 7)
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, 1)
+	}
+	request := func(start, end time.Time) *CreateLeaveRequest {
+		return &CreateLeaveRequest{LeaveType: model.LeaveTypeAnnual, StartDate: start.Format("2006-01-02"), EndDate: end.Format("2006-01-02")}
+	}
+
+	// Three full weeks are exactly the 15 working days annual leave allows
+	leave, err := s.Create(alice.ID, request(monday, monday.AddDate(0, 0, 18)))
+	if err != nil {
+		t.Fatalf("annual leave at the limit: %v", err)
+	}
+	if leave.WorkingDays != 15 {
+		t.Errorf("working days = %d, want 15", leave.WorkingDays)
+	}
+
+	later := monday.AddDate(0, 0, 28)
+	if _, err := s.Create(alice.ID, request(later, later.AddDate(0, 0, 21))); !errors.Is(err, ErrLeaveExceedsMax) {
+		t.Errorf("annual leave of 16 working days: err = %v, want ErrLeaveExceedsMax", err)
+	}
+}
+
+func TestMissingSignOutsBlockLeave(t *testing.T) {
+	db := testutil.NewDB(t)
+	cfg := testLeaveConfig()
+	cfg.BlockOnMissingSignOuts = true
+	s := NewLeaveService(db, cfg)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	today := Today()
+	lastMonth := today.AddDate(0, 0, 1-today.Day()).AddDate(0, -1, 0)
+
+	// Alice never signed out on three days last month; bob signed out every day
+	for i := range 3 {
+		day := lastMonth.AddDate(0, 0, i)
+		signIn := day.Add(9 * time.Hour)
+		if err := db.Create(&model.Attendance{EmployeeID: alice.ID, Date: day, SignInTime: &signIn}).Error; err != nil {
+			t.Fatalf("create attendance: %v", err)
+		}
+		signOut := day.Add(18 * time.Hour)
+		if err := db.Create(&model.Attendance{EmployeeID: bob.ID, Date: day, SignInTime: &signIn, SignOutTime: &signOut}).Error; err != nil {
+			t.Fatalf("create attendance: %v", err)
+		}
+	}
+	start := today.AddDate(0, 0, 3).Format("2006-01-02")
+	request := &CreateLeaveRequest{LeaveType: model.LeaveTypeAnnual, StartDate: start, EndDate: start}
+
+	if _, err := s.Create(alice.ID, request); !errors.Is(err, ErrAttendanceIssuesBlockLeave) {
+		t.Errorf("leave with 3 missing sign-outs: err = %v, want ErrAttendanceIssuesBlockLeave", err)
+	}
+	if _, err := s.Create(bob.ID, request); err != nil {
+		t.Errorf("leave with a clean record: %v", err)
+	}
+	// With the rule off the same request goes through
+	if _, err := newLeaveService(db).Create(alice.ID, request); err != nil {
+		t.Errorf("leave with the rule disabled: %v", err)
+	}
+	// A zero threshold would match everyone, including bob with no missing sign-outs, so it disables the rule
+	cfg.MissingSignOutThreshold = 0
+	later := today.AddDate(0, 0, 10).Format("2006-01-02")
+	if _, err := NewLeaveService(db, cfg).Create(bob.ID, &CreateLeaveRequest{LeaveType: model.LeaveTypeAnnual, StartDate: later, EndDate: later}); err != nil {
+		t.Errorf("leave with a zero threshold: %v", err)
+	}
+}
+
+func TestGetMyLeavesDateRange(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	before := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, date(2026, 2, 20), date(2026, 2, 27), model.LeaveStatusApproved)
+	straddling := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, date(2026, 2, 26), date(2026, 3, 3), model.LeaveStatusApproved)
+	inside := createLeave(t, db, alice.ID, model.LeaveTypeSick, date(2026, 3, 10), date(2026, 3, 10), model.LeaveStatusPending)
+	lastDay := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, date(2026, 3, 31), date(2026, 4, 2), model.LeaveStatusPending)
+	after := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, date(2026, 4, 6), date(2026, 4, 7), model.LeaveStatusPending)
+	createLeave(t, db, bob.ID, model.LeaveTypeAnnual, date(2026, 3, 10), date(2026, 3, 10), model.LeaveStatusPending)
+
+	ids := func(filters map[string]interface{}) map[uint]bool {
+		t.Helper()
+		leaves, err := s.GetMyLeaves(alice.ID, filters)
+		if err != nil {
+			t.Fatalf("GetMyLeaves(%v): %v", filters, err)
+		}
+		found := map[uint]bool{}
+		for _, leave := range leaves {
+			if leave.EmployeeID != alice.ID {
+				t.Errorf("leave %d of employee %d returned to alice", leave.ID, leave.EmployeeID)
+			}
+			found[leave.ID] = true
+		}
+		return found
+	}
+
+	got := ids(map[string]interface{}{"start": "2026-03-01", "end": "2026-03-31"})
+	for _, leave := range []*model.LeaveRequest{straddling, inside, lastDay} {
+		if !got[leave.ID] {
+			t.Errorf("leave %s..%s missing from March", leave.StartDate.Format("2006-01-02"), leave.EndDate.Format("2006-01-02"))
+		}
+	}
+	for _, leave := range []*model.LeaveRequest{before, after} {
+		if got[leave.ID] {
+			t.Errorf("leave %s..%s outside March was returned", leave.StartDate.Format("2006-01-02"), leave.EndDate.Format("2006-01-02"))
+		}
+	}
+
+	if got := ids(map[string]interface{}{"start": "2026-03-01", "end": "2026-03-31", "leave_type": model.LeaveTypeSick}); len(got) != 1 || !got[inside.ID] {
+		t.Errorf("sick leave in March = %v, want only %d", got, inside.ID)
+	}
+	if _, err := s.GetMyLeaves(alice.ID, map[string]interface{}{"start": "2026-03-31", "end": "2026-03-01"}); !errors.Is(err, ErrLeaveInvalidDateRange) {
+		t.Errorf("reversed range: err = %v, want ErrLeaveInvalidDateRange", err)
+	}
+}
+
+func TestDelegateApprover(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	employees := newEmployeeService(t, db)
+	ctx := context.Background()
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	deputy := testutil.CreateEmployee(t, db, "deputy", model.RoleEmployee)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	reportTo(t, db, boss, alice)
+	day := Today().AddDate(0, 0, 7)
+	first := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+	second := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day.AddDate(0, 0, 7), day.AddDate(0, 0, 7), model.LeaveStatusPending)
+
+	if _, err := s.Approve(first.ID, deputy.ID); !errors.Is(err, ErrLeaveNotSubordinate) {
+		t.Fatalf("approve before delegation: err = %v, want ErrLeaveNotSubordinate", err)
+	}
+	if _, err := employees.UpdateDelegate(ctx, boss.ID, &UpdateDelegateRequest{DelegateApproverID: &deputy.ID}); err != nil {
+		t.Fatalf("UpdateDelegate: %v", err)
+	}
+	pending, err := s.GetPendingForSupervisor(deputy.ID)
+	if err != nil {
+		t.Fatalf("GetPendingForSupervisor: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("delegate sees %d pending leaves, want 2", len(pending))
+	}
+	approved, err := s.Approve(first.ID, deputy.ID)
+	if err != nil {
+		t.Fatalf("approve as delegate: %v", err)
+	}
+	if approved.Status != model.LeaveStatusApproved {
+		t.Errorf("status = %q, want approved", approved.Status)
+	}
+
+	if _, err := employees.UpdateDelegate(ctx, boss.ID, &UpdateDelegateRequest{}); err != nil {
+		t.Fatalf("clear delegate: %v", err)
+	}
+	if _, err := s.Approve(second.ID, deputy.ID); !errors.Is(err, ErrLeaveNotSubordinate) {
+		t.Errorf("approve after clearing: err = %v, want ErrLeaveNotSubordinate", err)
+	}
+	if pending, _ := s.GetPendingForSupervisor(deputy.ID); len(pending) != 0 {
+		t.Errorf("former delegate sees %d pending leaves, want 0", len(pending))
+	}
+}
+
+func TestLeaveCalendarMonthBoundary(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	outsider := testutil.CreateEmployee(t, db, "outsider", model.RoleEmployee)
+	reportTo(t, db, boss, alice, bob)
+	spanning := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, date(2026, 3, 30), date(2026, 4, 2), model.LeaveStatusApproved)
+	lastDay := createLeave(t, db, bob.ID, model.LeaveTypeAnnual, date(2026, 3, 31), date(2026, 3, 31), model.LeaveStatusApproved)
+	createLeave(t, db, bob.ID, model.LeaveTypeAnnual, date(2026, 3, 10), date(2026, 3, 10), model.LeaveStatusPending)
+	createLeave(t, db, bob.ID, model.LeaveTypeAnnual, date(2026, 3, 11), date(2026, 3, 11), model.LeaveStatusRejected)
+	createLeave(t, db, outsider.ID, model.LeaveTypeAnnual, date(2026, 3, 12), date(2026, 3, 12), model.LeaveStatusApproved)
+
+	leaveIDs := func(month int) map[uint]bool {
+		t.Helper()
+		entries, err := s.GetCalendar(boss.ID, boss.Role, 2026, month)
+		if err != nil {
+			t.Fatalf("GetCalendar(%d): %v", month, err)
+		}
+		ids := map[uint]bool{}
+		for _, entry := range entries {
+			for _, leave := range entry.Leaves {
+				ids[leave.ID] = true
+			}
+		}
+		return ids
+	}
+
+	if got := leaveIDs(3); len(got) != 2 || !got[spanning.ID] || !got[lastDay.ID] {
+		t.Errorf("March calendar = %v, want leaves %d and %d", got, spanning.ID, lastDay.ID)
+	}
+	if got := leaveIDs(4); len(got) != 1 || !got[spanning.ID] {
+		t.Errorf("April calendar = %v, want only leave %d", got, spanning.ID)
+	}
+}
+
+func TestLeaveDecisionActor(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	reportTo(t, db, boss, alice)
+	day := Today().AddDate(0, 0, 7)
+
+	before := time.Now().Add(-time.Second)
+	approved, err := s.Approve(createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending).ID, boss.ID)
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	stored, err := s.GetByID(approved.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.ApprovedBy == nil || *stored.ApprovedBy != boss.ID || stored.Approver == nil || stored.Approver.Name != boss.Name {
+		t.Errorf("approved by = %v (%v), want %d", stored.ApprovedBy, stored.Approver, boss.ID)
+	}
+	if stored.ActionedAt == nil || stored.ActionedAt.Before(before) {
+		t.Errorf("actioned at = %v, want a time after %v", stored.ActionedAt, before)
+	}
+
+	cancelled, err := s.CancelByEmployee(createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day.AddDate(0, 0, 7), day.AddDate(0, 0, 7), model.LeaveStatusPending).ID, alice.ID)
+	if err != nil {
+		t.Fatalf("CancelByEmployee: %v", err)
+	}
+	if stored, _ := s.GetByID(cancelled.ID); stored.ApprovedBy != nil || stored.ActionedAt != nil {
+		t.Errorf("self-cancelled leave approved by %v at %v, want neither", stored.ApprovedBy, stored.ActionedAt)
+	}
+}
+
+func TestBulkApproveMixedBatch(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	reportTo(t, db, boss, alice, bob)
+	day := Today().AddDate(0, 0, 7)
+
+	raced := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+	pending := createLeave(t, db, bob.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+	decided := createLeave(t, db, alice.ID, model.LeaveTypeSick, day.AddDate(0, 0, 1), day.AddDate(0, 0, 1), model.LeaveStatusRejected)
+	notReport := createLeave(t, db, carol.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+
+	// Alice cancels her request after the batch has validated it but before it is written
+	cancelled := false
+	err := db.Callback().Update().Before("gorm:update").Register("test:cancel_raced", func(tx *gorm.DB) {
+		if cancelled || tx.Statement.Table != "leave_requests" {
+			return
+		}
+		cancelled = true
+		if err := tx.Session(&gorm.Session{NewDB: true}).Exec("UPDATE leave_requests SET status = ? WHERE id = ?", model.LeaveStatusCancelled, raced.ID).Error; err != nil {
+			t.Errorf("cancel raced leave: %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	ids := []uint{raced.ID, pending.ID, decided.ID, notReport.ID, pending.ID, 9999}
+	resp, err := s.BulkApprove(boss.ID, &BulkApproveLeaveRequest{IDs: ids})
+	if err != nil {
+		t.Fatalf("BulkApprove: %v", err)
+	}
+	if resp.Succeeded != 1 || resp.Failed != 5 {
+		t.Errorf("succeeded/failed = %d/%d, want 1/5", resp.Succeeded, resp.Failed)
+	}
+	wantErrors := map[int]string{
+		0: ErrLeaveInvalidStatus.Error(),
+		2: ErrLeaveInvalidStatus.Error(),
+		3: ErrLeaveNotSubordinate.Error(),
+		4: "duplicate leave request in batch",
+		5: ErrLeaveRequestNotFound.Error(),
+	}
+	for i, result := range resp.Results {
+		if result.ID != ids[i] {
+			t.Errorf("result %d is for leave %d, want %d", i, result.ID, ids[i])
+		}
+		if want, failed := wantErrors[i]; failed {
+			if result.Status != BatchEntryError || result.Error != want || result.Leave != nil {
+				t.Errorf("result %d = %s %q, want error %q", i, result.Status, result.Error, want)
+			}
+		} else if result.Status != model.LeaveStatusApproved || result.Leave == nil || result.Leave.Approver == nil {
+			t.Errorf("result %d = %s %q, want approved by boss", i, result.Status, result.Error)
+		}
+	}
+
+	status := func(leave *model.LeaveRequest) string {
+		t.Helper()
+		var stored model.LeaveRequest
+		if err := db.First(&stored, leave.ID).Error; err != nil {
+			t.Fatalf("load leave: %v", err)
+		}
+		return stored.Status
+	}
+	for leave, want := range map[*model.LeaveRequest]string{
+		raced:     model.LeaveStatusCancelled,
+		pending:   model.LeaveStatusApproved,
+		decided:   model.LeaveStatusRejected,
+		notReport: model.LeaveStatusPending,
+	} {
+		if got := status(leave); got != want {
+			t.Errorf("leave %d status = %q, want %q", leave.ID, got, want)
+		}
+	}
+}
+
+func TestUpdateLeave(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newLeaveService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	day := Today().AddDate(0, 0, 14)
+	request := func(start, end time.Time) *UpdateLeaveRequest {
+		return &UpdateLeaveRequest{LeaveType: model.LeaveTypeAnnual, StartDate: start.Format("2006-01-02"), EndDate: end.Format("2006-01-02"), Reason: "moved"}
+	}
+	pending := createLeave(t, db, alice.ID, model.LeaveTypeSick, day, day, model.LeaveStatusPending)
+	other := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day.AddDate(0, 0, 7), day.AddDate(0, 0, 7), model.LeaveStatusPending)
+	approved := createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day.AddDate(0, 0, 21), day.AddDate(0, 0, 21), model.LeaveStatusApproved)
+
+	// Moving a day later still covers the original day, which is the request itself
+	if _, err := s.Update(pending.ID, alice.ID, request(day, day.AddDate(0, 0, 1))); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	var stored model.LeaveRequest
+	if err := db.First(&stored, pending.ID).Error; err != nil {
+		t.Fatalf("load leave: %v", err)
+	}
+	if !stored.EndDate.Equal(day.AddDate(0, 0, 1)) || stored.LeaveType != model.LeaveTypeAnnual || stored.Reason != "moved" || stored.Status != model.LeaveStatusPending {
+		t.Errorf("stored = %s %s until %v %q, want pending annual until the next day with the new reason", stored.Status, stored.LeaveType, stored.EndDate, stored.Reason)
+	}
+
+	if _, err := s.Update(pending.ID, alice.ID, request(day, other.StartDate)); !errors.Is(err, ErrLeaveOverlap) {
+		t.Errorf("edit onto another pending leave: err = %v, want ErrLeaveOverlap", err)
+	}
+	if _, err := s.Update(approved.ID, alice.ID, request(day.AddDate(0, 0, 28), day.AddDate(0, 0, 28))); !errors.Is(err, ErrLeaveInvalidStatus) {
+		t.Errorf("edit an approved leave: err = %v, want ErrLeaveInvalidStatus", err)
+	}
+	if _, err := s.Update(other.ID, bob.ID, request(day.AddDate(0, 0, 8), day.AddDate(0, 0, 8))); !errors.Is(err, ErrLeaveAccessDenied) {
+		t.Errorf("edit someone else's leave: err = %v, want ErrLeaveAccessDenied", err)
+	}
+
+	// The overlap check only applies to edits; creating is unchanged
+	if _, err := s.Create(alice.ID, &CreateLeaveRequest{LeaveType: model.LeaveTypeSick, StartDate: day.Format("2006-01-02"), EndDate: day.Format("2006-01-02")}); err != nil {
+		t.Errorf("create on a day with a pending leave: %v", err)
+	}
+}
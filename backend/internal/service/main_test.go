@@ -0,0 +1,101 @@
+package service
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+)
+
+func TestMain(m *testing.M) {
+	// Calendar days are reckoned in UTC so fixtures do not depend on the machine's zone
+	SetTimezone(time.UTC)
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// date returns the calendar day as stored in date columns
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// countNotifications counts the notifications of a type sent to an employee
+func countNotifications(t *testing.T, db *gorm.DB, employeeID uint, notificationType string) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&model.Notification{}).Where("employee_id = ? AND type = ?", employeeID, notificationType).Count(&count).Error; err != nil {
+		t.Fatalf("count notifications: %v", err)
+	}
+	return count
+}
+
+// createSalary inserts a salary record without components
+func createSalary(t *testing.T, db *gorm.DB, employeeID uint, month string, base float64, published bool) *model.Salary {
+	t.Helper()
+	salary := &model.Salary{EmployeeID: employeeID, Month: month, BaseSalary: base, NetSalary: base, Published: published}
+	if err := db.Create(salary).Error; err != nil {
+		t.Fatalf("create salary: %v", err)
+	}
+	return salary
+}
+
+// testAttendanceConfig is a 09:00 to 18:00 working day counting overtime of 15 minutes or more
+func testAttendanceConfig() *config.AttendanceConfig {
+	return &config.AttendanceConfig{WorkStartTime: "09:00", WorkEndTime: "18:00", OvertimeThresholdMinutes: 15}
+}
+
+// testLeaveConfig allows sick leave to be backdated a week and caps annual leave at 15 days
+func testLeaveConfig() *config.LeaveConfig {
+	return &config.LeaveConfig{
+		BackdateDays:            7,
+		BackdateTypes:           []string{"sick"},
+		MaxDays:                 map[string]int{"annual": 15, "sick": 30},
+		MissingSignOutThreshold: 3,
+	}
+}
+
+// createDevice inserts a device with all units available
+func createDevice(t *testing.T, db *gorm.DB, name string, quantity int) *model.Device {
+	t.Helper()
+	device := &model.Device{Name: name, Type: "laptop", TotalQuantity: quantity, AvailableQuantity: quantity}
+	if err := db.Create(device).Error; err != nil {
+		t.Fatalf("create device: %v", err)
+	}
+	return device
+}
+
+// createDeviceRequest inserts a device request in the given status without going through the workflow
+func createDeviceRequest(t *testing.T, db *gorm.DB, employeeID, deviceID uint, status string) *model.DeviceRequest {
+	t.Helper()
+	request := &model.DeviceRequest{EmployeeID: employeeID, DeviceID: deviceID, Status: status}
+	if err := db.Create(request).Error; err != nil {
+		t.Fatalf("create device request: %v", err)
+	}
+	return request
+}
+
+// createLeave inserts a leave request in the given status without going through validation
+func createLeave(t *testing.T, db *gorm.DB, employeeID uint, leaveType string, start, end time.Time, status string) *model.LeaveRequest {
+	t.Helper()
+	leave := &model.LeaveRequest{EmployeeID: employeeID, LeaveType: leaveType, StartDate: start, EndDate: end, Status: status}
+	if err := db.Create(leave).Error; err != nil {
+		t.Fatalf("create leave: %v", err)
+	}
+	return leave
+}
+
+// reportTo makes supervisor the direct supervisor of each employee
+func reportTo(t *testing.T, db *gorm.DB, supervisor *model.Employee, employees ...*model.Employee) {
+	t.Helper()
+	for _, employee := range employees {
+		if err := db.Model(employee).Update("supervisor_id", supervisor.ID).Error; err != nil {
+			t.Fatalf("set supervisor of %s: %v", employee.Username, err)
+		}
+	}
+}
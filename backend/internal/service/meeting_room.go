@@ -2,51 +2,103 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
+	"oa-system/config"
 	"oa-system/internal/model"
 	"oa-system/internal/repository"
 )
 
 var (
-	ErrMeetingRoomNotFound    = errors.New("meeting room not found")
-	ErrBookingNotFound        = errors.New("booking not found")
-	ErrBookingConflict        = errors.New("booking time conflict")
-	ErrBookingLimitExceeded   = errors.New("already has an active booking")
-	ErrBookingInvalidStatus   = errors.New("booking status does not allow this operation")
-	ErrBookingNotOwner        = errors.New("can only operate on own booking")
+	ErrMeetingRoomNotFound     = errors.New("meeting room not found")
+	ErrBookingNotFound         = errors.New("booking not found")
+	ErrBookingConflict         = errors.New("booking time conflict")
+	ErrBookingLimitExceeded    = errors.New("already has an active booking")
+	ErrBookingInvalidStatus    = errors.New("booking status does not allow this operation")
+	ErrBookingNotOwner         = errors.New("can only operate on own booking")
+	ErrBookingCheckInNotOpen   = errors.New("check-in is not open before the booking start time")
+	ErrBookingCheckInExpired   = errors.New("check-in grace window has passed")
+	ErrBookingAlreadyCheckedIn = errors.New("booking already checked in")
+	ErrBookingEmployeeNotFound = errors.New("employee to book for not found or inactive")
+	ErrBookingInvalidTime      = errors.New("invalid booking time, expected HH:MM in 24-hour format")
+	ErrBookingDuration         = errors.New("booking duration is out of the allowed range")
+	ErrWaitlistEntryNotFound   = errors.New("waitlist entry not found")
+	ErrWaitlistNotOwner        = errors.New("can only operate on own waitlist entry")
+	ErrWaitlistInvalidStatus   = errors.New("waitlist entry is no longer waiting")
+	ErrWaitlistSlotAvailable   = errors.New("slot is available, book it directly")
+	ErrWaitlistDuplicate       = errors.New("already on the waitlist for this slot")
+	ErrBookingDuringLeave      = errors.New("employee is on approved leave on the booking date")
+)
+
+// defaultRoomOpen and defaultRoomClose are used when the configured operating hours cannot be parsed
+const (
+	defaultRoomOpen  = 8 * time.Hour
+	defaultRoomClose = 20 * time.Hour
 )
 
 // MeetingRoomService handles meeting room business logic
 type MeetingRoomService struct {
-	roomRepo    *repository.MeetingRoomRepository
-	bookingRepo *repository.MeetingRoomBookingRepository
-	db          *gorm.DB
+	roomRepo            *repository.MeetingRoomRepository
+	bookingRepo         *repository.MeetingRoomBookingRepository
+	waitlistRepo        *repository.BookingWaitlistRepository
+	employeeRepo        *repository.EmployeeRepository
+	leaveRepo           *repository.LeaveRepository
+	notificationService *NotificationService
+	db                  *gorm.DB
+	checkInGrace        time.Duration
+	minDuration         time.Duration
+	maxDuration         time.Duration
+	openTime            time.Duration // operating hours as offsets from midnight
+	closeTime           time.Duration
+	blockDuringLeave    bool
 }
 
 // NewMeetingRoomService creates a new meeting room service
-func NewMeetingRoomService(db *gorm.DB) *MeetingRoomService {
+func NewMeetingRoomService(db *gorm.DB, cfg *config.BookingConfig) *MeetingRoomService {
+	openTime, closeTime := defaultRoomOpen, defaultRoomClose
+	if start, err := parseClock(cfg.OpenTime); err == nil {
+		openTime = start
+	}
+	if end, err := parseClock(cfg.CloseTime); err == nil {
+		closeTime = end
+	}
+
 	return &MeetingRoomService{
-		roomRepo:    repository.NewMeetingRoomRepository(db),
-		bookingRepo: repository.NewMeetingRoomBookingRepository(db),
-		db:          db,
+		roomRepo:            repository.NewMeetingRoomRepository(db),
+		bookingRepo:         repository.NewMeetingRoomBookingRepository(db),
+		waitlistRepo:        repository.NewBookingWaitlistRepository(db),
+		employeeRepo:        repository.NewEmployeeRepository(db),
+		leaveRepo:           repository.NewLeaveRepository(db),
+		notificationService: NewNotificationService(db),
+		db:                  db,
+		checkInGrace:        time.Duration(cfg.CheckInGraceMinutes) * time.Minute,
+		minDuration:         time.Duration(cfg.MinDurationMinutes) * time.Minute,
+		maxDuration:         time.Duration(cfg.MaxDurationMinutes) * time.Minute,
+		openTime:            openTime,
+		closeTime:           closeTime,
+		blockDuringLeave:    cfg.BlockDuringLeave,
 	}
 }
 
 // CreateMeetingRoomRequest represents the request to create a meeting room
 type CreateMeetingRoomRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Capacity int    `json:"capacity" binding:"required,min=1"`
-	Location string `json:"location"`
+	Name      string   `json:"name" binding:"required"`
+	Capacity  int      `json:"capacity" binding:"required,min=1"`
+	Location  string   `json:"location"`
+	Amenities []string `json:"amenities" binding:"dive,max=50"`
 }
 
 // UpdateMeetingRoomRequest represents the request to update a meeting room
+// Amenities replaces the room's amenities when present; omit it to keep them unchanged
 type UpdateMeetingRoomRequest struct {
-	Name     string `json:"name"`
-	Capacity int    `json:"capacity"`
-	Location string `json:"location"`
+	Name      string   `json:"name"`
+	Capacity  int      `json:"capacity"`
+	Location  string   `json:"location"`
+	Amenities []string `json:"amenities" binding:"omitempty,dive,max=50"`
 }
 
 
@@ -58,6 +110,19 @@ type CreateBookingRequest struct {
 	EndTime       string `json:"end_time" binding:"required"`     // HH:MM format
 }
 
+// CreateBookingForRequest represents the request to book a meeting room on behalf of another employee
+type CreateBookingForRequest struct {
+	CreateBookingRequest
+	EmployeeID uint `json:"employee_id" binding:"required"`
+}
+
+// UpdateBookingRequest represents the request to move a booking to another date or time
+type UpdateBookingRequest struct {
+	BookingDate string `json:"booking_date" binding:"required"` // YYYY-MM-DD format
+	StartTime   string `json:"start_time" binding:"required"`   // HH:MM format
+	EndTime     string `json:"end_time" binding:"required"`     // HH:MM format
+}
+
 // BookingConflictInfo contains information about a conflicting booking
 type BookingConflictInfo struct {
 	BookingID    uint   `json:"booking_id"`
@@ -72,9 +137,10 @@ type BookingConflictInfo struct {
 // Implements Requirement 8.1: Super admin adds new meeting room
 func (s *MeetingRoomService) CreateMeetingRoom(req *CreateMeetingRoomRequest) (*model.MeetingRoom, error) {
 	room := &model.MeetingRoom{
-		Name:     req.Name,
-		Capacity: req.Capacity,
-		Location: req.Location,
+		Name:      req.Name,
+		Capacity:  req.Capacity,
+		Location:  req.Location,
+		Amenities: buildAmenities(req.Amenities),
 	}
 
 	if err := s.roomRepo.Create(room); err != nil {
@@ -96,9 +162,12 @@ func (s *MeetingRoomService) GetMeetingRoomByID(id uint) (*model.MeetingRoom, er
 	return room, nil
 }
 
-// GetAllMeetingRooms retrieves all meeting rooms
-func (s *MeetingRoomService) GetAllMeetingRooms() ([]model.MeetingRoom, error) {
-	return s.roomRepo.GetAll()
+// GetAllMeetingRooms retrieves all meeting rooms matching the filters
+func (s *MeetingRoomService) GetAllMeetingRooms(filters map[string]interface{}) ([]model.MeetingRoom, error) {
+	if amenity, ok := filters["amenity"].(string); ok {
+		filters["amenity"] = normalizeAmenity(amenity)
+	}
+	return s.roomRepo.GetAll(filters)
 }
 
 
@@ -123,8 +192,12 @@ func (s *MeetingRoomService) UpdateMeetingRoom(id uint, req *UpdateMeetingRoomRe
 	if req.Location != "" {
 		room.Location = req.Location
 	}
+	var amenities []model.RoomAmenity
+	if req.Amenities != nil {
+		amenities = buildAmenities(req.Amenities)
+	}
 
-	if err := s.roomRepo.Update(room); err != nil {
+	if err := s.roomRepo.Update(room, amenities); err != nil {
 		return nil, err
 	}
 
@@ -148,13 +221,34 @@ func (s *MeetingRoomService) DeleteMeetingRoom(id uint) error {
 
 // RoomAvailability represents a meeting room's availability for a date
 type RoomAvailability struct {
-	Room     model.MeetingRoom         `json:"room"`
-	Bookings []model.MeetingRoomBooking `json:"bookings"`
+	Room      model.MeetingRoom          `json:"room"`
+	Bookings  []model.MeetingRoomBooking `json:"bookings"`
+	Available *bool                      `json:"available,omitempty"` // whether the requested time slot is free, when one was given
 }
 
 // GetRoomAvailability retrieves a meeting room's availability for a specific date
 // Implements Requirement 8.4: Employee views meeting room availability
-func (s *MeetingRoomService) GetRoomAvailability(roomID uint, dateStr string) (*RoomAvailability, error) {
+// When startTime and endTime are given, the response also reports whether that slot is free
+func (s *MeetingRoomService) GetRoomAvailability(roomID uint, dateStr string, startTime string, endTime string) (*RoomAvailability, error) {
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return nil, errors.New("invalid date format, expected YYYY-MM-DD")
+	}
+
+	var start, end string
+	if startTime != "" || endTime != "" {
+		var startMinutes, endMinutes int
+		if start, startMinutes, err = normalizeBookingTime(startTime); err != nil {
+			return nil, err
+		}
+		if end, endMinutes, err = normalizeBookingTime(endTime); err != nil {
+			return nil, err
+		}
+		if startMinutes >= endMinutes {
+			return nil, errors.New("start time must be before end time")
+		}
+	}
+
 	room, err := s.roomRepo.GetByID(roomID)
 	if err != nil {
 		if errors.Is(err, repository.ErrMeetingRoomNotFound) {
@@ -163,22 +257,182 @@ func (s *MeetingRoomService) GetRoomAvailability(roomID uint, dateStr string) (*
 		return nil, err
 	}
 
+	availability := &RoomAvailability{Room: *room}
+	if start != "" {
+		hasConflict, _, err := s.bookingRepo.HasConflict(roomID, date, start, end)
+		if err != nil {
+			return nil, err
+		}
+		available := !hasConflict
+		availability.Available = &available
+	}
+
+	bookings, err := s.bookingRepo.GetByMeetingRoomAndDate(roomID, date)
+	if err != nil {
+		return nil, err
+	}
+	availability.Bookings = bookings
+
+	return availability, nil
+}
+
+// FreeSlot is a free interval in a meeting room's day
+type FreeSlot struct {
+	StartTime string `json:"start_time"` // HH:MM format
+	EndTime   string `json:"end_time"`   // HH:MM format
+}
+
+// GetFreeSlots computes the intervals within operating hours on a date that are not covered by an
+// active booking and last at least durationMinutes. A zero duration means the minimum booking
+// duration; anything outside the allowed booking durations is rejected. On today's date the part
+// of the day that has already passed is not offered.
+func (s *MeetingRoomService) GetFreeSlots(roomID uint, dateStr string, durationMinutes int) ([]FreeSlot, error) {
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		return nil, errors.New("invalid date format, expected YYYY-MM-DD")
 	}
 
+	duration := s.minDuration
+	if durationMinutes != 0 {
+		duration = time.Duration(durationMinutes) * time.Minute
+	}
+	if duration < s.minDuration || duration > s.maxDuration {
+		return nil, fmt.Errorf("%w: must be between %d and %d minutes", ErrBookingDuration, int(s.minDuration/time.Minute), int(s.maxDuration/time.Minute))
+	}
+
+	if _, err := s.roomRepo.GetByID(roomID); err != nil {
+		if errors.Is(err, repository.ErrMeetingRoomNotFound) {
+			return nil, ErrMeetingRoomNotFound
+		}
+		return nil, err
+	}
+
 	bookings, err := s.bookingRepo.GetByMeetingRoomAndDate(roomID, date)
 	if err != nil {
 		return nil, err
 	}
 
-	return &RoomAvailability{
-		Room:     *room,
-		Bookings: bookings,
-	}, nil
+	cursor := s.openTime
+	if date.Equal(Today()) {
+		now := time.Now().In(location)
+		elapsed := (now.Sub(dayStart(now)) + time.Minute - 1).Truncate(time.Minute)
+		if elapsed > cursor {
+			cursor = elapsed
+		}
+	}
+
+	slots := []FreeSlot{}
+	addSlot := func(start, end time.Duration) {
+		if end > s.closeTime {
+			end = s.closeTime
+		}
+		if end-start >= duration {
+			slots = append(slots, FreeSlot{StartTime: formatClock(start), EndTime: formatClock(end)})
+		}
+	}
+
+	// Bookings come sorted by start time, so the gaps lie between the furthest end seen so far and the next start
+	for _, booking := range bookings {
+		start, err := parseClock(booking.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(booking.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if start > cursor {
+			addSlot(cursor, start)
+		}
+		if end > cursor {
+			cursor = end
+		}
+	}
+	addSlot(cursor, s.closeTime)
+
+	return slots, nil
+}
+
+// formatClock formats an offset from midnight as HH:MM
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int(offset%time.Hour/time.Minute))
+}
+
+// maxAvailabilityDays caps the window of the multi-room availability view
+const maxAvailabilityDays = 14
+
+// DayBookings holds a room's active bookings on one day
+type DayBookings struct {
+	Date     string                     `json:"date"` // YYYY-MM-DD format
+	Bookings []model.MeetingRoomBooking `json:"bookings"`
+}
+
+// RoomSchedule holds a room's bookings for each day of a window
+type RoomSchedule struct {
+	Room model.MeetingRoom `json:"room"`
+	Days []DayBookings     `json:"days"`
 }
 
+// GetAvailabilityRange retrieves every room's active bookings for each day starting at startStr
+// An empty startStr means today; days is capped at maxAvailabilityDays
+func (s *MeetingRoomService) GetAvailabilityRange(startStr string, days int) ([]RoomSchedule, error) {
+	var start time.Time
+	if startStr == "" {
+		start = Today()
+	} else {
+		var err error
+		start, err = time.Parse("2006-01-02", startStr)
+		if err != nil {
+			return nil, errors.New("invalid date format, expected YYYY-MM-DD")
+		}
+	}
+	if days < 1 {
+		return nil, errors.New("days must be at least 1")
+	}
+	if days > maxAvailabilityDays {
+		days = maxAvailabilityDays
+	}
+
+	dates := make([]time.Time, days)
+	for i := range dates {
+		dates[i] = start.AddDate(0, 0, i)
+	}
+
+	rooms, err := s.roomRepo.GetAll(map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := s.bookingRepo.GetActiveByDates(dates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group bookings by room and day
+	byRoomDay := make(map[uint]map[string][]model.MeetingRoomBooking)
+	for _, booking := range bookings {
+		day := booking.BookingDate.Format("2006-01-02")
+		if byRoomDay[booking.MeetingRoomID] == nil {
+			byRoomDay[booking.MeetingRoomID] = make(map[string][]model.MeetingRoomBooking)
+		}
+		byRoomDay[booking.MeetingRoomID][day] = append(byRoomDay[booking.MeetingRoomID][day], booking)
+	}
+
+	schedules := make([]RoomSchedule, 0, len(rooms))
+	for _, room := range rooms {
+		schedule := RoomSchedule{Room: room, Days: make([]DayBookings, 0, days)}
+		for _, date := range dates {
+			day := date.Format("2006-01-02")
+			dayBookings := byRoomDay[room.ID][day]
+			if dayBookings == nil {
+				dayBookings = []model.MeetingRoomBooking{}
+			}
+			schedule.Days = append(schedule.Days, DayBookings{Date: day, Bookings: dayBookings})
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
 
 // ===== Booking Management =====
 
@@ -187,72 +441,212 @@ func (s *MeetingRoomService) GetRoomAvailability(roomID uint, dateStr string) (*
 // Implements Property 13: 员工单预定限制
 // Implements Requirement 8.5, 8.6, 8.7, 8.8: Booking with conflict check and single booking limit
 func (s *MeetingRoomService) CreateBooking(employeeID uint, req *CreateBookingRequest) (*model.MeetingRoomBooking, *BookingConflictInfo, error) {
-	// Validate meeting room exists
-	_, err := s.roomRepo.GetByID(req.MeetingRoomID)
+	return s.createBooking(employeeID, nil, req)
+}
+
+// CreateBookingFor books a meeting room on behalf of another employee
+// The booking belongs to the target employee, so the single-booking limit applies to them,
+// and the creator is recorded in CreatedBy
+func (s *MeetingRoomService) CreateBookingFor(creatorID uint, req *CreateBookingForRequest) (*model.MeetingRoomBooking, *BookingConflictInfo, error) {
+	employee, err := s.employeeRepo.GetByID(req.EmployeeID)
 	if err != nil {
-		if errors.Is(err, repository.ErrMeetingRoomNotFound) {
-			return nil, nil, ErrMeetingRoomNotFound
+		if errors.Is(err, repository.ErrEmployeeNotFound) {
+			return nil, nil, ErrBookingEmployeeNotFound
 		}
 		return nil, nil, err
 	}
+	if !employee.IsActive {
+		return nil, nil, ErrBookingEmployeeNotFound
+	}
+
+	return s.createBooking(employee.ID, &creatorID, &req.CreateBookingRequest)
+}
 
-	// Parse booking date
-	bookingDate, err := time.Parse("2006-01-02", req.BookingDate)
+// createBooking validates and creates a booking owned by employeeID
+// The conflict check and the insert happen in one transaction holding the room's lock, so a
+// booking cannot take a slot that a waitlist promotion is filling at the same time
+func (s *MeetingRoomService) createBooking(employeeID uint, createdBy *uint, req *CreateBookingRequest) (*model.MeetingRoomBooking, *BookingConflictInfo, error) {
+	// Validate the request before touching the database
+	bookingDate, startTime, endTime, err := s.parseBookingSlot(req.BookingDate, req.StartTime, req.EndTime)
 	if err != nil {
-		return nil, nil, errors.New("invalid date format, expected YYYY-MM-DD")
+		return nil, nil, err
 	}
 
-	// Validate time format and order
-	if req.StartTime >= req.EndTime {
-		return nil, nil, errors.New("start time must be before end time")
+	if err := s.checkLeaveOn(s.leaveRepo, employeeID, bookingDate); err != nil {
+		return nil, nil, err
 	}
 
 	// 先自动完成过期的预定
 	s.autoCompleteExpiredBookings(employeeID)
-	
-	// Property 13: Check if employee already has an active booking
-	hasActive, err := s.bookingRepo.HasActiveBooking(employeeID)
-	if err != nil {
-		return nil, nil, err
+
+	booking := &model.MeetingRoomBooking{
+		EmployeeID:    employeeID,
+		MeetingRoomID: req.MeetingRoomID,
+		BookingDate:   bookingDate,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		Status:        model.BookingStatusActive,
+		CreatedBy:     createdBy,
 	}
-	if hasActive {
-		return nil, nil, ErrBookingLimitExceeded
+
+	var conflictInfo *BookingConflictInfo
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Validate meeting room exists
+		if err := repository.NewMeetingRoomRepository(tx).LockByID(req.MeetingRoomID); err != nil {
+			if errors.Is(err, repository.ErrMeetingRoomNotFound) {
+				return ErrMeetingRoomNotFound
+			}
+			return err
+		}
+
+		bookingRepo := repository.NewMeetingRoomBookingRepository(tx)
+
+		// Property 13: Check if employee already has an active booking
+		hasActive, err := bookingRepo.HasActiveBooking(employeeID)
+		if err != nil {
+			return err
+		}
+		if hasActive {
+			return ErrBookingLimitExceeded
+		}
+
+		// Property 12: Check for booking conflicts
+		hasConflict, conflictBooking, err := bookingRepo.HasConflict(req.MeetingRoomID, bookingDate, startTime, endTime)
+		if err != nil {
+			return err
+		}
+		if hasConflict {
+			conflictInfo = newBookingConflictInfo(conflictBooking)
+			return ErrBookingConflict
+		}
+
+		return bookingRepo.Create(booking)
+	})
+	if err != nil {
+		return nil, conflictInfo, err
 	}
 
-	// Property 12: Check for booking conflicts
-	hasConflict, conflictBooking, err := s.bookingRepo.HasConflict(req.MeetingRoomID, bookingDate, req.StartTime, req.EndTime)
+	// Reload with associations
+	result, err := s.bookingRepo.GetByID(booking.ID)
+	return result, nil, err
+}
+
+
+// UpdateBooking moves one of the employee's active bookings to another date or time in the same room
+// The conflict check ignores the booking's own slot, and the check and the move happen in one
+// transaction holding the room's lock, so the booking never gives up its slot before gaining the new one
+func (s *MeetingRoomService) UpdateBooking(bookingID uint, employeeID uint, req *UpdateBookingRequest) (*model.MeetingRoomBooking, *BookingConflictInfo, error) {
+	bookingDate, startTime, endTime, err := s.parseBookingSlot(req.BookingDate, req.StartTime, req.EndTime)
 	if err != nil {
 		return nil, nil, err
 	}
-	if hasConflict {
-		conflictInfo := &BookingConflictInfo{
-			BookingID:    conflictBooking.ID,
-			EmployeeName: conflictBooking.Employee.Name,
-			StartTime:    conflictBooking.StartTime,
-			EndTime:      conflictBooking.EndTime,
+
+	booking, err := s.bookingRepo.GetByID(bookingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			return nil, nil, ErrBookingNotFound
 		}
-		return nil, conflictInfo, ErrBookingConflict
+		return nil, nil, err
 	}
 
-	// Create booking
-	booking := &model.MeetingRoomBooking{
-		EmployeeID:    employeeID,
-		MeetingRoomID: req.MeetingRoomID,
-		BookingDate:   bookingDate,
-		StartTime:     req.StartTime,
-		EndTime:       req.EndTime,
-		Status:        model.BookingStatusActive,
+	// Can only modify own booking
+	if booking.EmployeeID != employeeID {
+		return nil, nil, ErrBookingNotOwner
+	}
+
+	// Can only modify active bookings that have not started
+	if booking.Status != model.BookingStatusActive {
+		return nil, nil, ErrBookingInvalidStatus
+	}
+	if booking.CheckedInAt != nil {
+		return nil, nil, ErrBookingAlreadyCheckedIn
 	}
 
-	if err := s.bookingRepo.Create(booking); err != nil {
+	if err := s.checkLeaveOn(s.leaveRepo, employeeID, bookingDate); err != nil {
 		return nil, nil, err
 	}
 
+	var conflictInfo *BookingConflictInfo
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewMeetingRoomRepository(tx).LockByID(booking.MeetingRoomID); err != nil {
+			return err
+		}
+
+		bookingRepo := repository.NewMeetingRoomBookingRepository(tx)
+		hasConflict, conflictBooking, err := bookingRepo.HasConflictExcluding(booking.MeetingRoomID, bookingDate, startTime, endTime, booking.ID)
+		if err != nil {
+			return err
+		}
+		if hasConflict {
+			conflictInfo = newBookingConflictInfo(conflictBooking)
+			return ErrBookingConflict
+		}
+
+		return bookingRepo.Reschedule(booking.ID, bookingDate, startTime, endTime)
+	})
+	if err != nil {
+		return nil, conflictInfo, err
+	}
+
+	// The old slot is free now
+	s.promoteWaitlist(booking.MeetingRoomID, booking.BookingDate)
+
 	// Reload with associations
 	result, err := s.bookingRepo.GetByID(booking.ID)
 	return result, nil, err
 }
 
+// checkLeaveOn refuses a booking on a day the employee is on approved leave, when that rule is enabled
+func (s *MeetingRoomService) checkLeaveOn(leaveRepo *repository.LeaveRepository, employeeID uint, bookingDate time.Time) error {
+	if !s.blockDuringLeave {
+		return nil
+	}
+	onLeave, err := leaveRepo.HasApprovedOn(employeeID, bookingDate)
+	if err != nil {
+		return err
+	}
+	if onLeave {
+		return ErrBookingDuringLeave
+	}
+	return nil
+}
+
+// parseBookingSlot validates a booking's date and time range
+// Times are normalized to zero-padded HH:MM so the string comparisons in conflict checks stay correct
+func (s *MeetingRoomService) parseBookingSlot(date, start, end string) (time.Time, string, string, error) {
+	bookingDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, "", "", errors.New("invalid date format, expected YYYY-MM-DD")
+	}
+
+	// Validate time format, order and duration
+	startTime, startMinutes, err := normalizeBookingTime(start)
+	if err != nil {
+		return time.Time{}, "", "", err
+	}
+	endTime, endMinutes, err := normalizeBookingTime(end)
+	if err != nil {
+		return time.Time{}, "", "", err
+	}
+	if startMinutes >= endMinutes {
+		return time.Time{}, "", "", errors.New("start time must be before end time")
+	}
+	duration := time.Duration(endMinutes-startMinutes) * time.Minute
+	if duration < s.minDuration || duration > s.maxDuration {
+		return time.Time{}, "", "", fmt.Errorf("%w: must be between %d and %d minutes", ErrBookingDuration, int(s.minDuration/time.Minute), int(s.maxDuration/time.Minute))
+	}
+	return bookingDate, startTime, endTime, nil
+}
+
+// newBookingConflictInfo describes the booking that holds a requested slot
+func newBookingConflictInfo(booking *model.MeetingRoomBooking) *BookingConflictInfo {
+	return &BookingConflictInfo{
+		BookingID:    booking.ID,
+		EmployeeName: booking.Employee.Name,
+		StartTime:    booking.StartTime,
+		EndTime:      booking.EndTime,
+	}
+}
 
 // GetBookingByID retrieves a booking by ID
 func (s *MeetingRoomService) GetBookingByID(id uint) (*model.MeetingRoomBooking, error) {
@@ -276,7 +670,7 @@ func (s *MeetingRoomService) GetMyBookings(employeeID uint) ([]model.MeetingRoom
 
 // autoCompleteExpiredBookings automatically completes expired active bookings for an employee
 func (s *MeetingRoomService) autoCompleteExpiredBookings(employeeID uint) {
-	now := time.Now()
+	now := time.Now().In(location)
 	currentDate := now.Format("2006-01-02")
 	currentTime := now.Format("15:04:05")
 	
@@ -314,6 +708,8 @@ func (s *MeetingRoomService) CompleteBooking(bookingID uint, employeeID uint) (*
 		return nil, err
 	}
 
+	s.promoteWaitlist(booking.MeetingRoomID, booking.BookingDate)
+
 	return booking, nil
 }
 
@@ -345,5 +741,125 @@ func (s *MeetingRoomService) CancelBooking(bookingID uint, employeeID uint) (*mo
 		return nil, err
 	}
 
+	s.promoteWaitlist(booking.MeetingRoomID, booking.BookingDate)
+
 	return booking, nil
 }
+
+// CheckInBooking records that the booker has shown up for the booking
+// Check-in is only accepted from the start time until the grace window expires
+func (s *MeetingRoomService) CheckInBooking(bookingID uint, employeeID uint) (*model.MeetingRoomBooking, error) {
+	booking, err := s.bookingRepo.GetByID(bookingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+
+	// Can only check in to own booking
+	if booking.EmployeeID != employeeID {
+		return nil, ErrBookingNotOwner
+	}
+
+	// Can only check in to active bookings
+	if booking.Status != model.BookingStatusActive {
+		return nil, ErrBookingInvalidStatus
+	}
+
+	if booking.CheckedInAt != nil {
+		return nil, ErrBookingAlreadyCheckedIn
+	}
+
+	start, err := bookingStartTime(booking)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if now.Before(start) {
+		return nil, ErrBookingCheckInNotOpen
+	}
+	if !now.Before(start.Add(s.checkInGrace)) {
+		return nil, ErrBookingCheckInExpired
+	}
+
+	booking.CheckedInAt = &now
+	if err := s.bookingRepo.Update(booking); err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+// AutoCancelNoShows cancels active bookings that were not checked in within the
+// grace window after their start time, releasing the slot for others.
+// It returns the number of bookings cancelled.
+// The no-shows are fetched and cancelled in one transaction, so the waitlists promoted
+// afterwards are exactly those of the bookings that were cancelled
+func (s *MeetingRoomService) AutoCancelNoShows(now time.Time) (int64, error) {
+	cutoff := now.Add(-s.checkInGrace).In(location)
+	var noShows []model.MeetingRoomBooking
+	var cancelled int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		bookingRepo := repository.NewMeetingRoomBookingRepository(tx)
+		var err error
+		noShows, err = bookingRepo.GetNoShows(cutoff)
+		if err != nil || len(noShows) == 0 {
+			return err
+		}
+		ids := make([]uint, len(noShows))
+		for i, booking := range noShows {
+			ids[i] = booking.ID
+		}
+		cancelled, err = bookingRepo.CancelNoShows(ids)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.releaseSlots(noShows)
+	return cancelled, nil
+}
+
+// normalizeBookingTime parses an HH:MM 24-hour time, returning it zero-padded (e.g. "9:00" becomes "09:00")
+// along with its minutes since midnight
+func normalizeBookingTime(value string) (string, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return "", 0, ErrBookingInvalidTime
+	}
+	return t.Format("15:04"), t.Hour()*60 + t.Minute(), nil
+}
+
+// bookingStartTime combines a booking's date and HH:MM start time in the configured time zone
+func bookingStartTime(booking *model.MeetingRoomBooking) (time.Time, error) {
+	return slotStartTime(booking.BookingDate, booking.StartTime)
+}
+
+// slotStartTime combines a date and HH:MM start time in the configured time zone
+func slotStartTime(date time.Time, startTime string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02 15:04", date.Format("2006-01-02")+" "+startTime, location)
+}
+
+// normalizeAmenity trims and lowercases an amenity name so "Projector " and "projector" match
+func normalizeAmenity(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// buildAmenities converts amenity names into records, dropping blanks and duplicates
+// The result is never nil so an empty list clears a room's amenities on update
+func buildAmenities(names []string) []model.RoomAmenity {
+	amenities := make([]model.RoomAmenity, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = normalizeAmenity(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		amenities = append(amenities, model.RoomAmenity{Name: name})
+	}
+	return amenities
+}
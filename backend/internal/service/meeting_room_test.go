@@ -0,0 +1,449 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+)
+
+// testBookingConfig allows bookings of 15 minutes to 4 hours between 08:00 and 20:00
+func testBookingConfig() *config.BookingConfig {
+	return &config.BookingConfig{
+		CheckInGraceMinutes: 15,
+		MinDurationMinutes:  15,
+		MaxDurationMinutes:  240,
+		OpenTime:            "08:00",
+		CloseTime:           "20:00",
+	}
+}
+
+// createRoom inserts a meeting room
+func createRoom(t *testing.T, db *gorm.DB, name string, capacity int) *model.MeetingRoom {
+	t.Helper()
+	room := &model.MeetingRoom{Name: name, Capacity: capacity}
+	if err := db.Create(room).Error; err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	return room
+}
+
+// createBooking inserts an active booking without going through validation
+func createBooking(t *testing.T, db *gorm.DB, employeeID, roomID uint, day time.Time, start, end string) *model.MeetingRoomBooking {
+	t.Helper()
+	booking := &model.MeetingRoomBooking{
+		EmployeeID:    employeeID,
+		MeetingRoomID: roomID,
+		BookingDate:   day,
+		StartTime:     start,
+		EndTime:       end,
+		Status:        model.BookingStatusActive,
+	}
+	if err := db.Create(booking).Error; err != nil {
+		t.Fatalf("create booking: %v", err)
+	}
+	return booking
+}
+
+// bookingStatus reloads a booking's status
+func bookingStatus(t *testing.T, db *gorm.DB, id uint) string {
+	t.Helper()
+	var booking model.MeetingRoomBooking
+	if err := db.First(&booking, id).Error; err != nil {
+		t.Fatalf("load booking %d: %v", id, err)
+	}
+	return booking.Status
+}
+
+func TestAutoCancelNoShows(t *testing.T) {
+	db := testutil.NewDB(t)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	room := createRoom(t, db, "A", 6)
+	s := NewMeetingRoomService(db, testBookingConfig())
+
+	day := date(2026, 3, 2)
+	noShow := createBooking(t, db, employee.ID, room.ID, day, "09:00", "10:00")
+	checkedIn := createBooking(t, db, employee.ID, room.ID, day, "09:00", "10:00")
+	checkedInAt := time.Date(2026, 3, 2, 9, 5, 0, 0, time.UTC)
+	db.Model(checkedIn).Update("checked_in_at", checkedInAt)
+	withinGrace := createBooking(t, db, employee.ID, room.ID, day, "09:10", "10:00")
+	later := createBooking(t, db, employee.ID, room.ID, day, "11:00", "12:00")
+
+	cancelled, err := s.AutoCancelNoShows(time.Date(2026, 3, 2, 9, 20, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("AutoCancelNoShows: %v", err)
+	}
+	if cancelled != 1 {
+		t.Errorf("cancelled = %d, want 1", cancelled)
+	}
+
+	want := map[uint]string{
+		noShow.ID:      model.BookingStatusCancelled,
+		checkedIn.ID:   model.BookingStatusActive,
+		withinGrace.ID: model.BookingStatusActive,
+		later.ID:       model.BookingStatusActive,
+	}
+	for id, status := range want {
+		if got := bookingStatus(t, db, id); got != status {
+			t.Errorf("booking %d status = %q, want %q", id, got, status)
+		}
+	}
+}
+
+func TestFilterMeetingRooms(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	for _, req := range []CreateMeetingRoomRequest{
+		{Name: "small", Capacity: 4, Amenities: []string{"Projector", "whiteboard"}},
+		{Name: "large", Capacity: 12, Amenities: []string{"projector "}},
+		{Name: "hall", Capacity: 40, Amenities: []string{"whiteboard"}},
+	} {
+		if _, err := s.CreateMeetingRoom(&req); err != nil {
+			t.Fatalf("CreateMeetingRoom %s: %v", req.Name, err)
+		}
+	}
+
+	names := func(filters map[string]interface{}) []string {
+		t.Helper()
+		rooms, err := s.GetAllMeetingRooms(filters)
+		if err != nil {
+			t.Fatalf("GetAllMeetingRooms(%v): %v", filters, err)
+		}
+		names := []string{}
+		for _, room := range rooms {
+			names = append(names, room.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	if got := names(map[string]interface{}{"amenity": "PROJECTOR"}); !slices.Equal(got, []string{"large", "small"}) {
+		t.Errorf("rooms with a projector = %v, want [large small]", got)
+	}
+	if got := names(map[string]interface{}{"min_capacity": 10}); !slices.Equal(got, []string{"hall", "large"}) {
+		t.Errorf("rooms for 10 = %v, want [hall large]", got)
+	}
+	if got := names(map[string]interface{}{"amenity": "projector", "min_capacity": 10}); !slices.Equal(got, []string{"large"}) {
+		t.Errorf("rooms for 10 with a projector = %v, want [large]", got)
+	}
+}
+
+func TestSearchMeetingRooms(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	for _, req := range []CreateMeetingRoomRequest{
+		{Name: "Everest", Capacity: 4, Location: "3F East"},
+		{Name: "K2", Capacity: 12, Location: "3F West"},
+		{Name: "Lhotse", Capacity: 8, Location: "5F"},
+	} {
+		if _, err := s.CreateMeetingRoom(&req); err != nil {
+			t.Fatalf("CreateMeetingRoom %s: %v", req.Name, err)
+		}
+	}
+
+	names := func(filters map[string]interface{}) []string {
+		t.Helper()
+		rooms, err := s.GetAllMeetingRooms(filters)
+		if err != nil {
+			t.Fatalf("GetAllMeetingRooms(%v): %v", filters, err)
+		}
+		names := []string{}
+		for _, room := range rooms {
+			names = append(names, room.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	if got := names(map[string]interface{}{"q": "ever"}); !slices.Equal(got, []string{"Everest"}) {
+		t.Errorf("rooms matching ever = %v, want [Everest]", got)
+	}
+	if got := names(map[string]interface{}{"q": "3f"}); !slices.Equal(got, []string{"Everest", "K2"}) {
+		t.Errorf("rooms matching 3f = %v, want [Everest K2]", got)
+	}
+	if got := names(map[string]interface{}{"q": "3F", "min_capacity": 10}); !slices.Equal(got, []string{"K2"}) {
+		t.Errorf("rooms for 10 matching 3F = %v, want [K2]", got)
+	}
+	if got := names(map[string]interface{}{"q": "%"}); len(got) != 0 {
+		t.Errorf("rooms matching a literal %% = %v, want none", got)
+	}
+}
+
+func TestCreateBookingFor(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	assistant := testutil.CreateEmployee(t, db, "assistant", model.RoleSuperAdmin)
+	boss := testutil.CreateEmployee(t, db, "boss", model.RoleSupervisor)
+	room := createRoom(t, db, "A", 6)
+	other := createRoom(t, db, "B", 6)
+	day := Today().AddDate(0, 0, 7).Format("2006-01-02")
+
+	booking, _, err := s.CreateBookingFor(assistant.ID, &CreateBookingForRequest{
+		CreateBookingRequest: CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: day, StartTime: "10:00", EndTime: "11:00"},
+		EmployeeID:           boss.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateBookingFor: %v", err)
+	}
+	if booking.EmployeeID != boss.ID || booking.CreatedBy == nil || *booking.CreatedBy != assistant.ID {
+		t.Errorf("booking owner = %d, created by %v, want %d created by %d", booking.EmployeeID, booking.CreatedBy, boss.ID, assistant.ID)
+	}
+
+	// The assistant holds no booking of their own, the target does
+	if _, _, err := s.CreateBooking(assistant.ID, &CreateBookingRequest{MeetingRoomID: other.ID, BookingDate: day, StartTime: "10:00", EndTime: "11:00"}); err != nil {
+		t.Errorf("assistant's own booking: %v", err)
+	}
+	_, _, err = s.CreateBookingFor(assistant.ID, &CreateBookingForRequest{
+		CreateBookingRequest: CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: day, StartTime: "14:00", EndTime: "15:00"},
+		EmployeeID:           boss.ID,
+	})
+	if !errors.Is(err, ErrBookingLimitExceeded) {
+		t.Errorf("second booking for the same target: err = %v, want ErrBookingLimitExceeded", err)
+	}
+
+	// The slot is taken whoever asks for it
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	_, conflict, err := s.CreateBookingFor(assistant.ID, &CreateBookingForRequest{
+		CreateBookingRequest: CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: day, StartTime: "10:30", EndTime: "11:30"},
+		EmployeeID:           carol.ID,
+	})
+	if !errors.Is(err, ErrBookingConflict) || conflict == nil || conflict.BookingID != booking.ID {
+		t.Errorf("overlapping booking for another target: err = %v, conflict = %+v", err, conflict)
+	}
+}
+
+func TestBookingDuration(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	room := createRoom(t, db, "A", 6)
+	day := Today().AddDate(0, 0, 7).Format("2006-01-02")
+
+	tests := []struct {
+		name       string
+		start, end string
+		want       error
+	}{
+		{"too short", "10:00", "10:10", ErrBookingDuration},
+		{"too long", "09:00", "13:30", ErrBookingDuration},
+		{"malformed", "10:00", "11:6O", ErrBookingInvalidTime},
+		{"valid", "10:00", "10:15", nil},
+	}
+	for _, tt := range tests {
+		employee := testutil.CreateEmployee(t, db, strings.ReplaceAll(tt.name, " ", "-"), model.RoleEmployee)
+		_, _, err := s.CreateBooking(employee.ID, &CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: day, StartTime: tt.start, EndTime: tt.end})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s (%s-%s): err = %v, want %v", tt.name, tt.start, tt.end, err, tt.want)
+		}
+	}
+}
+
+func TestBookingTimeFormat(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	room := createRoom(t, db, "A", 6)
+	day := Today().AddDate(0, 0, 7).Format("2006-01-02")
+
+	booking, _, err := s.CreateBooking(alice.ID, &CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: day, StartTime: "9:00", EndTime: "10:00"})
+	if err != nil {
+		t.Fatalf("CreateBooking 9:00: %v", err)
+	}
+	if booking.StartTime != "09:00" {
+		t.Errorf("start time = %q, want 09:00", booking.StartTime)
+	}
+	// "9:30" compares after "10:00" as a string, so it only conflicts once normalized
+	if _, _, err := s.CreateBooking(bob.ID, &CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: day, StartTime: "9:30", EndTime: "10:30"}); !errors.Is(err, ErrBookingConflict) {
+		t.Errorf("overlapping 9:30 booking: err = %v, want ErrBookingConflict", err)
+	}
+	availability, err := s.GetRoomAvailability(room.ID, day, "9:30", "9:45")
+	if err != nil {
+		t.Fatalf("GetRoomAvailability: %v", err)
+	}
+	if availability.Available == nil || *availability.Available {
+		t.Error("9:30-9:45 reported free during the 09:00 booking")
+	}
+
+	if _, _, err := s.CreateBooking(bob.ID, &CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: day, StartTime: "23:30", EndTime: "24:30"}); !errors.Is(err, ErrBookingInvalidTime) {
+		t.Errorf("24:30: err = %v, want ErrBookingInvalidTime", err)
+	}
+	if _, err := s.GetRoomAvailability(room.ID, day, "23:30", "24:30"); !errors.Is(err, ErrBookingInvalidTime) {
+		t.Errorf("availability for 24:30: err = %v, want ErrBookingInvalidTime", err)
+	}
+}
+
+func TestAvailabilityRange(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	roomA := createRoom(t, db, "A", 6)
+	roomB := createRoom(t, db, "B", 6)
+	start := date(2026, 3, 2)
+	createBooking(t, db, alice.ID, roomA.ID, start, "09:00", "10:00")
+	createBooking(t, db, alice.ID, roomB.ID, start.AddDate(0, 0, 6), "09:00", "10:00")
+	createBooking(t, db, alice.ID, roomA.ID, start.AddDate(0, 0, 7), "09:00", "10:00")
+
+	schedules, err := s.GetAvailabilityRange("2026-03-02", 7)
+	if err != nil {
+		t.Fatalf("GetAvailabilityRange: %v", err)
+	}
+	if len(schedules) != 2 {
+		t.Fatalf("got %d rooms, want 2", len(schedules))
+	}
+	booked := 0
+	for _, schedule := range schedules {
+		if len(schedule.Days) != 7 || schedule.Days[0].Date != "2026-03-02" || schedule.Days[6].Date != "2026-03-08" {
+			t.Errorf("room %s days = %+v, want 2026-03-02 through 2026-03-08", schedule.Room.Name, schedule.Days)
+		}
+		for _, day := range schedule.Days {
+			booked += len(day.Bookings)
+		}
+	}
+	if booked != 2 {
+		t.Errorf("bookings in the window = %d, want 2", booked)
+	}
+
+	schedules, err = s.GetAvailabilityRange("2026-03-02", 60)
+	if err != nil {
+		t.Fatalf("GetAvailabilityRange over the cap: %v", err)
+	}
+	if got := len(schedules[0].Days); got != maxAvailabilityDays {
+		t.Errorf("days = %d, want the cap of %d", got, maxAvailabilityDays)
+	}
+}
+
+func TestUpdateBooking(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	room := createRoom(t, db, "A", 6)
+	day := Today().AddDate(0, 0, 7)
+	booking := createBooking(t, db, alice.ID, room.ID, day, "10:00", "11:00")
+	taken := createBooking(t, db, bob.ID, room.ID, day, "14:00", "15:00")
+	move := func(start, end string) *UpdateBookingRequest {
+		return &UpdateBookingRequest{BookingDate: day.Format("2006-01-02"), StartTime: start, EndTime: end}
+	}
+
+	// Overlapping its own current slot is not a conflict
+	moved, _, err := s.UpdateBooking(booking.ID, alice.ID, move("10:30", "11:30"))
+	if err != nil {
+		t.Fatalf("move to a free slot: %v", err)
+	}
+	if moved.StartTime != "10:30" || moved.EndTime != "11:30" {
+		t.Errorf("moved to %s-%s, want 10:30-11:30", moved.StartTime, moved.EndTime)
+	}
+
+	_, conflict, err := s.UpdateBooking(booking.ID, alice.ID, move("14:30", "15:30"))
+	if !errors.Is(err, ErrBookingConflict) {
+		t.Fatalf("move to an occupied slot: err = %v, want ErrBookingConflict", err)
+	}
+	if conflict == nil || conflict.BookingID != taken.ID || conflict.StartTime != "14:00" || conflict.EndTime != "15:00" {
+		t.Errorf("conflict = %+v, want bob's 14:00-15:00 booking %d", conflict, taken.ID)
+	}
+	var stored model.MeetingRoomBooking
+	if err := db.First(&stored, booking.ID).Error; err != nil {
+		t.Fatalf("load booking: %v", err)
+	}
+	if stored.StartTime != "10:30" {
+		t.Errorf("rejected move changed the booking to %s", stored.StartTime)
+	}
+
+	if _, _, err := s.UpdateBooking(taken.ID, alice.ID, move("16:00", "17:00")); !errors.Is(err, ErrBookingNotOwner) {
+		t.Errorf("move someone else's booking: err = %v, want ErrBookingNotOwner", err)
+	}
+	if err := db.Model(taken).Update("status", model.BookingStatusCancelled).Error; err != nil {
+		t.Fatalf("cancel booking: %v", err)
+	}
+	if _, _, err := s.UpdateBooking(taken.ID, bob.ID, move("16:00", "17:00")); !errors.Is(err, ErrBookingInvalidStatus) {
+		t.Errorf("move a cancelled booking: err = %v, want ErrBookingInvalidStatus", err)
+	}
+}
+
+func TestGetFreeSlots(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewMeetingRoomService(db, testBookingConfig())
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	room := createRoom(t, db, "A", 6)
+	full := createRoom(t, db, "B", 6)
+	day := Today().AddDate(0, 0, 7)
+	slot := day.Format("2006-01-02")
+	createBooking(t, db, alice.ID, room.ID, day, "10:00", "11:00")
+	createBooking(t, db, alice.ID, room.ID, day, "13:00", "14:30")
+	cancelled := createBooking(t, db, alice.ID, room.ID, day, "16:00", "17:00")
+	db.Model(cancelled).Update("status", model.BookingStatusCancelled)
+	for _, window := range [][2]string{{"08:00", "12:00"}, {"12:00", "16:00"}, {"16:00", "20:00"}} {
+		createBooking(t, db, alice.ID, full.ID, day, window[0], window[1])
+	}
+
+	slots, err := s.GetFreeSlots(room.ID, slot, 60)
+	if err != nil {
+		t.Fatalf("GetFreeSlots: %v", err)
+	}
+	want := []FreeSlot{{"08:00", "10:00"}, {"11:00", "13:00"}, {"14:30", "20:00"}}
+	if !slices.Equal(slots, want) {
+		t.Errorf("free slots = %v, want %v", slots, want)
+	}
+
+	// Gaps shorter than the requested duration are left out
+	slots, err = s.GetFreeSlots(room.ID, slot, 150)
+	if err != nil {
+		t.Fatalf("GetFreeSlots for 150 minutes: %v", err)
+	}
+	if want := []FreeSlot{{"14:30", "20:00"}}; !slices.Equal(slots, want) {
+		t.Errorf("free slots of 150 minutes = %v, want %v", slots, want)
+	}
+
+	slots, err = s.GetFreeSlots(full.ID, slot, 0)
+	if err != nil {
+		t.Fatalf("GetFreeSlots on a fully booked day: %v", err)
+	}
+	if len(slots) != 0 {
+		t.Errorf("free slots on a fully booked day = %v, want none", slots)
+	}
+
+	if _, err := s.GetFreeSlots(room.ID, slot, 5); !errors.Is(err, ErrBookingDuration) {
+		t.Errorf("duration below the minimum: err = %v, want ErrBookingDuration", err)
+	}
+}
+
+func TestBookingDuringLeave(t *testing.T) {
+	db := testutil.NewDB(t)
+	cfg := testBookingConfig()
+	cfg.BlockDuringLeave = true
+	s := NewMeetingRoomService(db, cfg)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	room := createRoom(t, db, "A", 6)
+	day := Today().AddDate(0, 0, 7)
+	createLeave(t, db, alice.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusApproved)
+	createLeave(t, db, bob.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusPending)
+	createLeave(t, db, carol.ID, model.LeaveTypeAnnual, day, day, model.LeaveStatusApproved)
+	book := func(s *MeetingRoomService, employee *model.Employee, on time.Time, start, end string) error {
+		_, _, err := s.CreateBooking(employee.ID, &CreateBookingRequest{MeetingRoomID: room.ID, BookingDate: on.Format("2006-01-02"), StartTime: start, EndTime: end})
+		return err
+	}
+
+	if err := book(s, alice, day, "09:00", "10:00"); !errors.Is(err, ErrBookingDuringLeave) {
+		t.Errorf("booking on a leave day: err = %v, want ErrBookingDuringLeave", err)
+	}
+	if err := book(s, alice, day.AddDate(0, 0, 1), "09:00", "10:00"); err != nil {
+		t.Errorf("booking the day after the leave: %v", err)
+	}
+	// Leave that is not approved yet does not block
+	if err := book(s, bob, day, "10:00", "11:00"); err != nil {
+		t.Errorf("booking during pending leave: %v", err)
+	}
+	// With the rule off, leave does not block bookings at all
+	if err := book(NewMeetingRoomService(db, testBookingConfig()), carol, day, "11:00", "12:00"); err != nil {
+		t.Errorf("booking on a leave day with the rule disabled: %v", err)
+	}
+}
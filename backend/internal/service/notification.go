@@ -0,0 +1,215 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+var (
+	ErrUnknownNotificationType = errors.New("unknown notification type")
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrNotificationNotOwner    = errors.New("can only operate on own notification")
+)
+
+// NotificationService handles notification business logic
+type NotificationService struct {
+	repo         *repository.NotificationRepository
+	employeeRepo *repository.EmployeeRepository
+	db           *gorm.DB
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{
+		repo:         repository.NewNotificationRepository(db),
+		employeeRepo: repository.NewEmployeeRepository(db),
+		db:           db,
+	}
+}
+
+// Notify sends a notification to a single employee, in-app unless they have muted its type and
+// by email if they have opted into it
+func (s *NotificationService) Notify(notification *model.Notification) error {
+	inAppMuted, emailEnabled, err := s.deliveryPreferences([]uint{notification.EmployeeID}, notification.Type)
+	if err != nil {
+		return err
+	}
+
+	if !inAppMuted[notification.EmployeeID] {
+		if err := s.repo.Create(notification); err != nil {
+			return err
+		}
+		publishNotifications(*notification)
+	}
+
+	if emailEnabled[notification.EmployeeID] {
+		recipient, err := s.employeeRepo.GetByID(notification.EmployeeID)
+		if err != nil {
+			return err
+		}
+		emailNotification(recipient, *notification)
+	}
+	return nil
+}
+
+// NotifyRoles sends a copy of the notification to every active employee holding one of the roles,
+// honouring each recipient's in-app and email preferences for its type
+func (s *NotificationService) NotifyRoles(roles []string, notification model.Notification) error {
+	recipients, err := s.employeeRepo.GetActiveByRoles(roles)
+	if err != nil {
+		return err
+	}
+
+	recipientIDs := make([]uint, len(recipients))
+	for i, recipient := range recipients {
+		recipientIDs[i] = recipient.ID
+	}
+	inAppMuted, emailEnabled, err := s.deliveryPreferences(recipientIDs, notification.Type)
+	if err != nil {
+		return err
+	}
+
+	notifications := make([]model.Notification, 0, len(recipients))
+	for _, id := range recipientIDs {
+		if inAppMuted[id] {
+			continue
+		}
+		copied := notification
+		copied.EmployeeID = id
+		notifications = append(notifications, copied)
+	}
+
+	if err := s.repo.CreateBatch(notifications); err != nil {
+		return err
+	}
+	publishNotifications(notifications...)
+
+	for i := range recipients {
+		if emailEnabled[recipients[i].ID] {
+			copied := notification
+			copied.EmployeeID = recipients[i].ID
+			emailNotification(&recipients[i], copied)
+		}
+	}
+	return nil
+}
+
+// deliveryPreferences returns which of the employees have muted the notification type in-app and
+// which have opted into it by email; employees without a stored preference get the defaults
+func (s *NotificationService) deliveryPreferences(employeeIDs []uint, notificationType string) (map[uint]bool, map[uint]bool, error) {
+	preferences, err := s.repo.GetPreferencesForType(employeeIDs, notificationType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inAppMuted := make(map[uint]bool)
+	emailEnabled := make(map[uint]bool)
+	for _, preference := range preferences {
+		inAppMuted[preference.EmployeeID] = !preference.InApp
+		emailEnabled[preference.EmployeeID] = preference.Email
+	}
+	return inAppMuted, emailEnabled, nil
+}
+
+// HasUnreadOfType checks if an employee has an unread notification of the given type
+func (s *NotificationService) HasUnreadOfType(employeeID uint, notificationType string) (bool, error) {
+	return s.repo.HasUnreadOfType(employeeID, notificationType)
+}
+
+// Delete removes one of the employee's notifications
+func (s *NotificationService) Delete(id uint, employeeID uint) error {
+	notification, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			return ErrNotificationNotFound
+		}
+		return err
+	}
+
+	if notification.EmployeeID != employeeID {
+		return ErrNotificationNotOwner
+	}
+
+	return s.repo.Delete(id)
+}
+
+// Clear removes the employee's notifications, only the read ones if readOnly is set, and returns
+// how many were removed
+func (s *NotificationService) Clear(employeeID uint, readOnly bool) (int64, error) {
+	return s.repo.DeleteByEmployeeID(employeeID, readOnly)
+}
+
+// UpdateNotificationPreferencesRequest represents the request to change notification preferences
+// Types left out keep their current setting
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceInput `json:"preferences" binding:"required,dive"`
+}
+
+// NotificationPreferenceInput represents the desired delivery channels for one notification type
+type NotificationPreferenceInput struct {
+	Type  string `json:"type" binding:"required"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+}
+
+// GetPreferences returns the employee's preference for every notification type, filling in the
+// defaults (in-app on, email off) for types they have not set
+func (s *NotificationService) GetPreferences(employeeID uint) ([]model.NotificationPreference, error) {
+	stored, err := s.repo.GetPreferences(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string]model.NotificationPreference, len(stored))
+	for _, preference := range stored {
+		byType[preference.Type] = preference
+	}
+
+	types := model.AllNotificationTypes()
+	preferences := make([]model.NotificationPreference, len(types))
+	for i, notificationType := range types {
+		preference, ok := byType[notificationType]
+		if !ok {
+			preference = model.NotificationPreference{EmployeeID: employeeID, Type: notificationType, InApp: true}
+		}
+		preferences[i] = preference
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences saves the employee's preferences for the given types
+func (s *NotificationService) UpdatePreferences(employeeID uint, req *UpdateNotificationPreferencesRequest) ([]model.NotificationPreference, error) {
+	known := make(map[string]bool)
+	for _, notificationType := range model.AllNotificationTypes() {
+		known[notificationType] = true
+	}
+
+	// A type listed twice keeps its last setting, so the upsert never touches a row twice
+	preferences := make([]model.NotificationPreference, 0, len(req.Preferences))
+	index := make(map[string]int, len(req.Preferences))
+	for _, input := range req.Preferences {
+		if !known[input.Type] {
+			return nil, ErrUnknownNotificationType
+		}
+		preference := model.NotificationPreference{
+			EmployeeID: employeeID,
+			Type:       input.Type,
+			InApp:      input.InApp,
+			Email:      input.Email,
+		}
+		if i, ok := index[input.Type]; ok {
+			preferences[i] = preference
+			continue
+		}
+		index[input.Type] = len(preferences)
+		preferences = append(preferences, preference)
+	}
+
+	if err := s.repo.SavePreferences(preferences); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(employeeID)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"sync"
+
+	"oa-system/internal/model"
+)
+
+// notificationStreamBuffer is how many notifications a subscriber may fall behind before
+// further ones are dropped from its stream; they remain stored either way
+const notificationStreamBuffer = 16
+
+// notificationHub fans newly created notifications out to the live streams of their recipients.
+// It is shared by every NotificationService since services create their own instances
+var notificationHub = struct {
+	sync.Mutex
+	subscribers map[uint]map[chan model.Notification]struct{}
+}{
+	subscribers: make(map[uint]map[chan model.Notification]struct{}),
+}
+
+// Subscribe registers a live stream for the employee's new notifications. The returned function
+// must be called once the stream ends to release it
+func (s *NotificationService) Subscribe(employeeID uint) (<-chan model.Notification, func()) {
+	ch := make(chan model.Notification, notificationStreamBuffer)
+
+	notificationHub.Lock()
+	if notificationHub.subscribers[employeeID] == nil {
+		notificationHub.subscribers[employeeID] = make(map[chan model.Notification]struct{})
+	}
+	notificationHub.subscribers[employeeID][ch] = struct{}{}
+	notificationHub.Unlock()
+
+	unsubscribe := func() {
+		notificationHub.Lock()
+		defer notificationHub.Unlock()
+		delete(notificationHub.subscribers[employeeID], ch)
+		if len(notificationHub.subscribers[employeeID]) == 0 {
+			delete(notificationHub.subscribers, employeeID)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// publishNotifications delivers stored notifications to their recipients' live streams without
+// blocking; a stream that is not keeping up misses them and the client can catch up by listing
+func publishNotifications(notifications ...model.Notification) {
+	notificationHub.Lock()
+	defer notificationHub.Unlock()
+
+	for _, notification := range notifications {
+		for ch := range notificationHub.subscribers[notification.EmployeeID] {
+			select {
+			case ch <- notification:
+			default:
+			}
+		}
+	}
+}
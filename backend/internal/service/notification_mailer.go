@@ -0,0 +1,47 @@
+package service
+
+import (
+	"log"
+	"sync"
+
+	"oa-system/internal/model"
+	"oa-system/pkg/emailer"
+)
+
+// notificationMailer delivers the email copy of notifications whose recipients opted into email.
+// It is shared by every NotificationService and discards mail until SetNotificationMailer is called
+var notificationMailer = struct {
+	sync.RWMutex
+	sender emailer.Sender
+}{
+	sender: emailer.NopSender{},
+}
+
+// SetNotificationMailer sets the sender used for notification emails. It should not block, since
+// notifications are emailed while the request that raised them is still being handled
+func SetNotificationMailer(sender emailer.Sender) {
+	notificationMailer.Lock()
+	defer notificationMailer.Unlock()
+	notificationMailer.sender = sender
+}
+
+// emailNotification emails the notification to the recipient; failures are logged rather than
+// returned because the in-app notification has already been delivered
+func emailNotification(recipient *model.Employee, notification model.Notification) {
+	if recipient.Email == "" {
+		return
+	}
+
+	notificationMailer.RLock()
+	sender := notificationMailer.sender
+	notificationMailer.RUnlock()
+
+	err := sender.Send(emailer.Message{
+		To:      recipient.Email,
+		Subject: notification.Title,
+		Body:    notification.Content,
+	})
+	if err != nil {
+		log.Printf("Failed to email notification to employee %d: %v", recipient.ID, err)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/emailer"
+)
+
+func TestNotificationStream(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewNotificationService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+
+	stream, unsubscribe := s.Subscribe(alice.ID)
+	notify := func(employee *model.Employee, title string) {
+		t.Helper()
+		if err := s.Notify(&model.Notification{EmployeeID: employee.ID, Type: model.NotificationTypeSupervisorAssigned, Title: title}); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+	notify(bob, "for bob")
+	notify(alice, "for alice")
+
+	select {
+	case notification := <-stream:
+		if notification.Title != "for alice" || notification.ID == 0 {
+			t.Errorf("streamed %q (id %d), want the stored notification for alice", notification.Title, notification.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notification for alice was not streamed")
+	}
+	select {
+	case notification := <-stream:
+		t.Errorf("alice's stream received %q", notification.Title)
+	default:
+	}
+
+	unsubscribe()
+	notify(alice, "after unsubscribe")
+	select {
+	case notification := <-stream:
+		t.Errorf("stream received %q after unsubscribing", notification.Title)
+	default:
+	}
+}
+
+func TestMutedNotificationType(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewNotificationService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+
+	_, err := s.UpdatePreferences(alice.ID, &UpdateNotificationPreferencesRequest{Preferences: []NotificationPreferenceInput{
+		{Type: model.NotificationTypeDeviceLowStock, InApp: false},
+	}})
+	if err != nil {
+		t.Fatalf("UpdatePreferences: %v", err)
+	}
+	if _, err := s.UpdatePreferences(alice.ID, &UpdateNotificationPreferencesRequest{Preferences: []NotificationPreferenceInput{{Type: "unknown"}}}); !errors.Is(err, ErrUnknownNotificationType) {
+		t.Errorf("unknown type: err = %v, want ErrUnknownNotificationType", err)
+	}
+
+	for _, employee := range []*model.Employee{alice, bob} {
+		for _, notificationType := range []string{model.NotificationTypeDeviceLowStock, model.NotificationTypeDeviceTransferred} {
+			if err := s.Notify(&model.Notification{EmployeeID: employee.ID, Type: notificationType, Title: notificationType}); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+		}
+	}
+
+	if got := countNotifications(t, db, alice.ID, model.NotificationTypeDeviceLowStock); got != 0 {
+		t.Errorf("muted type stored for alice = %d, want 0", got)
+	}
+	if got := countNotifications(t, db, alice.ID, model.NotificationTypeDeviceTransferred); got != 1 {
+		t.Errorf("other type stored for alice = %d, want 1", got)
+	}
+	if got := countNotifications(t, db, bob.ID, model.NotificationTypeDeviceLowStock); got != 1 {
+		t.Errorf("type muted only by alice stored for bob = %d, want 1", got)
+	}
+}
+
+// recordingSender keeps every message it is asked to send
+type recordingSender struct {
+	sync.Mutex
+	messages []emailer.Message
+}
+
+func (r *recordingSender) Send(msg emailer.Message) error {
+	r.Lock()
+	defer r.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func TestEmailNotification(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := NewNotificationService(db)
+	sender := &recordingSender{}
+	SetNotificationMailer(sender)
+	t.Cleanup(func() { SetNotificationMailer(emailer.NopSender{}) })
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	for _, employee := range []*model.Employee{alice, bob} {
+		if err := db.Model(employee).Update("email", employee.Username+"@example.com").Error; err != nil {
+			t.Fatalf("set email: %v", err)
+		}
+	}
+
+	_, err := s.UpdatePreferences(alice.ID, &UpdateNotificationPreferencesRequest{Preferences: []NotificationPreferenceInput{
+		{Type: model.NotificationTypeContractExpiring, InApp: true, Email: true},
+	}})
+	if err != nil {
+		t.Fatalf("UpdatePreferences: %v", err)
+	}
+	for _, employee := range []*model.Employee{alice, bob} {
+		notification := &model.Notification{EmployeeID: employee.ID, Type: model.NotificationTypeContractExpiring, Title: "Contract expiring", Content: "Renew soon"}
+		if err := s.Notify(notification); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+
+	want := []emailer.Message{{To: "alice@example.com", Subject: "Contract expiring", Body: "Renew soon"}}
+	if !slices.Equal(sender.messages, want) {
+		t.Errorf("emails = %+v, want %+v", sender.messages, want)
+	}
+	// Email is sent in addition to the in-app copy
+	if got := countNotifications(t, db, alice.ID, model.NotificationTypeContractExpiring); got != 1 {
+		t.Errorf("in-app notifications for alice = %d, want 1", got)
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+	"oa-system/pkg/pdf"
+)
+
+// OnboardingService chains the steps of bringing on a new hire and of letting an employee go:
+// account, contract, equipment and bookings
+type OnboardingService struct {
+	db                 *gorm.DB
+	auditService       *AuditService
+	employeeService    *EmployeeService
+	meetingRoomService *MeetingRoomService
+	pdfGenerator       *pdf.Generator
+	avatarCfg          *config.AvatarConfig
+	contractCfg        *config.ContractConfig
+	deviceCfg          *config.DeviceConfig
+	bookingCfg         *config.BookingConfig
+}
+
+// NewOnboardingService creates a new onboarding service
+func NewOnboardingService(db *gorm.DB, pdfGenerator *pdf.Generator, avatarCfg *config.AvatarConfig, contractCfg *config.ContractConfig, deviceCfg *config.DeviceConfig, bookingCfg *config.BookingConfig) *OnboardingService {
+	return &OnboardingService{
+		db:                 db,
+		auditService:       NewAuditService(db),
+		employeeService:    NewEmployeeService(db, avatarCfg, bookingCfg),
+		meetingRoomService: NewMeetingRoomService(db, bookingCfg),
+		pdfGenerator:       pdfGenerator,
+		avatarCfg:          avatarCfg,
+		contractCfg:        contractCfg,
+		deviceCfg:          deviceCfg,
+		bookingCfg:         bookingCfg,
+	}
+}
+
+// SetRevokeTokensOnDisable makes offboarding revoke every token issued to the employee so far
+func (s *OnboardingService) SetRevokeTokensOnDisable(enabled bool) {
+	s.employeeService.SetRevokeTokensOnDisable(enabled)
+}
+
+// OnboardingRequest represents a request to onboard a new hire
+type OnboardingRequest struct {
+	CreateEmployeeRequest
+	ContractExpiresAt string `json:"contract_expires_at"` // optional YYYY-MM-DD end of the contract term
+	DeviceIDs         []uint `json:"device_ids"`          // devices to issue, one approved request per ID
+}
+
+// OnboardingResult is the employee, contract and device requests created for a new hire
+type OnboardingResult struct {
+	Employee        *model.Employee       `json:"employee"`
+	InitialPassword string                `json:"initial_password"`
+	Contract        *model.Contract       `json:"contract"`
+	DeviceRequests  []model.DeviceRequest `json:"device_requests"`
+}
+
+// Onboard creates the employee, their onboarding contract from the onboarding template and
+// pre-approved requests for the given devices in one transaction; nothing is kept if any step fails
+func (s *OnboardingService) Onboard(ctx context.Context, req *OnboardingRequest) (*OnboardingResult, error) {
+	result := &OnboardingResult{DeviceRequests: []model.DeviceRequest{}}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created, err := NewEmployeeService(tx, s.avatarCfg, s.bookingCfg).Create(ctx, &req.CreateEmployeeRequest)
+		if err != nil {
+			return err
+		}
+		result.Employee = created.Employee
+		result.InitialPassword = created.InitialPassword
+
+		template, err := repository.NewContractRepository(tx).GetTemplateByType(model.ContractTypeOnboarding)
+		if err != nil {
+			if errors.Is(err, repository.ErrContractTemplateNotFound) {
+				return ErrContractTemplateNotFound
+			}
+			return err
+		}
+		contract, err := NewContractService(tx, s.pdfGenerator, s.contractCfg).Create(&CreateContractRequest{
+			EmployeeID: created.Employee.ID,
+			TemplateID: template.ID,
+			ExpiresAt:  req.ContractExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		result.Contract = contract
+
+		deviceService := NewDeviceService(tx, s.deviceCfg)
+		for _, deviceID := range req.DeviceIDs {
+			request, err := deviceService.createApprovedRequest(created.Employee.ID, deviceID, "入职配发")
+			if err != nil {
+				return fmt.Errorf("device %d: %w", deviceID, err)
+			}
+			result.DeviceRequests = append(result.DeviceRequests, *request)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// OffboardingResult is the state of a departing employee after offboarding, with the devices
+// they still have to hand back
+type OffboardingResult struct {
+	Employee           *model.Employee            `json:"employee"`
+	Contract           *model.Contract            `json:"contract"`
+	CancelledBookings  []model.MeetingRoomBooking `json:"cancelled_bookings"`
+	OutstandingDevices []model.DeviceRequest      `json:"outstanding_devices"`
+}
+
+// Offboard disables the employee's account, revoking their tokens as UpdateStatus does, generates their
+// offboarding contract from the offboarding template, flags collected devices for return and cancels
+// active bookings in one transaction
+func (s *OnboardingService) Offboard(ctx context.Context, id uint, actorID uint) (*OffboardingResult, error) {
+	if id == actorID {
+		return nil, ErrCannotModifySelf
+	}
+
+	result := &OffboardingResult{}
+	revoked := false
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		employeeRepo := repository.NewEmployeeRepository(tx)
+		employee, err := employeeRepo.GetByID(id)
+		if err != nil {
+			if errors.Is(err, repository.ErrEmployeeNotFound) {
+				return ErrEmployeeNotFound
+			}
+			return err
+		}
+		if employee.Role == model.RoleSuperAdmin {
+			return ErrCannotDisableSuperAdmin
+		}
+
+		revoked, err = s.employeeService.setActive(employeeRepo, employee, false)
+		if err != nil {
+			return err
+		}
+		result.Employee = employee
+
+		template, err := repository.NewContractRepository(tx).GetTemplateByType(model.ContractTypeOffboarding)
+		if err != nil {
+			if errors.Is(err, repository.ErrContractTemplateNotFound) {
+				return ErrContractTemplateNotFound
+			}
+			return err
+		}
+		contract, err := NewContractService(tx, s.pdfGenerator, s.contractCfg).Create(&CreateContractRequest{
+			EmployeeID: id,
+			TemplateID: template.ID,
+		})
+		if err != nil {
+			return err
+		}
+		result.Contract = contract
+
+		bookingRepo := repository.NewMeetingRoomBookingRepository(tx)
+		result.CancelledBookings, err = bookingRepo.GetActiveByEmployee(id)
+		if err != nil {
+			return err
+		}
+		if err := bookingRepo.CancelActiveByEmployee(id); err != nil {
+			return err
+		}
+		for i := range result.CancelledBookings {
+			result.CancelledBookings[i].Status = model.BookingStatusCancelled
+		}
+
+		deviceRequestRepo := repository.NewDeviceRequestRepository(tx)
+		if err := deviceRequestRepo.FlagCollectedForReturn(id); err != nil {
+			return err
+		}
+		result.OutstandingDevices, err = deviceRequestRepo.GetHeldByEmployee(id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.meetingRoomService.releaseSlots(result.CancelledBookings)
+
+	s.auditService.Record(actorID, model.AuditActionEmployeeOffboard, model.AuditTargetEmployee, id, map[string]interface{}{
+		"contract_id":         result.Contract.ID,
+		"cancelled_bookings":  len(result.CancelledBookings),
+		"outstanding_devices": len(result.OutstandingDevices),
+	})
+	if revoked {
+		s.employeeService.recordTokensRevoked(actorID, result.Employee, "offboarded")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/jwt"
+	"oa-system/pkg/password"
+	"oa-system/pkg/pdf"
+)
+
+func newOnboardingService(t *testing.T, db *gorm.DB) *OnboardingService {
+	return NewOnboardingService(db, pdf.NewGenerator(""), &config.AvatarConfig{StorageDir: t.TempDir(), MaxSizeMB: 1}, &config.ContractConfig{}, &config.DeviceConfig{}, testBookingConfig())
+}
+
+// createTemplate inserts a contract template of the given type
+func createTemplate(t *testing.T, db *gorm.DB, contractType string) *model.ContractTemplate {
+	t.Helper()
+	template := &model.ContractTemplate{Type: contractType, Title: contractType + "合同", Content: "{{employee_name}}"}
+	if err := db.Create(template).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	return template
+}
+
+func TestOnboard(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newOnboardingService(t, db)
+	ctx := context.Background()
+	createTemplate(t, db, model.ContractTypeOnboarding)
+	laptop := createDevice(t, db, "ThinkPad", 2)
+	monitor := createDevice(t, db, "Monitor", 1)
+	holder := testutil.CreateEmployee(t, db, "holder", model.RoleEmployee)
+	createDeviceRequest(t, db, holder.ID, monitor.ID, model.DeviceRequestStatusCollected)
+	db.Model(monitor).Update("available_quantity", 0)
+
+	result, err := s.Onboard(ctx, &OnboardingRequest{
+		CreateEmployeeRequest: CreateEmployeeRequest{Name: "Alice"},
+		DeviceIDs:             []uint{laptop.ID},
+	})
+	if err != nil {
+		t.Fatalf("Onboard: %v", err)
+	}
+	if result.Employee == nil || result.Employee.ID == 0 || result.InitialPassword == "" {
+		t.Fatalf("Onboard = %+v, want a stored employee with an initial password", result)
+	}
+	if result.Contract == nil || result.Contract.EmployeeID != result.Employee.ID || result.Contract.Type != model.ContractTypeOnboarding || result.Contract.Status != model.ContractStatusPending {
+		t.Errorf("contract = %+v, want a pending onboarding contract for the new hire", result.Contract)
+	}
+	if len(result.DeviceRequests) != 1 || result.DeviceRequests[0].DeviceID != laptop.ID || result.DeviceRequests[0].Status != model.DeviceRequestStatusApproved {
+		t.Errorf("device requests = %+v, want one approved laptop request", result.DeviceRequests)
+	}
+
+	// The monitor is taken, so the whole onboarding is rolled back
+	_, err = s.Onboard(ctx, &OnboardingRequest{
+		CreateEmployeeRequest: CreateEmployeeRequest{Name: "Bob"},
+		DeviceIDs:             []uint{laptop.ID, monitor.ID},
+	})
+	if !errors.Is(err, ErrDeviceNotAvailable) {
+		t.Fatalf("Onboard with an unavailable device: err = %v, want ErrDeviceNotAvailable", err)
+	}
+	var employees, contracts, requests int64
+	db.Model(&model.Employee{}).Where("name = ?", "Bob").Count(&employees)
+	db.Model(&model.Contract{}).Count(&contracts)
+	db.Model(&model.DeviceRequest{}).Where("device_id = ?", laptop.ID).Count(&requests)
+	if employees != 0 || contracts != 1 || requests != 1 {
+		t.Errorf("after rollback: %d employees named Bob, %d contracts, %d laptop requests; want 0, 1, 1", employees, contracts, requests)
+	}
+}
+
+func TestOffboard(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newOnboardingService(t, db)
+	s.SetRevokeTokensOnDisable(true)
+	auth := NewAuthService(db, jwt.NewJWTManager("test-secret", 1))
+	createTemplate(t, db, model.ContractTypeOffboarding)
+	hr := testutil.CreateEmployee(t, db, "hr", model.RoleHR)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	hashed, err := password.Hash("secret123")
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	db.Model(alice).Update("password", hashed)
+	held := createDeviceRequest(t, db, alice.ID, createDevice(t, db, "ThinkPad", 1).ID, model.DeviceRequestStatusCollected)
+	createDeviceRequest(t, db, alice.ID, createDevice(t, db, "Monitor", 1).ID, model.DeviceRequestStatusReturned)
+	login := &LoginRequest{Username: "alice", Password: "secret123"}
+	if _, err := auth.Login(login); err != nil {
+		t.Fatalf("Login before offboarding: %v", err)
+	}
+
+	result, err := s.Offboard(context.Background(), alice.ID, hr.ID)
+	if err != nil {
+		t.Fatalf("Offboard: %v", err)
+	}
+	if _, err := auth.Login(login); !errors.Is(err, ErrAccountDisabled) {
+		t.Errorf("Login after offboarding: err = %v, want ErrAccountDisabled", err)
+	}
+	if len(result.OutstandingDevices) != 1 || result.OutstandingDevices[0].ID != held.ID || result.OutstandingDevices[0].Status != model.DeviceRequestStatusReturnPending {
+		t.Errorf("outstanding devices = %+v, want the held laptop pending return", result.OutstandingDevices)
+	}
+
+	// Tokens already issued are revoked just as disabling the account revokes them
+	var stored model.Employee
+	if err := db.First(&stored, alice.ID).Error; err != nil {
+		t.Fatalf("load employee: %v", err)
+	}
+	if stored.IsActive || stored.TokensRevokedAt == nil {
+		t.Errorf("after offboarding: active %v, tokens revoked at %v; want disabled with tokens revoked", stored.IsActive, stored.TokensRevokedAt)
+	}
+	var revocations int64
+	db.Model(&model.AuditLog{}).Where("action = ? AND target_id = ?", model.AuditActionTokensRevoke, alice.ID).Count(&revocations)
+	if revocations != 1 {
+		t.Errorf("token revocation audit entries = %d, want 1", revocations)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrInvalidRoleName   = errors.New("invalid role name")
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+// roleNameRegex validates role names: lowercase letters, digits and underscores, fitting Employee.Role
+var roleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+// RoleService handles role and permission management
+type RoleService struct {
+	repo              *repository.RoleRepository
+	isKnownPermission func(permission string) bool
+	auditService      *AuditService
+	db                *gorm.DB
+}
+
+// NewRoleService creates a new role service; isKnownPermission reports whether a
+// permission name is one the application enforces
+func NewRoleService(db *gorm.DB, isKnownPermission func(permission string) bool) *RoleService {
+	return &RoleService{
+		repo:              repository.NewRoleRepository(db),
+		isKnownPermission: isKnownPermission,
+		auditService:      NewAuditService(db),
+		db:                db,
+	}
+}
+
+// CreateRoleRequest represents a request to create a custom role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// SetPermissionRequest represents a request to grant or revoke a permission on a role
+type SetPermissionRequest struct {
+	Permission string `json:"permission" binding:"required"`
+	Granted    bool   `json:"granted"`
+}
+
+// List retrieves all roles with their permissions
+func (s *RoleService) List() ([]model.Role, error) {
+	return s.repo.List()
+}
+
+// Create creates a custom role with an initial set of permissions
+func (s *RoleService) Create(actorID uint, req *CreateRoleRequest) (*model.Role, error) {
+	if !roleNameRegex.MatchString(req.Name) {
+		return nil, ErrInvalidRoleName
+	}
+
+	exists, err := s.repo.ExistsByName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrRoleExists
+	}
+
+	role := &model.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	for _, permission := range uniqueStrings(req.Permissions) {
+		if !s.isKnownPermission(permission) {
+			return nil, ErrUnknownPermission
+		}
+		role.Permissions = append(role.Permissions, model.RolePermission{Permission: permission})
+	}
+
+	if err := s.repo.Create(role); err != nil {
+		return nil, err
+	}
+
+	permissions := make([]string, len(role.Permissions))
+	for i, p := range role.Permissions {
+		permissions[i] = p.Permission
+	}
+	s.auditService.Record(actorID, model.AuditActionRoleCreate, model.AuditTargetRole, role.ID, map[string]interface{}{
+		"name":        role.Name,
+		"permissions": permissions,
+	})
+
+	return role, nil
+}
+
+// SetPermission grants or revokes a single permission on a role
+func (s *RoleService) SetPermission(roleID uint, actorID uint, req *SetPermissionRequest) (*model.Role, error) {
+	if !s.isKnownPermission(req.Permission) {
+		return nil, ErrUnknownPermission
+	}
+
+	if _, err := s.getRole(roleID); err != nil {
+		return nil, err
+	}
+
+	if req.Granted {
+		err := s.repo.GrantPermission(roleID, req.Permission)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err := s.repo.RevokePermission(roleID, req.Permission)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.auditService.Record(actorID, model.AuditActionRolePermissionUpdate, model.AuditTargetRole, roleID, map[string]interface{}{
+		"permission": req.Permission,
+		"granted":    req.Granted,
+	})
+
+	return s.getRole(roleID)
+}
+
+// LoadPermissionMap returns every role's granted permissions keyed by role name
+func (s *RoleService) LoadPermissionMap() (map[string][]string, error) {
+	roles, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	permissionMap := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, len(role.Permissions))
+		for i, permission := range role.Permissions {
+			permissions[i] = permission.Permission
+		}
+		permissionMap[role.Name] = permissions
+	}
+	return permissionMap, nil
+}
+
+// getRole retrieves a role by ID, mapping repository errors
+func (s *RoleService) getRole(id uint) (*model.Role, error) {
+	role, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
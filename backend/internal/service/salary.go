@@ -2,12 +2,17 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"regexp"
+	"strings"
 
 	"gorm.io/gorm"
 
+	"oa-system/config"
 	"oa-system/internal/model"
 	"oa-system/internal/repository"
+	"oa-system/pkg/pdf"
 )
 
 var (
@@ -21,27 +26,92 @@ var (
 type SalaryService struct {
 	repo         *repository.SalaryRepository
 	employeeRepo *repository.EmployeeRepository
+	pdfGenerator *pdf.Generator
+	auditService *AuditService
 	db           *gorm.DB
+	cfg          *config.SalaryConfig
 }
 
 // NewSalaryService creates a new salary service
-func NewSalaryService(db *gorm.DB) *SalaryService {
+func NewSalaryService(db *gorm.DB, pdfGenerator *pdf.Generator, cfg *config.SalaryConfig) *SalaryService {
 	return &SalaryService{
 		repo:         repository.NewSalaryRepository(db),
 		employeeRepo: repository.NewEmployeeRepository(db),
+		pdfGenerator: pdfGenerator,
+		auditService: NewAuditService(db),
 		db:           db,
+		cfg:          cfg,
 	}
 }
 
 // CreateSalaryRequest represents a request to create a salary record
 type CreateSalaryRequest struct {
+	EmployeeID uint                   `json:"employee_id" binding:"required"`
+	Month      string                 `json:"month" binding:"required"`
+	BaseSalary float64                `json:"base_salary" binding:"required"`
+	Bonus      float64                `json:"bonus"`
+	Deduction  float64                `json:"deduction"`
+	Components []SalaryComponentInput `json:"components" binding:"dive"`
+	Published  bool                   `json:"published"` // visible to the employee right away instead of kept as a draft
+}
+
+// SalaryComponentInput represents an itemized salary line in a request
+type SalaryComponentInput struct {
+	Name        string  `json:"name" binding:"required"`
+	Amount      float64 `json:"amount"`
+	IsDeduction bool    `json:"is_deduction"`
+}
+
+// UpdateSalaryRequest represents a request to update a salary record
+// Omitted fields keep their current values
+type UpdateSalaryRequest struct {
+	Month      *string                `json:"month"`
+	BaseSalary *float64               `json:"base_salary"`
+	Bonus      *float64               `json:"bonus"`
+	Deduction  *float64               `json:"deduction"`
+	Components []SalaryComponentInput `json:"components" binding:"dive"` // replaces existing components when provided
+	Version    *int                   `json:"version"`                   // version the edit was based on; a stale one is rejected
+}
+
+// BatchSalaryEntry represents a single employee's salary in a batch request
+type BatchSalaryEntry struct {
 	EmployeeID uint    `json:"employee_id" binding:"required"`
-	Month      string  `json:"month" binding:"required"`
-	BaseSalary float64 `json:"base_salary" binding:"required"`
+	BaseSalary float64 `json:"base_salary"`
 	Bonus      float64 `json:"bonus"`
 	Deduction  float64 `json:"deduction"`
 }
 
+// BatchCreateSalaryRequest represents a request to create salary records for a whole month
+type BatchCreateSalaryRequest struct {
+	Month        string             `json:"month" binding:"required"`
+	SkipExisting bool               `json:"skip_existing"`
+	Entries      []BatchSalaryEntry `json:"entries" binding:"required,min=1,dive"`
+}
+
+// Batch entry result statuses
+const (
+	BatchEntryCreated = "created"
+	BatchEntrySkipped = "skipped"
+	BatchEntryError   = "error"
+)
+
+// BatchSalaryResult represents the outcome for a single batch entry
+type BatchSalaryResult struct {
+	EmployeeID uint          `json:"employee_id"`
+	Status     string        `json:"status"`
+	Error      string        `json:"error,omitempty"`
+	Salary     *model.Salary `json:"salary,omitempty"`
+}
+
+// BatchCreateSalaryResponse summarizes a batch salary creation
+type BatchCreateSalaryResponse struct {
+	Month   string              `json:"month"`
+	Created int                 `json:"created"`
+	Skipped int                 `json:"skipped"`
+	Failed  int                 `json:"failed"`
+	Results []BatchSalaryResult `json:"results"`
+}
+
 // monthRegex validates YYYY-MM format
 var monthRegex = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
 
@@ -51,9 +121,70 @@ func validateMonth(month string) bool {
 }
 
 
+// toCents converts an amount to whole cents using the configured rounding mode
+func (s *SalaryService) toCents(amount float64) int64 {
+	if s.cfg.Rounding == config.SalaryRoundingHalfEven {
+		return int64(math.RoundToEven(amount * 100))
+	}
+	return int64(math.Round(amount * 100))
+}
+
+// roundCents rounds an amount to 2 decimals, so stored figures never carry float noise such as 5000.0000000001
+func (s *SalaryService) roundCents(amount float64) float64 {
+	return float64(s.toCents(amount)) / 100
+}
+
+// withCurrency stamps the configured currency code onto a salary record before it is returned
+func (s *SalaryService) withCurrency(salary *model.Salary) *model.Salary {
+	salary.Currency = s.cfg.Currency
+	return salary
+}
+
+// listWithCurrency stamps the configured currency code onto listed salary records
+func (s *SalaryService) listWithCurrency(salaries []model.Salary, err error) ([]model.Salary, error) {
+	if err != nil {
+		return nil, err
+	}
+	for i := range salaries {
+		s.withCurrency(&salaries[i])
+	}
+	return salaries, nil
+}
+
+// buildComponents converts and validates component inputs, rounding amounts to cents
+func (s *SalaryService) buildComponents(inputs []SalaryComponentInput) ([]model.SalaryComponent, error) {
+	components := make([]model.SalaryComponent, len(inputs))
+	for i, input := range inputs {
+		if input.Amount < 0 {
+			return nil, ErrInvalidSalaryData
+		}
+		components[i] = model.SalaryComponent{
+			Name:        input.Name,
+			Amount:      s.roundCents(input.Amount),
+			IsDeduction: input.IsDeduction,
+		}
+	}
+	return components, nil
+}
+
+// calculateNetSalary computes net salary as base + bonus + earning components
+// - deduction components - the legacy lump-sum deduction
+// The sum is taken in integer cents so it is exact to 2 decimals
+func (s *SalaryService) calculateNetSalary(baseSalary, bonus, deduction float64, components []model.SalaryComponent) float64 {
+	net := s.toCents(baseSalary) + s.toCents(bonus) - s.toCents(deduction)
+	for _, component := range components {
+		if component.IsDeduction {
+			net -= s.toCents(component.Amount)
+		} else {
+			net += s.toCents(component.Amount)
+		}
+	}
+	return float64(net) / 100
+}
+
 // Create creates a new salary record
 // Implements Property 15: Salary record uniqueness - only one record per employee per month
-func (s *SalaryService) Create(req *CreateSalaryRequest) (*model.Salary, error) {
+func (s *SalaryService) Create(actorID uint, req *CreateSalaryRequest) (*model.Salary, error) {
 	// Validate month format
 	if !validateMonth(req.Month) {
 		return nil, ErrInvalidMonth
@@ -82,23 +213,134 @@ func (s *SalaryService) Create(req *CreateSalaryRequest) (*model.Salary, error)
 		return nil, ErrSalaryDuplicate
 	}
 
+	components, err := s.buildComponents(req.Components)
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate net salary
-	netSalary := req.BaseSalary + req.Bonus - req.Deduction
+	netSalary := s.calculateNetSalary(req.BaseSalary, req.Bonus, req.Deduction, components)
 
 	salary := &model.Salary{
 		EmployeeID: req.EmployeeID,
 		Month:      req.Month,
-		BaseSalary: req.BaseSalary,
-		Bonus:      req.Bonus,
-		Deduction:  req.Deduction,
+		BaseSalary: s.roundCents(req.BaseSalary),
+		Bonus:      s.roundCents(req.Bonus),
+		Deduction:  s.roundCents(req.Deduction),
 		NetSalary:  netSalary,
+		Components: components,
+		Published:  req.Published,
 	}
 
 	if err := s.repo.Create(salary); err != nil {
 		return nil, err
 	}
 
-	return salary, nil
+	s.auditService.Record(actorID, model.AuditActionSalaryCreate, model.AuditTargetSalary, salary.ID, map[string]interface{}{
+		"employee_id": salary.EmployeeID,
+		"month":       salary.Month,
+		"net_salary":  salary.NetSalary,
+	})
+
+	return s.withCurrency(salary), nil
+}
+
+// BatchCreate creates salary records for many employees in the same month
+// All entries are validated up front; valid entries are inserted in a single transaction
+// while invalid ones are reported per entry. Existing records are skipped when SkipExisting
+// is set, otherwise they are reported as duplicates (Property 15).
+func (s *SalaryService) BatchCreate(req *BatchCreateSalaryRequest) (*BatchCreateSalaryResponse, error) {
+	if !validateMonth(req.Month) {
+		return nil, ErrInvalidMonth
+	}
+
+	employeeIDs := make([]uint, len(req.Entries))
+	for i, entry := range req.Entries {
+		employeeIDs[i] = entry.EmployeeID
+	}
+
+	employees, err := s.employeeRepo.GetByIDs(employeeIDs)
+	if err != nil {
+		return nil, err
+	}
+	knownEmployees := make(map[uint]bool, len(employees))
+	for _, emp := range employees {
+		knownEmployees[emp.ID] = true
+	}
+
+	existingIDs, err := s.repo.GetEmployeeIDsByMonth(req.Month, employeeIDs)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[uint]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+
+	resp := &BatchCreateSalaryResponse{
+		Month:   req.Month,
+		Results: make([]BatchSalaryResult, len(req.Entries)),
+	}
+	var salaries []*model.Salary
+	var salaryIndexes []int
+	seen := make(map[uint]bool, len(req.Entries))
+
+	for i, entry := range req.Entries {
+		result := BatchSalaryResult{EmployeeID: entry.EmployeeID}
+
+		switch {
+		case entry.BaseSalary < 0 || entry.Bonus < 0 || entry.Deduction < 0:
+			result.Status = BatchEntryError
+			result.Error = ErrInvalidSalaryData.Error()
+		case !knownEmployees[entry.EmployeeID]:
+			result.Status = BatchEntryError
+			result.Error = ErrEmployeeNotFound.Error()
+		case seen[entry.EmployeeID]:
+			result.Status = BatchEntryError
+			result.Error = "duplicate employee in batch"
+		case existing[entry.EmployeeID] && req.SkipExisting:
+			result.Status = BatchEntrySkipped
+		case existing[entry.EmployeeID]:
+			result.Status = BatchEntryError
+			result.Error = ErrSalaryDuplicate.Error()
+		default:
+			result.Status = BatchEntryCreated
+			salaries = append(salaries, &model.Salary{
+				EmployeeID: entry.EmployeeID,
+				Month:      req.Month,
+				BaseSalary: s.roundCents(entry.BaseSalary),
+				Bonus:      s.roundCents(entry.Bonus),
+				Deduction:  s.roundCents(entry.Deduction),
+				NetSalary:  s.calculateNetSalary(entry.BaseSalary, entry.Bonus, entry.Deduction, nil),
+			})
+			salaryIndexes = append(salaryIndexes, i)
+		}
+		seen[entry.EmployeeID] = true
+
+		switch result.Status {
+		case BatchEntryCreated:
+			resp.Created++
+		case BatchEntrySkipped:
+			resp.Skipped++
+		default:
+			resp.Failed++
+		}
+		resp.Results[i] = result
+	}
+
+	if len(salaries) > 0 {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			return tx.Create(&salaries).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, salary := range salaries {
+			resp.Results[salaryIndexes[i]].Salary = s.withCurrency(salary)
+		}
+	}
+
+	return resp, nil
 }
 
 // GetByID retrieves a salary record by ID
@@ -110,7 +352,7 @@ func (s *SalaryService) GetByID(id uint) (*model.Salary, error) {
 		}
 		return nil, err
 	}
-	return salary, nil
+	return s.withCurrency(salary), nil
 }
 
 // GetByIDForEmployee retrieves a salary record by ID, ensuring it belongs to the employee
@@ -125,24 +367,250 @@ func (s *SalaryService) GetByIDForEmployee(id uint, employeeID uint) (*model.Sal
 	}
 
 	// Property 4: Data isolation - only return if it belongs to the employee
-	if salary.EmployeeID != employeeID {
+	// Drafts stay hidden from the employee until finance publishes them
+	if salary.EmployeeID != employeeID || !salary.Published {
 		return nil, ErrSalaryNotFound
 	}
 
-	return salary, nil
+	return s.withCurrency(salary), nil
 }
 
 
-// ListByEmployeeID retrieves all salary records for an employee
+// ListByEmployeeID retrieves the published salary records of an employee
 // Implements Property 4: Data isolation - only returns records belonging to the employee
 // Implements Property 5: Records are ordered by month descending
 func (s *SalaryService) ListByEmployeeID(employeeID uint) ([]model.Salary, error) {
-	return s.repo.ListByEmployeeID(employeeID)
+	return s.listWithCurrency(s.repo.ListPublishedByEmployeeID(employeeID))
+}
+
+// SalarySummary is an employee's year-to-date salary totals with the month-by-month breakdown
+type SalarySummary struct {
+	Year       int                       `json:"year"`
+	BaseSalary float64                   `json:"base_salary"`
+	Bonus      float64                   `json:"bonus"`
+	Deduction  float64                   `json:"deduction"`
+	NetSalary  float64                   `json:"net_salary"`
+	Currency   string                    `json:"currency"`
+	Months     []repository.SalaryTotals `json:"months"`
+}
+
+// GetSummaryForEmployee returns the year-to-date totals of an employee's published salaries, defaulting to the current year
+// Implements Property 4: Data isolation - only records belonging to the employee are summed
+func (s *SalaryService) GetSummaryForEmployee(employeeID uint, year int) (*SalarySummary, error) {
+	if year == 0 {
+		year = Today().Year()
+	}
+
+	totals, err := s.repo.SumPublishedByEmployeeAndYear(employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	months, err := s.repo.SumPublishedByEmployeeAndYearPerMonth(employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+	if months == nil {
+		months = []repository.SalaryTotals{}
+	}
+	for i := range months {
+		s.roundTotals(&months[i])
+	}
+	s.roundTotals(totals)
+
+	return &SalarySummary{
+		Year:       year,
+		BaseSalary: totals.BaseSalary,
+		Bonus:      totals.Bonus,
+		Deduction:  totals.Deduction,
+		NetSalary:  totals.NetSalary,
+		Currency:   s.cfg.Currency,
+		Months:     months,
+	}, nil
+}
+
+// roundTotals rounds summed salary figures to cents, as databases without a decimal type sum them as floats
+func (s *SalaryService) roundTotals(totals *repository.SalaryTotals) {
+	totals.BaseSalary = s.roundCents(totals.BaseSalary)
+	totals.Bonus = s.roundCents(totals.Bonus)
+	totals.Deduction = s.roundCents(totals.Deduction)
+	totals.NetSalary = s.roundCents(totals.NetSalary)
+}
+
+// Publish makes a draft salary record visible to its employee; publishing twice is harmless
+func (s *SalaryService) Publish(id uint) (*model.Salary, error) {
+	salary, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if salary.Published {
+		return salary, nil
+	}
+
+	if err := s.repo.Publish(id); err != nil {
+		return nil, err
+	}
+	salary.Published = true
+	return salary, nil
+}
+
+// PublishMonth publishes every draft salary record of the month and returns how many were published
+func (s *SalaryService) PublishMonth(month string) (int64, error) {
+	if !validateMonth(month) {
+		return 0, ErrInvalidMonth
+	}
+	return s.repo.PublishMonth(month)
 }
 
 // List retrieves all salary records with optional filters (for finance role)
 func (s *SalaryService) List(filters map[string]interface{}) ([]model.Salary, error) {
-	return s.repo.List(filters)
+	return s.listWithCurrency(s.repo.List(filters))
+}
+
+// SalaryStatistics is the payroll summary for a month
+type SalaryStatistics struct {
+	Month        string                       `json:"month"`
+	Department   string                       `json:"department,omitempty"`
+	TotalPayroll float64                      `json:"total_payroll"`
+	AverageNet   float64                      `json:"average_net_salary"`
+	MinNet       float64                      `json:"min_net_salary"`
+	MaxNet       float64                      `json:"max_net_salary"`
+	Headcount    int64                        `json:"headcount"`
+	Currency     string                       `json:"currency"`
+	ByDepartment []repository.SalaryAggregate `json:"by_department"`
+}
+
+// GetStatistics returns aggregated payroll figures for a month, optionally limited to one department
+func (s *SalaryService) GetStatistics(month, department string) (*SalaryStatistics, error) {
+	if !validateMonth(month) {
+		return nil, ErrInvalidMonth
+	}
+
+	total, err := s.repo.AggregateByMonth(month, department)
+	if err != nil {
+		return nil, err
+	}
+
+	byDepartment, err := s.repo.AggregateByMonthPerDepartment(month, department)
+	if err != nil {
+		return nil, err
+	}
+	if byDepartment == nil {
+		byDepartment = []repository.SalaryAggregate{}
+	}
+	for i := range byDepartment {
+		byDepartment[i].Total = s.roundCents(byDepartment[i].Total)
+		byDepartment[i].Average = s.roundCents(byDepartment[i].Average)
+	}
+
+	return &SalaryStatistics{
+		Month:        month,
+		Department:   department,
+		TotalPayroll: s.roundCents(total.Total),
+		AverageNet:   s.roundCents(total.Average),
+		MinNet:       total.Min,
+		MaxNet:       total.Max,
+		Headcount:    total.Headcount,
+		Currency:     s.cfg.Currency,
+		ByDepartment: byDepartment,
+	}, nil
+}
+
+// Update updates a salary record and recomputes its net salary
+// Implements Property 15: Salary record uniqueness - changing the month must not collide with another record
+func (s *SalaryService) Update(id uint, req *UpdateSalaryRequest) (*model.Salary, error) {
+	salary, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrSalaryNotFound) {
+			return nil, ErrSalaryNotFound
+		}
+		return nil, err
+	}
+	if err := checkVersion(salary.Version, req.Version); err != nil {
+		return nil, err
+	}
+
+	if req.Month != nil && *req.Month != salary.Month {
+		if !validateMonth(*req.Month) {
+			return nil, ErrInvalidMonth
+		}
+
+		// Check for duplicate record (Property 15: Salary record uniqueness)
+		exists, err := s.repo.ExistsByEmployeeAndMonth(salary.EmployeeID, *req.Month)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrSalaryDuplicate
+		}
+		salary.Month = *req.Month
+	}
+
+	if req.BaseSalary != nil {
+		salary.BaseSalary = s.roundCents(*req.BaseSalary)
+	}
+	if req.Bonus != nil {
+		salary.Bonus = s.roundCents(*req.Bonus)
+	}
+	if req.Deduction != nil {
+		salary.Deduction = s.roundCents(*req.Deduction)
+	}
+
+	// Validate salary data
+	if salary.BaseSalary < 0 || salary.Bonus < 0 || salary.Deduction < 0 {
+		return nil, ErrInvalidSalaryData
+	}
+
+	components := salary.Components
+	if req.Components != nil {
+		components, err = s.buildComponents(req.Components)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Recalculate net salary
+	salary.NetSalary = s.calculateNetSalary(salary.BaseSalary, salary.Bonus, salary.Deduction, components)
+
+	if err := s.repo.UpdateWithComponents(salary, components); err != nil {
+		return nil, translateVersionConflict(err)
+	}
+
+	return s.withCurrency(salary), nil
+}
+
+// GeneratePayslip renders a salary record as a payslip PDF
+func (s *SalaryService) GeneratePayslip(salary *model.Salary) ([]byte, error) {
+	return s.pdfGenerator.Render(s.payslipDocument(salary))
+}
+
+// payslipDocument lays out the payslip text of a salary record
+func (s *SalaryService) payslipDocument(salary *model.Salary) *pdf.Document {
+	lines := []string{
+		"员工姓名：" + salary.Employee.Name,
+		"工号：" + salary.Employee.EmployeeNo,
+		"部门：" + salary.Employee.Department,
+		"职位：" + salary.Employee.Position,
+		"工资月份：" + salary.Month,
+		"币种：" + s.cfg.Currency,
+		"",
+		fmt.Sprintf("基本工资：%.2f", salary.BaseSalary),
+		fmt.Sprintf("奖金：%.2f", salary.Bonus),
+		fmt.Sprintf("扣款：%.2f", salary.Deduction),
+	}
+	for _, component := range salary.Components {
+		sign := ""
+		if component.IsDeduction {
+			sign = "-"
+		}
+		lines = append(lines, fmt.Sprintf("%s：%s%.2f", component.Name, sign, component.Amount))
+	}
+	lines = append(lines, "", fmt.Sprintf("实发工资：%.2f", salary.NetSalary))
+
+	return &pdf.Document{
+		Title: "工资条 " + salary.Month,
+		Body:  strings.Join(lines, "\n"),
+	}
 }
 
 // Delete soft deletes a salary record
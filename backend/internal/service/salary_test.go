@@ -0,0 +1,355 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+	"oa-system/internal/testutil"
+	"oa-system/pkg/pdf"
+)
+
+func newSalaryService(db *gorm.DB) *SalaryService {
+	return NewSalaryService(db, pdf.NewGenerator(""), &config.SalaryConfig{Currency: "CNY", Rounding: config.SalaryRoundingHalfUp})
+}
+
+func TestUpdateSalary(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newSalaryService(db)
+	finance := testutil.CreateEmployee(t, db, "finance", model.RoleFinance)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	salary, err := s.Create(finance.ID, &CreateSalaryRequest{EmployeeID: employee.ID, Month: "2026-03", BaseSalary: 10000, Bonus: 500, Deduction: 200})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := s.Create(finance.ID, &CreateSalaryRequest{EmployeeID: employee.ID, Month: "2026-04", BaseSalary: 10000}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	bonus, deduction := 1500.0, 300.0
+	updated, err := s.Update(salary.ID, &UpdateSalaryRequest{Bonus: &bonus, Deduction: &deduction})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.BaseSalary != 10000 || updated.NetSalary != 11200 {
+		t.Errorf("Update = base %.2f net %.2f, want base 10000.00 net 11200.00", updated.BaseSalary, updated.NetSalary)
+	}
+	stored, err := s.GetByID(salary.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.NetSalary != 11200 {
+		t.Errorf("stored net = %.2f, want 11200.00", stored.NetSalary)
+	}
+
+	negative := -1.0
+	if _, err := s.Update(salary.ID, &UpdateSalaryRequest{Bonus: &negative}); !errors.Is(err, ErrInvalidSalaryData) {
+		t.Errorf("negative bonus: err = %v, want ErrInvalidSalaryData", err)
+	}
+	colliding := "2026-04"
+	if _, err := s.Update(salary.ID, &UpdateSalaryRequest{Month: &colliding}); !errors.Is(err, ErrSalaryDuplicate) {
+		t.Errorf("colliding month: err = %v, want ErrSalaryDuplicate", err)
+	}
+	free := "2026-05"
+	moved, err := s.Update(salary.ID, &UpdateSalaryRequest{Month: &free})
+	if err != nil {
+		t.Fatalf("Update month: %v", err)
+	}
+	if moved.Month != free {
+		t.Errorf("month = %q, want %q", moved.Month, free)
+	}
+}
+
+func TestBatchCreateSalaries(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newSalaryService(db)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	carol := testutil.CreateEmployee(t, db, "carol", model.RoleEmployee)
+	createSalary(t, db, bob.ID, "2026-03", 7000, false)
+
+	entries := []BatchSalaryEntry{
+		{EmployeeID: alice.ID, BaseSalary: 8000, Bonus: 100},
+		{EmployeeID: bob.ID, BaseSalary: 7000},
+		{EmployeeID: carol.ID, BaseSalary: -1},
+		{EmployeeID: 9999, BaseSalary: 5000},
+		{EmployeeID: alice.ID, BaseSalary: 8000},
+	}
+
+	resp, err := s.BatchCreate(&BatchCreateSalaryRequest{Month: "2026-03", Entries: entries})
+	if err != nil {
+		t.Fatalf("BatchCreate: %v", err)
+	}
+	want := []string{BatchEntryCreated, BatchEntryError, BatchEntryError, BatchEntryError, BatchEntryError}
+	for i, result := range resp.Results {
+		if result.Status != want[i] {
+			t.Errorf("entry %d status = %q (%s), want %q", i, result.Status, result.Error, want[i])
+		}
+	}
+	if resp.Created != 1 || resp.Skipped != 0 || resp.Failed != 4 {
+		t.Errorf("counts = %d/%d/%d, want 1 created, 0 skipped, 4 failed", resp.Created, resp.Skipped, resp.Failed)
+	}
+	if salary := resp.Results[0].Salary; salary == nil || salary.NetSalary != 8100 {
+		t.Errorf("created salary = %+v, want net 8100.00", salary)
+	}
+
+	// Re-running with skip_existing skips both already-entered employees
+	resp, err = s.BatchCreate(&BatchCreateSalaryRequest{Month: "2026-03", SkipExisting: true, Entries: entries[:2]})
+	if err != nil {
+		t.Fatalf("BatchCreate: %v", err)
+	}
+	if resp.Created != 0 || resp.Skipped != 2 || resp.Failed != 0 {
+		t.Errorf("re-run counts = %d/%d/%d, want 0 created, 2 skipped, 0 failed", resp.Created, resp.Skipped, resp.Failed)
+	}
+
+	var count int64
+	db.Model(&model.Salary{}).Where("month = ?", "2026-03").Count(&count)
+	if count != 2 {
+		t.Errorf("salaries for the month = %d, want 2", count)
+	}
+}
+
+func TestSalaryComponentsNetSalary(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newSalaryService(db)
+	finance := testutil.CreateEmployee(t, db, "finance", model.RoleFinance)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+
+	salary, err := s.Create(finance.ID, &CreateSalaryRequest{
+		EmployeeID: employee.ID,
+		Month:      "2026-03",
+		BaseSalary: 10000,
+		Bonus:      1000,
+		Deduction:  100,
+		Components: []SalaryComponentInput{
+			{Name: "餐补", Amount: 300},
+			{Name: "社会保险", Amount: 1050.5, IsDeduction: true},
+			{Name: "个人所得税", Amount: 245.25, IsDeduction: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// 10000 + 1000 + 300 - 1050.50 - 245.25 - 100
+	if salary.NetSalary != 9904.25 {
+		t.Errorf("net = %.2f, want 9904.25", salary.NetSalary)
+	}
+
+	stored, err := s.GetByID(salary.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(stored.Components) != 3 {
+		t.Fatalf("components = %d, want 3", len(stored.Components))
+	}
+
+	// Replacing the components recomputes the net from the new set
+	updated, err := s.Update(salary.ID, &UpdateSalaryRequest{Components: []SalaryComponentInput{
+		{Name: "个人所得税", Amount: 500, IsDeduction: true},
+	}})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.NetSalary != 10400 {
+		t.Errorf("updated net = %.2f, want 10400.00", updated.NetSalary)
+	}
+	stored, err = s.GetByID(salary.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(stored.Components) != 1 || stored.Components[0].Amount != 500 {
+		t.Errorf("stored components = %+v, want only the 500.00 tax line", stored.Components)
+	}
+}
+
+func TestSalaryStatistics(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newSalaryService(db)
+	fixtures := []struct {
+		username, department string
+		net                  float64
+	}{
+		{"alice", "研发部", 12000},
+		{"bob", "研发部", 8000},
+		{"carol", "市场部", 6500.5},
+	}
+	for _, f := range fixtures {
+		employee := testutil.CreateEmployee(t, db, f.username, model.RoleEmployee)
+		db.Model(employee).Update("department", f.department)
+		createSalary(t, db, employee.ID, "2026-03", f.net, true)
+	}
+	other := testutil.CreateEmployee(t, db, "dave", model.RoleEmployee)
+	createSalary(t, db, other.ID, "2026-02", 99999, true)
+
+	stats, err := s.GetStatistics("2026-03", "")
+	if err != nil {
+		t.Fatalf("GetStatistics: %v", err)
+	}
+	if stats.Headcount != 3 || stats.TotalPayroll != 26500.5 || stats.AverageNet != 8833.5 || stats.MinNet != 6500.5 || stats.MaxNet != 12000 {
+		t.Errorf("totals = %+v, want headcount 3, total 26500.50, average 8833.50, min 6500.50, max 12000.00", stats)
+	}
+	if len(stats.ByDepartment) != 2 {
+		t.Fatalf("departments = %+v, want 2", stats.ByDepartment)
+	}
+	departments := map[string]float64{}
+	for _, d := range stats.ByDepartment {
+		departments[d.Department] = d.Total
+	}
+	if departments["研发部"] != 20000 || departments["市场部"] != 6500.5 {
+		t.Errorf("department totals = %v, want 研发部 20000.00 and 市场部 6500.50", departments)
+	}
+
+	stats, err = s.GetStatistics("2026-03", "研发部")
+	if err != nil {
+		t.Fatalf("GetStatistics: %v", err)
+	}
+	if stats.Headcount != 2 || stats.TotalPayroll != 20000 || stats.AverageNet != 10000 || len(stats.ByDepartment) != 1 {
+		t.Errorf("filtered = %+v, want 2 employees totalling 20000.00 in one department", stats)
+	}
+
+	if _, err := s.GetStatistics("2026-13", ""); !errors.Is(err, ErrInvalidMonth) {
+		t.Errorf("invalid month: err = %v, want ErrInvalidMonth", err)
+	}
+}
+
+func TestStaleSalaryUpdate(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newSalaryService(db)
+	employee := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	salary := createSalary(t, db, employee.ID, "2026-03", 10000, false)
+
+	loaded := salary.Version
+	first, second := 500.0, 800.0
+	if _, err := s.Update(salary.ID, &UpdateSalaryRequest{Bonus: &first, Version: &loaded}); err != nil {
+		t.Fatalf("first Update: %v", err)
+	}
+	if _, err := s.Update(salary.ID, &UpdateSalaryRequest{Bonus: &second, Version: &loaded}); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("second Update from the same version: err = %v, want ErrConcurrentModification", err)
+	}
+
+	// A writer that loaded the row before the first update loses the race in the repository
+	stale := *salary
+	stale.Bonus = second
+	if err := repository.NewSalaryRepository(db).Update(&stale); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("racing save: err = %v, want repository.ErrVersionConflict", err)
+	}
+}
+
+func TestSalarySummaryForEmployee(t *testing.T) {
+	db := testutil.NewDB(t)
+	s := newSalaryService(db)
+	finance := testutil.CreateEmployee(t, db, "finance", model.RoleFinance)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	create := func(employee *model.Employee, month string, base, bonus, deduction float64, published bool) {
+		t.Helper()
+		_, err := s.Create(finance.ID, &CreateSalaryRequest{EmployeeID: employee.ID, Month: month, BaseSalary: base, Bonus: bonus, Deduction: deduction, Published: published})
+		if err != nil {
+			t.Fatalf("create salary for %s %s: %v", employee.Username, month, err)
+		}
+	}
+	create(alice, "2025-01", 10000, 500, 100.1, true)
+	create(alice, "2025-02", 10000, 0, 100.2, true)
+	create(alice, "2025-03", 12000, 1000.5, 0, true)
+	// Left out: a draft, another year and another employee
+	create(alice, "2025-04", 12000, 0, 0, false)
+	create(alice, "2024-12", 9000, 0, 0, true)
+	create(bob, "2025-01", 8000, 0, 0, true)
+
+	summary, err := s.GetSummaryForEmployee(alice.ID, 2025)
+	if err != nil {
+		t.Fatalf("GetSummaryForEmployee: %v", err)
+	}
+	if summary.BaseSalary != 32000 || summary.Bonus != 1500.5 || summary.Deduction != 200.3 || summary.NetSalary != 33300.2 {
+		t.Errorf("totals = base %v bonus %v deduction %v net %v, want 32000 1500.5 200.3 33300.2", summary.BaseSalary, summary.Bonus, summary.Deduction, summary.NetSalary)
+	}
+	want := []repository.SalaryTotals{
+		{Month: "2025-01", BaseSalary: 10000, Bonus: 500, Deduction: 100.1, NetSalary: 10399.9},
+		{Month: "2025-02", BaseSalary: 10000, Deduction: 100.2, NetSalary: 9899.8},
+		{Month: "2025-03", BaseSalary: 12000, Bonus: 1000.5, NetSalary: 13000.5},
+	}
+	if !slices.Equal(summary.Months, want) {
+		t.Errorf("months = %+v, want %+v", summary.Months, want)
+	}
+	if summary.Currency != "CNY" {
+		t.Errorf("currency = %q, want CNY", summary.Currency)
+	}
+}
+
+func TestSalaryRounding(t *testing.T) {
+	db := testutil.NewDB(t)
+	finance := testutil.CreateEmployee(t, db, "finance", model.RoleFinance)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	bob := testutil.CreateEmployee(t, db, "bob", model.RoleEmployee)
+	s := newSalaryService(db)
+
+	// 0.1 + 0.2 is 0.30000000000000004 as a float
+	salary, err := s.Create(finance.ID, &CreateSalaryRequest{EmployeeID: alice.ID, Month: "2026-03", BaseSalary: 4999.9, Bonus: 0.1, Deduction: 0.3, Components: []SalaryComponentInput{
+		{Name: "餐补", Amount: 0.2},
+	}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if salary.NetSalary != 4999.9 || salary.Currency != "CNY" {
+		t.Errorf("net = %v %s, want exactly 4999.9 CNY", salary.NetSalary, salary.Currency)
+	}
+	bonus := 0.2
+	updated, err := s.Update(salary.ID, &UpdateSalaryRequest{Bonus: &bonus})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.NetSalary != 5000 {
+		t.Errorf("updated net = %v, want exactly 5000", updated.NetSalary)
+	}
+
+	// Half a cent rounds away from zero by default and to the even cent when configured
+	halfEven := NewSalaryService(db, pdf.NewGenerator(""), &config.SalaryConfig{Currency: "CNY", Rounding: config.SalaryRoundingHalfEven})
+	for _, tt := range []struct {
+		s        *SalaryService
+		employee *model.Employee
+		want     float64
+	}{
+		{s, bob, 100.13},
+		{halfEven, alice, 100.12},
+	} {
+		salary, err := tt.s.Create(finance.ID, &CreateSalaryRequest{EmployeeID: tt.employee.ID, Month: "2026-04", BaseSalary: 100.125})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if salary.BaseSalary != tt.want || salary.NetSalary != tt.want {
+			t.Errorf("base %v net %v, want both %v", salary.BaseSalary, salary.NetSalary, tt.want)
+		}
+	}
+}
+
+func TestPayslipDocument(t *testing.T) {
+	db := testutil.NewDB(t)
+	finance := testutil.CreateEmployee(t, db, "finance", model.RoleFinance)
+	alice := testutil.CreateEmployee(t, db, "alice", model.RoleEmployee)
+	s := newSalaryService(db)
+	salary, err := s.Create(finance.ID, &CreateSalaryRequest{EmployeeID: alice.ID, Month: "2026-03", BaseSalary: 10000, Bonus: 1500, Deduction: 300, Components: []SalaryComponentInput{
+		{Name: "社保", Amount: 200, IsDeduction: true},
+	}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	salary.Employee = *alice
+
+	doc := s.payslipDocument(salary)
+	if doc.Title != "工资条 2026-03" {
+		t.Errorf("title = %q, want 工资条 2026-03", doc.Title)
+	}
+	lines := strings.Split(doc.Body, "\n")
+	for _, want := range []string{"员工姓名：" + alice.Name, "币种：CNY", "基本工资：10000.00", "奖金：1500.00", "扣款：300.00", "社保：-200.00", "实发工资：11000.00"} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("payslip lacks line %q:\n%s", want, doc.Body)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import "time"
+
+// location is the time zone calendar days are reckoned in. Timestamps are stored in UTC, but whether
+// a sign-in or booking falls on a given day depends on where the users are, not on the server
+var location = time.Local
+
+// SetTimezone sets the time zone used to decide which calendar day an instant falls on
+// It must be called before any requests are served
+func SetTimezone(loc *time.Location) {
+	location = loc
+}
+
+// Today returns the current calendar day in the configured time zone
+func Today() time.Time {
+	return dateOf(time.Now())
+}
+
+// dateOf returns the calendar day t falls on in the configured time zone
+// Calendar days are represented as midnight UTC, the same value time.Parse gives for a YYYY-MM-DD date,
+// so they match the dates stored in date columns whatever the zone
+func dateOf(t time.Time) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dayStart returns the instant the calendar day t falls on begins in the configured time zone
+func dayStart(t time.Time) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"errors"
+
+	"oa-system/internal/repository"
+)
+
+var (
+	ErrConcurrentModification = errors.New("record was modified by someone else, reload and try again")
+)
+
+// checkVersion rejects an update made against a version other than the stored one; a nil
+// expected version skips the check for clients that do not send one
+func checkVersion(stored int, expected *int) error {
+	if expected != nil && *expected != stored {
+		return ErrConcurrentModification
+	}
+	return nil
+}
+
+// translateVersionConflict maps a lost optimistic lock race in the repository to ErrConcurrentModification
+func translateVersionConflict(err error) error {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return ErrConcurrentModification
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/internal/repository"
+)
+
+var (
+	ErrWorkScheduleNotFound        = errors.New("work schedule not found")
+	ErrWorkScheduleExists          = errors.New("a work schedule is already defined for this department")
+	ErrWorkScheduleInvalidTime     = errors.New("invalid work schedule time, expected HH:MM with start before end")
+	ErrWorkScheduleInvalidWorkdays = errors.New("invalid workdays bitmask, expected 1-127")
+)
+
+// allWorkdays is the workdays bitmask with every day of the week set
+const allWorkdays = 1<<7 - 1
+
+// workSchedule is a parsed schedule with times as offsets from midnight
+type workSchedule struct {
+	start    time.Duration
+	end      time.Duration
+	workdays int
+}
+
+// isWorkday reports whether the weekday is a working day in the schedule
+func (w workSchedule) isWorkday(weekday time.Weekday) bool {
+	return w.workdays&(1<<weekday) != 0
+}
+
+// parseClock parses an HH:MM time into its offset from midnight
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// WorkScheduleService handles per-department working hours
+type WorkScheduleService struct {
+	repo *repository.WorkScheduleRepository
+	db   *gorm.DB
+}
+
+// NewWorkScheduleService creates a new work schedule service
+func NewWorkScheduleService(db *gorm.DB) *WorkScheduleService {
+	return &WorkScheduleService{
+		repo: repository.NewWorkScheduleRepository(db),
+		db:   db,
+	}
+}
+
+// CreateWorkScheduleRequest represents the request to create a department work schedule
+type CreateWorkScheduleRequest struct {
+	Department string `json:"department" binding:"required,max=100"`
+	StartTime  string `json:"start_time" binding:"required"` // HH:MM format
+	EndTime    string `json:"end_time" binding:"required"`   // HH:MM format
+	Workdays   int    `json:"workdays"`                      // bitmask, defaults to Monday through Friday
+}
+
+// UpdateWorkScheduleRequest represents the request to update a department work schedule
+type UpdateWorkScheduleRequest struct {
+	Department string `json:"department" binding:"max=100"`
+	StartTime  string `json:"start_time"` // HH:MM format
+	EndTime    string `json:"end_time"`   // HH:MM format
+	Workdays   *int   `json:"workdays"`
+}
+
+// Create creates a new department work schedule
+func (s *WorkScheduleService) Create(req *CreateWorkScheduleRequest) (*model.WorkSchedule, error) {
+	schedule := &model.WorkSchedule{
+		Department: strings.TrimSpace(req.Department),
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Workdays:   req.Workdays,
+	}
+	if schedule.Workdays == 0 {
+		schedule.Workdays = model.DefaultWorkdays
+	}
+	if err := validateWorkSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.ExistsByDepartment(schedule.Department, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrWorkScheduleExists
+	}
+
+	if err := s.repo.Create(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// List retrieves all department work schedules
+func (s *WorkScheduleService) List() ([]model.WorkSchedule, error) {
+	return s.repo.GetAll()
+}
+
+// Update updates a department work schedule
+func (s *WorkScheduleService) Update(id uint, req *UpdateWorkScheduleRequest) (*model.WorkSchedule, error) {
+	schedule, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWorkScheduleNotFound) {
+			return nil, ErrWorkScheduleNotFound
+		}
+		return nil, err
+	}
+
+	if department := strings.TrimSpace(req.Department); department != "" {
+		schedule.Department = department
+	}
+	if req.StartTime != "" {
+		schedule.StartTime = req.StartTime
+	}
+	if req.EndTime != "" {
+		schedule.EndTime = req.EndTime
+	}
+	if req.Workdays != nil {
+		schedule.Workdays = *req.Workdays
+	}
+	if err := validateWorkSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.repo.ExistsByDepartment(schedule.Department, id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrWorkScheduleExists
+	}
+
+	if err := s.repo.Update(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Delete deletes a department work schedule, returning the department to the global hours
+func (s *WorkScheduleService) Delete(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		if errors.Is(err, repository.ErrWorkScheduleNotFound) {
+			return ErrWorkScheduleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// validateWorkSchedule checks the schedule's times and workdays
+func validateWorkSchedule(schedule *model.WorkSchedule) error {
+	start, err := parseClock(schedule.StartTime)
+	if err != nil {
+		return ErrWorkScheduleInvalidTime
+	}
+	end, err := parseClock(schedule.EndTime)
+	if err != nil || end <= start {
+		return ErrWorkScheduleInvalidTime
+	}
+	if schedule.Workdays < 1 || schedule.Workdays > allWorkdays {
+		return ErrWorkScheduleInvalidWorkdays
+	}
+	return nil
+}
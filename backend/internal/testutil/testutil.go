@@ -0,0 +1,66 @@
+// Package testutil provides database fixtures shared by the package tests
+package testutil
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"oa-system/config"
+	"oa-system/internal/model"
+)
+
+// NewDB opens a fresh in-memory SQLite database with every table migrated,
+// closed when the test ends
+func NewDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	return openDB(t, &config.DatabaseConfig{Driver: "sqlite", SQLitePath: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1})
+}
+
+// NewFileDB opens a fresh SQLite database file in a temporary directory with every table migrated.
+// Unlike NewDB it allows several connections, so tests can run writes against it concurrently
+func NewFileDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "oa.db")
+	return openDB(t, &config.DatabaseConfig{Driver: "sqlite", SQLitePath: path, MaxOpenConns: 4, MaxIdleConns: 4})
+}
+
+// openDB initializes the global database from cfg, migrates it and closes it when the test ends
+func openDB(t testing.TB, cfg *config.DatabaseConfig) *gorm.DB {
+	t.Helper()
+	if err := model.InitDB(cfg); err != nil {
+		t.Fatalf("init db: %v", err)
+	}
+	db := model.GetDB()
+	db.Logger = logger.Discard
+	// The shared in-memory database is dropped once its last connection closes
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db handle: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := model.AutoMigrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// CreateEmployee inserts an active employee with the given username and role
+func CreateEmployee(t testing.TB, db *gorm.DB, username, role string) *model.Employee {
+	t.Helper()
+	employee := &model.Employee{
+		Username:   username,
+		EmployeeNo: "T-" + username,
+		Name:       username,
+		Role:       role,
+		IsActive:   true,
+		HireDate:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := db.Create(employee).Error; err != nil {
+		t.Fatalf("create employee %s: %v", username, err)
+	}
+	return employee
+}
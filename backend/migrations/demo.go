@@ -0,0 +1,230 @@
+package migrations
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"oa-system/internal/model"
+	"oa-system/pkg/password"
+)
+
+// demoPassword is the login password of every demo account
+const demoPassword = "demo123"
+
+// demoEmployee describes a sample account; the supervisor is referenced by username
+type demoEmployee struct {
+	Username   string
+	EmployeeNo string
+	Name       string
+	Department string
+	Position   string
+	Role       string
+	Supervisor string
+}
+
+// demoLeave describes a sample leave request, dated in days from today
+type demoLeave struct {
+	Username  string
+	LeaveType string
+	StartIn   int
+	Days      int
+	Reason    string
+	Status    string
+}
+
+var demoDepartments = []model.Department{
+	{Name: "研发部", Code: "RD"},
+	{Name: "人力资源部", Code: "HR"},
+	{Name: "财务部", Code: "FIN"},
+	{Name: "行政部", Code: "ADM"},
+}
+
+var demoEmployees = []demoEmployee{
+	{Username: "hr", EmployeeNo: "EMP000002", Name: "王芳", Department: "人力资源部", Position: "人事经理", Role: model.RoleHR},
+	{Username: "finance", EmployeeNo: "EMP000003", Name: "李强", Department: "财务部", Position: "财务主管", Role: model.RoleFinance},
+	{Username: "deviceadmin", EmployeeNo: "EMP000004", Name: "赵敏", Department: "行政部", Position: "资产管理员", Role: model.RoleDeviceAdmin},
+	{Username: "supervisor", EmployeeNo: "EMP000005", Name: "陈刚", Department: "研发部", Position: "研发经理", Role: model.RoleSupervisor},
+	{Username: "zhangsan", EmployeeNo: "EMP000006", Name: "张三", Department: "研发部", Position: "后端工程师", Role: model.RoleEmployee, Supervisor: "supervisor"},
+	{Username: "lisi", EmployeeNo: "EMP000007", Name: "李四", Department: "研发部", Position: "前端工程师", Role: model.RoleEmployee, Supervisor: "supervisor"},
+}
+
+var demoDevices = []model.Device{
+	{Name: "MacBook Pro 14", Type: "笔记本电脑", TotalQuantity: 5, AvailableQuantity: 5, Description: "M3 Pro / 18GB / 512GB"},
+	{Name: "Dell U2723QE", Type: "显示器", TotalQuantity: 8, AvailableQuantity: 8, Description: "27英寸 4K 显示器"},
+	{Name: "罗技 MX Keys", Type: "键盘", TotalQuantity: 10, AvailableQuantity: 10},
+	{Name: "USB-C 扩展坞", Type: "配件", TotalQuantity: 20, AvailableQuantity: 20, AutoApprove: true},
+}
+
+var demoMeetingRooms = []model.MeetingRoom{
+	{Name: "长城厅", Capacity: 20, Location: "3楼东侧", Amenities: []model.RoomAmenity{{Name: "projector"}, {Name: "video_conference"}}},
+	{Name: "黄山厅", Capacity: 8, Location: "3楼西侧", Amenities: []model.RoomAmenity{{Name: "whiteboard"}}},
+	{Name: "小会议室", Capacity: 4, Location: "2楼"},
+}
+
+var demoLeaves = []demoLeave{
+	{Username: "zhangsan", LeaveType: model.LeaveTypeAnnual, StartIn: 7, Days: 2, Reason: "家庭旅行", Status: model.LeaveStatusPending},
+	{Username: "lisi", LeaveType: model.LeaveTypeSick, StartIn: -3, Days: 1, Reason: "感冒发烧", Status: model.LeaveStatusApproved},
+	{Username: "zhangsan", LeaveType: model.LeaveTypePersonal, StartIn: 14, Days: 1, Reason: "办理个人事务", Status: model.LeaveStatusPending},
+}
+
+// seedDemoData creates sample departments, employees across every role, devices, meeting rooms
+// and leave requests for development and demo environments. Each record is looked up by its
+// natural key first, so running it again creates nothing new
+func seedDemoData(db *gorm.DB) error {
+	departmentIDs, err := seedDemoDepartments(db)
+	if err != nil {
+		return err
+	}
+	employeeIDs, err := seedDemoEmployees(db, departmentIDs)
+	if err != nil {
+		return err
+	}
+	if err := seedDemoDevices(db); err != nil {
+		return err
+	}
+	if err := seedDemoMeetingRooms(db); err != nil {
+		return err
+	}
+	return seedDemoLeaves(db, employeeIDs)
+}
+
+// seedDemoDepartments creates the demo departments and returns their IDs by name
+func seedDemoDepartments(db *gorm.DB) (map[string]uint, error) {
+	ids := make(map[string]uint, len(demoDepartments))
+	for _, department := range demoDepartments {
+		var existing model.Department
+		err := db.Where("name = ?", department.Name).Attrs(department).FirstOrCreate(&existing).Error
+		if err != nil {
+			return nil, err
+		}
+		ids[existing.Name] = existing.ID
+	}
+	return ids, nil
+}
+
+// seedDemoEmployees creates the demo accounts and returns their IDs by username
+func seedDemoEmployees(db *gorm.DB, departmentIDs map[string]uint) (map[string]uint, error) {
+	hashedPassword, err := password.Hash(demoPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]uint, len(demoEmployees))
+	for _, demo := range demoEmployees {
+		var existing model.Employee
+		err := db.Unscoped().Where("username = ?", demo.Username).First(&existing).Error
+		if err == nil {
+			ids[demo.Username] = existing.ID
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		employee := model.Employee{
+			Username:   demo.Username,
+			EmployeeNo: demo.EmployeeNo,
+			Name:       demo.Name,
+			Department: demo.Department,
+			Position:   demo.Position,
+			Email:      demo.Username + "@company.com",
+			HireDate:   time.Now(),
+			Role:       demo.Role,
+			Password:   hashedPassword,
+			IsActive:   true,
+		}
+		if departmentID, ok := departmentIDs[demo.Department]; ok {
+			employee.DepartmentID = &departmentID
+		}
+		if supervisorID, ok := ids[demo.Supervisor]; ok {
+			employee.SupervisorID = &supervisorID
+		}
+
+		if err := db.Create(&employee).Error; err != nil {
+			return nil, err
+		}
+		ids[demo.Username] = employee.ID
+		log.Printf("Demo account '%s' created successfully (password: %s)", demo.Username, demoPassword)
+	}
+	return ids, nil
+}
+
+// seedDemoDevices creates the demo devices, matched by name
+func seedDemoDevices(db *gorm.DB) error {
+	for _, device := range demoDevices {
+		var count int64
+		db.Model(&model.Device{}).Where("name = ?", device.Name).Count(&count)
+		if count > 0 {
+			continue
+		}
+		if err := db.Create(&device).Error; err != nil {
+			return err
+		}
+		log.Printf("Demo device '%s' created successfully", device.Name)
+	}
+	return nil
+}
+
+// seedDemoMeetingRooms creates the demo meeting rooms with their amenities, matched by name
+func seedDemoMeetingRooms(db *gorm.DB) error {
+	for _, room := range demoMeetingRooms {
+		var count int64
+		db.Model(&model.MeetingRoom{}).Where("name = ?", room.Name).Count(&count)
+		if count > 0 {
+			continue
+		}
+		if err := db.Create(&room).Error; err != nil {
+			return err
+		}
+		log.Printf("Demo meeting room '%s' created successfully", room.Name)
+	}
+	return nil
+}
+
+// seedDemoLeaves creates the demo leave requests, matched by employee and reason
+func seedDemoLeaves(db *gorm.DB, employeeIDs map[string]uint) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, demo := range demoLeaves {
+		employeeID, ok := employeeIDs[demo.Username]
+		if !ok {
+			continue
+		}
+
+		var count int64
+		db.Model(&model.LeaveRequest{}).Where("employee_id = ? AND reason = ?", employeeID, demo.Reason).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		leave := model.LeaveRequest{
+			EmployeeID: employeeID,
+			LeaveType:  demo.LeaveType,
+			StartDate:  today.AddDate(0, 0, demo.StartIn),
+			EndDate:    today.AddDate(0, 0, demo.StartIn+demo.Days-1),
+			Reason:     demo.Reason,
+			Status:     demo.Status,
+		}
+		for day := leave.StartDate; !day.After(leave.EndDate); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+				leave.WorkingDays++
+			}
+		}
+		if demo.Status == model.LeaveStatusApproved {
+			if approverID, ok := employeeIDs["supervisor"]; ok {
+				leave.ApprovedBy = &approverID
+			}
+			leave.ActionedAt = &now
+		}
+
+		if err := db.Create(&leave).Error; err != nil {
+			return err
+		}
+		log.Printf("Demo %s leave for '%s' created successfully", demo.LeaveType, demo.Username)
+	}
+	return nil
+}
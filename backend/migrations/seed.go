@@ -6,19 +6,27 @@ import (
 
 	"gorm.io/gorm"
 
+	"oa-system/internal/middleware"
 	"oa-system/internal/model"
 	"oa-system/pkg/password"
 )
 
 // SeedDatabase seeds the database with initial data
+// Sample data for development and demo environments is only added when demo is set
 // Requirements: 11.1, 9.1
-func SeedDatabase(db *gorm.DB) error {
+func SeedDatabase(db *gorm.DB, demo bool) error {
 	if err := seedSuperAdmin(db); err != nil {
 		return err
 	}
 	if err := seedContractTemplates(db); err != nil {
 		return err
 	}
+	if err := seedRoles(db); err != nil {
+		return err
+	}
+	if demo {
+		return seedDemoData(db)
+	}
 	return nil
 }
 
@@ -154,3 +162,30 @@ func seedContractTemplates(db *gorm.DB) error {
 
 	return nil
 }
+
+// seedRoles populates the roles tables from the built-in role permission map on first run
+func seedRoles(db *gorm.DB) error {
+	var count int64
+	db.Model(&model.Role{}).Count(&count)
+	if count > 0 {
+		log.Println("Roles already exist, skipping...")
+		return nil
+	}
+
+	for name, permissions := range middleware.RolePermissions {
+		role := model.Role{
+			Name:     name,
+			IsSystem: true,
+		}
+		for _, permission := range permissions {
+			role.Permissions = append(role.Permissions, model.RolePermission{Permission: string(permission)})
+		}
+
+		if err := db.Create(&role).Error; err != nil {
+			return err
+		}
+		log.Printf("Role '%s' created successfully", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package emailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrQueueFull   = errors.New("email queue is full")
+	ErrQueueClosed = errors.New("email queue is closed")
+)
+
+// Message is a plain text email to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(msg Message) error
+}
+
+// NopSender discards every message; it is used when SMTP is not configured
+type NopSender struct{}
+
+// Send discards the message
+func (NopSender) Send(Message) error {
+	return nil
+}
+
+// SMTPConfig holds the SMTP server settings
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // leave empty for servers that do not require authentication
+	Password string
+	From     string
+}
+
+// SMTPSender delivers messages through an SMTP server
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPSender creates a sender for the given SMTP server
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+// Send delivers the message as a UTF-8 plain text email
+func (s *SMTPSender) Send(msg Message) error {
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	return smtp.SendMail(addr, auth, s.cfg.From, []string{msg.To}, s.render(msg))
+}
+
+// render builds the raw message with headers, encoding the subject and body so that
+// non-ASCII text survives any mail server
+func (s *SMTPSender) render(msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	// RFC 2045 limits encoded lines to 76 characters
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
+
+// Queue sends messages in the background so that callers never wait on the mail server
+// A Queue is itself a Sender whose Send only enqueues
+type Queue struct {
+	sender   Sender
+	messages chan Message
+	mu       sync.RWMutex
+	closed   bool
+	done     chan struct{}
+}
+
+// NewQueue starts a background worker delivering up to size pending messages through sender
+func NewQueue(sender Sender, size int) *Queue {
+	q := &Queue{
+		sender:   sender,
+		messages: make(chan Message, size),
+		done:     make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// run delivers queued messages until the queue is closed and drained
+func (q *Queue) run() {
+	defer close(q.done)
+	for msg := range q.messages {
+		if err := q.sender.Send(msg); err != nil {
+			log.Printf("Failed to send email to %s: %v", msg.To, err)
+		}
+	}
+}
+
+// Send enqueues the message without blocking, failing if the queue is full or closed
+func (q *Queue) Send(msg Message) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.messages <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting messages and waits for the pending ones to be sent
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.messages)
+	}
+	q.mu.Unlock()
+	<-q.done
+}
@@ -0,0 +1,48 @@
+package emailer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// blockingSender records messages, holding each send until release is closed
+type blockingSender struct {
+	release chan struct{}
+	mu      sync.Mutex
+	sent    []Message
+}
+
+func (b *blockingSender) Send(msg Message) error {
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, msg)
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	sender := &blockingSender{release: make(chan struct{})}
+	q := NewQueue(sender, 1)
+
+	// Sending never waits on the mail server; once the worker and the buffer are busy it fails
+	queued := 0
+	var err error
+	for i := 0; i < 4 && err == nil; i++ {
+		if err = q.Send(Message{To: "a@example.com"}); err == nil {
+			queued++
+		}
+	}
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Send to a full queue: err = %v, want ErrQueueFull", err)
+	}
+
+	close(sender.release)
+	q.Close()
+	if len(sender.sent) != queued {
+		t.Errorf("sent %d messages, want the %d queued", len(sender.sent), queued)
+	}
+	if err := q.Send(Message{To: "c@example.com"}); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Send after Close: err = %v, want ErrQueueClosed", err)
+	}
+}
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -23,8 +24,11 @@ type Claims struct {
 
 // JWTManager handles JWT token operations
 type JWTManager struct {
-	secretKey  []byte
-	expireHour int
+	secretKey    []byte
+	expireHour   int
+	issuer       string
+	audience     string
+	acceptLegacy bool
 }
 
 // NewJWTManager creates a new JWT manager
@@ -35,6 +39,14 @@ func NewJWTManager(secretKey string, expireHour int) *JWTManager {
 	}
 }
 
+// SetIssuerAndAudience makes generated tokens carry the issuer and audience and rejects tokens that do not
+// With acceptLegacy, tokens carrying neither claim, minted before they were introduced, are still accepted
+func (m *JWTManager) SetIssuerAndAudience(issuer, audience string, acceptLegacy bool) {
+	m.issuer = issuer
+	m.audience = audience
+	m.acceptLegacy = acceptLegacy
+}
+
 // GenerateToken generates a new JWT token for a user
 func (m *JWTManager) GenerateToken(userID uint, username, role string, isFirstLogin bool) (string, error) {
 	now := time.Now()
@@ -44,11 +56,15 @@ func (m *JWTManager) GenerateToken(userID uint, username, role string, isFirstLo
 		Role:         role,
 		IsFirstLogin: isFirstLogin,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
 			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(m.expireHour) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
+	if m.audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.audience}
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(m.secretKey)
@@ -75,6 +91,23 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	if !ok || !token.Valid {
 		return nil, ErrInvalidToken
 	}
+	if !m.validIssuerAndAudience(claims) {
+		return nil, ErrInvalidToken
+	}
 
 	return claims, nil
 }
+
+// validIssuerAndAudience checks the token was minted for this service by this service
+func (m *JWTManager) validIssuerAndAudience(claims *Claims) bool {
+	if m.acceptLegacy && claims.Issuer == "" && len(claims.Audience) == 0 {
+		return true
+	}
+	if m.issuer != "" && claims.Issuer != m.issuer {
+		return false
+	}
+	if m.audience != "" && !slices.Contains(claims.Audience, m.audience) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+// mint generates a token for user 1 from another manager sharing the secret, with the given issuer and audience
+func mint(t *testing.T, issuer, audience string) string {
+	t.Helper()
+	minter := NewJWTManager("test-secret", 1)
+	minter.SetIssuerAndAudience(issuer, audience, false)
+	token, err := minter.GenerateToken(1, "alice", "employee", false)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	return token
+}
+
+func TestValidateTokenIssuerAndAudience(t *testing.T) {
+	m := NewJWTManager("test-secret", 1)
+	m.SetIssuerAndAudience("oa-system", "oa-system-api", false)
+
+	claims, err := m.ValidateToken(mint(t, "oa-system", "oa-system-api"))
+	if err != nil {
+		t.Fatalf("ValidateToken with matching claims: %v", err)
+	}
+	if claims.UserID != 1 || claims.Issuer != "oa-system" {
+		t.Errorf("claims = user %d issuer %q, want user 1 issuer oa-system", claims.UserID, claims.Issuer)
+	}
+
+	tests := []struct {
+		name             string
+		issuer, audience string
+	}{
+		{"wrong audience", "oa-system", "billing-api"},
+		{"wrong issuer", "billing", "oa-system-api"},
+		{"legacy token without either", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.ValidateToken(mint(t, tt.issuer, tt.audience)); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("ValidateToken: err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestValidateLegacyToken(t *testing.T) {
+	m := NewJWTManager("test-secret", 1)
+	m.SetIssuerAndAudience("oa-system", "oa-system-api", true)
+
+	// During the rollout a token minted before the claims existed is still accepted, but a mismatch is not
+	if _, err := m.ValidateToken(mint(t, "", "")); err != nil {
+		t.Errorf("legacy token: %v", err)
+	}
+	if _, err := m.ValidateToken(mint(t, "oa-system", "billing-api")); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("wrong audience: err = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,118 @@
+package pagination
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPage     = errors.New("page must be a positive integer")
+	ErrInvalidPageSize = errors.New("page_size must be between 1 and 100")
+	ErrInvalidSort     = errors.New("unknown sort field")
+	ErrInvalidOrder    = errors.New("order must be asc or desc")
+)
+
+const (
+	DefaultPage     = 1
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// SortOptions whitelists the fields a list can be sorted by, mapping each field name accepted
+// from clients to its database column, so that user input never reaches ORDER BY directly
+type SortOptions struct {
+	Columns      map[string]string
+	DefaultSort  string // field used when the request has no sort
+	DefaultOrder string // asc or desc, used when the request has no order
+}
+
+// Params holds the parsed pagination and sorting parameters of a list request
+type Params struct {
+	Page     int
+	PageSize int
+	Column   string // whitelisted database column to sort by
+	Order    string // ASC or DESC
+}
+
+// PageMeta describes the page returned by a list endpoint
+type PageMeta struct {
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// Parse reads page, page_size, sort and order from the query string, rejecting sort fields
+// that are not in the options' whitelist
+func Parse(c *gin.Context, options SortOptions) (Params, error) {
+	params := Params{Page: DefaultPage, PageSize: DefaultPageSize}
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return Params{}, ErrInvalidPage
+		}
+		params.Page = page
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		pageSize, err := strconv.Atoi(pageSizeStr)
+		if err != nil || pageSize < 1 || pageSize > MaxPageSize {
+			return Params{}, ErrInvalidPageSize
+		}
+		params.PageSize = pageSize
+	}
+
+	column, err := options.column(c.DefaultQuery("sort", options.DefaultSort))
+	if err != nil {
+		return Params{}, err
+	}
+	params.Column = column
+
+	switch strings.ToLower(c.DefaultQuery("order", options.DefaultOrder)) {
+	case "asc":
+		params.Order = "ASC"
+	case "desc":
+		params.Order = "DESC"
+	default:
+		return Params{}, ErrInvalidOrder
+	}
+
+	return params, nil
+}
+
+// column resolves a client sort field to its database column
+func (o SortOptions) column(field string) (string, error) {
+	column, ok := o.Columns[field]
+	if !ok {
+		return "", ErrInvalidSort
+	}
+	return column, nil
+}
+
+// Offset returns the number of rows to skip for the page
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// Apply orders the query by the sort column, breaking ties by id, and limits it to the page
+func (p Params) Apply(db *gorm.DB) *gorm.DB {
+	return db.Order(p.Column + " " + p.Order).
+		Order("id " + p.Order).
+		Offset(p.Offset()).
+		Limit(p.PageSize)
+}
+
+// Meta describes the page given the total number of matching rows
+func (p Params) Meta(total int64) PageMeta {
+	totalPages := int((total + int64(p.PageSize) - 1) / int64(p.PageSize))
+	return PageMeta{
+		Total:      total,
+		Page:       p.Page,
+		PageSize:   p.PageSize,
+		TotalPages: totalPages,
+	}
+}
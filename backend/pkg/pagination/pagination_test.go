@@ -0,0 +1,61 @@
+package pagination
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testOptions = SortOptions{
+	Columns:      map[string]string{"name": "name", "hire_date": "hire_date"},
+	DefaultSort:  "hire_date",
+	DefaultOrder: "desc",
+}
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// parseQuery parses the pagination parameters of a request with the given query string
+func parseQuery(query string) (Params, error) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/items?"+query, nil)
+	return Parse(c, testOptions)
+}
+
+func TestParse(t *testing.T) {
+	params, err := parseQuery("")
+	if err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+	if params != (Params{Page: DefaultPage, PageSize: DefaultPageSize, Column: "hire_date", Order: "DESC"}) {
+		t.Errorf("defaults = %+v", params)
+	}
+
+	params, err = parseQuery("page=3&page_size=10&sort=name&order=ASC")
+	if err != nil {
+		t.Fatalf("explicit: %v", err)
+	}
+	if params.Offset() != 20 || params.Column != "name" || params.Order != "ASC" {
+		t.Errorf("explicit = %+v, offset %d", params, params.Offset())
+	}
+	if meta := params.Meta(21); meta.TotalPages != 3 {
+		t.Errorf("pages for 21 rows of 10 = %d, want 3", meta.TotalPages)
+	}
+
+	for query, want := range map[string]error{
+		"sort=password":                ErrInvalidSort,
+		"sort=name%3BDROP%20TABLE%20x": ErrInvalidSort,
+		"order=sideways":               ErrInvalidOrder,
+		"page=0":                       ErrInvalidPage,
+		"page_size=101":                ErrInvalidPageSize,
+	} {
+		if _, err := parseQuery(query); !errors.Is(err, want) {
+			t.Errorf("%s: err = %v, want %v", query, err, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package pdf
+
+import (
+	"embed"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/signintech/gopdf"
+)
+
+var (
+	ErrFontUnavailable = errors.New("pdf font is not available")
+)
+
+// bundledFonts holds the fonts compiled into the binary, see fonts/README.md
+//
+//go:embed fonts/*.ttf
+var bundledFonts embed.FS
+
+const (
+	bundledFontPath = "fonts/Unifont-CJK.ttf"
+	fontFamily      = "cjk"
+	pageMargin      = 56.0
+	titleFontSize   = 18
+	bodyFontSize    = 11
+	lineHeight      = 18.0
+)
+
+// Document represents a plain text document to be rendered as PDF
+type Document struct {
+	Title  string
+	Body   string
+	Footer []string
+}
+
+// Generator renders documents to PDF using a CJK-capable TrueType font, which is
+// embedded in every document so Chinese text renders in any reader
+type Generator struct {
+	fontPath string
+	once     sync.Once
+	fontData []byte
+	fontErr  error
+}
+
+// NewGenerator creates a new PDF generator. A non-empty fontPath overrides the
+// bundled font with the TrueType font at that path
+func NewGenerator(fontPath string) *Generator {
+	return &Generator{fontPath: fontPath}
+}
+
+// loadFont reads the configured or bundled font once and caches its contents
+func (g *Generator) loadFont() ([]byte, error) {
+	g.once.Do(func() {
+		var data []byte
+		var err error
+		if g.fontPath == "" {
+			data, err = bundledFonts.ReadFile(bundledFontPath)
+		} else {
+			data, err = os.ReadFile(g.fontPath)
+		}
+		if err != nil {
+			g.fontErr = ErrFontUnavailable
+			return
+		}
+		g.fontData = data
+	})
+	return g.fontData, g.fontErr
+}
+
+// Render renders the document to an A4 PDF and returns its bytes
+func (g *Generator) Render(doc *Document) ([]byte, error) {
+	fontData, err := g.loadFont()
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin, pageMargin)
+	if err := pdf.AddTTFFontData(fontFamily, fontData); err != nil {
+		return nil, ErrFontUnavailable
+	}
+	pdf.AddPage()
+
+	contentWidth := gopdf.PageSizeA4.W - 2*pageMargin
+	bottom := gopdf.PageSizeA4.H - pageMargin
+
+	// writeLines writes wrapped text, adding pages as needed
+	writeLines := func(text string, size int) error {
+		if err := pdf.SetFont(fontFamily, "", size); err != nil {
+			return err
+		}
+		for _, paragraph := range strings.Split(text, "\n") {
+			lines := []string{""}
+			if strings.TrimSpace(paragraph) != "" {
+				lines, err = pdf.SplitText(paragraph, contentWidth)
+				if err != nil {
+					return err
+				}
+			}
+			for _, line := range lines {
+				if pdf.GetY()+lineHeight > bottom {
+					pdf.AddPage()
+				}
+				pdf.SetX(pageMargin)
+				if err := pdf.Cell(nil, line); err != nil {
+					return err
+				}
+				pdf.Br(lineHeight)
+			}
+		}
+		return nil
+	}
+
+	if doc.Title != "" {
+		if err := writeLines(doc.Title, titleFontSize); err != nil {
+			return nil, err
+		}
+		pdf.Br(lineHeight)
+	}
+	if err := writeLines(doc.Body, bodyFontSize); err != nil {
+		return nil, err
+	}
+	if len(doc.Footer) > 0 {
+		pdf.Br(lineHeight)
+		if err := writeLines(strings.Join(doc.Footer, "\n"), bodyFontSize); err != nil {
+			return nil, err
+		}
+	}
+
+	return pdf.GetBytesPdfReturnErr()
+}
@@ -0,0 +1,44 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	g := NewGenerator("")
+	data, err := g.Render(&Document{
+		Title:  "劳动合同",
+		Body:   "甲方：示例公司\n乙方：张三\n\n" + strings.Repeat("This contract is made between the parties. 本合同自签署之日起生效。", 80),
+		Footer: []string{"签署时间：未签署", "员工签名：张三（E001）"},
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("Render returned an empty document")
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Errorf("document starts with %q, want %%PDF", data[:min(len(data), 8)])
+	}
+	if !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Error("document does not end with the EOF marker")
+	}
+	if pages := bytes.Count(data, []byte("/Type /Page\n")); pages < 2 {
+		t.Errorf("long body rendered on %d page(s), want at least 2", pages)
+	}
+	// The bundled font is embedded as a font file stream rather than referenced by name
+	if !bytes.Contains(data, []byte("/FontFile2")) {
+		t.Error("document does not embed a font file")
+	}
+}
+
+func TestRenderMissingFontOverride(t *testing.T) {
+	g := NewGenerator(filepath.Join(t.TempDir(), "missing.ttf"))
+	if _, err := g.Render(&Document{Title: "合同"}); !errors.Is(err, ErrFontUnavailable) {
+		t.Errorf("Render error = %v, want ErrFontUnavailable", err)
+	}
+}
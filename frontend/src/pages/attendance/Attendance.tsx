@@ -78,9 +78,13 @@ export default function AttendancePage() {
     setIsSigningIn(true);
     try {
       const data = await attendanceService.signIn();
-      setTodayAttendance(data);
+      setTodayAttendance(data.attendance);
       await fetchMonthlyRecords();
-      toast.success('签到成功');
+      if (data.already) {
+        toast.info(data.message);
+      } else {
+        toast.success('签到成功');
+      }
     } catch (error: unknown) {
       const err = error as { response?: { data?: { message?: string } } };
       const message = err.response?.data?.message || '签到失败';
@@ -95,9 +99,13 @@ export default function AttendancePage() {
     setIsSigningOut(true);
     try {
       const data = await attendanceService.signOut();
-      setTodayAttendance(data);
+      setTodayAttendance(data.attendance);
       await fetchMonthlyRecords();
-      toast.success('签退成功');
+      if (data.already) {
+        toast.info(data.message);
+      } else {
+        toast.success('签退成功');
+      }
     } catch (error: unknown) {
       const err = error as { response?: { data?: { message?: string } } };
       const message = err.response?.data?.message || '签退失败';
@@ -1,5 +1,5 @@
 import { useState, useEffect, useCallback } from 'react';
-import { Link } from 'react-router-dom';
+import { Link, useSearchParams } from 'react-router-dom';
 import { toast } from 'sonner';
 import { Laptop, Plus, Pencil, Trash2, Package } from 'lucide-react';
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card';
@@ -21,6 +21,7 @@ import type { Device } from '@/types';
 
 export default function DeviceList() {
   const { employee } = useAuthStore();
+  const [searchParams, setSearchParams] = useSearchParams();
   const [devices, setDevices] = useState<Device[]>([]);
   const [isLoading, setIsLoading] = useState(true);
   const [deleteDialogOpen, setDeleteDialogOpen] = useState(false);
@@ -52,6 +53,21 @@ export default function DeviceList() {
     loadData();
   }, [fetchDevices]);
 
+  // 扫描设备二维码进入时，直接打开该设备的申请对话框
+  useEffect(() => {
+    const scannedId = Number(searchParams.get('device_id'));
+    if (isLoading || !scannedId) return;
+
+    const device = devices.find((d) => d.id === scannedId);
+    if (device) {
+      setSelectedDevice(device);
+      setRequestDialogOpen(true);
+    } else {
+      toast.error('设备不存在');
+    }
+    setSearchParams({}, { replace: true });
+  }, [isLoading, devices, searchParams, setSearchParams]);
+
   // 打开删除确认对话框
   const openDeleteDialog = (device: Device) => {
     setSelectedDevice(device);
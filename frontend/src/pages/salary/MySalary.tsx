@@ -15,10 +15,10 @@ import { salaryService } from '@/services/salary';
 import type { Salary } from '@/types';
 
 // 格式化金额显示
-function formatMoney(amount: number): string {
+function formatMoney(amount: number, currency = 'CNY'): string {
   return new Intl.NumberFormat('zh-CN', {
     style: 'currency',
-    currency: 'CNY',
+    currency,
   }).format(amount);
 }
 
@@ -105,7 +105,7 @@ export default function MySalary() {
               <CardDescription>累计收入</CardDescription>
             </CardHeader>
             <CardContent>
-              <div className="text-2xl font-bold text-green-600">{formatMoney(totalNetSalary)}</div>
+              <div className="text-2xl font-bold text-green-600">{formatMoney(totalNetSalary, salaries[0]?.currency)}</div>
             </CardContent>
           </Card>
           <Card>
@@ -113,7 +113,7 @@ export default function MySalary() {
               <CardDescription>平均月薪</CardDescription>
             </CardHeader>
             <CardContent>
-              <div className="text-2xl font-bold">{formatMoney(avgNetSalary)}</div>
+              <div className="text-2xl font-bold">{formatMoney(avgNetSalary, salaries[0]?.currency)}</div>
             </CardContent>
           </Card>
         </div>
@@ -151,10 +151,10 @@ export default function MySalary() {
                   {salaries.map((salary) => (
                     <TableRow key={salary.id}>
                       <TableCell className="font-medium">{formatMonth(salary.month)}</TableCell>
-                      <TableCell className="text-right">{formatMoney(salary.base_salary)}</TableCell>
-                      <TableCell className="text-right text-green-600">+{formatMoney(salary.bonus)}</TableCell>
-                      <TableCell className="text-right text-red-600">-{formatMoney(salary.deduction)}</TableCell>
-                      <TableCell className="text-right font-bold">{formatMoney(salary.net_salary)}</TableCell>
+                      <TableCell className="text-right">{formatMoney(salary.base_salary, salary.currency)}</TableCell>
+                      <TableCell className="text-right text-green-600">+{formatMoney(salary.bonus, salary.currency)}</TableCell>
+                      <TableCell className="text-right text-red-600">-{formatMoney(salary.deduction, salary.currency)}</TableCell>
+                      <TableCell className="text-right font-bold">{formatMoney(salary.net_salary, salary.currency)}</TableCell>
                       <TableCell>
                         <Button
                           variant="ghost"
@@ -192,19 +192,19 @@ export default function MySalary() {
                 </div>
                 <div className="flex justify-between py-2 border-b">
                   <span className="text-muted-foreground">基本工资</span>
-                  <span>{formatMoney(selectedSalary.base_salary)}</span>
+                  <span>{formatMoney(selectedSalary.base_salary, selectedSalary.currency)}</span>
                 </div>
                 <div className="flex justify-between py-2 border-b">
                   <span className="text-muted-foreground">奖金</span>
-                  <span className="text-green-600">+{formatMoney(selectedSalary.bonus)}</span>
+                  <span className="text-green-600">+{formatMoney(selectedSalary.bonus, selectedSalary.currency)}</span>
                 </div>
                 <div className="flex justify-between py-2 border-b">
                   <span className="text-muted-foreground">扣款</span>
-                  <span className="text-red-600">-{formatMoney(selectedSalary.deduction)}</span>
+                  <span className="text-red-600">-{formatMoney(selectedSalary.deduction, selectedSalary.currency)}</span>
                 </div>
                 <div className="flex justify-between py-3 bg-muted rounded-lg px-3">
                   <span className="font-medium">实发工资</span>
-                  <span className="font-bold text-xl">{formatMoney(selectedSalary.net_salary)}</span>
+                  <span className="font-bold text-xl">{formatMoney(selectedSalary.net_salary, selectedSalary.currency)}</span>
                 </div>
               </div>
               <div className="text-xs text-muted-foreground text-right">
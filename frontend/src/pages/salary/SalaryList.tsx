@@ -26,10 +26,10 @@ import { employeeService } from '@/services/employee';
 import type { Salary, Employee } from '@/types';
 
 // 格式化金额显示
-function formatMoney(amount: number): string {
+function formatMoney(amount: number, currency = 'CNY'): string {
   return new Intl.NumberFormat('zh-CN', {
     style: 'currency',
-    currency: 'CNY',
+    currency,
   }).format(amount);
 }
 
@@ -204,10 +204,10 @@ export default function SalaryList() {
                       <TableCell>{salary.employee?.name || '-'}</TableCell>
                       <TableCell>{salary.employee?.employee_no || '-'}</TableCell>
                       <TableCell>{salary.month}</TableCell>
-                      <TableCell className="text-right">{formatMoney(salary.base_salary)}</TableCell>
-                      <TableCell className="text-right text-green-600">{formatMoney(salary.bonus)}</TableCell>
-                      <TableCell className="text-right text-red-600">{formatMoney(salary.deduction)}</TableCell>
-                      <TableCell className="text-right font-medium">{formatMoney(salary.net_salary)}</TableCell>
+                      <TableCell className="text-right">{formatMoney(salary.base_salary, salary.currency)}</TableCell>
+                      <TableCell className="text-right text-green-600">{formatMoney(salary.bonus, salary.currency)}</TableCell>
+                      <TableCell className="text-right text-red-600">{formatMoney(salary.deduction, salary.currency)}</TableCell>
+                      <TableCell className="text-right font-medium">{formatMoney(salary.net_salary, salary.currency)}</TableCell>
                       <TableCell>{formatDateTime(salary.created_at)}</TableCell>
                       <TableCell>
                         <Button
@@ -264,19 +264,19 @@ export default function SalaryList() {
                 </div>
                 <div className="flex justify-between">
                   <span className="text-muted-foreground">基本工资</span>
-                  <span>{formatMoney(selectedSalary.base_salary)}</span>
+                  <span>{formatMoney(selectedSalary.base_salary, selectedSalary.currency)}</span>
                 </div>
                 <div className="flex justify-between">
                   <span className="text-muted-foreground">奖金</span>
-                  <span className="text-green-600">+{formatMoney(selectedSalary.bonus)}</span>
+                  <span className="text-green-600">+{formatMoney(selectedSalary.bonus, selectedSalary.currency)}</span>
                 </div>
                 <div className="flex justify-between">
                   <span className="text-muted-foreground">扣款</span>
-                  <span className="text-red-600">-{formatMoney(selectedSalary.deduction)}</span>
+                  <span className="text-red-600">-{formatMoney(selectedSalary.deduction, selectedSalary.currency)}</span>
                 </div>
                 <div className="flex justify-between border-t pt-3">
                   <span className="font-medium">实发工资</span>
-                  <span className="font-bold text-lg">{formatMoney(selectedSalary.net_salary)}</span>
+                  <span className="font-bold text-lg">{formatMoney(selectedSalary.net_salary, selectedSalary.currency)}</span>
                 </div>
               </div>
               <div className="text-xs text-muted-foreground text-right">
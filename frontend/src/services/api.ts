@@ -1,5 +1,6 @@
 import axios, { type AxiosError, type InternalAxiosRequestConfig } from 'axios';
 import { useAuthStore } from '@/store/authStore';
+import type { PaginatedResponse } from '@/types';
 
 const api = axios.create({
   baseURL: '/api',
@@ -39,4 +40,21 @@ api.interceptors.response.use(
   }
 );
 
+// 分页接口单页上限，与后端 pagination.MaxPageSize 一致
+const MAX_PAGE_SIZE = 100;
+
+// 逐页读取分页接口，返回全部记录（用于下拉框等需要完整列表的场景）
+export async function fetchAllPages<T>(url: string, params?: Record<string, unknown>): Promise<T[]> {
+  const items: T[] = [];
+  for (let page = 1; ; page++) {
+    const response = await api.get<PaginatedResponse<T>>(url, {
+      params: { ...params, page, page_size: MAX_PAGE_SIZE },
+    });
+    items.push(...response.data.items);
+    if (page >= response.data.total_pages) {
+      return items;
+    }
+  }
+}
+
 export default api;
@@ -2,22 +2,23 @@ import api from './api';
 import type { Attendance } from '@/types';
 
 // 签到/签退响应类型
-interface AttendanceResponse {
+export interface AttendanceResponse {
   attendance: Attendance;
   message: string;
+  already: boolean; // 今日已签到/签退，记录未改变
 }
 
 export const attendanceService = {
   // 签到
-  signIn: async (): Promise<Attendance> => {
+  signIn: async (): Promise<AttendanceResponse> => {
     const response = await api.post<AttendanceResponse>('/attendance/sign-in');
-    return response.data.attendance;
+    return response.data;
   },
 
   // 签退
-  signOut: async (): Promise<Attendance> => {
+  signOut: async (): Promise<AttendanceResponse> => {
     const response = await api.post<AttendanceResponse>('/attendance/sign-out');
-    return response.data.attendance;
+    return response.data;
   },
 
   // 获取考勤记录
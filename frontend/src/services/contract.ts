@@ -1,4 +1,4 @@
-import api from './api';
+import api, { fetchAllPages } from './api';
 import type { Contract, ContractTemplate, ContractTypeValue } from '@/types';
 
 export interface CreateContractRequest {
@@ -22,11 +22,8 @@ export const contractService = {
     return response.data;
   },
 
-  // 获取合同列表（HR）
-  getList: async (): Promise<Contract[]> => {
-    const response = await api.get<Contract[]>('/contracts');
-    return response.data;
-  },
+  // 获取合同列表（HR，接口分页，这里读取全部页）
+  getList: (): Promise<Contract[]> => fetchAllPages<Contract>('/contracts'),
 
   // 获取合同详情
   getById: async (id: number): Promise<Contract> => {
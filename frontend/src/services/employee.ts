@@ -1,4 +1,4 @@
-import api from './api';
+import api, { fetchAllPages } from './api';
 import type { Employee, RoleType } from '@/types';
 
 export interface CreateEmployeeRequest {
@@ -23,11 +23,8 @@ export interface CreateEmployeeResponse {
 }
 
 export const employeeService = {
-  // 获取员工列表
-  getList: async (): Promise<Employee[]> => {
-    const response = await api.get<Employee[]>('/employees');
-    return response.data;
-  },
+  // 获取员工列表（接口分页，这里读取全部页）
+  getList: (): Promise<Employee[]> => fetchAllPages<Employee>('/employees'),
 
   // 获取员工详情
   getById: async (id: number): Promise<Employee> => {
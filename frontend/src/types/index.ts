@@ -196,6 +196,7 @@ export interface Salary {
   bonus: number;
   deduction: number;
   net_salary: number;
+  currency: string;
   created_at: string;
 }
 
@@ -244,4 +245,5 @@ export interface PaginatedResponse<T> {
   total: number;
   page: number;
   page_size: number;
+  total_pages: number;
 }